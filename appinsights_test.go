@@ -0,0 +1,207 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	json "github.com/goccy/go-json"
+)
+
+func TestAppInsightsWriter(t *testing.T) {
+	type envelope struct {
+		Name string `json:"name"`
+		IKey string `json:"iKey"`
+		Tags struct {
+			Role string `json:"ai.cloud.role"`
+		} `json:"tags"`
+		Data struct {
+			BaseType string `json:"baseType"`
+			BaseData struct {
+				Message    string `json:"message"`
+				Severity   int    `json:"severityLevel"`
+				Exceptions []struct {
+					TypeName string `json:"typeName"`
+					Message  string `json:"message"`
+				} `json:"exceptions"`
+			} `json:"baseData"`
+		} `json:"data"`
+	}
+	var (
+		mu        sync.Mutex
+		envelopes []envelope
+		fails     = 1
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path != "/v2/track" {
+			t.Errorf("AppInsightsWriter path = %s", r.URL.Path)
+		}
+		if fails > 0 {
+			fails--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var es []envelope
+		if err := json.Unmarshal(body, &es); err != nil {
+			t.Errorf("AppInsightsWriter body = %s, %v", body, err)
+		}
+		envelopes = append(envelopes, es...)
+	}))
+	defer srv.Close()
+
+	ai := NewAppInsightsWriter("InstrumentationKey=0000-1111;IngestionEndpoint=" + srv.URL + "/").
+		SetHTTPClient(srv.Client()).
+		SetRoleName("api").
+		SetFlushInterval(0)
+	g := New().SetMode(WRITER).DisableTimestamp().SetLineTraceMode(TraceLineNone).
+		SetLevelWriter(WARN, ai.Level(WARN)).
+		SetLevelWriter(ERR, ai.Level(ERR))
+	g.Warn("slow")
+	g.Error("failed")
+	if err := ai.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(envelopes) != 2 {
+		t.Fatalf("AppInsightsWriter envelopes = %+v", envelopes)
+	}
+	msg, exc := envelopes[0], envelopes[1]
+	if msg.Name != "Microsoft.ApplicationInsights.00001111.Message" || msg.IKey != "0000-1111" || msg.Tags.Role != "api" ||
+		msg.Data.BaseType != "MessageData" || msg.Data.BaseData.Message != "[WARN]:\tslow" || msg.Data.BaseData.Severity != 2 {
+		t.Errorf("AppInsightsWriter message = %+v", msg)
+	}
+	if exc.Data.BaseType != "ExceptionData" || exc.Data.BaseData.Severity != 3 ||
+		len(exc.Data.BaseData.Exceptions) != 1 || exc.Data.BaseData.Exceptions[0].Message != "[ERR]:\tfailed" {
+		t.Errorf("AppInsightsWriter exception = %+v", exc)
+	}
+}
+
+func TestAppInsightsWriter_Partial(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		messages [][]string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		var es []struct {
+			Data struct {
+				BaseData struct {
+					Message string `json:"message"`
+				} `json:"baseData"`
+			} `json:"data"`
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &es)
+		var msgs []string
+		for _, e := range es {
+			msgs = append(msgs, e.Data.BaseData.Message)
+		}
+		messages = append(messages, msgs)
+		if len(messages) == 1 {
+			w.WriteHeader(http.StatusPartialContent)
+			io.WriteString(w, `{"itemsReceived":3,"itemsAccepted":1,"errors":[`+
+				`{"index":1,"statusCode":429,"message":"throttled"},{"index":2,"statusCode":400,"message":"invalid"}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	ai := NewAppInsightsWriter("InstrumentationKey=0000-1111;IngestionEndpoint=" + srv.URL).
+		SetHTTPClient(srv.Client()).
+		SetFlushInterval(0)
+	for _, msg := range []string{"accepted", "throttled", "invalid"} {
+		ai.Write([]byte(msg))
+	}
+	if err := ai.Flush(); err != nil {
+		t.Errorf("AppInsightsWriter.Flush() error = %v", err)
+	}
+	if len(messages) != 2 || len(messages[1]) != 1 || messages[1][0] != "throttled" {
+		t.Errorf("AppInsightsWriter requests = %v, want the throttled item retried", messages)
+	}
+}
+
+func TestAppInsightsWriter_CustomLevel(t *testing.T) {
+	Get().AddErrLevel("AI-ALERT", WRITER, false, LevelOptions{Rank: FAIL})
+	defer Get().RemoveLevel("AI-ALERT")
+	lv := Get().TagStringToLevel("AI-ALERT")
+	w := NewAppInsightsWriter("0000-1111")
+	b := string(w.appendEnvelope(nil, batchEntry{data: []byte("down"), level: lv}))
+	if !strings.Contains(b, `"typeName":"AI-ALERT"`) || !strings.Contains(b, `"severityLevel":3`) {
+		t.Errorf("AppInsightsWriter envelope = %s, want the custom level ranked as FAIL", b)
+	}
+	b = string(w.appendEnvelope(nil, batchEntry{data: []byte("plain"), level: UNKNOWN}))
+	if !strings.Contains(b, `"baseType":"MessageData"`) || !strings.Contains(b, `"severityLevel":1`) {
+		t.Errorf("AppInsightsWriter envelope = %s, want Information", b)
+	}
+}
+
+func TestNewAppInsightsWriter(t *testing.T) {
+	w := NewAppInsightsWriter("abcd-ef")
+	if w.ikey != "abcd-ef" || w.endpoint != DefaultAppInsightsEndpoint {
+		t.Errorf("NewAppInsightsWriter() ikey = %s, endpoint = %s", w.ikey, w.endpoint)
+	}
+}
+
+func TestAppInsightsWriter_Shutdown(t *testing.T) {
+	hung := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-hung:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(hung)
+
+	handled := make(chan error, 1)
+	ai := NewAppInsightsWriter("InstrumentationKey=k;IngestionEndpoint=" + srv.URL).
+		SetFlushInterval(0).SetMaxRetries(0).SetTimeout(0).SetErrorHandler(func(err error) {
+		handled <- err
+	})
+	g := New().SetMode(WRITER).SetLevelWriter(ERR, ai.Level(ERR))
+	g.Error("stuck")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := g.Shutdown(ctx); err == nil {
+		t.Error("Shutdown() error = nil, want the canceled batch")
+	}
+	select {
+	case err := <-handled:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("error handler got %v, want DeadlineExceeded", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("the batch is not canceled by Shutdown")
+	}
+}
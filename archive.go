@@ -0,0 +1,449 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultArchiveBucket is the default time range of the archived objects
+	DefaultArchiveBucket = time.Hour
+	// DefaultArchiveMaxSize is the default uncompressed size limit of the archived objects
+	DefaultArchiveMaxSize = 64 << 20
+	// DefaultArchiveMaxPending is the default number of the objects kept in memory while they fail to upload without the spill directory
+	DefaultArchiveMaxPending = 16
+
+	archiveKeyFormat = "2006/01/02/15-04-05"
+)
+
+// ObjectUploader puts the object to S3 compatible storage.
+// It is implemented by a thin adapter of the storage SDK, so that glg does not depend on it
+type ObjectUploader interface {
+	PutObject(ctx context.Context, key string, body []byte) error
+}
+
+// ArchiveWriter is io.Writer which accumulates entries into gzip compressed objects bucketed by time
+// and uploads them by ObjectUploader, the object key is
+// prefix + bucket start (UTC, 2006/01/02/15-04-05) + "/" + hostname-pid-id-sequence.log.gz,
+// the id is random per writer so the writers restarted with the same pid or sharing the prefix never overwrite the objects.
+// The objects are uploaded in order by the background goroutine, so Write does not wait for the storage.
+// Objects failed to upload are kept in memory, or spilled to the local directory set by SetSpillDir, and uploaded again later.
+// Each upload gets the context limited by the timeout, which is canceled as well when the context of CloseContext is done
+type ArchiveWriter struct {
+	uploader   ObjectUploader
+	prefix     string
+	id         string
+	bucket     time.Duration
+	maxSize    int
+	maxPending int
+	spillDir   string
+	timeout    time.Duration
+	onError    func(error)
+	ctx        context.Context
+	cancel     context.CancelFunc
+
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	gz      *gzip.Writer
+	start   time.Time
+	size    int
+	seq     int
+	pending []archiveObject
+	err     error
+	closed  bool
+
+	// uploadMu serializes the uploads of the pending and spilled objects
+	uploadMu sync.Mutex
+
+	once sync.Once
+	kick chan struct{}
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// archiveObject is the compressed object to upload
+type archiveObject struct {
+	key  string
+	body []byte
+}
+
+// NewArchiveWriter returns ArchiveWriter uploading objects of the bucket time range under the key prefix,
+// Close it to upload the current object
+func NewArchiveWriter(uploader ObjectUploader, prefix string, bucket time.Duration) *ArchiveWriter {
+	if bucket <= 0 {
+		bucket = DefaultArchiveBucket
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &ArchiveWriter{
+		uploader:   uploader,
+		prefix:     prefix,
+		id:         archiveWriterID(),
+		bucket:     bucket,
+		maxSize:    DefaultArchiveMaxSize,
+		maxPending: DefaultArchiveMaxPending,
+		timeout:    DefaultSinkTimeout,
+		ctx:        ctx,
+		cancel:     cancel,
+		kick:       make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	w.gz = gzip.NewWriter(&w.buf)
+	return w
+}
+
+// SetMaxSize sets the uncompressed size limit of the object, the object is uploaded before the bucket ends when it exceeds
+func (w *ArchiveWriter) SetMaxSize(size int) *ArchiveWriter {
+	w.mu.Lock()
+	w.maxSize = size
+	w.mu.Unlock()
+	return w
+}
+
+// SetMaxPending sets the number of the objects kept in memory while they fail to upload without the spill directory,
+// the oldest object is discarded and reported to the error handler when it is exceeded, n <= 0 is unlimited
+func (w *ArchiveWriter) SetMaxPending(n int) *ArchiveWriter {
+	w.mu.Lock()
+	w.maxPending = n
+	w.mu.Unlock()
+	return w
+}
+
+// SetSpillDir sets the local directory to keep the objects failed to upload instead of the memory
+func (w *ArchiveWriter) SetSpillDir(dir string) *ArchiveWriter {
+	w.mu.Lock()
+	w.spillDir = dir
+	w.mu.Unlock()
+	return w
+}
+
+// SetTimeout sets the time limit to upload one object, default is DefaultSinkTimeout and 0 is unlimited.
+// The upload timed out fails with the error wrapping context.DeadlineExceeded and the object is kept to upload again
+func (w *ArchiveWriter) SetTimeout(d time.Duration) *ArchiveWriter {
+	w.mu.Lock()
+	w.timeout = d
+	w.mu.Unlock()
+	return w
+}
+
+// SetErrorHandler sets the handler called with the error of each failed upload, including the timeouts
+// and the uploads in the background which have no Write to return the error
+func (w *ArchiveWriter) SetErrorHandler(fn func(error)) *ArchiveWriter {
+	w.mu.Lock()
+	w.onError = fn
+	w.mu.Unlock()
+	return w
+}
+
+// Write appends p to the object of the current time bucket, the finished objects are uploaded in the background.
+// The error of the former background upload is returned once
+func (w *ArchiveWriter) Write(p []byte) (int, error) {
+	w.once.Do(w.run)
+	now := time.Now()
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return 0, ErrWriterClosed
+	}
+	err := w.err
+	w.err = nil
+	var (
+		cut  bool
+		lost error
+	)
+	if bucket := now.Truncate(w.bucket); !w.start.Equal(bucket) {
+		if w.size != 0 {
+			lost, cut = w.enqueue(w.cut(), lost), true
+		}
+		w.start = bucket
+	}
+	if _, werr := w.gz.Write(p); werr != nil {
+		w.mu.Unlock()
+		w.report(lost)
+		return 0, werr
+	}
+	w.size += len(p)
+	if w.maxSize > 0 && w.size >= w.maxSize {
+		lost, cut = w.enqueue(w.cut(), lost), true
+	}
+	w.mu.Unlock()
+	w.report(lost)
+	if err == nil {
+		err = lost
+	}
+	if cut {
+		select {
+		case w.kick <- struct{}{}:
+		default:
+		}
+	}
+	return len(p), err
+}
+
+// Flush uploads the current object, the pending objects and the spilled objects
+func (w *ArchiveWriter) Flush() error {
+	w.mu.Lock()
+	var lost error
+	if w.size != 0 {
+		lost = w.enqueue(w.cut(), nil)
+	}
+	err := errors.Join(w.err, lost)
+	w.err = nil
+	w.mu.Unlock()
+	w.report(lost)
+	if derr := w.drain(); derr != nil {
+		return derr
+	}
+	return err
+}
+
+// Close stops the background upload and uploads the current object
+func (w *ArchiveWriter) Close() error {
+	return w.CloseContext(context.Background())
+}
+
+// CloseContext stops the background upload and uploads the current object until ctx is done,
+// the upload being sent is canceled then and the objects not uploaded are spilled, they are lost without the spill directory.
+// Shutdown closes the writer by it with its context
+func (w *ArchiveWriter) CloseContext(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrWriterClosed
+	}
+	w.closed = true
+	w.mu.Unlock()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			w.cancel()
+		case <-stop:
+		}
+	}()
+	close(w.done)
+	w.wg.Wait()
+	err := w.Flush()
+	w.cancel()
+	return err
+}
+
+// enqueue adds the object to the pending objects, the oldest one is discarded over the limit and its error is returned.
+// It is called with mu locked
+func (w *ArchiveWriter) enqueue(obj archiveObject, lost error) error {
+	w.pending = append(w.pending, obj)
+	if w.maxPending > 0 && w.spillDir == "" && len(w.pending) > w.maxPending {
+		lost = errors.Join(lost, fmt.Errorf("error:\tarchive object %s is discarded, %d objects are pending", w.pending[0].key, w.maxPending))
+		w.pending = append(w.pending[:0], w.pending[1:]...)
+	}
+	return lost
+}
+
+// report passes the error to the error handler, it is called without the locks
+// so the handler can log through the logger writing to the same writer
+func (w *ArchiveWriter) report(err error) {
+	w.mu.Lock()
+	onError := w.onError
+	w.mu.Unlock()
+	if err != nil && onError != nil {
+		onError(err)
+	}
+}
+
+// cut finishes the current object, it is called with mu locked
+func (w *ArchiveWriter) cut() archiveObject {
+	w.gz.Close()
+	w.seq++
+	hostnameOnce.Do(func() {
+		hostname, _ = os.Hostname()
+	})
+	obj := archiveObject{
+		key:  w.prefix + w.start.UTC().Format(archiveKeyFormat) + "/" + hostname + "-" + pid + "-" + w.id + "-" + strconv.Itoa(w.seq) + ".log.gz",
+		body: append([]byte(nil), w.buf.Bytes()...),
+	}
+	w.buf.Reset()
+	w.gz.Reset(&w.buf)
+	w.size = 0
+	return obj
+}
+
+// archiveWriterID returns the random id of the writer in the object keys, the start time is used when random is unavailable
+func archiveWriterID() string {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// run uploads the objects cut by Write and the object whose time bucket ends without new entries,
+// the objects failed to upload are retried at every tick
+func (w *ArchiveWriter) run() {
+	tick := w.bucket / 4
+	if tick > time.Minute {
+		tick = time.Minute
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(tick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-w.kick:
+			case now := <-ticker.C:
+				var lost error
+				w.mu.Lock()
+				if w.size != 0 && !w.start.Equal(now.Truncate(w.bucket)) {
+					lost = w.enqueue(w.cut(), nil)
+					w.err = errors.Join(w.err, lost)
+				}
+				w.mu.Unlock()
+				w.report(lost)
+			}
+			if err := w.drain(); err != nil {
+				w.mu.Lock()
+				w.err = err
+				w.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// put uploads the object with the context limited by the timeout and reports the error to the error handler,
+// the error of the upload timed out or canceled wraps context.DeadlineExceeded or context.Canceled
+func (w *ArchiveWriter) put(key string, body []byte) error {
+	w.mu.Lock()
+	timeout, onError := w.timeout, w.onError
+	w.mu.Unlock()
+	ctx := w.ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	err := w.uploader.PutObject(ctx, key, body)
+	if cerr := ctx.Err(); err != nil && cerr != nil && !errors.Is(err, cerr) {
+		err = fmt.Errorf("error:\tuploading %s is stopped: %w: %v", key, cerr, err)
+	}
+	if err != nil && onError != nil {
+		onError(err)
+	}
+	return err
+}
+
+// drain uploads the pending objects in order and then the spilled objects, it stops at the first failure.
+// The failed object and the rest are spilled to the spill directory, or kept pending in memory without it
+func (w *ArchiveWriter) drain() error {
+	w.uploadMu.Lock()
+	defer w.uploadMu.Unlock()
+	for {
+		w.mu.Lock()
+		if len(w.pending) == 0 {
+			w.mu.Unlock()
+			break
+		}
+		obj := w.pending[0]
+		w.pending = w.pending[1:]
+		w.mu.Unlock()
+		if err := w.put(obj.key, obj.body); err != nil {
+			w.mu.Lock()
+			w.pending = append([]archiveObject{obj}, w.pending...)
+			w.mu.Unlock()
+			if serr := w.spill(); serr != nil {
+				return errors.Join(err, serr)
+			}
+			return err
+		}
+	}
+	return w.uploadSpilled()
+}
+
+// spill moves the pending objects to the spill directory, they are kept pending without it
+func (w *ArchiveWriter) spill() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.spillDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(w.spillDir, 0o700); err != nil {
+		return err
+	}
+	for len(w.pending) != 0 {
+		obj := w.pending[0]
+		if err := os.WriteFile(filepath.Join(w.spillDir, url.PathEscape(obj.key)), obj.body, 0o600); err != nil {
+			return err
+		}
+		w.pending = w.pending[1:]
+	}
+	return nil
+}
+
+// uploadSpilled uploads the spilled objects and removes them, it is called with uploadMu locked
+func (w *ArchiveWriter) uploadSpilled() error {
+	w.mu.Lock()
+	dir := w.spillDir
+	w.mu.Unlock()
+	if dir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, e := range entries {
+		key, err := url.PathUnescape(e.Name())
+		if e.IsDir() || err != nil {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err = w.put(key, body); err != nil {
+			return err
+		}
+		os.Remove(path)
+	}
+	return nil
+}
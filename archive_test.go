@@ -0,0 +1,236 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeUploader struct {
+	mu      sync.Mutex
+	fail    bool
+	objects map[string][]byte
+	keys    []string
+}
+
+func (u *fakeUploader) PutObject(ctx context.Context, key string, body []byte) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.fail {
+		return errors.New("service unavailable")
+	}
+	if u.objects == nil {
+		u.objects = make(map[string][]byte)
+	}
+	u.objects[key] = body
+	u.keys = append(u.keys, key)
+	return nil
+}
+
+// hangUploader blocks until the context of PutObject is done
+type hangUploader struct{}
+
+func (hangUploader) PutObject(ctx context.Context, key string, body []byte) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func gunzip(t *testing.T, body []byte) string {
+	t.Helper()
+	zr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestArchiveWriter(t *testing.T) {
+	u := new(fakeUploader)
+	w := NewArchiveWriter(u, "logs/", time.Hour).SetMaxSize(32)
+	g := New().SetMode(WRITER).SetWriter(w).DisableTimestamp()
+	g.Info("first entry")
+	g.Info("second entry")
+	g.Info("third")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(u.keys) != 2 {
+		t.Fatalf("ArchiveWriter objects = %v", u.keys)
+	}
+	prefix := "logs/" + time.Now().Truncate(time.Hour).UTC().Format(archiveKeyFormat) + "/" + hostname + "-" + pid + "-" + w.id + "-"
+	for i, key := range u.keys {
+		if !strings.HasPrefix(key, prefix) || !strings.HasSuffix(key, ".log.gz") {
+			t.Errorf("ArchiveWriter key[%d] = %s, want prefix %s", i, key, prefix)
+		}
+	}
+	if got, want := gunzip(t, u.objects[u.keys[0]]), "[INFO]:\tfirst entry\n[INFO]:\tsecond entry\n"; got != want {
+		t.Errorf("ArchiveWriter object = %q, want %q", got, want)
+	}
+	if got, want := gunzip(t, u.objects[u.keys[1]]), "[INFO]:\tthird\n"; got != want {
+		t.Errorf("ArchiveWriter object = %q, want %q", got, want)
+	}
+	if _, err := w.Write([]byte("closed")); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("ArchiveWriter.Write() error = %v", err)
+	}
+}
+
+func TestArchiveWriter_DistinctKeys(t *testing.T) {
+	u := new(fakeUploader)
+	for i := 0; i < 2; i++ {
+		w := NewArchiveWriter(u, "logs/", time.Hour)
+		w.Write([]byte("entry\n"))
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(u.keys) != 2 || u.keys[0] == u.keys[1] || len(u.objects) != 2 {
+		t.Errorf("ArchiveWriter keys = %v, want 2 distinct keys", u.keys)
+	}
+}
+
+func TestArchiveWriter_Spill(t *testing.T) {
+	dir := t.TempDir()
+	u := &fakeUploader{fail: true}
+	w := NewArchiveWriter(u, "", time.Hour).SetSpillDir(dir)
+	w.Write([]byte("spilled\n"))
+	if err := w.Flush(); err == nil {
+		t.Error("ArchiveWriter.Flush() error = nil")
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Fatalf("ArchiveWriter spilled files = %v", files)
+	}
+
+	u.fail = false
+	w.Write([]byte("next\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(u.keys) != 2 {
+		t.Fatalf("ArchiveWriter objects = %v", u.keys)
+	}
+	if got := gunzip(t, u.objects[u.keys[1]]); got != "spilled\n" {
+		t.Errorf("ArchiveWriter spilled object = %q", got)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("ArchiveWriter spilled files = %v", files)
+	}
+}
+
+func TestArchiveWriter_Context(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	w := NewArchiveWriter(hangUploader{}, "", time.Hour).SetTimeout(20 * time.Millisecond).SetErrorHandler(func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	})
+	w.Write([]byte("slow\n"))
+	if err := w.Flush(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ArchiveWriter.Flush() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	mu.Lock()
+	if len(errs) != 1 || !errors.Is(errs[0], context.DeadlineExceeded) {
+		t.Errorf("ArchiveWriter error handler got %v", errs)
+	}
+	mu.Unlock()
+
+	dir := t.TempDir()
+	w = NewArchiveWriter(hangUploader{}, "", time.Hour).SetTimeout(0).SetSpillDir(dir)
+	w.Write([]byte("pending\n"))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := w.CloseContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("ArchiveWriter.CloseContext() error = %v, want %v", err, context.Canceled)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Errorf("ArchiveWriter spilled files = %v", files)
+	}
+}
+
+func TestArchiveWriter_Pending(t *testing.T) {
+	u := &fakeUploader{fail: true}
+	w := NewArchiveWriter(u, "", time.Hour).SetMaxSize(1).SetMaxPending(2)
+	for _, entry := range []string{"first\n", "second\n", "third\n"} {
+		w.Write([]byte(entry))
+	}
+	if err := w.Flush(); err == nil {
+		t.Error("ArchiveWriter.Flush() error = nil")
+	}
+
+	u.mu.Lock()
+	u.fail = false
+	u.mu.Unlock()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.Flush()
+		}()
+	}
+	wg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(u.keys) != 2 || len(u.objects) != 2 {
+		t.Fatalf("ArchiveWriter objects = %v, want the 2 objects kept in memory uploaded once", u.keys)
+	}
+	if got := gunzip(t, u.objects[u.keys[0]]) + gunzip(t, u.objects[u.keys[1]]); got != "second\nthird\n" {
+		t.Errorf("ArchiveWriter objects = %q", got)
+	}
+}
+
+func TestArchiveWriter_Background(t *testing.T) {
+	w := NewArchiveWriter(hangUploader{}, "", time.Hour).SetMaxSize(1).SetTimeout(time.Hour)
+	done := make(chan struct{})
+	go func() {
+		w.Write([]byte("first\n"))
+		w.Write([]byte("second\n"))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("ArchiveWriter.Write() waits for the upload")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := w.CloseContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("ArchiveWriter.CloseContext() error = %v, want %v", err, context.Canceled)
+	}
+}
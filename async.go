@@ -0,0 +1,345 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"io"
+	"sort"
+	"sync"
+)
+
+// DefaultAsyncQueueSize is the default size of the asynchronous queues
+const DefaultAsyncQueueSize = 1024
+
+// asyncConfig is the configuration of the level dedicated queue
+type asyncConfig struct {
+	size     int
+	priority int
+}
+
+// asyncItem is the rendered entry waiting to be written
+type asyncItem struct {
+	w     io.Writer
+	data  []byte
+	level LEVEL
+}
+
+type asyncQueue struct {
+	ch       chan asyncItem
+	priority int
+}
+
+// asyncer writes the rendered entries in the background, the queues are drained in the priority order.
+// asyncer is immutable after it is started, reconfiguration replaces it
+type asyncer struct {
+	def      *asyncQueue
+	levels   map[LEVEL]*asyncQueue
+	queues   []*asyncQueue
+	overflow OverflowPolicy
+	drop     func(LEVEL)
+
+	mu     sync.RWMutex
+	closed bool
+
+	wake    chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+
+	pmu     sync.Mutex
+	cond    *sync.Cond
+	pending int
+	err     error
+	stats   sinkStats
+}
+
+// asyncWriter enqueues the writes to w
+type asyncWriter struct {
+	a     *asyncer
+	q     *asyncQueue
+	w     io.Writer
+	level LEVEL
+}
+
+func newAsyncer(size int, levels map[LEVEL]asyncConfig, overflow OverflowPolicy, drop func(LEVEL)) *asyncer {
+	if size <= 0 {
+		size = DefaultAsyncQueueSize
+	}
+	a := &asyncer{
+		def:      &asyncQueue{ch: make(chan asyncItem, size)},
+		levels:   make(map[LEVEL]*asyncQueue, len(levels)),
+		overflow: overflow,
+		drop:     drop,
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	a.cond = sync.NewCond(&a.pmu)
+	a.queues = append(a.queues, a.def)
+	for lv, c := range levels {
+		if c.size <= 0 {
+			c.size = DefaultAsyncQueueSize
+		}
+		q := &asyncQueue{
+			ch:       make(chan asyncItem, c.size),
+			priority: c.priority,
+		}
+		a.levels[lv] = q
+		a.queues = append(a.queues, q)
+	}
+	sort.SliceStable(a.queues, func(i, j int) bool {
+		return a.queues[i].priority > a.queues[j].priority
+	})
+	go a.run()
+	return a
+}
+
+// writer returns the writer enqueueing the entries of the level to w
+func (a *asyncer) writer(level LEVEL, w io.Writer) io.Writer {
+	if w == nil {
+		return nil
+	}
+	q, ok := a.levels[level]
+	if !ok {
+		q = a.def
+	}
+	return asyncWriter{a: a, q: q, w: w, level: level}
+}
+
+// Write implements io.Writer, p is copied to the queue and written in the background
+func (aw asyncWriter) Write(p []byte) (int, error) {
+	aw.a.mu.RLock()
+	defer aw.a.mu.RUnlock()
+	if aw.a.closed {
+		return aw.w.Write(p)
+	}
+	aw.a.add(1)
+	aw.a.enqueue(aw.q, asyncItem{
+		w:     aw.w,
+		data:  append(make([]byte, 0, len(p)), p...),
+		level: aw.level,
+	})
+	select {
+	case aw.a.wake <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+// enqueue sends the item to the queue by the overflow policy
+func (a *asyncer) enqueue(q *asyncQueue, it asyncItem) {
+	if a.overflow == OverflowBlock {
+		q.ch <- it
+		return
+	}
+	for {
+		select {
+		case q.ch <- it:
+			return
+		default:
+		}
+		if a.overflow == OverflowDropNewest {
+			a.add(-1)
+			a.drop(it.level)
+			return
+		}
+		select {
+		case old := <-q.ch:
+			a.add(-1)
+			a.drop(old.level)
+		default:
+		}
+	}
+}
+
+// add adds delta to the number of the pending entries
+func (a *asyncer) add(delta int) {
+	a.pmu.Lock()
+	a.pending += delta
+	if a.pending == 0 {
+		a.cond.Broadcast()
+	}
+	a.pmu.Unlock()
+}
+
+func (a *asyncer) run() {
+	defer close(a.stopped)
+	for {
+		if a.writeNext() {
+			continue
+		}
+		select {
+		case <-a.wake:
+		case <-a.done:
+			for a.writeNext() {
+			}
+			return
+		}
+	}
+}
+
+// writeNext writes an entry of the highest priority queue, it returns false when all the queues are empty
+func (a *asyncer) writeNext() bool {
+	for _, q := range a.queues {
+		select {
+		case it := <-q.ch:
+			n, err := it.w.Write(it.data)
+			a.stats.record(n, err)
+			if err != nil {
+				a.pmu.Lock()
+				if a.err == nil {
+					a.err = err
+				}
+				a.pmu.Unlock()
+			}
+			a.add(-1)
+			return true
+		default:
+		}
+	}
+	return false
+}
+
+// status returns the statuses of the queues, the write results are shared by the queues
+func (a *asyncer) status(g *Glg) map[string]SinkStatus {
+	s := a.stats.status()
+	s.QueueDepth = len(a.def.ch)
+	st := map[string]SinkStatus{"async": s}
+	for lv, q := range a.levels {
+		s.QueueDepth = len(q.ch)
+		tag := lv.String()
+		if l, ok := g.logger.Load(lv); ok {
+			tag = l.tag
+		}
+		st["async:"+tag] = s
+	}
+	return st
+}
+
+// flush waits until the queues are empty, it returns and clears the first write error
+func (a *asyncer) flush() error {
+	a.pmu.Lock()
+	defer a.pmu.Unlock()
+	for a.pending != 0 {
+		a.cond.Wait()
+	}
+	err := a.err
+	a.err = nil
+	return err
+}
+
+// close drains the queues and stops the background writer, the later writes are written synchronously
+func (a *asyncer) close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	a.mu.Unlock()
+	close(a.done)
+	<-a.stopped
+	return a.flush()
+}
+
+func (g *Glg) asyncer() *asyncer {
+	a, _ := g.async.Load().(*asyncer)
+	return a
+}
+
+// EnableAsync writes the entries in the background through the queue of size entries, size <= 0 means DefaultAsyncQueueSize.
+// Entries are rendered synchronously, so callers and timestamps are kept, and are written in the order of the queue priorities.
+// Writes block while the queue is full unless SetAsyncOverflow sets the dropping policy, write errors are returned by Flush
+func (g *Glg) EnableAsync(size int) *Glg {
+	g.asyncMu.Lock()
+	defer g.asyncMu.Unlock()
+	g.asyncSize = size
+	g.restartAsync()
+	return g
+}
+
+// DisableAsync writes the queued entries and disables the asynchronous writing
+func (g *Glg) DisableAsync() *Glg {
+	g.asyncMu.Lock()
+	defer g.asyncMu.Unlock()
+	if a := g.asyncer(); a != nil {
+		g.async.Store((*asyncer)(nil))
+		a.close()
+	}
+	return g
+}
+
+// SetAsyncQueue gives the level the dedicated queue of size entries, queues of higher priority are written first,
+// the shared queue of EnableAsync has the priority 0.
+// e.g. SetAsyncQueue(ERR, 256, 10) keeps errors from waiting behind the burst of debug logs.
+// Asynchronous writing is enabled when it is not enabled yet
+func (g *Glg) SetAsyncQueue(level LEVEL, size, priority int) *Glg {
+	g.asyncMu.Lock()
+	defer g.asyncMu.Unlock()
+	if g.asyncLevels == nil {
+		g.asyncLevels = make(map[LEVEL]asyncConfig)
+	}
+	g.asyncLevels[level] = asyncConfig{
+		size:     size,
+		priority: priority,
+	}
+	g.restartAsync()
+	return g
+}
+
+// SetAsyncOverflow sets the policy of the full asynchronous queues, the dropped entries are counted by Dropped and the metrics hook
+func (g *Glg) SetAsyncOverflow(policy OverflowPolicy) *Glg {
+	g.asyncMu.Lock()
+	defer g.asyncMu.Unlock()
+	g.asyncOverflow = policy
+	if g.asyncer() != nil {
+		g.restartAsync()
+	}
+	return g
+}
+
+// restartAsync replaces the running asyncer by the current configuration, asyncMu must be held
+func (g *Glg) restartAsync() {
+	if old := g.asyncer(); old != nil {
+		old.close()
+	}
+	g.async.Store(newAsyncer(g.asyncSize, g.asyncQueues(), g.asyncOverflow, g.drop))
+}
+
+// Flush waits until the asynchronously queued entries are written and writes the std output buffered by SetStdFlushInterval,
+// it returns the first write error since the last Flush
+func (g *Glg) Flush() error {
+	var err error
+	if a := g.asyncer(); a != nil {
+		err = a.flush()
+	}
+	if serr := g.flushStd(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// Flush waits until the asynchronously queued entries are written
+func Flush() error {
+	return Get().Flush()
+}
@@ -0,0 +1,148 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gateWriter records the lines, the first write blocks until the gate is opened
+type gateWriter struct {
+	mu    sync.Mutex
+	lines []string
+	gate  chan struct{}
+	err   error
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	if w.gate != nil {
+		<-w.gate
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.lines = append(w.lines, strings.TrimSuffix(string(p), "\n"))
+	return len(p), w.err
+}
+
+func (w *gateWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return strings.Join(w.lines, ",")
+}
+
+func TestGlg_EnableAsync(t *testing.T) {
+	w := new(gateWriter)
+	g := New().SetMode(WRITER).SetWriter(w).DisableTimestamp().SetLineFormat("{{msg}}").EnableAsync(0)
+	defer g.DisableAsync()
+	for _, msg := range []string{"a", "b", "c"} {
+		if err := g.Info(msg); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := g.Flush(); err != nil {
+		t.Error(err)
+	}
+	if got, want := w.String(), "a,b,c"; got != want {
+		t.Errorf("EnableAsync() = %s, want %s", got, want)
+	}
+
+	w.err = errors.New("failed")
+	g.Info("d")
+	if err := g.Flush(); err == nil {
+		t.Error("Flush() error = nil")
+	}
+	w.err = nil
+	if err := g.Flush(); err != nil {
+		t.Errorf("Flush() error = %v, want cleared", err)
+	}
+
+	g.DisableAsync()
+	if g.asyncer() != nil {
+		t.Error("DisableAsync() asyncer is running")
+	}
+	g.Info("e")
+	if got, want := w.String(), "a,b,c,d,e"; got != want {
+		t.Errorf("DisableAsync() = %s, want %s", got, want)
+	}
+}
+
+func TestGlg_SetAsyncQueue(t *testing.T) {
+	w := &gateWriter{gate: make(chan struct{})}
+	g := New().SetMode(WRITER).SetWriter(w).DisableTimestamp().SetLineFormat("{{msg}}").
+		EnableAsync(1).SetAsyncQueue(ERR, 4, 10)
+	defer g.DisableAsync()
+
+	g.Debug("d0")
+	// wait for the background writer to block on d0
+	for len(g.asyncer().def.ch) != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	g.Debug("d1")
+	blocked := make(chan struct{})
+	go func() {
+		g.Debug("d2")
+		close(blocked)
+	}()
+	done := make(chan struct{})
+	go func() {
+		g.Error("e0")
+		g.Error("e1")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("errors are blocked by the saturated queue")
+	}
+	select {
+	case <-blocked:
+		t.Fatal("write to the saturated queue is not blocked")
+	default:
+	}
+	close(w.gate)
+	<-blocked
+	if err := g.Flush(); err != nil {
+		t.Error(err)
+	}
+	if got, want := w.String(), "d0,e0,e1,d1,d2"; got != want {
+		t.Errorf("SetAsyncQueue() = %s, want %s", got, want)
+	}
+}
+
+func TestGlg_EnableAsync_JSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().EnableJSON().EnableAsync(0)
+	defer g.DisableAsync()
+	g.Info("hello", F("id", 1))
+	if err := g.Flush(); err != nil {
+		t.Error(err)
+	}
+	if got, want := buf.String(), `{"level":"INFO","detail":"hello","fields":{"id":1}}`+"\n"; got != want {
+		t.Errorf("EnableAsync() = %s, want %s", got, want)
+	}
+}
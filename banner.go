@@ -0,0 +1,108 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultSectionWidth is the width of the rule rendered by Section
+const DefaultSectionWidth = 60
+
+// heading returns the format and the value of Banner and Section, JSON mode outputs the title as it is.
+// The box and the rule are colored Cyan only when PRINT writes to the colored std alone, so that the writers get no escape sequences
+func (g *Glg) heading(title string, banner bool) (string, string) {
+	if g.opts().enableJSON {
+		return "", title
+	}
+	color := Colorless
+	if l, ok := g.logger.Load(PRINT); ok && l.writeMode == writeColorStd {
+		color = Cyan
+	}
+	if !banner {
+		n := DefaultSectionWidth - utf8.RuneCountInString(title) - 4
+		if n < 3 {
+			n = 3
+		}
+		return "%s", color("──") + spw + title + spw + color(strings.Repeat("─", n))
+	}
+	lines := strings.Split(title, rc)
+	var width int
+	for _, l := range lines {
+		if n := utf8.RuneCountInString(l); n > width {
+			width = n
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString(rc)
+	sb.WriteString(color("╭" + strings.Repeat("─", width+2) + "╮"))
+	for _, l := range lines {
+		sb.WriteString(rc)
+		sb.WriteString(color("│"))
+		sb.WriteString(spw)
+		sb.WriteString(l)
+		sb.WriteString(strings.Repeat(spw, width-utf8.RuneCountInString(l)+1))
+		sb.WriteString(color("│"))
+	}
+	sb.WriteString(rc)
+	sb.WriteString(color("╰" + strings.Repeat("─", width+2) + "╯"))
+	return "%s", sb.String()
+}
+
+// Banner outputs Print level title in the box, e.g. for the startup message of CLI
+func (g *Glg) Banner(title string) error {
+	if g.isModeEnable(PRINT) {
+		format, val := g.heading(title, true)
+		return g.out(PRINT, format, val)
+	}
+	return nil
+}
+
+// Section outputs Print level title on the horizontal rule, e.g. for the phases of CLI
+func (g *Glg) Section(title string) error {
+	if g.isModeEnable(PRINT) {
+		format, val := g.heading(title, false)
+		return g.out(PRINT, format, val)
+	}
+	return nil
+}
+
+// Banner outputs Print level title in the box
+func Banner(title string) error {
+	if isModeEnable(PRINT) {
+		format, val := Get().heading(title, true)
+		return Get().out(PRINT, format, val)
+	}
+	return nil
+}
+
+// Section outputs Print level title on the horizontal rule
+func Section(title string) error {
+	if isModeEnable(PRINT) {
+		format, val := Get().heading(title, false)
+		return Get().out(PRINT, format, val)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGlg_Banner(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		json  bool
+		want  string
+	}{
+		{
+			name:  "single line",
+			title: "Starting server v1.2.3",
+			want: "\n" +
+				"╭────────────────────────╮\n" +
+				"│ Starting server v1.2.3 │\n" +
+				"╰────────────────────────╯\n",
+		},
+		{
+			name:  "multi line",
+			title: "glg\nversion 1.2.3",
+			want: "\n" +
+				"╭───────────────╮\n" +
+				"│ glg           │\n" +
+				"│ version 1.2.3 │\n" +
+				"╰───────────────╯\n",
+		},
+		{
+			name:  "json",
+			title: "Starting server",
+			json:  true,
+			want:  `"detail":"Starting server"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf)
+			if tt.json {
+				g.EnableJSON()
+			}
+			if err := g.Banner(tt.title); err != nil {
+				t.Fatalf("Banner() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("Banner() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_Section(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{
+			name:  "short title",
+			title: "Phase 2: compile",
+			want:  "── Phase 2: compile " + strings.Repeat("─", DefaultSectionWidth-20) + "\n",
+		},
+		{
+			name:  "long title",
+			title: strings.Repeat("x", DefaultSectionWidth),
+			want:  "── " + strings.Repeat("x", DefaultSectionWidth) + " ───\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf)
+			if err := g.Section(tt.title); err != nil {
+				t.Fatalf("Section() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("Section() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_Section_Color(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(STD)
+	g.logger.Range(func(lev LEVEL, l *logger) bool {
+		l.std = buf
+		g.logger.Store(lev, l)
+		return true
+	})
+	g.Section("colored")
+	if !strings.Contains(buf.String(), Cyan("──")+" colored ") {
+		t.Errorf("Section() = %q, want colored rule", buf.String())
+	}
+
+	buf.Reset()
+	g.SetMode(BOTH).SetWriter(new(bytes.Buffer)).Section("plain")
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("Section() = %q, want no escape sequences", buf.String())
+	}
+}
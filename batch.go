@@ -0,0 +1,177 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// Batch collects the entries logged by its embedded Glg and writes them by Commit with one write call per destination,
+// e.g. for the summary of the request. The entries are rendered when they are logged
+type Batch struct {
+	*Glg
+	mu    sync.Mutex
+	lines []batchLine
+}
+
+// batchLine is the rendered entry to the destination
+type batchLine struct {
+	w     io.Writer
+	level LEVEL
+	std   bool
+	data  []byte
+}
+
+// batchWriter collects the writes to w into the batch
+type batchWriter struct {
+	b     *Batch
+	w     io.Writer
+	level LEVEL
+	std   bool
+}
+
+// NewBatch returns the batch logging with the configuration and the fields of g
+func (g *Glg) NewBatch() *Batch {
+	b := new(Batch)
+	b.Glg = &Glg{
+		core:   g.core,
+		fields: g.fields,
+		groups: g.groups,
+		batch:  b,
+		to:     g.to,
+	}
+	return b
+}
+
+// Batch calls f with the new batch and commits it, e.g.
+//
+//	g.Batch(func(b *glg.Batch) {
+//		b.Info("request", id)
+//		b.Info("response", status)
+//	})
+func (g *Glg) Batch(f func(b *Batch)) error {
+	b := g.NewBatch()
+	f(b)
+	return b.Commit()
+}
+
+// NewBatch returns the batch of the global instance
+func NewBatch() *Batch {
+	return Get().NewBatch()
+}
+
+// writer returns the writer collecting the writes to w, std is coordinated with the terminal on Commit
+func (b *Batch) writer(level LEVEL, w io.Writer, std bool) io.Writer {
+	if w == nil {
+		return nil
+	}
+	return batchWriter{b: b, w: w, level: level, std: std}
+}
+
+// Write implements io.Writer
+func (bw batchWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+	bw.b.mu.Lock()
+	if f, ok := bw.w.(fanout); ok {
+		// the writers added by AddWriter are the destinations of their own
+		for _, w := range f {
+			bw.b.lines = append(bw.b.lines, batchLine{w: w, level: bw.level, std: bw.std, data: data})
+		}
+	} else {
+		bw.b.lines = append(bw.b.lines, batchLine{w: bw.w, level: bw.level, std: bw.std, data: data})
+	}
+	bw.b.mu.Unlock()
+	return len(p), nil
+}
+
+// Len returns the number of the uncommitted writes to the destinations
+func (b *Batch) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.lines)
+}
+
+// Commit writes the collected entries in the logged order, the entries of each destination are joined into one write.
+// The batch is empty and reusable after Commit, the write errors are joined as WriteError
+func (b *Batch) Commit() error {
+	b.mu.Lock()
+	lines := b.lines
+	b.lines = nil
+	b.mu.Unlock()
+
+	var groups []batchLine
+next:
+	for _, l := range lines {
+		for i := range groups {
+			if groups[i].std == l.std && sameWriter(groups[i].w, l.w) {
+				groups[i].data = append(groups[i].data, l.data...)
+				continue next
+			}
+		}
+		// the data may be shared by the destinations of fanout
+		l.data = append([]byte(nil), l.data...)
+		groups = append(groups, l)
+	}
+
+	var errs []error
+	a := b.asyncer()
+	for _, grp := range groups {
+		w := grp.w
+		if grp.std {
+			w = b.terminal(grp.level, w)
+		}
+		if a != nil {
+			w = a.writer(grp.level, w)
+		}
+		if _, err := w.Write(grp.data); err != nil {
+			errs = append(errs, writeError(grp.w, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sameWriter reports a and b are the same destination without panicking on the uncomparable writers
+func sameWriter(a, b io.Writer) bool {
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb {
+		return false
+	}
+	if fa, ok := a.(fanout); ok {
+		fb := b.(fanout)
+		if len(fa) != len(fb) {
+			return false
+		}
+		for i := range fa {
+			if !sameWriter(fa[i], fb[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return ta.Comparable() && a == b
+}
@@ -0,0 +1,121 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type countWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	c.writes++
+	return c.Buffer.Write(p)
+}
+
+func TestGlg_Batch(t *testing.T) {
+	all, errs := new(countWriter), new(countWriter)
+	g := New().SetMode(WRITER).SetLineTraceMode(TraceLineNone).
+		SetWriter(all).
+		AddLevelWriter(ERR, errs)
+
+	err := g.With(String("request_id", "r1")).Batch(func(b *Batch) {
+		b.Info("request")
+		b.With(String("step", "db")).Warn("slow")
+		b.Error("failed")
+		if all.writes != 0 || b.Len() != 4 {
+			t.Errorf("batch wrote before Commit: writes = %d, Len() = %d", all.writes, b.Len())
+		}
+	})
+	if err != nil {
+		t.Fatalf("Batch() error = %v", err)
+	}
+	if all.writes != 1 || errs.writes != 1 {
+		t.Errorf("writes = %d, %d, want 1 per destination", all.writes, errs.writes)
+	}
+	lines := strings.Split(strings.TrimSpace(all.String()), "\n")
+	want := []string{"[INFO]:\trequest\trequest_id=r1", "[WARN]:\tslow\trequest_id=r1 step=db", "[ERR]:\tfailed\trequest_id=r1"}
+	if len(lines) != len(want) {
+		t.Fatalf("output = %q, want %d lines", all.String(), len(want))
+	}
+	for i, w := range want {
+		if !strings.Contains(lines[i], w) {
+			t.Errorf("line %d = %q, want %q", i, lines[i], w)
+		}
+	}
+	if !strings.Contains(errs.String(), "failed") || strings.Contains(errs.String(), "request\t") {
+		t.Errorf("ERR writer output = %q", errs.String())
+	}
+}
+
+func TestBatch_Commit(t *testing.T) {
+	w := new(countWriter)
+	g := New().SetMode(WRITER).SetWriter(w).SetLineTraceMode(TraceLineNone)
+	b := g.NewBatch()
+	if err := b.Commit(); err != nil || w.writes != 0 {
+		t.Errorf("Commit() of empty batch error = %v, writes = %d", err, w.writes)
+	}
+	b.Info("first")
+	b.Commit()
+	b.Info("second")
+	b.Commit()
+	if w.writes != 2 || b.Len() != 0 {
+		t.Errorf("writes = %d, Len() = %d, want reusable batch", w.writes, b.Len())
+	}
+
+	errBroken := errors.New("broken pipe")
+	b = New().SetMode(WRITER).SetWriter(namedErrWriter{name: "remote", err: errBroken}).NewBatch()
+	b.Info("lost")
+	var we *WriteError
+	if err := b.Commit(); !errors.As(err, &we) || we.Writer != "remote" || !errors.Is(err, errBroken) {
+		t.Errorf("Commit() error = %v, want WriteError of remote", err)
+	}
+}
+
+func TestSameWriter(t *testing.T) {
+	a, b := new(bytes.Buffer), new(bytes.Buffer)
+	tests := []struct {
+		name string
+		x, y interface{ Write([]byte) (int, error) }
+		want bool
+	}{
+		{name: "same pointer", x: a, y: a, want: true},
+		{name: "other pointer", x: a, y: b},
+		{name: "same fanout", x: fanout{a, b}, y: fanout{a, b}, want: true},
+		{name: "other fanout", x: fanout{a, b}, y: fanout{b, a}},
+		{name: "uncomparable", x: namedErrWriter{}, y: fanout{a}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameWriter(tt.x, tt.y); got != tt.want {
+				t.Errorf("sameWriter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,803 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	json "github.com/goccy/go-json"
+)
+
+// Encoder encodes the entry of the level instead of the text and JSON formats, e.g. CBOR or MsgPack.
+// Encode writes the entry to w by one Write call
+type Encoder interface {
+	Encode(w io.Writer, e *Entry) error
+}
+
+// BinaryFormat is the binary encoding of the entries, the entry is the map of
+// "time", "level", "file", "message" and "fields" keys whose fields are nested maps keeping the order of the fields.
+// The encoded entries are self-delimiting, so the stream of entries is read back by NewBinaryReader
+type BinaryFormat struct {
+	name   string
+	append func(b []byte, v binaryValue) []byte
+	decode func(d *binaryDecoder) (interface{}, error)
+}
+
+var (
+	// CBOR encodes the entries in CBOR (RFC 8949), the time is the epoch-based date/time of tag 1
+	CBOR = &BinaryFormat{name: "cbor", append: appendCBOR, decode: decodeCBOR}
+	// MsgPack encodes the entries in MessagePack, the time is the timestamp extension type
+	MsgPack = &BinaryFormat{name: "msgpack", append: appendMsgPack, decode: decodeMsgPack}
+)
+
+// maxBinaryDepth limits the nesting of the decoded values
+const maxBinaryDepth = 64
+
+// ParseBinaryFormat returns the binary format of the name, "cbor" or "msgpack"
+func ParseBinaryFormat(name string) (*BinaryFormat, error) {
+	switch strings.ToLower(name) {
+	case CBOR.name:
+		return CBOR, nil
+	case MsgPack.name, "messagepack":
+		return MsgPack, nil
+	}
+	return nil, fmt.Errorf("error:\tunknown binary format %q", name)
+}
+
+// String returns the name of the format
+func (f *BinaryFormat) String() string {
+	return f.name
+}
+
+// Encode implements Encoder
+func (f *BinaryFormat) Encode(w io.Writer, e *Entry) error {
+	_, err := w.Write(f.Append(make([]byte, 0, 128+len(e.Message)), e))
+	return err
+}
+
+// Append appends the encoded entry to b
+func (f *BinaryFormat) Append(b []byte, e *Entry) []byte {
+	n := 2
+	if !e.Time.IsZero() {
+		n++
+	}
+	if e.Caller != "" {
+		n++
+	}
+	if len(e.Fields) != 0 {
+		n++
+	}
+	b = f.append(b, binaryValue{kind: binaryMap, n: n})
+	if !e.Time.IsZero() {
+		b = f.append(f.append(b, binaryStr("time")), binaryValue{kind: binaryTime, t: e.Time})
+	}
+	b = f.append(f.append(b, binaryStr("level")), binaryStr(e.tag()))
+	if e.Caller != "" {
+		b = f.append(f.append(b, binaryStr("file")), binaryStr(e.Caller))
+	}
+	b = f.append(f.append(b, binaryStr("message")), binaryStr(e.Message))
+	if len(e.Fields) != 0 {
+		b = f.appendFields(f.append(b, binaryStr("fields")), e.Fields, 0)
+	}
+	return b
+}
+
+// appendFields appends the fields as the map, the fields overridden by the later ones are omitted
+// and the groups of the same key are merged like the JSON format
+func (f *BinaryFormat) appendFields(b []byte, fields []Field, depth int) []byte {
+	jf := jsonFields{fields: fields}
+	n := 0
+	for i := range fields {
+		if !jf.overridden(i) {
+			if fields[i].kind != fieldGroup {
+				n++
+			} else if _, ok := jf.mergeGroup(i); ok {
+				n++
+			}
+		}
+	}
+	b = f.append(b, binaryValue{kind: binaryMap, n: n})
+	for i, fd := range fields {
+		if jf.overridden(i) {
+			continue
+		}
+		if fd.kind == fieldGroup {
+			group, ok := jf.mergeGroup(i)
+			if ok {
+				b = f.appendFields(f.append(b, binaryStr(fd.Key)), group.iface.([]Field), depth+1)
+			}
+			continue
+		}
+		b = f.appendField(f.append(b, binaryStr(fd.Key)), fd, depth)
+	}
+	return b
+}
+
+// appendField appends the value of the field, durations are nanoseconds like the JSON format
+func (f *BinaryFormat) appendField(b []byte, fd Field, depth int) []byte {
+	switch fd.kind {
+	case fieldString:
+		return f.append(b, binaryStr(fd.str))
+	case fieldInt, fieldDuration, fieldBytes:
+		return f.append(b, binaryValue{kind: binaryInt, i: fd.num})
+	case fieldUint:
+		return f.append(b, binaryValue{kind: binaryUint, u: uint64(fd.num)})
+	case fieldFloat, fieldPercent:
+		return f.append(b, binaryValue{kind: binaryFloat, f: math.Float64frombits(uint64(fd.num))})
+	case fieldBool:
+		return f.append(b, binaryValue{kind: binaryBool, i: fd.num})
+	case fieldError:
+		if fd.iface == nil {
+			return f.append(b, binaryValue{})
+		}
+		return f.append(b, binaryStr(fd.iface.(error).Error()))
+	}
+	v, _ := resolve(fd.iface, true, 0)
+	return f.appendValue(b, v, depth)
+}
+
+// appendValue appends the value of any type, the types unknown to the encoding are encoded through JSON
+func (f *BinaryFormat) appendValue(b []byte, v interface{}, depth int) []byte {
+	if depth > maxBinaryDepth {
+		return f.append(b, binaryStr(fmt.Sprint(v)))
+	}
+	switch t := v.(type) {
+	case nil:
+		return f.append(b, binaryValue{})
+	case string:
+		return f.append(b, binaryStr(t))
+	case []byte:
+		return f.append(b, binaryValue{kind: binaryBytes, s: string(t)})
+	case bool:
+		if t {
+			return f.append(b, binaryValue{kind: binaryBool, i: 1})
+		}
+		return f.append(b, binaryValue{kind: binaryBool})
+	case int:
+		return f.append(b, binaryValue{kind: binaryInt, i: int64(t)})
+	case int8:
+		return f.append(b, binaryValue{kind: binaryInt, i: int64(t)})
+	case int16:
+		return f.append(b, binaryValue{kind: binaryInt, i: int64(t)})
+	case int32:
+		return f.append(b, binaryValue{kind: binaryInt, i: int64(t)})
+	case int64:
+		return f.append(b, binaryValue{kind: binaryInt, i: t})
+	case uint:
+		return f.append(b, binaryValue{kind: binaryUint, u: uint64(t)})
+	case uint8:
+		return f.append(b, binaryValue{kind: binaryUint, u: uint64(t)})
+	case uint16:
+		return f.append(b, binaryValue{kind: binaryUint, u: uint64(t)})
+	case uint32:
+		return f.append(b, binaryValue{kind: binaryUint, u: uint64(t)})
+	case uint64:
+		return f.append(b, binaryValue{kind: binaryUint, u: t})
+	case float32:
+		return f.append(b, binaryValue{kind: binaryFloat, f: float64(t)})
+	case float64:
+		return f.append(b, binaryValue{kind: binaryFloat, f: t})
+	case time.Time:
+		return f.append(b, binaryValue{kind: binaryTime, t: t})
+	case time.Duration:
+		return f.append(b, binaryValue{kind: binaryInt, i: int64(t)})
+	case Field:
+		return f.appendFields(b, []Field{t}, depth+1)
+	case []Field:
+		return f.appendFields(b, t, depth+1)
+	case structValue:
+		return f.appendFields(b, t, depth+1)
+	case Lazy:
+		rv, _ := resolve(t.value(), true, 0)
+		return f.appendValue(b, rv, depth+1)
+	case []interface{}:
+		b = f.append(b, binaryValue{kind: binaryArray, n: len(t)})
+		for _, e := range t {
+			b = f.appendValue(b, e, depth+1)
+		}
+		return b
+	case []string:
+		b = f.append(b, binaryValue{kind: binaryArray, n: len(t)})
+		for _, s := range t {
+			b = f.append(b, binaryStr(s))
+		}
+		return b
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b = f.append(b, binaryValue{kind: binaryMap, n: len(keys)})
+		for _, k := range keys {
+			b = f.appendValue(f.append(b, binaryStr(k)), t[k], depth+1)
+		}
+		return b
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return f.append(b, binaryValue{kind: binaryInt, i: n})
+		}
+		fd := jsonField("", t)
+		return f.appendField(b, fd, depth)
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return f.append(b, binaryStr(fmt.Sprint(v)))
+	}
+	if bytes.HasPrefix(raw, []byte("{")) {
+		if fields, err := decodeFields(raw); err == nil {
+			return f.appendFields(b, fields, depth+1)
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var jv interface{}
+	if err = dec.Decode(&jv); err != nil {
+		return f.append(b, binaryStr(string(raw)))
+	}
+	return f.appendValue(b, jv, depth+1)
+}
+
+type binaryKind uint8
+
+const (
+	binaryNil binaryKind = iota
+	binaryBool
+	binaryInt
+	binaryUint
+	binaryFloat
+	binaryString
+	binaryBytes
+	binaryTime
+	binaryArray
+	binaryMap
+)
+
+// binaryValue is the scalar value or the header of the array or the map written by the encoding
+type binaryValue struct {
+	kind binaryKind
+	i    int64
+	u    uint64
+	f    float64
+	s    string
+	t    time.Time
+	n    int
+}
+
+func binaryStr(s string) binaryValue {
+	return binaryValue{kind: binaryString, s: s}
+}
+
+// appendCBOR appends v in CBOR
+func appendCBOR(b []byte, v binaryValue) []byte {
+	switch v.kind {
+	case binaryBool:
+		if v.i != 0 {
+			return append(b, 0xf5)
+		}
+		return append(b, 0xf4)
+	case binaryInt:
+		if v.i < 0 {
+			return appendCBORHead(b, 1, uint64(-(v.i + 1)))
+		}
+		return appendCBORHead(b, 0, uint64(v.i))
+	case binaryUint:
+		return appendCBORHead(b, 0, v.u)
+	case binaryFloat:
+		return binary.BigEndian.AppendUint64(append(b, 0xfb), math.Float64bits(v.f))
+	case binaryString:
+		return append(appendCBORHead(b, 3, uint64(len(v.s))), v.s...)
+	case binaryBytes:
+		return append(appendCBORHead(b, 2, uint64(len(v.s))), v.s...)
+	case binaryTime:
+		b = append(b, 0xc1)
+		if v.t.Nanosecond() == 0 {
+			return appendCBOR(b, binaryValue{kind: binaryInt, i: v.t.Unix()})
+		}
+		return appendCBOR(b, binaryValue{kind: binaryFloat, f: float64(v.t.UnixNano()) / 1e9})
+	case binaryArray:
+		return appendCBORHead(b, 4, uint64(v.n))
+	case binaryMap:
+		return appendCBORHead(b, 5, uint64(v.n))
+	}
+	return append(b, 0xf6)
+}
+
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major|27), n)
+}
+
+// appendMsgPack appends v in MessagePack
+func appendMsgPack(b []byte, v binaryValue) []byte {
+	switch v.kind {
+	case binaryBool:
+		if v.i != 0 {
+			return append(b, 0xc3)
+		}
+		return append(b, 0xc2)
+	case binaryInt:
+		switch {
+		case v.i >= 0:
+			return appendMsgPack(b, binaryValue{kind: binaryUint, u: uint64(v.i)})
+		case v.i >= -32:
+			return append(b, byte(v.i))
+		case v.i >= math.MinInt8:
+			return append(b, 0xd0, byte(v.i))
+		case v.i >= math.MinInt16:
+			return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(v.i))
+		case v.i >= math.MinInt32:
+			return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(v.i))
+		}
+		return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v.i))
+	case binaryUint:
+		switch {
+		case v.u <= 0x7f:
+			return append(b, byte(v.u))
+		case v.u <= math.MaxUint8:
+			return append(b, 0xcc, byte(v.u))
+		case v.u <= math.MaxUint16:
+			return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(v.u))
+		case v.u <= math.MaxUint32:
+			return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(v.u))
+		}
+		return binary.BigEndian.AppendUint64(append(b, 0xcf), v.u)
+	case binaryFloat:
+		return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v.f))
+	case binaryString:
+		n := len(v.s)
+		switch {
+		case n < 32:
+			b = append(b, 0xa0|byte(n))
+		case n <= math.MaxUint8:
+			b = append(b, 0xd9, byte(n))
+		case n <= math.MaxUint16:
+			b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+		default:
+			b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+		}
+		return append(b, v.s...)
+	case binaryBytes:
+		n := len(v.s)
+		switch {
+		case n <= math.MaxUint8:
+			b = append(b, 0xc4, byte(n))
+		case n <= math.MaxUint16:
+			b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+		default:
+			b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+		}
+		return append(b, v.s...)
+	case binaryTime:
+		sec, nsec := v.t.Unix(), uint64(v.t.Nanosecond())
+		switch {
+		case sec >= 0 && sec <= math.MaxUint32 && nsec == 0:
+			return binary.BigEndian.AppendUint32(append(b, 0xd6, 0xff), uint32(sec))
+		case sec >= 0 && sec < 1<<34:
+			return binary.BigEndian.AppendUint64(append(b, 0xd7, 0xff), nsec<<34|uint64(sec))
+		}
+		b = binary.BigEndian.AppendUint32(append(b, 0xc7, 12, 0xff), uint32(nsec))
+		return binary.BigEndian.AppendUint64(b, uint64(sec))
+	case binaryArray:
+		switch {
+		case v.n < 16:
+			return append(b, 0x90|byte(v.n))
+		case v.n <= math.MaxUint16:
+			return binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(v.n))
+		}
+		return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(v.n))
+	case binaryMap:
+		switch {
+		case v.n < 16:
+			return append(b, 0x80|byte(v.n))
+		case v.n <= math.MaxUint16:
+			return binary.BigEndian.AppendUint16(append(b, 0xde), uint16(v.n))
+		}
+		return binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(v.n))
+	}
+	return append(b, 0xc0)
+}
+
+// BinaryReader reads the entries encoded by BinaryFormat back
+type BinaryReader struct {
+	d binaryDecoder
+	f *BinaryFormat
+}
+
+// NewBinaryReader returns BinaryReader reading the entries of the format from r
+func NewBinaryReader(r io.Reader, f *BinaryFormat) *BinaryReader {
+	return &BinaryReader{
+		d: binaryDecoder{r: bufio.NewReader(r)},
+		f: f,
+	}
+}
+
+// Read returns the next entry, or io.EOF at the end of the stream.
+// The values which are not the map of the entry are returned as the message of UNKNOWN entries
+func (r *BinaryReader) Read() (*Entry, error) {
+	if _, err := r.d.r.Peek(1); err != nil {
+		return nil, err
+	}
+	r.d.depth = 0
+	v, err := r.f.decode(&r.d)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("error:\tdecoding %s entry: %w", r.f.name, err)
+	}
+	fields, ok := v.([]Field)
+	if !ok {
+		return &Entry{Level: UNKNOWN, Message: detailMessage(v)}, nil
+	}
+	return entryOf(fields), nil
+}
+
+var errBinaryDepth = errors.New("too deeply nested")
+
+// binaryDecoder reads the values, maps are decoded as []Field keeping the order of the keys
+type binaryDecoder struct {
+	r     *bufio.Reader
+	depth int
+}
+
+func (d *binaryDecoder) readN(n uint64) ([]byte, error) {
+	if n > maxReadLine {
+		return nil, fmt.Errorf("%d bytes value exceeds the limit", n)
+	}
+	b := make([]byte, n)
+	_, err := io.ReadFull(d.r, b)
+	return b, err
+}
+
+func (d *binaryDecoder) uint(size int) (uint64, error) {
+	b, err := d.readN(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n, nil
+}
+
+// container decodes n values by decode, as []Field when isMap
+func (d *binaryDecoder) container(n uint64, isMap bool, decode func(*binaryDecoder) (interface{}, error)) (interface{}, error) {
+	if d.depth++; d.depth > maxBinaryDepth {
+		return nil, errBinaryDepth
+	}
+	defer func() { d.depth-- }()
+	if n > maxReadLine {
+		return nil, fmt.Errorf("%d items exceed the limit", n)
+	}
+	if !isMap {
+		vals := make([]interface{}, 0, minUint64(n, 1024))
+		for i := uint64(0); i < n; i++ {
+			v, err := decode(d)
+			if err != nil {
+				return nil, err
+			}
+			vals = append(vals, v)
+		}
+		return vals, nil
+	}
+	fields := make([]Field, 0, minUint64(n, 1024))
+	for i := uint64(0); i < n; i++ {
+		k, err := decode(d)
+		if err != nil {
+			return nil, err
+		}
+		v, err := decode(d)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			key = fmt.Sprint(k)
+		}
+		fields = append(fields, binaryField(key, v))
+	}
+	return fields, nil
+}
+
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// binaryField types the decoded value
+func binaryField(key string, v interface{}) Field {
+	switch t := v.(type) {
+	case []Field:
+		return Group(key, t...)
+	case string:
+		return String(key, t)
+	case int64:
+		return Int64(key, t)
+	case uint64:
+		return Uint64(key, t)
+	case float64:
+		return Float64(key, t)
+	case bool:
+		return Bool(key, t)
+	}
+	return F(key, v)
+}
+
+// decodeCBOR decodes the CBOR value of definite length
+func decodeCBOR(d *binaryDecoder) (interface{}, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	major, info := c>>5, c&0x1f
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22, 23:
+			return nil, nil
+		case 25:
+			n, err := d.uint(2)
+			return halfFloat(uint16(n)), err
+		case 26:
+			n, err := d.uint(4)
+			return float64(math.Float32frombits(uint32(n))), err
+		case 27:
+			n, err := d.uint(8)
+			return math.Float64frombits(n), err
+		}
+		return nil, fmt.Errorf("unsupported CBOR simple value 0x%02x", c)
+	}
+	var n uint64
+	switch {
+	case info < 24:
+		n = uint64(info)
+	case info <= 27:
+		if n, err = d.uint(1 << (info - 24)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported CBOR length 0x%02x", c)
+	}
+	switch major {
+	case 0:
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+	case 1:
+		if n > math.MaxInt64 {
+			return nil, errors.New("CBOR negative integer overflows int64")
+		}
+		return -1 - int64(n), nil
+	case 2:
+		return d.readN(n)
+	case 3:
+		b, err := d.readN(n)
+		return string(b), err
+	case 4:
+		return d.container(n, false, decodeCBOR)
+	case 5:
+		return d.container(n, true, decodeCBOR)
+	}
+	// tags, the date/time tags are decoded as time.Time and the others as the tagged value
+	if d.depth++; d.depth > maxBinaryDepth {
+		return nil, errBinaryDepth
+	}
+	v, err := decodeCBOR(d)
+	d.depth--
+	if err != nil {
+		return nil, err
+	}
+	switch n {
+	case 0:
+		if s, ok := v.(string); ok {
+			return time.Parse(time.RFC3339Nano, s)
+		}
+	case 1:
+		switch t := v.(type) {
+		case int64:
+			return time.Unix(t, 0), nil
+		case float64:
+			sec, frac := math.Modf(t)
+			return time.Unix(int64(sec), int64(math.Round(frac*1e6))*1e3), nil
+		}
+	}
+	return v, nil
+}
+
+// halfFloat converts IEEE 754 half precision to float64
+func halfFloat(h uint16) float64 {
+	exp, frac := int(h>>10&0x1f), float64(h&0x3ff)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(frac, -24)
+	case 0x1f:
+		if frac == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(frac+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+// decodeMsgPack decodes the MessagePack value
+func decodeMsgPack(d *binaryDecoder) (interface{}, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.container(uint64(c&0x0f), true, decodeMsgPack)
+	case c&0xf0 == 0x90:
+		return d.container(uint64(c&0x0f), false, decodeMsgPack)
+	case c&0xe0 == 0xa0:
+		b, err := d.readN(uint64(c & 0x1f))
+		return string(b), err
+	}
+	var size int
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xd0, 0xd9, 0xc4, 0xc7, 0xd4:
+		size = 1
+	case 0xcd, 0xd1, 0xda, 0xc5, 0xdc, 0xde, 0xc8, 0xd5:
+		size = 2
+	case 0xce, 0xd2, 0xdb, 0xc6, 0xdd, 0xdf, 0xc9, 0xca, 0xd6:
+		size = 4
+	case 0xcf, 0xd3, 0xcb, 0xd7:
+		size = 8
+	case 0xd8:
+		size = 16
+	default:
+		return nil, fmt.Errorf("unsupported MessagePack type 0x%02x", c)
+	}
+	if c >= 0xd4 && c <= 0xd8 {
+		// fixext, the size is the length of the data after the type
+		return d.ext(uint64(size))
+	}
+	n, err := d.uint(size)
+	if err != nil {
+		return nil, err
+	}
+	switch c {
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+	case 0xd0:
+		return int64(int8(n)), nil
+	case 0xd1:
+		return int64(int16(n)), nil
+	case 0xd2:
+		return int64(int32(n)), nil
+	case 0xd3:
+		return int64(n), nil
+	case 0xca:
+		return float64(math.Float32frombits(uint32(n))), nil
+	case 0xcb:
+		return math.Float64frombits(n), nil
+	case 0xd9, 0xda, 0xdb:
+		b, err := d.readN(n)
+		return string(b), err
+	case 0xc4, 0xc5, 0xc6:
+		return d.readN(n)
+	case 0xdc, 0xdd:
+		return d.container(n, false, decodeMsgPack)
+	case 0xde, 0xdf:
+		return d.container(n, true, decodeMsgPack)
+	}
+	return d.ext(n)
+}
+
+// ext decodes the extension of n bytes data, the timestamp is decoded as time.Time and the others as []byte
+func (d *binaryDecoder) ext(n uint64) (interface{}, error) {
+	typ, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	b, err := d.readN(n)
+	if err != nil || int8(typ) != -1 {
+		return b, err
+	}
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(b)), 0), nil
+	case 8:
+		v := binary.BigEndian.Uint64(b)
+		return time.Unix(int64(v&(1<<34-1)), int64(v>>34)), nil
+	case 12:
+		return time.Unix(int64(binary.BigEndian.Uint64(b[4:])), int64(binary.BigEndian.Uint32(b))), nil
+	}
+	return b, nil
+}
+
+// SetEncoder encodes the entries of all the levels by enc instead of the text and JSON formats, nil restores them.
+// The colors, the layouts and the multi-line handling of the text format are not applied to the encoded entries
+func (g *Glg) SetEncoder(enc Encoder) *Glg {
+	g.updateLoggers(func(_ LEVEL, l *logger) {
+		l.encoder = enc
+	})
+	return g
+}
+
+// SetLevelEncoder encodes the entries of the level by enc, nil restores the text and JSON formats
+func (g *Glg) SetLevelEncoder(lv LEVEL, enc Encoder) *Glg {
+	g.updateLogger(lv, func(l *logger) {
+		l.encoder = enc
+	})
+	return g
+}
+
+// SetEncoder encodes the entries of all the levels of the global instance by enc
+func SetEncoder(enc Encoder) *Glg {
+	return Get().SetEncoder(enc)
+}
+
+// SetLevelEncoder encodes the entries of the level of the global instance by enc
+func SetLevelEncoder(lv LEVEL, enc Encoder) *Glg {
+	return Get().SetLevelEncoder(lv, enc)
+}
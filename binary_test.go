@@ -0,0 +1,186 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBinaryFormat(t *testing.T) {
+	ts := time.Date(2026, 3, 1, 12, 30, 45, 123456000, time.UTC)
+	type payload struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	for _, f := range []*BinaryFormat{CBOR, MsgPack} {
+		t.Run(f.String(), func(t *testing.T) {
+			e := &Entry{
+				Time:    ts,
+				Level:   WARN,
+				Caller:  "main.go:10",
+				Message: "disk \xe3\x81\x82 low",
+				Fields: []Field{
+					String("s", "v"),
+					Int("neg", -70000),
+					Int64("min", math.MinInt64),
+					Uint64("max", math.MaxUint64),
+					Float64("f", 1.5),
+					Bool("ok", true),
+					Dur("d", time.Second),
+					Err(errors.New("failed")),
+					F("nil", nil),
+					F("bytes", []byte{1, 2}),
+					F("list", []interface{}{1, "a"}),
+					F("payload", payload{ID: 1, Name: "n"}),
+					F("at", ts),
+					String("s", "overridden"),
+					Group("g", Int("a", 1)),
+					Group("g", Int("b", 2)),
+				},
+			}
+			buf := new(bytes.Buffer)
+			if err := f.Encode(buf, e); err != nil {
+				t.Fatal(err)
+			}
+			f.Encode(buf, &Entry{Level: INFO, Message: strings.Repeat("x", 70000)})
+
+			r := NewBinaryReader(buf, f)
+			got, err := r.Read()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Time.Equal(ts) || got.Level != WARN || got.Tag != "WARN" || got.Caller != e.Caller || got.Message != e.Message {
+				t.Errorf("Read() = %+v", got)
+			}
+			want := "neg=-70000 min=-9223372036854775808 max=18446744073709551615 f=1.5 ok=true d=1000000000 " +
+				"error=failed nil=<nil> bytes=[1 2] list=[1 a] payload={id=1 name=n} at=" + ts.Local().String() + " s=overridden g={a=1 b=2}"
+			if s := fieldsString(got.Fields); s != want {
+				t.Errorf("Read() fields = %s, want %s", s, want)
+			}
+			if got, err = r.Read(); err != nil || len(got.Message) != 70000 || !got.Time.IsZero() {
+				t.Errorf("Read() = %v, %v", got, err)
+			}
+			if _, err = r.Read(); err != io.EOF {
+				t.Errorf("Read() error = %v, want EOF", err)
+			}
+		})
+	}
+}
+
+// fieldsString renders the decoded fields, nested groups in braces
+func fieldsString(fields []Field) string {
+	s := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if f.kind == fieldGroup {
+			s = append(s, f.Key+"={"+fieldsString(f.iface.([]Field))+"}")
+			continue
+		}
+		v := f.Value()
+		if vs, ok := v.([]interface{}); ok {
+			parts := make([]string, len(vs))
+			for i, e := range vs {
+				parts[i] = string(Field{iface: e}.appendText(nil, false))
+			}
+			s = append(s, f.Key+"=["+strings.Join(parts, " ")+"]")
+			continue
+		}
+		s = append(s, f.Key+"="+string(f.appendText(nil, false)))
+	}
+	return strings.Join(s, " ")
+}
+
+func TestBinaryFormat_Wire(t *testing.T) {
+	e := &Entry{Time: time.Unix(1, 0), Level: INFO, Message: "m"}
+	tests := []struct {
+		f    *BinaryFormat
+		want []byte
+	}{
+		{CBOR, []byte("\xa3\x64time\xc1\x01\x65level\x64INFO\x67message\x61m")},
+		{MsgPack, []byte("\x83\xa4time\xd6\xff\x00\x00\x00\x01\xa5level\xa4INFO\xa7message\xa1m")},
+	}
+	for _, tt := range tests {
+		if got := tt.f.Append(nil, e); !bytes.Equal(got, tt.want) {
+			t.Errorf("%s Append() = %x, want %x", tt.f, got, tt.want)
+		}
+	}
+}
+
+func TestBinaryReader_Error(t *testing.T) {
+	for _, tt := range []struct {
+		f    *BinaryFormat
+		data string
+	}{
+		{CBOR, "\xa1\x61"},
+		{CBOR, "\x5f"},
+		{CBOR, "\x7b\xff\xff\xff\xff\xff\xff\xff\xff"},
+		{CBOR, strings.Repeat("\x81", 100) + "\x00"},
+		{MsgPack, "\x81\xa1"},
+		{MsgPack, "\xc1"},
+		{MsgPack, "\xdb\xff\xff\xff\xff"},
+	} {
+		if _, err := NewBinaryReader(strings.NewReader(tt.data), tt.f).Read(); err == nil || err == io.EOF {
+			t.Errorf("%s Read(%x) error = %v", tt.f, tt.data, err)
+		}
+	}
+	e, err := NewBinaryReader(strings.NewReader("\x63abc"), CBOR).Read()
+	if err != nil || e.Level != UNKNOWN || e.Message != "abc" {
+		t.Errorf("Read() of the non-map value = %+v, %v", e, err)
+	}
+}
+
+func TestParseBinaryFormat(t *testing.T) {
+	if f, err := ParseBinaryFormat("CBOR"); f != CBOR || err != nil {
+		t.Errorf("ParseBinaryFormat(CBOR) = %v, %v", f, err)
+	}
+	if f, err := ParseBinaryFormat("msgpack"); f != MsgPack || err != nil {
+		t.Errorf("ParseBinaryFormat(msgpack) = %v, %v", f, err)
+	}
+	if _, err := ParseBinaryFormat("avro"); err == nil {
+		t.Error("ParseBinaryFormat(avro) error = nil")
+	}
+}
+
+func TestGlg_SetEncoder(t *testing.T) {
+	bin, text := new(bytes.Buffer), new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetLineTraceMode(TraceLineNone).EnableJSON().
+		SetLevelWriter(INFO, bin).SetLevelWriter(WARN, text).
+		SetEncoder(MsgPack).SetLevelEncoder(WARN, nil)
+	g.With(String("k", "v")).Infof("hello %d", 1)
+	g.Warn("json")
+	e, err := NewBinaryReader(bin, MsgPack).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Level != INFO || e.Message != "hello 1" || e.Time.IsZero() || fieldsString(e.Fields) != "k=v" {
+		t.Errorf("encoded entry = %+v", e)
+	}
+	if !strings.Contains(text.String(), `"detail":"json"`) {
+		t.Errorf("JSON level = %q", text.String())
+	}
+}
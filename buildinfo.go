@@ -0,0 +1,77 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"errors"
+	"runtime/debug"
+)
+
+// errNoBuildInfo is returned by LogBuildInfo when the binary is built without module support
+var errNoBuildInfo = errors.New("error:\tbuild info is not available")
+
+// LogBuildInfo logs the build info of the binary at INFO, e.g. at the startup
+//
+//	[INFO]:	build info	module=example.com/app version=v1.2.0 go_version=go1.22.1 vcs_revision=1a2b3c vcs_time=2026-01-02T15:04:05Z vcs_modified=false
+//
+// The VCS fields are logged when the binary is built in the repository with -buildvcs, vcs_modified is the dirty flag
+func (g *Glg) LogBuildInfo() error {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return errNoBuildInfo
+	}
+	vals := timerVals("build info", buildInfoFields(bi))
+	return g.out(INFO, g.blankFormat(len(vals)), vals...)
+}
+
+// LogBuildInfo logs the build info of the binary at INFO by the global instance
+func LogBuildInfo() error {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return errNoBuildInfo
+	}
+	g := Get()
+	vals := timerVals("build info", buildInfoFields(bi))
+	return g.out(INFO, g.blankFormat(len(vals)), vals...)
+}
+
+// buildInfoFields returns the fields of the main module, the Go version and the VCS settings
+func buildInfoFields(bi *debug.BuildInfo) []Field {
+	fields := []Field{
+		String("module", bi.Main.Path),
+		String("version", bi.Main.Version),
+		String("go_version", bi.GoVersion),
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			fields = append(fields, String("vcs_revision", s.Value))
+		case "vcs.time":
+			fields = append(fields, String("vcs_time", s.Value))
+		case "vcs.modified":
+			fields = append(fields, Bool("vcs_modified", s.Value == "true"))
+		}
+	}
+	return fields
+}
@@ -0,0 +1,172 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"strconv"
+	"sync"
+)
+
+const (
+	chainText = "\tchain="
+	chainJSON = `,"chain":"`
+	chainSize = sha256.Size * 2
+)
+
+// ChainError reports the entry which does not match the hash chain
+type ChainError struct {
+	Line int
+}
+
+func (e *ChainError) Error() string {
+	return "error:\thash chain is broken at line " + strconv.Itoa(e.Line)
+}
+
+// ChainWriter is io.Writer for audit logs which appends HMAC-SHA256 of the entry chained to the previous entry,
+// chain=HMAC(key, previous chain + entry). Text entries get "\tchain=<hex>" and JSON entries get "chain" field,
+// so modified, inserted, removed or reordered entries are detected by VerifyChain.
+// Removal of the last entries is detectable only by keeping the last chain elsewhere
+type ChainWriter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	mac  hash.Hash
+	prev []byte
+}
+
+// NewChainWriter returns ChainWriter of the HMAC key
+func NewChainWriter(w io.Writer, key []byte) *ChainWriter {
+	return &ChainWriter{
+		w:   w,
+		mac: hmac.New(sha256.New, key),
+	}
+}
+
+// Resume continues the chain of the existing log from its last chain returned by VerifyChain
+func (c *ChainWriter) Resume(prev []byte) *ChainWriter {
+	c.mu.Lock()
+	c.prev = append([]byte(nil), prev...)
+	c.mu.Unlock()
+	return c
+}
+
+// Write writes p as one entry with its chain
+func (c *ChainWriter) Write(p []byte) (int, error) {
+	entry := bytes.TrimRight(p, "\n")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sum := chainSum(c.mac, c.prev, entry)
+	b := make([]byte, 0, len(entry)+len(chainJSON)+chainSize+3)
+	if isJSONObject(entry) {
+		b = append(b, entry[:len(entry)-1]...)
+		b = append(b, chainJSON...)
+		b = hexAppend(b, sum)
+		b = append(b, '"', '}')
+	} else {
+		b = append(b, entry...)
+		b = append(b, chainText...)
+		b = hexAppend(b, sum)
+	}
+	b = append(b, '\n')
+	if _, err := c.w.Write(b); err != nil {
+		return 0, err
+	}
+	c.prev = sum
+	return len(p), nil
+}
+
+// VerifyChain verifies the chain of the log written by ChainWriter and returns its last chain
+func VerifyChain(r io.Reader, key []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, key)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64<<10), encryptMaxRecord)
+	var (
+		prev  []byte
+		entry []byte
+		line  int
+	)
+	for sc.Scan() {
+		line++
+		l := sc.Bytes()
+		if len(entry) != 0 {
+			entry = append(entry, '\n')
+		}
+		entry = append(entry, l...)
+		body, got, ok := splitChain(entry)
+		if !ok {
+			// continuation line of the multi-line entry
+			continue
+		}
+		sum := chainSum(mac, prev, body)
+		want := hexAppend(nil, sum)
+		if !hmac.Equal(got, want) {
+			return prev, &ChainError{Line: line}
+		}
+		prev, entry = sum, entry[:0]
+	}
+	if err := sc.Err(); err != nil {
+		return prev, err
+	}
+	if len(entry) != 0 {
+		return prev, &ChainError{Line: line}
+	}
+	return prev, nil
+}
+
+func chainSum(mac hash.Hash, prev, entry []byte) []byte {
+	mac.Reset()
+	mac.Write(prev)
+	mac.Write(entry)
+	return mac.Sum(nil)
+}
+
+// splitChain splits the written entry into the original entry and the hex chain
+func splitChain(b []byte) (entry, sum []byte, ok bool) {
+	if n := len(b) - chainSize - len(chainText); n >= 0 && bytes.Equal(b[n:n+len(chainText)], []byte(chainText)) {
+		return b[:n], b[n+len(chainText):], true
+	}
+	if n := len(b) - chainSize - len(chainJSON) - 2; n >= 0 && b[len(b)-1] == '}' && b[len(b)-2] == '"' &&
+		bytes.Equal(b[n:n+len(chainJSON)], []byte(chainJSON)) {
+		entry = append(append([]byte(nil), b[:n]...), '}')
+		return entry, b[n+len(chainJSON) : len(b)-2], true
+	}
+	return nil, nil, false
+}
+
+func isJSONObject(b []byte) bool {
+	return len(b) > 2 && b[0] == '{' && b[len(b)-1] == '}'
+}
+
+func hexAppend(b, src []byte) []byte {
+	n := len(b)
+	b = append(b, make([]byte, hex.EncodedLen(len(src)))...)
+	hex.Encode(b[n:], src)
+	return b
+}
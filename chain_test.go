@@ -0,0 +1,118 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	json "github.com/goccy/go-json"
+)
+
+func TestChainWriter(t *testing.T) {
+	key := []byte("audit key")
+	buf := new(bytes.Buffer)
+	c := NewChainWriter(buf, key)
+	g := New().SetMode(WRITER).SetWriter(c).DisableTimestamp()
+	g.Info("login", F("user", "bob"))
+	g.Info("multi\nline")
+	g.EnableJSON().Warn("json entry")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "[INFO]:\tlogin\tuser=bob\tchain=") {
+		t.Fatalf("ChainWriter = %q", buf.String())
+	}
+	var entry struct {
+		Detail string `json:"detail"`
+		Chain  string `json:"chain"`
+	}
+	if err := json.Unmarshal([]byte(lines[3]), &entry); err != nil || entry.Detail != "json entry" || len(entry.Chain) != chainSize {
+		t.Errorf("ChainWriter json = %s, %v", lines[3], err)
+	}
+	last, err := VerifyChain(bytes.NewReader(buf.Bytes()), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// resumed chain verifies as one chain
+	c = NewChainWriter(buf, key).Resume(last)
+	c.Write([]byte("resumed\n"))
+	if _, err = VerifyChain(bytes.NewReader(buf.Bytes()), key); err != nil {
+		t.Error(err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(lines []string) []string
+		key    []byte
+		line   int
+	}{
+		{
+			name: "wrong key",
+			key:  []byte("other"),
+			line: 1,
+		},
+		{
+			name: "modified",
+			modify: func(lines []string) []string {
+				lines[0] = strings.Replace(lines[0], "bob", "eve", 1)
+				return lines
+			},
+			line: 1,
+		},
+		{
+			name: "removed",
+			modify: func(lines []string) []string {
+				return append(lines[:1], lines[3:]...)
+			},
+			line: 2,
+		},
+		{
+			name: "reordered",
+			modify: func(lines []string) []string {
+				lines[3], lines[4] = lines[4], lines[3]
+				return lines
+			},
+			line: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			if tt.modify != nil {
+				lines = tt.modify(lines)
+			}
+			k := key
+			if tt.key != nil {
+				k = tt.key
+			}
+			_, err := VerifyChain(strings.NewReader(strings.Join(lines, "\n")+"\n"), k)
+			var cerr *ChainError
+			if !errors.As(err, &cerr) || cerr.Line != tt.line {
+				t.Errorf("VerifyChain() error = %v, want line %d", err, tt.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"errors"
+)
+
+// ErrorRule returns the level of the entries logging err and true to reclassify them, false keeps the level
+type ErrorRule func(err error) (LEVEL, bool)
+
+// ErrorIs returns ErrorRule logging the errors matching target by errors.Is at lv, e.g. ErrorIs(context.Canceled, DEBG)
+func ErrorIs(target error, lv LEVEL) ErrorRule {
+	return func(err error) (LEVEL, bool) {
+		return lv, errors.Is(err, target)
+	}
+}
+
+// ErrorAs returns ErrorRule logging the errors having T in the chain by errors.As at lv, e.g. ErrorAs[*os.PathError](WARN)
+func ErrorAs[T error](lv LEVEL) ErrorRule {
+	return func(err error) (LEVEL, bool) {
+		var target T
+		return lv, errors.As(err, &target)
+	}
+}
+
+// ErrorTimeout returns ErrorRule logging the timeout errors at lv, the errors having Timeout() bool returning true
+// in the chain like net.Error and context.DeadlineExceeded
+func ErrorTimeout(lv LEVEL) ErrorRule {
+	return func(err error) (LEVEL, bool) {
+		var t interface{ Timeout() bool }
+		return lv, errors.As(err, &t) && t.Timeout()
+	}
+}
+
+// AddErrorRule adds the rules reclassifying the entries by the errors logged as the values or Err fields,
+// the rules are tried in order on each error and the first match decides the level, e.g.
+//
+//	glg.Get().AddErrorRule(glg.ErrorIs(context.Canceled, glg.DEBG), glg.ErrorTimeout(glg.WARN))
+//
+// The rules may be added while logging, they are shared by the instances derived by With
+func (g *Glg) AddErrorRule(rules ...ErrorRule) *Glg {
+	g.setSwitches(func(s *switches) {
+		for _, rule := range rules {
+			if rule != nil {
+				// the full slice expression copies the rules, the entries being logged keep reading the old ones
+				s.errorRules = append(s.errorRules[:len(s.errorRules):len(s.errorRules)], rule)
+			}
+		}
+	})
+	return g
+}
+
+// ResetErrorRules removes the rules added by AddErrorRule
+func (g *Glg) ResetErrorRules() *Glg {
+	g.setSwitches(func(s *switches) {
+		s.errorRules = nil
+	})
+	return g
+}
+
+// AddErrorRule adds the rules reclassifying the entries of the global instance by the logged errors
+func AddErrorRule(rules ...ErrorRule) *Glg {
+	return Get().AddErrorRule(rules...)
+}
+
+// ResetErrorRules removes the error rules of the global instance
+func ResetErrorRules() *Glg {
+	return Get().ResetErrorRules()
+}
+
+// classify returns the level of the entry decided by the error rules, level is kept without errors or matching rules
+func (g *Glg) classify(rules []ErrorRule, level LEVEL, val []interface{}) LEVEL {
+	for _, f := range g.fields {
+		if lv, ok := classifyValue(rules, f); ok {
+			return lv
+		}
+	}
+	for _, v := range val {
+		if lv, ok := classifyValue(rules, v); ok {
+			return lv
+		}
+	}
+	return level
+}
+
+func classifyValue(rules []ErrorRule, v interface{}) (LEVEL, bool) {
+	var err error
+	switch e := v.(type) {
+	case error:
+		err = e
+	case Field:
+		if e.kind == fieldError {
+			err, _ = e.iface.(error)
+		}
+	}
+	if err == nil {
+		return 0, false
+	}
+	for _, rule := range rules {
+		if lv, ok := rule(err); ok {
+			return lv, true
+		}
+	}
+	return 0, false
+}
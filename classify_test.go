@@ -0,0 +1,120 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestGlg_AddErrorRule(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(g *Glg) error
+		want string
+	}{
+		{
+			name: "errors.Is on the value",
+			log:  func(g *Glg) error { return g.Error("request", fmt.Errorf("wrapped: %w", context.Canceled)) },
+			want: "[DEBG]",
+		},
+		{
+			name: "timeout on the Err field",
+			log:  func(g *Glg) error { return g.Error("request", Err(timeoutError{})) },
+			want: "[WARN]",
+		},
+		{
+			name: "context deadline is a timeout",
+			log:  func(g *Glg) error { return g.Errorf("request: %v", context.DeadlineExceeded) },
+			want: "[WARN]",
+		},
+		{
+			name: "errors.As",
+			log: func(g *Glg) error {
+				return g.Warn("open", Any("cause", &fs.PathError{Op: "open", Path: "x", Err: os.ErrNotExist}))
+			},
+			want: "[ERR]",
+		},
+		{
+			name: "error of With",
+			log:  func(g *Glg) error { return g.With(Err(context.Canceled)).Error("request") },
+			want: "[DEBG]",
+		},
+		{
+			name: "no matching rule",
+			log:  func(g *Glg) error { return g.Error("request", errors.New("boom")) },
+			want: "[ERR]",
+		},
+		{
+			name: "no error",
+			log:  func(g *Glg) error { return g.Info("request", String("error", "canceled")) },
+			want: "[INFO]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone).
+				AddErrorRule(ErrorIs(context.Canceled, DEBG), ErrorTimeout(WARN), nil, ErrorAs[*fs.PathError](ERR))
+			if err := tt.log(g); err != nil {
+				t.Fatalf("log error = %v", err)
+			}
+			if got := buf.String(); !strings.Contains(got, tt.want) {
+				t.Errorf("output = %q, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_ResetErrorRules(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).
+		AddErrorRule(ErrorIs(context.Canceled, DEBG)).
+		ResetErrorRules()
+	g.Error("request", context.Canceled)
+	if !strings.Contains(buf.String(), "[ERR]") {
+		t.Errorf("output = %q, want [ERR]", buf.String())
+	}
+}
+
+func TestGlg_AddErrorRule_Disabled(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLevel(INFO).
+		AddErrorRule(ErrorIs(context.Canceled, DEBG))
+	g.Error("request", context.Canceled)
+	if buf.Len() != 0 {
+		t.Errorf("output = %q, want the entry downgraded to the disabled level", buf.String())
+	}
+}
@@ -0,0 +1,153 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+const (
+	// cliLineFormat is the layout of NewCLI, the caller is written only when the trace mode is set
+	cliLineFormat = "{{level}} {{msg}}"
+
+	// VerbosityFlagName is the flag name registered by RegisterVerbosityFlags, -v increases the verbosity by one
+	VerbosityFlagName = "v"
+)
+
+// NewCLI returns the instance configured for the command line tools. The lines are written without timestamps
+// and callers as the short level badge and the message, e.g. "WRN disk is almost full", and PRINT writes the message alone
+// as the output of the command. WARN and above are written to stderr, the rest to stdout, and each stream is colored
+// only when it is the terminal and NO_COLOR is not set. DEBG and TRACE are disabled until SetVerbosity enables them
+func NewCLI() *Glg {
+	g := New().DisableTimestamp().EnableShortLevel().SetLineFormat(cliLineFormat).
+		SetLevelLineFormat(PRINT, "{{msg}}").SetLineTraceMode(TraceLineNone).SetStdRouting(CLIStdRouting)
+	g.updateLoggers(func(lv LEVEL, l *logger) {
+		l.isColor = colorTerminal(l.std)
+		l.updateMode()
+	})
+	return g.SetVerbosity(0)
+}
+
+// SetVerbosity enables the levels by the count of -v, 0 enables PRINT and above, 1 enables TRACE and 2 or more enables DEBG
+func (g *Glg) SetVerbosity(n int) *Glg {
+	switch {
+	case n <= 0:
+		return g.SetLevel(PRINT)
+	case n == 1:
+		return g.SetLevel(TRACE)
+	}
+	return g.SetLevel(DEBG)
+}
+
+// SetVerbosity enables the levels of the global instance by the count of -v
+func SetVerbosity(n int) *Glg {
+	return Get().SetVerbosity(n)
+}
+
+// VerbosityValue is the boolean flag.Value counting its occurrences, e.g. -v -v, and setting the verbosity by SetVerbosity.
+// The number is accepted as well, e.g. -v=2
+type VerbosityValue struct {
+	g    *Glg
+	n    *int
+	step int
+}
+
+// VerbosityVar returns flag.Value setting the verbosity of the instance, e.g. flag.Var(g.VerbosityVar(), "v", "verbose output")
+func (g *Glg) VerbosityVar() *VerbosityValue {
+	return &VerbosityValue{g: g, n: new(int), step: 1}
+}
+
+// RegisterVerbosityFlags registers -v counting its occurrences and -vv adding two to fs, flag.CommandLine when fs is nil
+func (g *Glg) RegisterVerbosityFlags(fs *flag.FlagSet) *Glg {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	v := g.VerbosityVar()
+	fs.Var(v, VerbosityFlagName, "verbose output, repeat for more")
+	fs.Var(&VerbosityValue{g: g, n: v.n, step: 2}, VerbosityFlagName+VerbosityFlagName, "more verbose output, the same as -v -v")
+	return g
+}
+
+// VerbosityVar returns flag.Value setting the verbosity of the global instance
+func VerbosityVar() *VerbosityValue {
+	return Get().VerbosityVar()
+}
+
+// RegisterVerbosityFlags registers -v and -vv of the global instance to fs, flag.CommandLine when fs is nil
+func RegisterVerbosityFlags(fs *flag.FlagSet) *Glg {
+	return Get().RegisterVerbosityFlags(fs)
+}
+
+// String implements flag.Value
+func (v *VerbosityValue) String() string {
+	if v == nil || v.n == nil {
+		return "0"
+	}
+	return strconv.Itoa(*v.n)
+}
+
+// Set implements flag.Value, "true" counts the occurrence, "false" resets the verbosity and the number sets it
+func (v *VerbosityValue) Set(s string) error {
+	switch s {
+	case "true":
+		*v.n += v.step
+	case "false":
+		*v.n = 0
+	default:
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return fmt.Errorf("error:\tinvalid verbosity %q", s)
+		}
+		*v.n = n
+	}
+	v.g.SetVerbosity(*v.n)
+	return nil
+}
+
+// IsBoolFlag makes -v set without the value
+func (v *VerbosityValue) IsBoolFlag() bool {
+	return true
+}
+
+// Type implements pflag.Value
+func (v *VerbosityValue) Type() string {
+	return "count"
+}
+
+// colorTerminal reports the colored output is written to w, w must be the terminal and NO_COLOR must not be set
+func colorTerminal(w io.Writer) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
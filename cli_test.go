@@ -0,0 +1,110 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNewCLI(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	g := NewCLI()
+	for _, l := range g.Config().Levels {
+		want := os.Stdout.Name()
+		if l.Rank >= WARN {
+			want = os.Stderr.Name()
+		}
+		if l.Std != want || l.Color || l.Timestamp || l.Trace != "none" {
+			t.Errorf("level %s = %+v, want %s without color, timestamp and trace", l.Tag, l, want)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	g.SetMode(WRITER).SetWriter(buf).SetVerbosity(0)
+	g.Print("result")
+	g.Info("done")
+	g.Warn("almost full")
+	g.Debug("hidden")
+	g.Trace("hidden")
+	if want := "result\nINF done\nWRN almost full\n"; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestGlg_RegisterVerbosityFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want LEVEL
+	}{
+		{
+			name: "quiet",
+			want: PRINT,
+		},
+		{
+			name: "v",
+			args: []string{"-v"},
+			want: TRACE,
+		},
+		{
+			name: "v v",
+			args: []string{"-v", "-v"},
+			want: DEBG,
+		},
+		{
+			name: "vv",
+			args: []string{"-vv"},
+			want: DEBG,
+		},
+		{
+			name: "number",
+			args: []string{"-v=1"},
+			want: TRACE,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewCLI()
+			fs := flag.NewFlagSet("cli", flag.ContinueOnError)
+			g.RegisterVerbosityFlags(fs)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if got := g.GetCurrentLevel(); got != tt.want {
+				t.Errorf("GetCurrentLevel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	fs := flag.NewFlagSet("cli", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	New().RegisterVerbosityFlags(fs)
+	if err := fs.Parse([]string{"-v=much"}); err == nil || !strings.Contains(err.Error(), "much") {
+		t.Errorf("Parse() error = %v, want invalid verbosity", err)
+	}
+}
@@ -0,0 +1,162 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"io"
+	"reflect"
+	"sync/atomic"
+)
+
+// Clone returns the independent copy of the instance, e.g. for the request handler or the test changing the configuration
+// without affecting the shared instance. Unlike the loggers derived by With, which share the configuration,
+// the levels, modes, colors, formats, fields, rules and hooks of the copy are changed separately.
+// The writers are shared and are not closed by Shutdown of the copy, CloneWriters duplicates them.
+// The counters, the suspended output, the loss report and the runtime stats are not copied, the asynchronous writing of the copy has its own queues
+func (g *Glg) Clone() *Glg {
+	return g.CloneWriters(nil)
+}
+
+// CloneWriters returns the copy of the instance like Clone whose writers and route writers are replaced by dup(w),
+// the writers added by AddWriter are replaced one by one and the writer set to several levels is duplicated once,
+// the std outputs are kept. The duplicated writers are closed by Shutdown of the copy
+func (g *Glg) CloneWriters(dup func(w io.Writer) io.Writer) *Glg {
+	c := New()
+	c.fields = append([]Field(nil), g.fields...)
+	c.groups = append([]string(nil), g.groups...)
+
+	// the rules, filters, hooks and routes of the switches are copied on write, the copy shares them until it changes them
+	c.switches.Store(g.opts())
+	c.escalation.rules.Store(g.escalation.rules.Load())
+	if opts := g.httpOptions.Load(); opts != nil {
+		// the options are replaced as a whole by SetHTTPOptions, the copy shares them until it sets its own
+		c.httpOptions.Store(opts)
+	}
+	atomic.StoreInt32(&c.ordered, atomic.LoadInt32(&g.ordered))
+	atomic.StoreUint32(c.levelCounter, atomic.LoadUint32(g.levelCounter))
+	g.levelMap.Range(func(tag string, lv LEVEL) bool {
+		c.levelMap.Store(tag, lv)
+		return true
+	})
+	g.prefixVars.Range(func(name, fn interface{}) bool {
+		c.prefixVars.Store(name, fn)
+		return true
+	})
+	g.writerGroups.Range(func(name, wg interface{}) bool {
+		c.writerGroups.Store(name, wg)
+		return true
+	})
+	g.term.mu.Lock()
+	c.term.lock = g.term.lock
+	g.term.mu.Unlock()
+	c.term.updateActive()
+
+	dups := make(map[io.Writer]io.Writer)
+	g.logger.Range(func(lv LEVEL, l *logger) bool {
+		cl := *l
+		if dup != nil {
+			cl.writer = c.dupWriter(l.writer, dup, dups)
+			cl.routes = c.dupRoutes(l.routes, dup, dups)
+		}
+		c.logger.Store(lv, &cl)
+		return true
+	})
+
+	c.recent = g.Recent()
+	g.configMu.Lock()
+	c.tail = g.tail
+	g.configMu.Unlock()
+	if dup != nil {
+		routes := c.dupRoutes(c.opts().routes, dup, dups)
+		c.setSwitches(func(s *switches) {
+			s.routes = routes
+		})
+		if rb, ok := dups[c.recent].(*RecentBuffer); ok {
+			c.recent = rb
+		}
+	}
+
+	g.asyncMu.Lock()
+	c.asyncSize = g.asyncSize
+	c.asyncOverflow = g.asyncOverflow
+	if g.asyncLevels != nil {
+		c.asyncLevels = make(map[LEVEL]asyncConfig, len(g.asyncLevels))
+		for lv, ac := range g.asyncLevels {
+			c.asyncLevels[lv] = ac
+		}
+	}
+	async := g.asyncer() != nil
+	g.asyncMu.Unlock()
+	if async {
+		c.asyncMu.Lock()
+		c.restartAsync()
+		c.asyncMu.Unlock()
+	}
+	return c
+}
+
+// Clone returns the independent copy of the global instance
+func Clone() *Glg {
+	return Get().Clone()
+}
+
+// dupWriter returns the duplicate of w made by dup, the writers of fanout are duplicated one by one
+// and the comparable writers are duplicated once through dups
+func (g *Glg) dupWriter(w io.Writer, dup func(w io.Writer) io.Writer, dups map[io.Writer]io.Writer) io.Writer {
+	if w == nil {
+		return nil
+	}
+	if f, ok := w.(fanout); ok {
+		df := make(fanout, len(f))
+		for i, w := range f {
+			df[i] = g.dupWriter(w, dup, dups)
+		}
+		return df
+	}
+	canMap := reflect.TypeOf(w).Comparable()
+	if canMap {
+		if dw, ok := dups[w]; ok {
+			return dw
+		}
+	}
+	dw := dup(w)
+	if canMap {
+		dups[w] = dw
+	}
+	g.trackWriter(dw, false)
+	return dw
+}
+
+// dupRoutes returns the routes writing to the duplicated writers
+func (g *Glg) dupRoutes(routes []Route, dup func(w io.Writer) io.Writer, dups map[io.Writer]io.Writer) []Route {
+	if len(routes) == 0 {
+		return nil
+	}
+	rs := make([]Route, len(routes))
+	for i, r := range routes {
+		r.Writer = g.dupWriter(r.Writer, dup, dups)
+		rs[i] = r
+	}
+	return rs
+}
@@ -0,0 +1,100 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGlg_Clone(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone).
+		AddStdLevel("AUDIT", WRITER, false).
+		With(String("app", "api"))
+	c := g.Clone()
+
+	c.SetLevel(WARN).EnableJSON().SetLevelString(ERR, "ERROR")
+	if g.GetCurrentLevel() != DEBG || g.Config().JSON || g.LevelString(ERR) != "ERR" {
+		t.Fatalf("Clone() changes affected the original: %+v", g.Config())
+	}
+	c.AddStdLevel("CLONE", WRITER, false)
+	if g.TagStringToLevel("CLONE") != UNKNOWN {
+		t.Error("Clone() custom level is registered to the original")
+	}
+
+	g.Info("original")
+	c.Info("filtered")
+	c.Warn("clone")
+	if err := c.CustomLog("AUDIT", "audit"); err != nil {
+		t.Errorf("CustomLog() of the copied level error = %v", err)
+	}
+	got := buf.String()
+	if !strings.Contains(got, "original\tapp=api") || strings.Contains(got, "filtered") ||
+		!strings.Contains(got, `"detail":"clone"`) || !strings.Contains(got, `"app":"api"`) {
+		t.Errorf("output = %q", got)
+	}
+
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := g.Info("after the copy is shut down"); err != nil || !strings.Contains(buf.String(), "after the copy") {
+		t.Errorf("Shutdown() of the copy affected the original: %v", err)
+	}
+}
+
+func TestGlg_CloneWriters(t *testing.T) {
+	shared := new(bytes.Buffer)
+	added := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(shared).AddLevelWriter(ERR, added)
+
+	dups := make(map[io.Writer]*closeBuffer)
+	c := g.CloneWriters(func(w io.Writer) io.Writer {
+		if _, ok := dups[w]; ok {
+			t.Errorf("CloneWriters() duplicated %T twice", w)
+		}
+		dups[w] = new(closeBuffer)
+		return dups[w]
+	})
+	if len(dups) != 2 {
+		t.Fatalf("CloneWriters() duplicated %d writers, want 2", len(dups))
+	}
+	c.Info("info")
+	c.Error("error")
+	if shared.Len() != 0 || added.Len() != 0 {
+		t.Errorf("CloneWriters() wrote to the original writers: %q, %q", shared.String(), added.String())
+	}
+	if got := dups[shared].String(); !strings.Contains(got, "info") || !strings.Contains(got, "error") {
+		t.Errorf("duplicated writer = %q", got)
+	}
+	if got := dups[added].String(); strings.Contains(got, "info") || !strings.Contains(got, "error") {
+		t.Errorf("duplicated added writer = %q", got)
+	}
+	c.Shutdown(context.Background())
+	if dups[shared].closed != 1 || dups[added].closed != 1 {
+		t.Error("Shutdown() did not close the duplicated writers")
+	}
+}
@@ -0,0 +1,172 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	json "github.com/goccy/go-json"
+)
+
+const (
+	// CloudLoggingTraceKey is the field key of the trace ID written as logging.googleapis.com/trace
+	CloudLoggingTraceKey = "trace"
+	// CloudLoggingSpanKey is the field key of the span ID written as logging.googleapis.com/spanId
+	CloudLoggingSpanKey = "span_id"
+)
+
+// cloudLoggingSeverity maps the level to Cloud Logging LogSeverity
+func cloudLoggingSeverity(l LEVEL) string {
+	switch l {
+	case DEBG, TRACE:
+		return "DEBUG"
+	case INFO:
+		return "INFO"
+	case OK:
+		return "NOTICE"
+	case WARN:
+		return "WARNING"
+	case ERR, FAIL:
+		return "ERROR"
+	case FATAL:
+		return "CRITICAL"
+	}
+	return "DEFAULT"
+}
+
+// EnableCloudLogging enables JSON output in the structured format of Google Cloud Logging (Stackdriver),
+// so the logs written to stdout on GKE or Cloud Run get correct severities.
+// Fields are written at the top level of jsonPayload, the trace field is written as
+// logging.googleapis.com/trace of the projectID
+func (g *Glg) EnableCloudLogging(projectID string) *Glg {
+	g.setSwitches(func(s *switches) {
+		s.enableJSON = true
+		s.cloudLogging = true
+		s.gcpProjectID = projectID
+	})
+	return g
+}
+
+// DisableCloudLogging disables Cloud Logging format, JSON output keeps enabled
+func (g *Glg) DisableCloudLogging() *Glg {
+	g.setSwitches(func(s *switches) {
+		s.cloudLogging = false
+	})
+	return g
+}
+
+// writeCloudLogging writes the entry in the Cloud Logging format, the time is omitted when now is zero
+func (g *Glg) writeCloudLogging(o *switches, w io.Writer, level LEVEL, fl string, now time.Time, detail interface{}, fields []Field) error {
+	b := make([]byte, 0, 256)
+	b = append(b, `{"severity":`...)
+	b = appendJSONString(b, cloudLoggingSeverity(level))
+	b = append(b, `,"message":`...)
+	b = appendJSONString(b, detailMessage(detail))
+	if !now.IsZero() {
+		b = append(b, `,"time":"`...)
+		b = now.AppendFormat(b, time.RFC3339Nano)
+		b = append(b, '"')
+	}
+	if fl != "" {
+		file, line := splitCaller(fl)
+		b = append(b, `,"logging.googleapis.com/sourceLocation":{"file":`...)
+		b = appendJSONString(b, file)
+		if line != "" {
+			b = append(b, `,"line":`...)
+			b = appendJSONString(b, line)
+		}
+		b = append(b, '}')
+	}
+	if len(o.resource) != 0 {
+		buf, err := json.Marshal(o.resource)
+		if err != nil {
+			return err
+		}
+		b = append(b, `,"logging.googleapis.com/labels":`...)
+		b = append(b, buf...)
+	}
+	rest := make([]Field, 0, len(fields))
+	for _, f := range fields {
+		switch f.Key {
+		case CloudLoggingTraceKey:
+			trace := string(f.appendText(nil, false))
+			if o.gcpProjectID != "" && !strings.HasPrefix(trace, "projects/") {
+				trace = "projects/" + o.gcpProjectID + "/traces/" + trace
+			}
+			b = append(b, `,"logging.googleapis.com/trace":`...)
+			b = appendJSONString(b, trace)
+		case CloudLoggingSpanKey:
+			b = append(b, `,"logging.googleapis.com/spanId":`...)
+			b = appendJSONString(b, string(f.appendText(nil, false)))
+		case "severity", "message", "time":
+			// reserved by Cloud Logging
+		default:
+			rest = append(rest, f)
+		}
+	}
+	if len(rest) != 0 {
+		buf, err := jsonFields{fields: rest, max: o.maxFieldSize}.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		if len(buf) > 2 {
+			b = append(b, ',')
+			b = append(b, buf[1:len(buf)-1]...)
+		}
+	}
+	b = append(b, '}', '\n')
+	_, err := w.Write(b)
+	return err
+}
+
+// detailMessage returns the message text of the JSON detail, multiple values are joined with spaces
+func detailMessage(detail interface{}) string {
+	switch d := detail.(type) {
+	case nil:
+		return ""
+	case string:
+		return d
+	case []interface{}:
+		strs := make([]string, len(d))
+		for i, v := range d {
+			strs[i] = fmt.Sprint(v)
+		}
+		return strings.Join(strs, " ")
+	}
+	return fmt.Sprint(detail)
+}
+
+// splitCaller splits the caller into the file and the line, the caller is file:line or URL#Lline
+func splitCaller(fl string) (file, line string) {
+	if i := strings.LastIndex(fl, "#L"); i > 0 {
+		return fl[:i], fl[i+2:]
+	}
+	if i := strings.LastIndexByte(fl, ':'); i > 0 {
+		return fl[:i], fl[i+1:]
+	}
+	return fl, ""
+}
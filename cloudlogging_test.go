@@ -0,0 +1,110 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"testing"
+
+	json "github.com/goccy/go-json"
+)
+
+func TestGlg_EnableCloudLogging(t *testing.T) {
+	tests := []struct {
+		name  string
+		level LEVEL
+		val   []interface{}
+		want  string
+	}{
+		{
+			name:  "info",
+			level: INFO,
+			val:   []interface{}{"hello", "world"},
+			want:  `{"severity":"INFO","message":"hello world"}` + "\n",
+		},
+		{
+			name:  "error with fields",
+			level: ERR,
+			val:   []interface{}{"failed", F("user", "bob"), F("message", "dropped")},
+			want:  `{"severity":"ERROR","message":"failed","user":"bob"}` + "\n",
+		},
+		{
+			name:  "warn with trace",
+			level: WARN,
+			val:   []interface{}{"slow", String(CloudLoggingTraceKey, "abc"), String(CloudLoggingSpanKey, "def")},
+			want:  `{"severity":"WARNING","message":"slow","logging.googleapis.com/trace":"projects/my-project/traces/abc","logging.googleapis.com/spanId":"def"}` + "\n",
+		},
+		{
+			name:  "fatal",
+			level: FATAL,
+			val:   []interface{}{"down"},
+			want:  `{"severity":"CRITICAL","message":"down"}` + "\n",
+		},
+		{
+			name:  "log",
+			level: LOG,
+			val:   []interface{}{1},
+			want:  `{"severity":"DEFAULT","message":"1"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetLineTraceMode(TraceLineNone).EnableCloudLogging("my-project")
+			if err := g.out(tt.level, g.blankFormat(len(tt.val)), tt.val...); err != nil {
+				t.Error(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Glg.EnableCloudLogging() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_EnableCloudLogging_Time(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).EnableCloudLogging("").EnableUTC().SetLineTraceMode(TraceLineShort)
+	g.Infof("hello %s", "world")
+	var got struct {
+		Severity string `json:"severity"`
+		Message  string `json:"message"`
+		Time     string `json:"time"`
+		Source   struct {
+			File string `json:"file"`
+			Line string `json:"line"`
+		} `json:"logging.googleapis.com/sourceLocation"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Severity != "INFO" || got.Message != "hello world" || got.Time == "" ||
+		got.Source.File != "cloudlogging_test.go" || got.Source.Line == "" {
+		t.Errorf("Glg.EnableCloudLogging() = %s", buf.String())
+	}
+
+	buf.Reset()
+	g.DisableCloudLogging().SetLineTraceMode(TraceLineNone).DisableTimestamp().Info("hello")
+	if got, want := buf.String(), `{"level":"INFO","detail":"hello"}`+"\n"; got != want {
+		t.Errorf("Glg.DisableCloudLogging() = %s, want %s", got, want)
+	}
+}
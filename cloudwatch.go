@@ -0,0 +1,130 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// CloudWatch Logs PutLogEvents limits, every event is counted with 26 bytes of overhead
+	cloudWatchMaxBatchEvents = 10000
+	cloudWatchMaxBatchBytes  = 1048576
+	cloudWatchEventOverhead  = 26
+	cloudWatchMaxEventBytes  = 262144 - cloudWatchEventOverhead
+	// cloudWatchMaxTokenRetries is the number of the resends with the expected sequence token
+	cloudWatchMaxTokenRetries = 3
+)
+
+// CloudWatchEvent is the input log event of CloudWatch Logs PutLogEvents
+type CloudWatchEvent struct {
+	Message string
+	// Timestamp is milliseconds since the Unix epoch
+	Timestamp int64
+}
+
+// CloudWatchClient sends log events to CloudWatch Logs.
+// It is implemented by a thin adapter of the AWS SDK, so that glg does not depend on it.
+// The adapter returns *CloudWatchSequenceTokenError when the sequence token is rejected or the batch is already accepted
+type CloudWatchClient interface {
+	PutLogEvents(ctx context.Context, group, stream, sequenceToken string, events []CloudWatchEvent) (nextSequenceToken string, err error)
+}
+
+// CloudWatchSequenceTokenError reports the sequence token is invalid or the batch is already accepted.
+// CloudWatchWriter resends the batch with Expected token, the batch already accepted is not resent and only Expected is kept
+type CloudWatchSequenceTokenError struct {
+	Expected string
+	// Accepted reports the batch is already accepted, e.g. DataAlreadyAcceptedException
+	Accepted bool
+}
+
+func (e *CloudWatchSequenceTokenError) Error() string {
+	if e.Accepted {
+		return "error:\tCloudWatch Logs batch already accepted, expected " + e.Expected
+	}
+	return "error:\tinvalid CloudWatch Logs sequence token, expected " + e.Expected
+}
+
+// CloudWatchWriter is io.Writer which batches entries to the CloudWatch Logs stream.
+// Each Write is sent as one event, so multi-line entries are kept together,
+// e.g. glg.Get().AddLevelWriter(glg.ERR, glg.NewCloudWatchWriter(client, group, stream))
+type CloudWatchWriter struct {
+	*batcher
+	sinkOptions[*CloudWatchWriter]
+	client CloudWatchClient
+	group  string
+	stream string
+	token  string
+}
+
+// NewCloudWatchWriter returns CloudWatchWriter, Close it to send the buffered entries
+func NewCloudWatchWriter(client CloudWatchClient, group, stream string) *CloudWatchWriter {
+	w := &CloudWatchWriter{
+		client: client,
+		group:  group,
+		stream: stream,
+	}
+	w.batcher = newBatcher(w.send, cloudWatchMaxBatchEvents, cloudWatchMaxBatchBytes, cloudWatchEventOverhead)
+	w.sinkOptions = sinkOptions[*CloudWatchWriter]{b: w.batcher, self: w}
+	return w
+}
+
+// send is called with sendMu locked, so the sequence token is updated in order
+func (w *CloudWatchWriter) send(ctx context.Context, batch []batchEntry) error {
+	events := make([]CloudWatchEvent, len(batch))
+	for i, e := range batch {
+		msg := string(e.data)
+		if len(msg) > cloudWatchMaxEventBytes {
+			msg = truncate(msg, cloudWatchMaxEventBytes-len(truncatedPrefix+truncatedSuffix)-10)
+		}
+		events[i] = CloudWatchEvent{
+			Message:   msg,
+			Timestamp: e.ts / int64(time.Millisecond),
+		}
+	}
+	for retry := 0; ; retry++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		token, err := w.client.PutLogEvents(ctx, w.group, w.stream, w.token, events)
+		var se *CloudWatchSequenceTokenError
+		if errors.As(err, &se) {
+			if se.Accepted {
+				w.token = se.Expected
+				return nil
+			}
+			if se.Expected != w.token && retry < cloudWatchMaxTokenRetries {
+				w.token = se.Expected
+				continue
+			}
+		}
+		if err != nil {
+			return err
+		}
+		w.token = token
+		return nil
+	}
+}
@@ -0,0 +1,165 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type fakeCloudWatch struct {
+	mu      sync.Mutex
+	token   string
+	fails   int
+	dup     bool
+	calls   int
+	batches [][]CloudWatchEvent
+}
+
+func (f *fakeCloudWatch) PutLogEvents(ctx context.Context, group, stream, token string, events []CloudWatchEvent) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.dup {
+		// the batch was accepted by the former request whose response was lost
+		f.dup = false
+		f.batches = append(f.batches, events)
+		f.token += "1"
+		return "", &CloudWatchSequenceTokenError{Expected: f.token, Accepted: true}
+	}
+	if f.fails > 0 {
+		f.fails--
+		return "", errors.New("throttled")
+	}
+	if token != f.token {
+		return "", &CloudWatchSequenceTokenError{Expected: f.token}
+	}
+	f.batches = append(f.batches, events)
+	f.token = token + "1"
+	return f.token, nil
+}
+
+func TestCloudWatchWriter(t *testing.T) {
+	client := &fakeCloudWatch{token: "seq"}
+	w := NewCloudWatchWriter(client, "group", "stream").SetFlushInterval(0)
+	g := New().SetMode(WRITER).SetWriter(w).DisableTimestamp()
+	if err := g.Info("first\nsecond"); err != nil {
+		t.Error(err)
+	}
+	if err := g.Error("failed"); err != nil {
+		t.Error(err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Error(err)
+	}
+	if len(client.batches) != 1 || len(client.batches[0]) != 2 {
+		t.Fatalf("CloudWatchWriter batches = %v", client.batches)
+	}
+	if got, want := client.batches[0][0].Message, "[INFO]:\tfirst\nsecond"; got != want {
+		t.Errorf("CloudWatchWriter message = %q, want %q", got, want)
+	}
+	if client.batches[0][0].Timestamp == 0 {
+		t.Error("CloudWatchWriter timestamp is zero")
+	}
+
+	client.fails = 2
+	g.Info("retried")
+	if err := w.Close(); err != nil {
+		t.Error(err)
+	}
+	if len(client.batches) != 2 || client.batches[1][0].Message != "[INFO]:\tretried" {
+		t.Errorf("CloudWatchWriter batches = %v", client.batches)
+	}
+	if _, err := w.Write([]byte("closed")); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("CloudWatchWriter.Write() error = %v, want %v", err, ErrWriterClosed)
+	}
+}
+
+func TestCloudWatchWriter_Limits(t *testing.T) {
+	client := new(fakeCloudWatch)
+	w := NewCloudWatchWriter(client, "group", "stream").SetFlushInterval(0).SetMaxRetries(0)
+	large := strings.Repeat("a", 300000)
+	for i := 0; i < 5; i++ {
+		if _, err := w.Write([]byte(large)); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Error(err)
+	}
+	var n int
+	for _, batch := range client.batches {
+		var size int
+		for _, e := range batch {
+			if len(e.Message) > cloudWatchMaxEventBytes {
+				t.Errorf("CloudWatchWriter event size = %d", len(e.Message))
+			}
+			size += len(e.Message) + cloudWatchEventOverhead
+		}
+		if size > cloudWatchMaxBatchBytes {
+			t.Errorf("CloudWatchWriter batch size = %d", size)
+		}
+		n += len(batch)
+	}
+	if n != 5 {
+		t.Errorf("CloudWatchWriter events = %d, want 5", n)
+	}
+
+	client = &fakeCloudWatch{fails: 1}
+	w = NewCloudWatchWriter(client, "group", "stream").SetFlushInterval(0).SetMaxRetries(0)
+	w.Write([]byte("lost"))
+	if err := w.Flush(); err == nil {
+		t.Error("CloudWatchWriter.Flush() error = nil")
+	}
+}
+
+func TestCloudWatchWriter_SequenceToken(t *testing.T) {
+	client := &fakeCloudWatch{token: "seq", dup: true}
+	w := NewCloudWatchWriter(client, "group", "stream").SetFlushInterval(0).SetMaxRetries(0)
+	w.Write([]byte("accepted"))
+	if err := w.Flush(); err != nil {
+		t.Errorf("CloudWatchWriter.Flush() error = %v", err)
+	}
+	if len(client.batches) != 1 || client.calls != 1 {
+		t.Errorf("CloudWatchWriter batches = %v in %d calls, want the accepted batch not resent", client.batches, client.calls)
+	}
+	w.Write([]byte("next"))
+	if err := w.Flush(); err != nil {
+		t.Errorf("CloudWatchWriter.Flush() error = %v", err)
+	}
+	if len(client.batches) != 2 || client.calls != 2 {
+		t.Errorf("CloudWatchWriter batches = %v in %d calls, want the expected token kept", client.batches, client.calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := w.send(ctx, []batchEntry{{data: []byte("canceled")}}); !errors.Is(err, context.Canceled) {
+		t.Errorf("CloudWatchWriter.send() error = %v, want %v", err, context.Canceled)
+	}
+	if client.calls != 2 {
+		t.Errorf("CloudWatchWriter calls = %d after the cancel, want 2", client.calls)
+	}
+}
@@ -0,0 +1,616 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Command glg tails, filters, colorizes, converts and summarizes glg logs.
+//
+//	glg cat [flags] [file...]     print entries of the files or stdin
+//	glg tail [flags] file         follow the file like tail -f
+//	glg stats [flags] [file...]   count entries by level
+//	glg decrypt -key-file id=path [file...]  decrypt logs written by glg.EncryptWriter
+//	glg decode [flags] [file...]  print entries encoded by glg.CBOR or glg.MsgPack
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gmazay/glg"
+	json "github.com/goccy/go-json"
+)
+
+const usage = `usage: glg <command> [flags] [file...]
+
+commands:
+	cat      print entries of the files or stdin
+	tail     follow the file like tail -f
+	stats    count entries by level
+	decrypt  decrypt logs written by glg.EncryptWriter
+	decode   print entries encoded by glg.CBOR or glg.MsgPack
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+	switch args[0] {
+	case "cat":
+		return runCat(args[1:], stdin, stdout)
+	case "tail":
+		return runTail(args[1:], stdout)
+	case "stats":
+		return runStats(args[1:], stdin, stdout)
+	case "decrypt":
+		return runDecrypt(args[1:], stdin, stdout)
+	case "decode":
+		return runDecode(args[1:], stdin, stdout)
+	case "help", "-h", "-help", "--help":
+		_, err := io.WriteString(stdout, usage)
+		return err
+	}
+	return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+}
+
+// fieldFlags is the repeatable -field key=value flag
+type fieldFlags map[string]string
+
+func (f fieldFlags) String() string {
+	pairs := make([]string, 0, len(f))
+	for k, v := range f {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f fieldFlags) Set(s string) error {
+	i := strings.IndexByte(s, '=')
+	if i <= 0 {
+		return fmt.Errorf("invalid field filter %q, want key=value", s)
+	}
+	f[s[:i]] = s[i+1:]
+	return nil
+}
+
+// filter selects and prints the entries
+type filter struct {
+	level  string
+	grep   string
+	fields fieldFlags
+	output string
+	color  string
+
+	min      glg.LEVEL
+	colorize bool
+}
+
+func newFilter(fs *flag.FlagSet) *filter {
+	f := &filter{fields: fieldFlags{}}
+	fs.StringVar(&f.level, "level", "", "minimum level to print, e.g. WARN")
+	fs.StringVar(&f.grep, "grep", "", "print entries whose message contains the string")
+	fs.Var(f.fields, "field", "print entries having the field, key=value, repeatable, groups are dotted keys")
+	fs.StringVar(&f.output, "o", "text", "output format, text or json")
+	fs.StringVar(&f.color, "color", "auto", "colorize text output, auto, always or never")
+	return f
+}
+
+func (f *filter) init(stdout io.Writer) error {
+	if f.level != "" {
+		if f.min = glg.Atol(f.level); f.min == glg.UNKNOWN {
+			return fmt.Errorf("unknown level %q", f.level)
+		}
+	}
+	switch f.output {
+	case "text", "json":
+	default:
+		return fmt.Errorf("unknown output format %q", f.output)
+	}
+	switch f.color {
+	case "always":
+		f.colorize = true
+	case "never":
+	case "auto":
+		f.colorize = isTerminal(stdout)
+	default:
+		return fmt.Errorf("unknown color mode %q", f.color)
+	}
+	f.colorize = f.colorize && f.output == "text"
+	return nil
+}
+
+func (f *filter) match(e *glg.Entry) bool {
+	if f.min != 0 && e.Level != glg.UNKNOWN && e.Level < f.min {
+		return false
+	}
+	if f.grep != "" && !strings.Contains(e.Message, f.grep) {
+		return false
+	}
+	if len(f.fields) == 0 {
+		return true
+	}
+	found := 0
+	flatten("", e.Fields, func(key string, val interface{}) {
+		if want, ok := f.fields[key]; ok && fmt.Sprint(val) == want {
+			found++
+		}
+	})
+	return found >= len(f.fields)
+}
+
+// flatten calls fn with the dotted keys of fields
+func flatten(prefix string, fields []glg.Field, fn func(key string, val interface{})) {
+	for _, fl := range fields {
+		val := fl.Value()
+		if group, ok := val.([]glg.Field); ok {
+			flatten(prefix+fl.Key+".", group, fn)
+			continue
+		}
+		fn(prefix+fl.Key, val)
+	}
+}
+
+var levelColors = map[glg.LEVEL]func(string) string{
+	glg.DEBG:  glg.Purple,
+	glg.TRACE: glg.Yellow,
+	glg.INFO:  glg.Green,
+	glg.OK:    glg.Cyan,
+	glg.WARN:  glg.Orange,
+	glg.ERR:   glg.Red,
+	glg.FAIL:  glg.Red,
+	glg.FATAL: glg.Red,
+}
+
+func (f *filter) print(w io.Writer, e *glg.Entry) error {
+	if !f.match(e) {
+		return nil
+	}
+	if f.output == "json" {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(b, '\n'))
+		return err
+	}
+	line := e.String()
+	if color, ok := levelColors[e.Level]; ok && f.colorize {
+		line = color(line)
+	}
+	_, err := io.WriteString(w, line+"\n")
+	return err
+}
+
+// copyEntries prints the matched entries of r
+func (f *filter) copyEntries(w io.Writer, r io.Reader) error {
+	lr := glg.NewReader(r)
+	for {
+		e, err := lr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = f.print(w, e); err != nil {
+			return err
+		}
+	}
+}
+
+// eachInput calls fn with the named files, or stdin when no files are given
+func eachInput(names []string, stdin io.Reader, fn func(io.Reader) error) error {
+	if len(names) == 0 {
+		return fn(stdin)
+	}
+	for _, name := range names {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		err = fn(file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runCat(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("cat", flag.ContinueOnError)
+	f := newFilter(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := f.init(stdout); err != nil {
+		return err
+	}
+	return eachInput(fs.Args(), stdin, func(r io.Reader) error {
+		return f.copyEntries(stdout, r)
+	})
+}
+
+func runTail(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("tail", flag.ContinueOnError)
+	f := newFilter(fs)
+	fromStart := fs.Bool("from-start", false, "print the existing entries before following")
+	interval := fs.Duration("interval", 200*time.Millisecond, "polling interval")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("tail requires exactly one file")
+	}
+	if err := f.init(stdout); err != nil {
+		return err
+	}
+	t, err := newTailer(fs.Arg(0), *fromStart)
+	if err != nil {
+		return err
+	}
+	defer t.close()
+	// the last entry is kept until the next entry starts or nothing is written for the interval,
+	// so its continuation lines read by the next call are not printed as separate entries
+	sp := newEntrySplitter()
+	for {
+		chunk, err := t.next()
+		if err != nil {
+			return err
+		}
+		if len(chunk) == 0 {
+			if err = f.copyEntries(stdout, bytes.NewReader(sp.flush())); err != nil {
+				return err
+			}
+			time.Sleep(*interval)
+			continue
+		}
+		if err = f.copyEntries(stdout, bytes.NewReader(sp.add(chunk))); err != nil {
+			return err
+		}
+	}
+}
+
+// maxPendingEntry is the size of the last entry kept by entrySplitter,
+// the longer entry is printed and its following continuation lines are printed as separate entries
+const maxPendingEntry = 1 << 20
+
+// entrySplitter splits the complete lines into the finished entries and the lines of the last entry,
+// each line is parsed once when it is added
+type entrySplitter struct {
+	src     bytes.Reader
+	rd      *glg.Reader
+	pending []byte
+}
+
+func newEntrySplitter() *entrySplitter {
+	s := new(entrySplitter)
+	s.rd = glg.NewReader(&s.src)
+	return s
+}
+
+// add appends the complete lines and returns the entries finished by them
+func (s *entrySplitter) add(lines []byte) (done []byte) {
+	start := -1
+	for off := 0; off < len(lines); {
+		end := len(lines)
+		if i := bytes.IndexByte(lines[off:], '\n'); i >= 0 {
+			end = off + i + 1
+		}
+		if s.startsEntry(lines[off:end]) {
+			start = off
+		}
+		off = end
+	}
+	if start >= 0 {
+		done = append(s.pending, lines[:start]...)
+		s.pending = append([]byte(nil), lines[start:]...)
+	} else {
+		s.pending = append(s.pending, lines...)
+	}
+	if len(s.pending) > maxPendingEntry {
+		done = append(done, s.pending...)
+		s.pending = nil
+	}
+	return done
+}
+
+// flush returns the last entry
+func (s *entrySplitter) flush() []byte {
+	done := s.pending
+	s.pending = nil
+	return done
+}
+
+// startsEntry reports whether the line starts an entry, the other lines continue the former entry
+func (s *entrySplitter) startsEntry(line []byte) bool {
+	s.src.Reset(line)
+	s.rd.Reset(&s.src)
+	e, err := s.rd.Read()
+	return err == nil && (e.Level != glg.UNKNOWN || e.Tag != "")
+}
+
+// tailer reads the complete lines appended to the file, following truncation and rotation
+type tailer struct {
+	name string
+	file *os.File
+	info os.FileInfo
+	off  int64
+	rest []byte
+}
+
+func newTailer(name string, fromStart bool) (*tailer, error) {
+	t := &tailer{name: name}
+	if err := t.open(); err != nil {
+		return nil, err
+	}
+	if !fromStart {
+		t.off = t.info.Size()
+	}
+	return t, nil
+}
+
+func (t *tailer) open() (err error) {
+	if t.file, err = os.Open(t.name); err != nil {
+		return err
+	}
+	if t.info, err = t.file.Stat(); err != nil {
+		t.file.Close()
+		return err
+	}
+	t.off, t.rest = 0, nil
+	return nil
+}
+
+func (t *tailer) close() {
+	t.file.Close()
+}
+
+// next returns the complete lines written since the last call, it returns nil when nothing is written
+func (t *tailer) next() ([]byte, error) {
+	if info, err := os.Stat(t.name); err == nil && !os.SameFile(info, t.info) {
+		// rotated, drain the old file first
+		if chunk, err := t.read(); err != nil || len(chunk) != 0 {
+			return chunk, err
+		}
+		t.file.Close()
+		if err = t.open(); err != nil {
+			return nil, err
+		}
+	}
+	info, err := t.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < t.off {
+		t.off, t.rest = 0, nil
+	}
+	return t.read()
+}
+
+func (t *tailer) read() ([]byte, error) {
+	buf := make([]byte, 64<<10)
+	n, err := t.file.ReadAt(buf, t.off)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	t.off += int64(n)
+	data := append(t.rest, buf[:n]...)
+	i := bytes.LastIndexByte(data, '\n')
+	if i < 0 {
+		t.rest = data
+		return nil, nil
+	}
+	t.rest = append([]byte(nil), data[i+1:]...)
+	return data[:i+1], nil
+}
+
+func runStats(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	f := newFilter(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	f.color = "never"
+	if err := f.init(stdout); err != nil {
+		return err
+	}
+	counts := make(map[string]int)
+	var total int
+	var first, last time.Time
+	err := eachInput(fs.Args(), stdin, func(r io.Reader) error {
+		lr := glg.NewReader(r)
+		for {
+			e, err := lr.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if !f.match(e) {
+				continue
+			}
+			total++
+			tag := e.Tag
+			if tag == "" {
+				tag = glg.UNKNOWN.String()
+			}
+			counts[tag]++
+			if !e.Time.IsZero() {
+				if first.IsZero() || e.Time.Before(first) {
+					first = e.Time
+				}
+				if e.Time.After(last) {
+					last = e.Time
+				}
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	tags := make([]string, 0, len(counts))
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if counts[tags[i]] != counts[tags[j]] {
+			return counts[tags[i]] > counts[tags[j]]
+		}
+		return tags[i] < tags[j]
+	})
+	if f.output == "json" {
+		b, err := json.Marshal(map[string]interface{}{
+			"total":  total,
+			"levels": counts,
+			"first":  first,
+			"last":   last,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = stdout.Write(append(b, '\n'))
+		return err
+	}
+	fmt.Fprintf(stdout, "total\t%d\n", total)
+	for _, tag := range tags {
+		fmt.Fprintf(stdout, "%s\t%d\n", tag, counts[tag])
+	}
+	if !first.IsZero() {
+		fmt.Fprintf(stdout, "first\t%s\nlast\t%s\n", first.Format(time.RFC3339), last.Format(time.RFC3339))
+		if d := last.Sub(first); d > 0 {
+			fmt.Fprintf(stdout, "rate\t%.2f/s\n", float64(total)/d.Seconds())
+		}
+	}
+	return nil
+}
+
+// keyFlags is the repeatable -key-file id=path and -key-env id=NAME flag, the key is hex in the file or the variable,
+// it is never passed on the command line where ps and the shell history would see it
+type keyFlags struct {
+	keys   map[string][]byte
+	lookup func(string) ([]byte, error)
+}
+
+func (k keyFlags) String() string {
+	ids := make([]string, 0, len(k.keys))
+	for id := range k.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+func (k keyFlags) Set(s string) error {
+	i := strings.IndexByte(s, '=')
+	if i < 0 {
+		return fmt.Errorf("invalid key %q, want id=source", s)
+	}
+	b, err := k.lookup(s[i+1:])
+	if err != nil {
+		return err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil {
+		return fmt.Errorf("invalid key %q: %w", s[:i], err)
+	}
+	k.keys[s[:i]] = key
+	return nil
+}
+
+func lookupEnv(name string) ([]byte, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("environment variable %s is not set", name)
+	}
+	return []byte(v), nil
+}
+
+func runDecrypt(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("decrypt", flag.ContinueOnError)
+	keys := map[string][]byte{}
+	fs.Var(keyFlags{keys: keys, lookup: os.ReadFile}, "key-file", "decryption key, id=path of the file holding the hex key, repeatable for rotated keys")
+	fs.Var(keyFlags{keys: keys, lookup: lookupEnv}, "key-env", "decryption key, id=name of the variable holding the hex key, repeatable for rotated keys")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return errors.New("decrypt requires -key-file or -key-env")
+	}
+	return eachInput(fs.Args(), stdin, func(r io.Reader) error {
+		return glg.Decrypt(stdout, r, keys)
+	})
+}
+
+func runDecode(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("decode", flag.ContinueOnError)
+	f := newFilter(fs)
+	format := fs.String("format", "cbor", "binary format of the entries, cbor or msgpack")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	bf, err := glg.ParseBinaryFormat(*format)
+	if err != nil {
+		return err
+	}
+	if err = f.init(stdout); err != nil {
+		return err
+	}
+	return eachInput(fs.Args(), stdin, func(r io.Reader) error {
+		br := glg.NewBinaryReader(r, bf)
+		for {
+			e, err := br.Read()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err = f.print(stdout, e); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,251 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gmazay/glg"
+)
+
+const testLog = "2021-03-04 05:06:07\t[INFO]:\tstarted\tport=8080\n" +
+	"2021-03-04 05:06:08\t[WARN]:\tslow\tpath=/a\n" +
+	"2021-03-04 05:06:09\t[ERR]:\tfailed\tpath=/b code=500\n"
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "cat",
+			args: []string{"cat", "-color", "never"},
+			want: testLog,
+		},
+		{
+			name: "cat level",
+			args: []string{"cat", "-level", "WARN"},
+			want: "2021-03-04 05:06:08\t[WARN]:\tslow\tpath=/a\n" +
+				"2021-03-04 05:06:09\t[ERR]:\tfailed\tpath=/b code=500\n",
+		},
+		{
+			name: "cat field",
+			args: []string{"cat", "-field", "path=/b"},
+			want: "2021-03-04 05:06:09\t[ERR]:\tfailed\tpath=/b code=500\n",
+		},
+		{
+			name: "cat grep json",
+			args: []string{"cat", "-grep", "start", "-o", "json"},
+			want: `{"date":"2021-03-04 05:06:07","level":"INFO","detail":"started","fields":{"port":8080}}` + "\n",
+		},
+		{
+			name: "cat color",
+			args: []string{"cat", "-color", "always", "-level", "ERR"},
+			want: glg.Red("2021-03-04 05:06:09\t[ERR]:\tfailed\tpath=/b code=500") + "\n",
+		},
+		{
+			name: "stats",
+			args: []string{"stats"},
+			want: "total\t3\nERR\t1\nINFO\t1\nWARN\t1\n" +
+				"first\t2021-03-04T05:06:07Z\nlast\t2021-03-04T05:06:09Z\nrate\t1.50/s\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			if err := run(tt.args, strings.NewReader(testLog), out); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("run(%v) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_Error(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"unknown"},
+		{"cat", "-level", "NOPE"},
+		{"cat", "-o", "yaml"},
+		{"cat", "-field", "nokey"},
+		{"tail"},
+		{"decrypt"},
+		{"decrypt", "-key-env", "k1=GLG_TEST_UNSET_KEY"},
+		{"decode", "-format", "avro"},
+	} {
+		if err := run(args, strings.NewReader(""), new(bytes.Buffer)); err == nil {
+			t.Errorf("run(%v) error = nil", args)
+		}
+	}
+}
+
+func TestRun_Decrypt(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	enc := new(bytes.Buffer)
+	w, err := glg.NewEncryptWriter(enc, "k1", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write([]byte(testLog)); err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(t.TempDir(), "enc.log")
+	if err = os.WriteFile(name, enc.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "k1.key")
+	if err = os.WriteFile(keyFile, []byte(hex.EncodeToString(key)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GLG_TEST_KEY", hex.EncodeToString(key))
+	for _, args := range [][]string{
+		{"decrypt", "-key-file", "k1=" + keyFile, name},
+		{"decrypt", "-key-env", "k1=GLG_TEST_KEY", name},
+	} {
+		out := new(bytes.Buffer)
+		if err = run(args, nil, out); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != testLog {
+			t.Errorf("run(%v) = %q, want %q", args, out.String(), testLog)
+		}
+	}
+}
+
+func TestRun_Decode(t *testing.T) {
+	enc := new(bytes.Buffer)
+	g := glg.New().SetMode(glg.WRITER).SetWriter(enc).SetLineTraceMode(glg.TraceLineNone).DisableTimestamp().SetEncoder(glg.MsgPack)
+	g.Info("started", glg.Int("port", 80))
+	g.Warn("slow")
+	out := new(bytes.Buffer)
+	if err := run([]string{"decode", "-format", "msgpack", "-level", "WARN"}, bytes.NewReader(enc.Bytes()), out); err != nil {
+		t.Fatal(err)
+	}
+	if want := "[WARN]:\tslow\n"; out.String() != want {
+		t.Errorf("run(decode) = %q, want %q", out.String(), want)
+	}
+	out.Reset()
+	if err := run([]string{"decode", "-format", "msgpack", "-o", "json"}, bytes.NewReader(enc.Bytes()), out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"detail":"started","fields":{"port":80}`) {
+		t.Errorf("run(decode -o json) = %q", out.String())
+	}
+}
+
+func TestTailer(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(name, []byte("old\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tl, err := newTailer(name, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tl.close()
+	appendFile := func(s string) {
+		t.Helper()
+		f, err := os.OpenFile(name, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(s)
+		f.Close()
+	}
+	next := func(want string) {
+		t.Helper()
+		got, err := tl.next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("tailer.next() = %q, want %q", got, want)
+		}
+	}
+	next("")
+	appendFile("a\nb")
+	next("a\n")
+	appendFile("\n")
+	next("b\n")
+	if err = os.Rename(name, name+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile("c\n")
+	next("c\n")
+	if err = os.Truncate(name, 0); err != nil {
+		t.Fatal(err)
+	}
+	next("")
+	appendFile("d\n")
+	next("d\n")
+}
+
+func TestEntrySplitter(t *testing.T) {
+	tests := []struct {
+		data, done, last string
+	}{
+		{
+			data: "[INFO]:\ta\n[ERR]:\tfailed\n\tat main.go:10\n",
+			done: "[INFO]:\ta\n",
+			last: "[ERR]:\tfailed\n\tat main.go:10\n",
+		},
+		{
+			data: "{\"level\":\"INFO\",\"detail\":\"a\"}\n[WARN]:\tb\n",
+			done: "{\"level\":\"INFO\",\"detail\":\"a\"}\n",
+			last: "[WARN]:\tb\n",
+		},
+		{
+			data: "\tcontinued\n",
+			last: "\tcontinued\n",
+		},
+	}
+	for _, tt := range tests {
+		sp := newEntrySplitter()
+		done := sp.add([]byte(tt.data))
+		if last := sp.flush(); string(done) != tt.done || string(last) != tt.last {
+			t.Errorf("entrySplitter(%q) = %q, %q, want %q, %q", tt.data, done, last, tt.done, tt.last)
+		}
+	}
+
+	// the continuation line read by the next call joins the pending entry
+	sp := newEntrySplitter()
+	sp.add([]byte("[INFO]:\ta\n[ERR]:\tfailed\n"))
+	done := sp.add([]byte("\tat main.go:10\n[INFO]:\tb\n"))
+	if last := sp.flush(); string(done) != "[ERR]:\tfailed\n\tat main.go:10\n" || string(last) != "[INFO]:\tb\n" {
+		t.Errorf("entrySplitter() = %q, %q", done, last)
+	}
+
+	// the entry longer than maxPendingEntry is not kept
+	sp.add([]byte("[ERR]:\tfailed\n"))
+	if done = sp.add(bytes.Repeat([]byte("\tat main.go:10\n"), maxPendingEntry/15+1)); len(done) <= maxPendingEntry {
+		t.Errorf("entrySplitter() kept %d bytes", len(sp.pending))
+	}
+}
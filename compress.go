@@ -0,0 +1,156 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"compress/gzip"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultCompressFlushInterval is the default interval of the flush boundaries of CompressWriter
+const DefaultCompressFlushInterval = time.Second
+
+// CompressEncoder is the streaming compressor of CompressWriter, e.g. *gzip.Writer or the zstd encoder
+// of github.com/klauspost/compress/zstd, whose Flush makes the written data decodable from the stream
+type CompressEncoder interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// CompressWriter is io.Writer compressing the entries by CompressEncoder.
+// The encoder is flushed at the interval after the entry is written, so `zcat | tail` of the live file
+// shows the entries and at most the interval of entries is lost when the process crashes.
+// Close writes the end of the stream and does not close the underlying writer,
+// the streams appended to the same file by the restarted process are read by zcat as one
+type CompressWriter struct {
+	mu       sync.Mutex
+	enc      CompressEncoder
+	interval time.Duration
+	timer    *time.Timer
+	closed   bool
+}
+
+// NewCompressWriter returns CompressWriter of enc writing to the underlying writer, e.g. for zstd
+//
+//	enc, _ := zstd.NewWriter(file)
+//	w := glg.NewCompressWriter(enc)
+func NewCompressWriter(enc CompressEncoder) *CompressWriter {
+	return &CompressWriter{
+		enc:      enc,
+		interval: DefaultCompressFlushInterval,
+	}
+}
+
+// NewGzipWriter returns CompressWriter writing the gzip stream of the compression level to w
+func NewGzipWriter(w io.Writer, level int) (*CompressWriter, error) {
+	gz, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		return nil, err
+	}
+	return NewCompressWriter(gz), nil
+}
+
+// SetFlushInterval sets the interval of the flush boundaries, interval <= 0 flushes every entry
+func (c *CompressWriter) SetFlushInterval(interval time.Duration) *CompressWriter {
+	c.mu.Lock()
+	c.interval = interval
+	c.mu.Unlock()
+	return c
+}
+
+// Write compresses p and schedules the flush
+func (c *CompressWriter) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, ErrWriterClosed
+	}
+	n, err := c.enc.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if c.interval <= 0 {
+		return n, c.enc.Flush()
+	}
+	if c.timer == nil {
+		c.timer = time.AfterFunc(c.interval, func() {
+			c.Flush()
+		})
+	}
+	return n, nil
+}
+
+// Flush flushes the compressed entries to the underlying writer
+func (c *CompressWriter) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	if c.closed {
+		return nil
+	}
+	return c.enc.Flush()
+}
+
+// Close writes the end of the compressed stream
+func (c *CompressWriter) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.enc.Close()
+}
+
+// GzipMiddleware returns the middleware compressing the entries by gzip of the level, flushed at DefaultCompressFlushInterval
+func GzipMiddleware(level int) (WriterMiddleware, error) {
+	if _, err := gzip.NewWriterLevel(io.Discard, level); err != nil {
+		return nil, err
+	}
+	return func(w io.Writer) io.Writer {
+		c, _ := NewGzipWriter(w, level)
+		return c
+	}, nil
+}
+
+// CompressMiddleware returns the middleware compressing the entries by the encoder of newEncoder, e.g. for zstd
+//
+//	glg.CompressMiddleware(func(w io.Writer) glg.CompressEncoder {
+//		enc, _ := zstd.NewWriter(w)
+//		return enc
+//	})
+func CompressMiddleware(newEncoder func(w io.Writer) CompressEncoder) WriterMiddleware {
+	return func(w io.Writer) io.Writer {
+		return NewCompressWriter(newEncoder(w))
+	}
+}
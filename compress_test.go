@@ -0,0 +1,126 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+// gunzipLive returns the decompressed entries readable from the gzip stream written so far
+func gunzipLive(b []byte) string {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return ""
+	}
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+func TestCompressWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, err := NewGzipWriter(buf, gzip.BestSpeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetFlushInterval(0)
+	g := New().SetMode(WRITER).SetWriter(w).SetLineTraceMode(TraceLineNone).DisableTimestamp()
+	g.Info("first")
+	if got := gunzipLive(buf.Bytes()); got != "[INFO]:\tfirst\n" {
+		t.Errorf("live stream = %q", got)
+	}
+	g.Info("second")
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write([]byte("closed")); err != ErrWriterClosed {
+		t.Errorf("Write() after Close error = %v", err)
+	}
+
+	// the stream appended by the restarted process
+	w, _ = NewGzipWriter(buf, gzip.DefaultCompression)
+	w.Write([]byte("restarted\n"))
+	w.Close()
+	r, err := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil || string(out) != "[INFO]:\tfirst\n[INFO]:\tsecond\nrestarted\n" {
+		t.Errorf("stream = %q, %v", out, err)
+	}
+
+	if _, err = NewGzipWriter(buf, 42); err == nil {
+		t.Error("NewGzipWriter() error = nil for the invalid level")
+	}
+}
+
+func TestCompressWriter_FlushInterval(t *testing.T) {
+	buf := new(lockedBuffer)
+	w, _ := NewGzipWriter(buf, gzip.DefaultCompression)
+	w.SetFlushInterval(10 * time.Millisecond)
+	w.Write([]byte("entry\n"))
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		buf.mu.Lock()
+		got := gunzipLive(buf.Bytes())
+		buf.mu.Unlock()
+		if got == "entry\n" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the entry is not flushed, stream = %q", got)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	w.Close()
+}
+
+func TestGzipMiddleware(t *testing.T) {
+	if _, err := GzipMiddleware(-5); err == nil {
+		t.Error("GzipMiddleware() error = nil for the invalid level")
+	}
+	gz, err := GzipMiddleware(gzip.BestCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zip := CompressMiddleware(func(w io.Writer) CompressEncoder {
+		return gzip.NewWriter(w)
+	})
+	a, b := new(closeBuffer), new(closeBuffer)
+	g := New().SetMode(WRITER).SetLineTraceMode(TraceLineNone).DisableTimestamp().
+		SetLevelWriter(INFO, a).SetLevelWriter(WARN, b).
+		UseLevelMiddleware(INFO, gz).UseLevelMiddleware(WARN, zip)
+	g.Info("info")
+	g.Warn("warn")
+	if err = g.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if gunzipLive(a.Bytes()) != "[INFO]:\tinfo\n" || gunzipLive(b.Bytes()) != "[WARN]:\twarn\n" || a.closed != 1 || b.closed != 1 {
+		t.Errorf("compressed writers = %q, %q", gunzipLive(a.Bytes()), gunzipLive(b.Bytes()))
+	}
+}
@@ -0,0 +1,192 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"io"
+	"sort"
+	"sync/atomic"
+)
+
+// Snapshot is the configuration of the instance returned by Config, e.g. to log or assert the logging setup
+type Snapshot struct {
+	JSON           bool          `json:"json"`
+	UTC            bool          `json:"utc"`
+	Epoch          bool          `json:"epoch"`
+	Sanitize       bool          `json:"sanitize"`
+	Human          bool          `json:"human"`
+	CloudLogging   bool          `json:"cloud_logging"`
+	StrictFormat   bool          `json:"strict_format"`
+	OrderedWrite   bool          `json:"ordered_write"`
+	Async          bool          `json:"async"`
+	CallerDepth    int           `json:"caller_depth"`
+	MaxMessageSize int           `json:"max_message_size,omitempty"`
+	MaxFieldSize   int           `json:"max_field_size,omitempty"`
+	Levels         []LevelConfig `json:"levels"`
+}
+
+// LevelConfig is the configuration of the level in Snapshot
+type LevelConfig struct {
+	Level     LEVEL  `json:"level"`
+	Tag       string `json:"tag"`
+	Rank      LEVEL  `json:"rank"`
+	Mode      MODE   `json:"mode"`
+	Enabled   bool   `json:"enabled"`
+	Color     bool   `json:"color"`
+	JSON      bool   `json:"json"`
+	Timestamp bool   `json:"timestamp"`
+	// Trace is "none", "short" or "long"
+	Trace string `json:"trace"`
+	// Std and Writers are the Name of the writers or their types
+	Std     string   `json:"std,omitempty"`
+	Writers []string `json:"writers,omitempty"`
+}
+
+// GetCurrentLevel returns the lowest ranked enabled level, e.g. the level set by SetLevel, UNKNOWN when all levels are disabled
+func (g *Glg) GetCurrentLevel() LEVEL {
+	cur, min := UNKNOWN, UNKNOWN
+	g.logger.Range(func(lv LEVEL, l *logger) bool {
+		if l.mode == NONE {
+			return true
+		}
+		if r := l.rankOf(lv); r < min || (r == min && lv < cur) {
+			cur, min = lv, r
+		}
+		return true
+	})
+	return cur
+}
+
+// Levels returns the built-in and custom levels of the instance in ascending order
+func (g *Glg) Levels() []LEVEL {
+	var lvs []LEVEL
+	g.logger.Range(func(lv LEVEL, _ *logger) bool {
+		lvs = append(lvs, lv)
+		return true
+	})
+	sort.Slice(lvs, func(i, j int) bool { return lvs[i] < lvs[j] })
+	return lvs
+}
+
+// LevelString returns the tag of lv, including the custom levels and the tags set by SetLevelString,
+// it returns empty string for the unknown levels
+func (g *Glg) LevelString(lv LEVEL) string {
+	if l, ok := g.logger.Load(lv); ok {
+		return l.tag
+	}
+	return ""
+}
+
+// Config returns the snapshot of the configuration of the instance
+func (g *Glg) Config() Snapshot {
+	o := g.opts()
+	s := Snapshot{
+		JSON:           o.enableJSON,
+		UTC:            o.enableUTC,
+		Epoch:          o.enableEpoch,
+		Sanitize:       o.enableSanitize,
+		Human:          o.enableHuman,
+		CloudLogging:   o.cloudLogging,
+		StrictFormat:   o.strictFormat,
+		OrderedWrite:   atomic.LoadInt32(&g.ordered) != 0,
+		Async:          g.asyncer() != nil,
+		CallerDepth:    o.callerDepth,
+		MaxMessageSize: o.maxMessageSize,
+		MaxFieldSize:   o.maxFieldSize,
+	}
+	for _, lv := range g.Levels() {
+		l, ok := g.logger.Load(lv)
+		if !ok {
+			continue
+		}
+		lc := LevelConfig{
+			Level:     lv,
+			Tag:       l.tag,
+			Rank:      l.rankOf(lv),
+			Mode:      l.mode,
+			Enabled:   l.mode != NONE,
+			Color:     l.isColor,
+			JSON:      l.isJSON(o.enableJSON),
+			Timestamp: !l.disableTimestamp,
+			Trace:     "none",
+		}
+		switch {
+		case l.traceMode&TraceLineLong != 0:
+			lc.Trace = "long"
+		case l.traceMode&TraceLineShort != 0:
+			lc.Trace = "short"
+		}
+		if l.std != nil {
+			lc.Std = writerName(l.std)
+		}
+		lc.Writers = writerNames(l.writer)
+		s.Levels = append(s.Levels, lc)
+	}
+	return s
+}
+
+// GetCurrentLevel returns the lowest ranked enabled level of the global instance
+func GetCurrentLevel() LEVEL {
+	return Get().GetCurrentLevel()
+}
+
+// Levels returns the levels of the global instance in ascending order
+func Levels() []LEVEL {
+	return Get().Levels()
+}
+
+// LevelString returns the tag of lv of the global instance
+func LevelString(lv LEVEL) string {
+	return Get().LevelString(lv)
+}
+
+// levelName returns the name of lv for the writers bound to the level, the custom levels are named by the global instance
+// where they are registered by AddStdLevel or AddErrLevel, it returns empty string for the unknown levels
+func levelName(lv LEVEL) string {
+	if name := lv.String(); name != "" {
+		return name
+	}
+	return LevelString(lv)
+}
+
+// Config returns the snapshot of the configuration of the global instance
+func Config() Snapshot {
+	return Get().Config()
+}
+
+// writerNames returns the names of w and the writers added by AddWriter
+func writerNames(w io.Writer) []string {
+	if w == nil {
+		return nil
+	}
+	f, ok := w.(fanout)
+	if !ok {
+		return []string{writerName(w)}
+	}
+	names := make([]string, 0, len(f))
+	for _, w := range f {
+		names = append(names, writerNames(w)...)
+	}
+	return names
+}
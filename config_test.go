@@ -0,0 +1,136 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestGlg_GetCurrentLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		g    func() *Glg
+		want LEVEL
+	}{
+		{
+			name: "default",
+			g:    New,
+			want: DEBG,
+		},
+		{
+			name: "set level",
+			g:    func() *Glg { return New().SetLevel(WARN) },
+			want: WARN,
+		},
+		{
+			name: "disabled level mode",
+			g:    func() *Glg { return New().SetLevelMode(DEBG, NONE) },
+			want: TRACE,
+		},
+		{
+			name: "ranked custom level",
+			g: func() *Glg {
+				g := New().AddStdLevel("NOTICE", STD, false).SetLevel(WARN)
+				lv := g.TagStringToLevel("NOTICE")
+				return g.SetLevelRank(lv, INFO).SetLevel(lv)
+			},
+			want: INFO,
+		},
+		{
+			name: "all disabled",
+			g:    func() *Glg { return New().SetMode(NONE) },
+			want: UNKNOWN,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.g().GetCurrentLevel(); got != tt.want {
+				t.Errorf("GetCurrentLevel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_Levels(t *testing.T) {
+	g := New().AddStdLevel("AUDIT", STD, false)
+	lv := g.TagStringToLevel("AUDIT")
+	want := []LEVEL{DEBG, TRACE, PRINT, LOG, INFO, OK, WARN, ERR, FAIL, FATAL, lv}
+	if got := g.Levels(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Levels() = %v, want %v", got, want)
+	}
+	if got := g.LevelString(lv); got != "AUDIT" {
+		t.Errorf("LevelString(%d) = %q, want AUDIT", lv, got)
+	}
+	if got := g.SetLevelString(WARN, "WARNING").LevelString(WARN); got != "WARNING" {
+		t.Errorf("LevelString(WARN) = %q, want WARNING", got)
+	}
+	if got := g.LevelString(lv + 1); got != "" {
+		t.Errorf("LevelString(unknown) = %q, want empty", got)
+	}
+}
+
+func TestGlg_Config(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(BOTH).SetWriter(buf).AddLevelWriter(ERR, io.Discard).
+		SetLevelLineTraceMode(ERR, TraceLineShort).
+		EnableLevelColor(ERR).
+		EnableStrictFormat().
+		EnableJSON().
+		SetLevel(INFO)
+	s := g.Config()
+	if !s.JSON || !s.StrictFormat || s.Async || s.CallerDepth != DefaultCallerDepth || len(s.Levels) != 10 {
+		t.Fatalf("Config() = %+v", s)
+	}
+	var debg, errc LevelConfig
+	for _, lc := range s.Levels {
+		switch lc.Level {
+		case DEBG:
+			debg = lc
+		case ERR:
+			errc = lc
+		}
+	}
+	if debg.Enabled || debg.Mode != NONE || debg.Tag != "DEBG" {
+		t.Errorf("Config() DEBG = %+v", debg)
+	}
+	want := LevelConfig{
+		Level:     ERR,
+		Tag:       "ERR",
+		Rank:      ERR,
+		Mode:      BOTH,
+		Enabled:   true,
+		Color:     true,
+		JSON:      true,
+		Timestamp: true,
+		Trace:     "short",
+		Std:       os.Stderr.Name(),
+		Writers:   []string{"*bytes.Buffer", "io.discard"},
+	}
+	if !reflect.DeepEqual(errc, want) {
+		t.Errorf("Config() ERR = %+v, want %+v", errc, want)
+	}
+}
@@ -0,0 +1,44 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import "context"
+
+// ctxKey is the context key of the logger
+type ctxKey struct{}
+
+// NewContext returns the copy of ctx carrying g, e.g. the middleware stores the request scoped logger made by With
+func NewContext(ctx context.Context, g *Glg) context.Context {
+	return context.WithValue(ctx, ctxKey{}, g)
+}
+
+// FromContext returns the logger stored by NewContext, the global logger is returned when ctx has no logger
+func FromContext(ctx context.Context) *Glg {
+	if ctx != nil {
+		if g, ok := ctx.Value(ctxKey{}).(*Glg); ok && g != nil {
+			return g
+		}
+	}
+	return Get()
+}
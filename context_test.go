@@ -0,0 +1,76 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	g := New().With(String("request_id", "abc"))
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want *Glg
+	}{
+		{
+			name: "stored logger",
+			ctx:  NewContext(context.Background(), g),
+			want: g,
+		},
+		{
+			name: "no logger",
+			ctx:  context.Background(),
+			want: Get(),
+		},
+		{
+			name: "nil logger",
+			ctx:  NewContext(context.Background(), nil),
+			want: Get(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FromContext(tt.ctx); got != tt.want {
+				t.Errorf("FromContext() = %p, want %p", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_HTTPLoggerFunc_Context(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone)
+	h := g.HTTPLoggerFunc("ctx", func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).With(String("path", r.URL.Path)).Info("handled")
+	})
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+	if !strings.Contains(buf.String(), "handled") || !strings.Contains(buf.String(), "path=/users") {
+		t.Errorf("handler output = %q", buf.String())
+	}
+}
@@ -0,0 +1,138 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	json "github.com/goccy/go-json"
+)
+
+// DebugHandler returns the admin handler of the instance, the endpoints are matched by the last path element
+// so it can be mounted under any prefix, e.g. mux.Handle("/debug/log/", g.DebugHandler()):
+//
+//	level    GET returns the current level and the level settings, PUT or POST ?level=WARN calls SetLevel
+//	recent   GET serves the last entries of EnableRecent, see RecentHandler
+//	tail     GET streams the new entries, see TailHandler
+//	status   GET returns Status of the writers
+//	config   GET returns Config
+//	pprof/   serves the profiles like net/http/pprof, e.g. pprof/heap, pprof/profile?seconds=30 and pprof/trace
+//
+// The other paths list the endpoints. The profiles are served by runtime/pprof,
+// so importing glg does not register net/http/pprof to http.DefaultServeMux
+func (g *Glg) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if name, ok := pprofName(r.URL.Path); ok {
+			servePprof(w, r, name)
+			return
+		}
+		switch path.Base(r.URL.Path) {
+		case "level":
+			g.serveLevel(w, r)
+		case "recent":
+			g.serveRecent(w, r)
+		case "tail":
+			g.TailHandler().ServeHTTP(w, r)
+		case "status":
+			serveJSON(w, g.Status())
+		case "config":
+			serveJSON(w, g.Config())
+		default:
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			io.WriteString(w, "level\nrecent\ntail\nstatus\nconfig\npprof/\n")
+		}
+	})
+}
+
+// DebugHandler returns the admin handler of the global instance
+func DebugHandler() http.Handler {
+	return Get().DebugHandler()
+}
+
+// pprofName returns the profile name of the pprof path under any prefix, the name is empty for the index
+func pprofName(p string) (string, bool) {
+	if strings.HasSuffix(p, "/pprof") {
+		return "", true
+	}
+	i := strings.LastIndex(p, "/pprof/")
+	if i < 0 {
+		return "", false
+	}
+	return p[i+len("/pprof/"):], true
+}
+
+// serveLevel serves the current level and sets the level by the level parameter or the request body
+func (g *Glg) serveLevel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+	case http.MethodPut, http.MethodPost:
+		s := r.URL.Query().Get("level")
+		if s == "" {
+			body, err := io.ReadAll(io.LimitReader(r.Body, 64))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			s = string(body)
+			var v struct {
+				Level string `json:"level"`
+			}
+			if json.Unmarshal(body, &v) == nil {
+				s = v.Level
+			}
+		}
+		lv := g.Atol(s)
+		if lv == UNKNOWN {
+			http.Error(w, "unknown log level "+strconv.Quote(strings.TrimSpace(s)), http.StatusBadRequest)
+			return
+		}
+		g.SetLevel(lv)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	serveJSON(w, struct {
+		Level  string        `json:"level"`
+		Levels []LevelConfig `json:"levels"`
+	}{
+		Level:  g.LevelString(g.GetCurrentLevel()),
+		Levels: g.Config().Levels,
+	})
+}
+
+// serveJSON writes v as the JSON response
+func serveJSON(w http.ResponseWriter, v interface{}) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(data, '\n'))
+}
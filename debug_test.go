@@ -0,0 +1,205 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	json "github.com/goccy/go-json"
+)
+
+func TestGlg_DebugHandler(t *testing.T) {
+	g := New().SetMode(WRITER).SetWriter(new(strings.Builder)).EnableRecent(10)
+	h := g.DebugHandler()
+	g.Info("hello")
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		want       string
+		wantLevel  LEVEL
+	}{
+		{
+			name:       "index",
+			method:     http.MethodGet,
+			target:     "/debug/log/",
+			wantStatus: http.StatusOK,
+			want:       "recent",
+		},
+		{
+			name:       "get level",
+			method:     http.MethodGet,
+			target:     "/debug/log/level",
+			wantStatus: http.StatusOK,
+			want:       `"level": "DEBG"`,
+		},
+		{
+			name:       "set level by query",
+			method:     http.MethodPut,
+			target:     "/debug/log/level?level=warn",
+			wantStatus: http.StatusOK,
+			want:       `"level": "WARN"`,
+			wantLevel:  WARN,
+		},
+		{
+			name:       "set level by json",
+			method:     http.MethodPost,
+			target:     "/level",
+			body:       `{"level":"ERR"}`,
+			wantStatus: http.StatusOK,
+			want:       `"level": "ERR"`,
+			wantLevel:  ERR,
+		},
+		{
+			name:       "unknown level",
+			method:     http.MethodPut,
+			target:     "/level",
+			body:       "LOUD",
+			wantStatus: http.StatusBadRequest,
+			want:       `"LOUD"`,
+		},
+		{
+			name:       "method not allowed",
+			method:     http.MethodDelete,
+			target:     "/level",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "recent",
+			method:     http.MethodGet,
+			target:     "/debug/log/recent?n=1",
+			wantStatus: http.StatusOK,
+			want:       `"detail":"hello"`,
+		},
+		{
+			name:       "status",
+			method:     http.MethodGet,
+			target:     "/status",
+			wantStatus: http.StatusOK,
+			want:       `"recent"`,
+		},
+		{
+			name:       "config",
+			method:     http.MethodGet,
+			target:     "/config",
+			wantStatus: http.StatusOK,
+			want:       `"levels"`,
+		},
+		{
+			name:       "pprof index",
+			method:     http.MethodGet,
+			target:     "/debug/log/pprof/",
+			wantStatus: http.StatusOK,
+			want:       "goroutine",
+		},
+		{
+			name:       "pprof redirect",
+			method:     http.MethodGet,
+			target:     "/debug/log/pprof",
+			wantStatus: http.StatusMovedPermanently,
+		},
+		{
+			name:       "pprof profile",
+			method:     http.MethodGet,
+			target:     "/debug/log/pprof/heap?debug=1",
+			wantStatus: http.StatusOK,
+			want:       "heap profile",
+		},
+		{
+			name:       "pprof cmdline",
+			method:     http.MethodGet,
+			target:     "/debug/log/pprof/cmdline",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "pprof unknown profile",
+			method:     http.MethodGet,
+			target:     "/debug/log/pprof/missing",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "pprof cpu profile",
+			method:     http.MethodGet,
+			target:     "/debug/log/pprof/profile?seconds=0.01",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "pprof trace",
+			method:     http.MethodGet,
+			target:     "/debug/log/pprof/trace?seconds=0.01",
+			wantStatus: http.StatusOK,
+			want:       "go 1.",
+		},
+		{
+			name:       "pprof symbol",
+			method:     http.MethodGet,
+			target:     "/debug/log/pprof/symbol?" + strconv.FormatUint(uint64(reflect.ValueOf(Get).Pointer()), 10),
+			wantStatus: http.StatusOK,
+			want:       "glg.Get\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rr.Code, tt.wantStatus, rr.Body)
+			}
+			if !strings.Contains(rr.Body.String(), tt.want) {
+				t.Errorf("body = %s, want %s", rr.Body, tt.want)
+			}
+			if tt.wantLevel != 0 && g.GetCurrentLevel() != tt.wantLevel {
+				t.Errorf("GetCurrentLevel() = %v, want %v", g.GetCurrentLevel(), tt.wantLevel)
+			}
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/recent", nil))
+	var entries []JSONFormat
+	if err := json.Unmarshal(rr.Body.Bytes(), &entries); err != nil || len(entries) != 1 {
+		t.Errorf("recent = %s, %v, want 1 entry", rr.Body, err)
+	}
+
+	rr = httptest.NewRecorder()
+	New().DebugHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/recent", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("recent without EnableRecent status = %d, want 404", rr.Code)
+	}
+}
+
+func TestDebugHandler_DefaultServeMux(t *testing.T) {
+	rr := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/debug/pprof/cmdline", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("DefaultServeMux /debug/pprof/cmdline status = %d, want %d", rr.Code, http.StatusNotFound)
+	}
+}
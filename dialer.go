@@ -0,0 +1,113 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// ContextDialer connects the network writers to their endpoints, e.g. *net.Dialer with the local address or the keep-alive,
+// the SOCKS5 dialer of golang.org/x/net/proxy or the dialer of NewHTTPProxyDialer
+type ContextDialer interface {
+	DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// httpProxyDialer tunnels the connections through the HTTP proxy by CONNECT
+type httpProxyDialer struct {
+	proxy   *url.URL
+	forward ContextDialer
+}
+
+// NewHTTPProxyDialer returns ContextDialer tunneling the connections through the HTTP proxy of proxyURL by CONNECT,
+// the user info of proxyURL is sent as the basic authorization. forward connects to the proxy, nil is net.Dialer
+func NewHTTPProxyDialer(proxyURL *url.URL, forward ContextDialer) ContextDialer {
+	if forward == nil {
+		forward = new(net.Dialer)
+	}
+	return &httpProxyDialer{proxy: proxyURL, forward: forward}
+}
+
+func (d *httpProxyDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host := d.proxy.Host
+	if d.proxy.Port() == "" {
+		host = net.JoinHostPort(d.proxy.Hostname(), "80")
+	}
+	conn, err := d.forward.DialContext(ctx, network, host)
+	if err != nil {
+		return nil, err
+	}
+	// the handshake is interrupted when ctx is done
+	errc := make(chan error, 1)
+	go func() {
+		errc <- d.connect(conn, addr)
+	}()
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		conn.Close()
+		<-errc
+		err = ctx.Err()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// connect requests the tunnel to addr by CONNECT over conn
+func (d *httpProxyDialer) connect(conn net.Conn, addr string) error {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if u := d.proxy.User; u != nil {
+		pass, _ := u.Password()
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(u.Username()+":"+pass)))
+	}
+	if err := req.Write(conn); err != nil {
+		return err
+	}
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("error:\tproxy %s refused CONNECT %s: %s", d.proxy.Host, addr, res.Status)
+	}
+	if br.Buffered() != 0 {
+		return fmt.Errorf("error:\tproxy %s sent data before the tunnel is established", d.proxy.Host)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeConnectProxy tunnels CONNECT requests authorized as user:pass, or accepts and never answers when hang is set
+type fakeConnectProxy struct {
+	ln      net.Listener
+	hang    bool
+	mu      sync.Mutex
+	targets []string
+}
+
+func newFakeConnectProxy(t *testing.T, hang bool) *fakeConnectProxy {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	p := &fakeConnectProxy{ln: ln, hang: hang}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go p.serve(conn)
+		}
+	}()
+	return p
+}
+
+func (p *fakeConnectProxy) serve(conn net.Conn) {
+	defer conn.Close()
+	req, err := http.ReadRequest(bufio.NewReader(conn))
+	if err != nil || p.hang {
+		io.Copy(io.Discard, conn)
+		return
+	}
+	if req.Method != http.MethodConnect || req.Header.Get("Proxy-Authorization") != "Basic "+base64.StdEncoding.EncodeToString([]byte("user:pass")) {
+		io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+		return
+	}
+	p.mu.Lock()
+	p.targets = append(p.targets, req.Host)
+	p.mu.Unlock()
+	target, err := net.Dial("tcp", req.Host)
+	if err != nil {
+		io.WriteString(conn, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+		return
+	}
+	defer target.Close()
+	io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+	go io.Copy(target, conn)
+	io.Copy(conn, target)
+}
+
+func TestNewHTTPProxyDialer(t *testing.T) {
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer echo.Close()
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	proxy := newFakeConnectProxy(t, false)
+	defer proxy.ln.Close()
+	hung := newFakeConnectProxy(t, true)
+	defer hung.ln.Close()
+
+	tests := []struct {
+		name    string
+		proxy   string
+		timeout time.Duration
+		wantErr string
+	}{
+		{name: "tunnel", proxy: "http://user:pass@" + proxy.ln.Addr().String()},
+		{name: "unauthorized", proxy: "http://user:wrong@" + proxy.ln.Addr().String(), wantErr: "407"},
+		{name: "hung proxy", proxy: "http://" + hung.ln.Addr().String(), timeout: 50 * time.Millisecond, wantErr: context.DeadlineExceeded.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.proxy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			conn, err := NewHTTPProxyDialer(u, nil).DialContext(ctx, "tcp", echo.Addr().String())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("DialContext() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			io.WriteString(conn, "ping")
+			buf := make([]byte, 4)
+			if _, err = io.ReadFull(conn, buf); err != nil || string(buf) != "ping" {
+				t.Errorf("tunnel echoed %q, %v", buf, err)
+			}
+		})
+	}
+}
+
+// countDialer counts the connections dialed
+type countDialer struct {
+	net.Dialer
+	n int32
+}
+
+func (d *countDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	atomic.AddInt32(&d.n, 1)
+	return d.Dialer.DialContext(ctx, network, addr)
+}
+
+func TestRedisWriter_SetDialer(t *testing.T) {
+	r := newFakeRedis(t)
+	defer r.ln.Close()
+	proxy := newFakeConnectProxy(t, false)
+	defer proxy.ln.Close()
+
+	u := &url.URL{Scheme: "http", User: url.UserPassword("user", "pass"), Host: proxy.ln.Addr().String()}
+	d := new(countDialer)
+	w := NewRedisListWriter(r.ln.Addr().String(), "logs").SetFlushInterval(0).SetDialer(NewHTTPProxyDialer(u, d))
+	w.Write([]byte("proxied"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	proxy.mu.Lock()
+	defer proxy.mu.Unlock()
+	if len(r.cmds) != 1 || r.cmds[0][2] != "proxied" || len(proxy.targets) != 1 || proxy.targets[0] != r.ln.Addr().String() || d.n != 1 {
+		t.Errorf("RedisWriter commands = %q, proxy targets = %q, dials = %d", r.cmds, proxy.targets, d.n)
+	}
+}
+
+func TestAppInsightsWriter_SetProxy(t *testing.T) {
+	hosts := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts <- r.URL.Host
+	}))
+	defer proxy.Close()
+	u, _ := url.Parse(proxy.URL)
+
+	d := new(countDialer)
+	ai := NewAppInsightsWriter("InstrumentationKey=k;IngestionEndpoint=http://ingest.example").
+		SetFlushInterval(0).SetProxy(u).SetDialer(d)
+	ai.Write([]byte("proxied"))
+	if err := ai.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if host := <-hosts; host != "ingest.example" || atomic.LoadInt32(&d.n) != 1 {
+		t.Errorf("proxy got the request to %s by %d dials", host, d.n)
+	}
+}
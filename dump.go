@@ -0,0 +1,104 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxDumpSize is default maximum byte size of the binary payload dumped by Debugd
+const DefaultMaxDumpSize = 4096
+
+// DumpFormat is json object structure of binary payload dumped by Debugd
+type DumpFormat struct {
+	Label     string `json:"label,omitempty"`
+	Size      int    `json:"size"`
+	Data      []byte `json:"data,omitempty"`
+	Truncated int    `json:"truncated,omitempty"`
+}
+
+func (g *Glg) dump(lv LEVEL, label string, data []byte) (string, interface{}) {
+	max := g.opts().maxDumpSize
+	if max == 0 {
+		max = DefaultMaxDumpSize
+	}
+	var trunc int
+	if max > 0 && len(data) > max {
+		trunc = len(data) - max
+		data = data[:max]
+	}
+	if g.levelJSON(lv) {
+		return "", DumpFormat{
+			Label:     label,
+			Size:      len(data) + trunc,
+			Data:      data,
+			Truncated: trunc,
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString(label)
+	sb.WriteString(" (")
+	sb.WriteString(strconv.Itoa(len(data) + trunc))
+	sb.WriteString(" bytes):")
+	if len(data) != 0 {
+		sb.WriteString(rc)
+		sb.WriteString(strings.TrimSuffix(hex.Dump(data), rc))
+	}
+	if trunc > 0 {
+		sb.WriteString(rc)
+		sb.WriteString(truncatedPrefix)
+		sb.WriteString(strconv.Itoa(trunc))
+		sb.WriteString(truncatedSuffix)
+	}
+	return "%s", sb.String()
+}
+
+// SetMaxDumpSize sets the maximum byte size of the binary payload dumped by Debugd.
+// Negative size disables the limit
+func (g *Glg) SetMaxDumpSize(size int) *Glg {
+	g.setSwitches(func(s *switches) {
+		s.maxDumpSize = size
+	})
+	return g
+}
+
+// Debugd outputs Debug level hex dump of the binary payload, JSON mode outputs it as base64 field
+func (g *Glg) Debugd(label string, data []byte) error {
+	if g.isModeEnable(DEBG) {
+		format, detail := g.dump(DEBG, label, data)
+		return g.out(DEBG, format, detail)
+	}
+	return nil
+}
+
+// Debugd outputs Debug level hex dump of the binary payload, JSON mode outputs it as base64 field
+func Debugd(label string, data []byte) error {
+	if isModeEnable(DEBG) {
+		format, detail := Get().dump(DEBG, label, data)
+		return Get().out(DEBG, format, detail)
+	}
+	return nil
+}
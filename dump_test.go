@@ -0,0 +1,88 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	json "github.com/goccy/go-json"
+)
+
+func TestGlg_Debugd(t *testing.T) {
+	data := []byte("GET / HTTP/1.1\r\n\r\n")
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp()
+	if err := g.Debugd("request", data); err != nil {
+		t.Error(err)
+	}
+	want := "[DEBG]:\trequest (18 bytes):\n" + hex.Dump(data)
+	if got := buf.String(); got != want {
+		t.Errorf("Glg.Debugd() = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	g.SetMaxDumpSize(4).Debugd("request", data)
+	if got := buf.String(); !strings.HasSuffix(got, "\n...(truncated 14 bytes)\n") {
+		t.Errorf("Glg.Debugd() truncated = %q", got)
+	}
+
+	buf.Reset()
+	g.EnableJSON().Debugd("request", data)
+	var dec struct {
+		Detail DumpFormat `json:"detail"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &dec); err != nil {
+		t.Fatal(err)
+	}
+	if dec.Detail.Label != "request" || dec.Detail.Size != 18 || dec.Detail.Truncated != 14 || string(dec.Detail.Data) != "GET " {
+		t.Errorf("Glg.Debugd() json = %s", buf.String())
+	}
+
+	buf.Reset()
+	g.SetLevelMode(DEBG, NONE).Debugd("request", data)
+	if buf.Len() != 0 {
+		t.Errorf("Glg.Debugd() disabled = %s", buf.String())
+	}
+}
+
+func TestGlg_Debugd_LevelJSON(t *testing.T) {
+	data := []byte("GET ")
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().EnableLevelJSON(DEBG)
+	g.Debugd("request", data)
+	var dec struct {
+		Detail DumpFormat `json:"detail"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &dec); err != nil || dec.Detail.Size != 4 || string(dec.Detail.Data) != "GET " {
+		t.Errorf("Glg.Debugd() level json = %s, error = %v", buf.String(), err)
+	}
+
+	buf.Reset()
+	g.EnableJSON().DisableLevelJSON(DEBG).Debugd("request", data)
+	if want := "[DEBG]:\trequest (4 bytes):\n" + hex.Dump(data); buf.String() != want {
+		t.Errorf("Glg.Debugd() level text = %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,290 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// frames of the encrypted stream, the header starts the segment of the key
+const (
+	encryptHeader = 'H'
+	encryptRecord = 'R'
+
+	encryptNonceSize = 12
+	encryptMaxRecord = 64 << 20
+)
+
+// EncryptWriter is io.Writer which encrypts each entry by AES-GCM before writing it to the underlying writer.
+// The stream is segmented by the key, each segment starts with the key ID and the random base nonce,
+// and the nonce of each entry is the base nonce xor the entry counter.
+// Decrypt reads the stream back with the keys of the IDs
+type EncryptWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	keyID   string
+	aead    cipher.AEAD
+	nonce   [encryptNonceSize]byte
+	counter uint64
+	started bool
+}
+
+// NewEncryptWriter returns EncryptWriter of the AES-128, AES-192 or AES-256 key
+func NewEncryptWriter(w io.Writer, keyID string, key []byte) (*EncryptWriter, error) {
+	e := &EncryptWriter{
+		w: w,
+	}
+	if err := e.RotateKey(keyID, key); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// RotateKey starts the new segment of the key from the next entry
+func (e *EncryptWriter) RotateKey(keyID string, key []byte) error {
+	if len(keyID) > 255 {
+		return errors.New("error:\tkey ID is longer than 255 bytes")
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, err = io.ReadFull(rand.Reader, e.nonce[:]); err != nil {
+		return err
+	}
+	e.keyID, e.aead, e.counter, e.started = keyID, aead, 0, false
+	return nil
+}
+
+// Write encrypts p as one entry
+func (e *EncryptWriter) Write(p []byte) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	b := make([]byte, 0, 2+len(e.keyID)+encryptNonceSize+5+len(p)+e.aead.Overhead())
+	if !e.started {
+		b = append(b, encryptHeader, byte(len(e.keyID)))
+		b = append(b, e.keyID...)
+		b = append(b, e.nonce[:]...)
+	}
+	nonce := entryNonce(e.nonce, e.counter)
+	b = append(b, encryptRecord, 0, 0, 0, 0)
+	n := len(b)
+	b = e.aead.Seal(b, nonce[:], p, nil)
+	binary.BigEndian.PutUint32(b[n-4:n], uint32(len(b)-n))
+	// the nonce is consumed by Seal, it is never used again even when the write fails
+	e.counter++
+	if _, err := e.w.Write(b); err != nil {
+		// the reader cannot tell how much of the record was written, the next entry starts the new segment
+		// of the fresh base nonce, the counter keeps advancing when the random source fails
+		if _, rerr := io.ReadFull(rand.Reader, e.nonce[:]); rerr == nil {
+			e.counter = 0
+		}
+		e.started = false
+		return 0, err
+	}
+	e.started = true
+	return len(p), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func entryNonce(base [encryptNonceSize]byte, counter uint64) [encryptNonceSize]byte {
+	var c [8]byte
+	binary.BigEndian.PutUint64(c[:], counter)
+	for i := range c {
+		base[encryptNonceSize-8+i] ^= c[i]
+	}
+	return base
+}
+
+// errTornFrame is the frame broken by the failed write, Decrypt scans for the next segment header from its second byte
+var errTornFrame = errors.New("error:\ttorn encrypted frame")
+
+// Decrypt reads the stream written by EncryptWriter and writes the decrypted entries to dst,
+// keys are looked up by the key IDs of the segments.
+// The frames torn by the failed writes are skipped up to the next segment header of the known key,
+// the entries after them are still written and the skipped bytes are reported by the returned error
+func Decrypt(dst io.Writer, src io.Reader, keys map[string][]byte) error {
+	d := &decrypter{
+		s:    decryptStream{rd: bufio.NewReader(src)},
+		keys: keys,
+	}
+	var skipped int
+	for {
+		err := d.next(dst)
+		switch {
+		case err == io.EOF:
+			if skipped != 0 {
+				return fmt.Errorf("error:\tskipped %d bytes of torn or undecryptable entries", skipped)
+			}
+			return nil
+		case errors.Is(err, errTornFrame):
+			d.s.skip()
+			d.aead, d.resync = nil, true
+			skipped++
+		case err != nil:
+			return err
+		}
+	}
+}
+
+// decrypter is the state of Decrypt, resync is set while it scans for the segment header after the torn frame
+type decrypter struct {
+	s       decryptStream
+	keys    map[string][]byte
+	aead    cipher.AEAD
+	base    [encryptNonceSize]byte
+	counter uint64
+	plain   []byte
+	resync  bool
+}
+
+// next decrypts the next frame, io.EOF is returned at the end of the stream
+func (d *decrypter) next(dst io.Writer) error {
+	d.s.commit()
+	b, err := d.s.take(1)
+	if err != nil {
+		return err
+	}
+	switch b[0] {
+	case encryptHeader:
+		if b, err = d.s.take(1); err != nil {
+			return torn(err)
+		}
+		id, err := d.s.take(int(b[0]))
+		if err != nil {
+			return torn(err)
+		}
+		base, err := d.s.take(encryptNonceSize)
+		if err != nil {
+			return torn(err)
+		}
+		key, ok := d.keys[string(id)]
+		if !ok {
+			if d.resync {
+				return errTornFrame
+			}
+			return fmt.Errorf("error:\tkey %q not found", id)
+		}
+		if d.aead, err = newGCM(key); err != nil {
+			return err
+		}
+		copy(d.base[:], base)
+		d.counter, d.resync = 0, false
+	case encryptRecord:
+		if d.resync {
+			return errTornFrame
+		}
+		if d.aead == nil {
+			return errors.New("error:\tencrypted entry without header")
+		}
+		if b, err = d.s.take(4); err != nil {
+			return torn(err)
+		}
+		n := binary.BigEndian.Uint32(b)
+		if n > encryptMaxRecord {
+			return errTornFrame
+		}
+		if b, err = d.s.take(int(n)); err != nil {
+			return torn(err)
+		}
+		nonce := entryNonce(d.base, d.counter)
+		// the plaintext has its own buffer, the failed Open must not wipe the frame which is scanned again
+		if d.plain, err = d.aead.Open(d.plain[:0], nonce[:], b, nil); err != nil {
+			return errTornFrame
+		}
+		if _, err = dst.Write(d.plain); err != nil {
+			return err
+		}
+		d.counter++
+	default:
+		return errTornFrame
+	}
+	return nil
+}
+
+func torn(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return errTornFrame
+	}
+	return err
+}
+
+// decryptStream keeps the bytes of the current frame until it is committed,
+// so the torn frame is scanned again from its second byte
+type decryptStream struct {
+	rd      *bufio.Reader
+	pending []byte
+	off     int
+}
+
+// take returns the next n bytes of the frame
+func (s *decryptStream) take(n int) ([]byte, error) {
+	if need := s.off + n - len(s.pending); need > 0 {
+		l := len(s.pending)
+		s.pending = append(s.pending, make([]byte, need)...)
+		m, err := io.ReadFull(s.rd, s.pending[l:])
+		s.pending = s.pending[:l+m]
+		if err != nil {
+			if err == io.EOF && s.off != 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+	}
+	b := s.pending[s.off : s.off+n]
+	s.off += n
+	return b, nil
+}
+
+// commit drops the bytes of the decrypted frame
+func (s *decryptStream) commit() {
+	if s.off == len(s.pending) {
+		s.pending = s.pending[:0]
+	} else {
+		s.pending = s.pending[s.off:]
+	}
+	s.off = 0
+}
+
+// skip drops the first byte of the torn frame and rewinds to the next one
+func (s *decryptStream) skip() {
+	s.pending, s.off = s.pending[1:], 0
+}
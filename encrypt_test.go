@@ -0,0 +1,175 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEncryptWriter(t *testing.T) {
+	keys := map[string][]byte{
+		"2026-01": bytes.Repeat([]byte{1}, 32),
+		"2026-02": bytes.Repeat([]byte{2}, 16),
+	}
+	buf := new(bytes.Buffer)
+	e, err := NewEncryptWriter(buf, "2026-01", keys["2026-01"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := New().SetMode(WRITER).SetWriter(e).DisableTimestamp()
+	g.Info("secret entry")
+	g.Warn("second")
+	if err = e.RotateKey("2026-02", keys["2026-02"]); err != nil {
+		t.Fatal(err)
+	}
+	g.Info("rotated")
+
+	if strings.Contains(buf.String(), "secret") {
+		t.Fatalf("EncryptWriter wrote plain text %q", buf.String())
+	}
+	out := new(bytes.Buffer)
+	if err = Decrypt(out, bytes.NewReader(buf.Bytes()), keys); err != nil {
+		t.Fatal(err)
+	}
+	if want := "[INFO]:\tsecret entry\n[WARN]:\tsecond\n[INFO]:\trotated\n"; out.String() != want {
+		t.Errorf("Decrypt() = %q, want %q", out.String(), want)
+	}
+
+	tests := []struct {
+		name string
+		data func() []byte
+		keys map[string][]byte
+	}{
+		{
+			name: "missing key",
+			data: buf.Bytes,
+			keys: map[string][]byte{"2026-01": keys["2026-01"]},
+		},
+		{
+			name: "tampered",
+			data: func() []byte {
+				b := append([]byte(nil), buf.Bytes()...)
+				b[len(b)-1] ^= 1
+				return b
+			},
+			keys: keys,
+		},
+		{
+			name: "truncated",
+			data: func() []byte {
+				return buf.Bytes()[:buf.Len()-3]
+			},
+			keys: keys,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Decrypt(new(bytes.Buffer), bytes.NewReader(tt.data()), tt.keys); err == nil {
+				t.Error("Decrypt() error = nil")
+			}
+		})
+	}
+
+	if _, err = NewEncryptWriter(buf, "bad", []byte("short")); err == nil {
+		t.Error("NewEncryptWriter() error = nil")
+	}
+}
+
+// failOnceWriter fails the first write and keeps every attempted write
+type failOnceWriter struct {
+	attempts [][]byte
+}
+
+func (w *failOnceWriter) Write(p []byte) (int, error) {
+	w.attempts = append(w.attempts, append([]byte(nil), p...))
+	if len(w.attempts) == 1 {
+		return 0, errors.New("write failed")
+	}
+	return len(p), nil
+}
+
+func TestEncryptWriter_WriteError(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	w := new(failOnceWriter)
+	e, err := NewEncryptWriter(w, "k", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = e.Write([]byte("first")); err == nil {
+		t.Fatal("Write() error = nil")
+	}
+	if _, err = e.Write([]byte("second")); err != nil {
+		t.Fatal(err)
+	}
+	// each attempt starts the segment of its own base nonce, so the records never share the nonce
+	nonce := func(b []byte) [encryptNonceSize]byte {
+		if b[0] != encryptHeader {
+			t.Fatalf("record %q does not start the segment", b)
+		}
+		var base [encryptNonceSize]byte
+		copy(base[:], b[2+int(b[1]):])
+		return entryNonce(base, 0)
+	}
+	if n1, n2 := nonce(w.attempts[0]), nonce(w.attempts[1]); n1 == n2 {
+		t.Errorf("records after the failed write share the nonce %x", n1)
+	}
+	out := new(bytes.Buffer)
+	if err = Decrypt(out, bytes.NewReader(w.attempts[1]), map[string][]byte{"k": key}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "second" {
+		t.Errorf("Decrypt() = %q, want %q", out.String(), "second")
+	}
+}
+
+func TestDecrypt_Torn(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	w := new(failOnceWriter)
+	e, err := NewEncryptWriter(w, "k", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = e.Write([]byte("first")); err == nil {
+		t.Fatal("Write() error = nil")
+	}
+	if _, err = e.Write([]byte("second")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = e.Write([]byte("third")); err != nil {
+		t.Fatal(err)
+	}
+	// the failed write left the half of its frame in the stream
+	torn := w.attempts[0][:len(w.attempts[0])-8]
+	data := append(append([]byte(nil), torn...), bytes.Join(w.attempts[1:], nil)...)
+	out := new(bytes.Buffer)
+	err = Decrypt(out, bytes.NewReader(data), map[string][]byte{"k": key})
+	if err == nil {
+		t.Error("Decrypt() error = nil")
+	}
+	if out.String() != "secondthird" {
+		t.Errorf("Decrypt() = %q, want %q", out.String(), "secondthird")
+	}
+}
@@ -0,0 +1,75 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEnvironmentFields(t *testing.T) {
+	environ := []string{
+		"PATH=/usr/bin",
+		"APP_ENV=prod",
+		"APP_DB_PASSWORD=hunter2",
+		"APP_API_KEY=abc",
+		"APP_DEBUG_DUMP=1",
+		"APP_DB=postgres://app:hunter2@db:5432/app",
+		"APP_REPO=https://ghp_token@github.com/org/repo",
+		"APP_SITE=https://example.com/path",
+		"HOME=/root",
+	}
+	b := new(bytes.Buffer)
+	writeFields(b, environmentFields(environ, []string{"APP_*", "HOME"}, []string{"*_DUMP"}), 0, false)
+	want := "env.APP_API_KEY=*** env.APP_DB=postgres://app:***@db:5432/app env.APP_DB_PASSWORD=*** env.APP_ENV=prod " +
+		"env.APP_REPO=https://***@github.com/org/repo env.APP_SITE=https://example.com/path env.HOME=/root gomaxprocs="
+	if got := b.String(); !strings.HasPrefix(got, want) || strings.Contains(got, "PATH") || !strings.Contains(got, " num_cpu=") {
+		t.Errorf("environmentFields() = %s, want prefix %s", got, want)
+	}
+
+	b.Reset()
+	writeFields(b, environmentFields(environ, nil, nil), 0, false)
+	if got := b.String(); !strings.HasPrefix(got, "gomaxprocs=") {
+		t.Errorf("environmentFields() without allow = %s, want no variables", got)
+	}
+	b.Reset()
+	writeFields(b, environmentFields(environ, []string{"*"}, nil), 0, false)
+	if got := b.String(); !strings.Contains(got, "env.PATH=/usr/bin") || strings.Contains(got, "hunter2") {
+		t.Errorf("environmentFields() of all = %s", got)
+	}
+}
+
+func TestGlg_LogEnvironment(t *testing.T) {
+	t.Setenv("GLG_TEST_ENV", "value")
+	t.Setenv("GLG_TEST_TOKEN", "secret-value")
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone).DisableTimestamp()
+	if err := g.LogEnvironment([]string{"GLG_TEST_*"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	if !strings.HasPrefix(got, "[INFO]:\tenvironment\tenv.GLG_TEST_ENV=value env.GLG_TEST_TOKEN=*** gomaxprocs=") || strings.Contains(got, "secret-value") {
+		t.Errorf("LogEnvironment() = %q", got)
+	}
+}
@@ -0,0 +1,214 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kpango/fastime"
+)
+
+// maxEscalationKeys is the number of the counted messages the expired windows are swept at
+const maxEscalationKeys = 4096
+
+// Escalation raises the level of the message repeated more than Threshold times within Window, e.g.
+//
+//	g.AddEscalation(glg.Escalation{Threshold: 10, Window: time.Minute, Level: glg.ERR, Levels: []glg.LEVEL{glg.WARN}})
+//
+// logs the WARN message repeated for the 11th time within a minute and after at ERR
+type Escalation struct {
+	// Threshold is the number of the repetitions written at the original level
+	Threshold int
+	// Window is the period the repetitions are counted in, the count restarts after the window
+	Window time.Duration
+	// Level is the escalated level, zero keeps the level and only calls Hook
+	Level LEVEL
+	// Levels are the escalated levels, empty means the levels ranked below Level
+	Levels []LEVEL
+	// Hook is called with the entry and the count when the message exceeds Threshold in the window, once per window.
+	// It is called synchronously before the entry is written
+	Hook func(e Entry, count int)
+}
+
+// escalationKey identifies the message counted by the rule
+type escalationKey struct {
+	rule  int
+	level LEVEL
+	msg   string
+}
+
+// escalationWindow is the count of the message in the window started at start
+type escalationWindow struct {
+	start time.Time
+	count int
+}
+
+// escalations counts the repeated messages of the escalation rules.
+// The rules are copied on write and loaded without mu, so the entries of the levels no rule counts are not serialized
+type escalations struct {
+	rules     atomic.Pointer[[]Escalation]
+	mu        sync.Mutex
+	windows   map[escalationKey]*escalationWindow
+	nextSweep time.Time
+}
+
+// AddEscalation adds the rule escalating the repeated messages, the messages are compared after formatting without the fields.
+// The rules may be added while logging, they are shared by the instances derived by With
+func (g *Glg) AddEscalation(rule Escalation) *Glg {
+	if rule.Threshold < 0 || rule.Window <= 0 || (rule.Level == 0 && rule.Hook == nil) {
+		return g
+	}
+	e := &g.escalation
+	e.mu.Lock()
+	rules := e.load()
+	rules = append(rules[:len(rules):len(rules)], rule)
+	e.rules.Store(&rules)
+	e.mu.Unlock()
+	return g
+}
+
+// ResetEscalations removes the escalation rules and their counts
+func (g *Glg) ResetEscalations() *Glg {
+	e := &g.escalation
+	e.mu.Lock()
+	e.rules.Store(nil)
+	e.windows = nil
+	e.mu.Unlock()
+	return g
+}
+
+// AddEscalation adds the rule escalating the repeated messages of the global instance
+func AddEscalation(rule Escalation) *Glg {
+	return Get().AddEscalation(rule)
+}
+
+// ResetEscalations removes the escalation rules of the global instance
+func ResetEscalations() *Glg {
+	return Get().ResetEscalations()
+}
+
+// escalate counts the message of format and val and returns the escalated level,
+// format and val are the arguments after the fields are separated and the Lazy values are memoized
+func (g *Glg) escalate(level LEVEL, format string, val []interface{}) LEVEL {
+	e := &g.escalation
+	if !g.escalatesAny(e.load(), level) {
+		return level
+	}
+	if format == "" {
+		format = spaceFormat(len(val))
+	}
+	msg := fmt.Sprintf(format, val...)
+	now := fastime.Now()
+	type hook struct {
+		fn    func(Entry, int)
+		count int
+	}
+	var hooks []hook
+	esc := level
+	e.mu.Lock()
+	// the rules are loaded again under mu, the window keys are the indexes of the rules reset under mu
+	rules := e.load()
+	if e.windows == nil {
+		e.windows = make(map[escalationKey]*escalationWindow)
+	} else if len(e.windows) >= maxEscalationKeys && !now.Before(e.nextSweep) {
+		e.sweep(rules, now)
+		e.nextSweep = now.Add(time.Second)
+	}
+	for i, r := range rules {
+		if !g.escalates(r, level) {
+			continue
+		}
+		key := escalationKey{rule: i, level: level, msg: msg}
+		w, ok := e.windows[key]
+		if !ok || now.Sub(w.start) >= r.Window {
+			if !ok && len(e.windows) >= maxEscalationKeys {
+				// the new messages are not counted until the windows expire
+				continue
+			}
+			w = &escalationWindow{start: now}
+			e.windows[key] = w
+		}
+		w.count++
+		if w.count <= r.Threshold {
+			continue
+		}
+		if r.Level != 0 && g.rank(r.Level) > g.rank(esc) {
+			esc = r.Level
+		}
+		if r.Hook != nil && w.count == r.Threshold+1 {
+			hooks = append(hooks, hook{fn: r.Hook, count: w.count})
+		}
+	}
+	e.mu.Unlock()
+	for _, h := range hooks {
+		h.fn(Entry{Time: now, Level: level, Tag: g.LevelString(level), Message: msg}, h.count)
+	}
+	return esc
+}
+
+// escalatesAny reports any of the rules counts the messages of level
+func (g *Glg) escalatesAny(rules []Escalation, level LEVEL) bool {
+	for _, r := range rules {
+		if g.escalates(r, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// escalates reports the rule counts the messages of level
+func (g *Glg) escalates(r Escalation, level LEVEL) bool {
+	if len(r.Levels) != 0 {
+		return hasLevel(r.Levels, level)
+	}
+	return r.Level == 0 || g.rank(level) < g.rank(r.Level)
+}
+
+// rank returns the rank of lv, see SetLevelRank
+func (g *Glg) rank(lv LEVEL) LEVEL {
+	if l, ok := g.logger.Load(lv); ok {
+		return l.rankOf(lv)
+	}
+	return lv
+}
+
+// load returns the escalation rules
+func (e *escalations) load() []Escalation {
+	if rules := e.rules.Load(); rules != nil {
+		return *rules
+	}
+	return nil
+}
+
+// sweep removes the windows expired at now, mu must be held
+func (e *escalations) sweep(rules []Escalation, now time.Time) {
+	for key, w := range e.windows {
+		if key.rule >= len(rules) || now.Sub(w.start) >= rules[key.rule].Window {
+			delete(e.windows, key)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGlg_AddEscalation(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  Escalation
+		level LEVEL
+		msgs  []string
+		want  []string
+		hooks int
+	}{
+		{
+			name:  "escalates after threshold",
+			rule:  Escalation{Threshold: 2, Window: time.Minute, Level: ERR},
+			level: WARN,
+			msgs:  []string{"disk slow", "disk slow", "disk slow", "disk slow"},
+			want:  []string{"[WARN]", "[WARN]", "[ERR]", "[ERR]"},
+		},
+		{
+			name:  "counts messages separately",
+			rule:  Escalation{Threshold: 1, Window: time.Minute, Level: ERR},
+			level: WARN,
+			msgs:  []string{"a", "b", "a", "b"},
+			want:  []string{"[WARN]", "[WARN]", "[ERR]", "[ERR]"},
+		},
+		{
+			name:  "ignores levels not listed",
+			rule:  Escalation{Threshold: 0, Window: time.Minute, Level: ERR, Levels: []LEVEL{WARN}},
+			level: INFO,
+			msgs:  []string{"x", "x"},
+			want:  []string{"[INFO]", "[INFO]"},
+		},
+		{
+			name:  "does not lower the level",
+			rule:  Escalation{Threshold: 0, Window: time.Minute, Level: WARN},
+			level: ERR,
+			msgs:  []string{"x"},
+			want:  []string{"[ERR]"},
+		},
+		{
+			name:  "hook only is called once per window",
+			level: WARN,
+			msgs:  []string{"x", "x", "x", "x"},
+			want:  []string{"[WARN]", "[WARN]", "[WARN]", "[WARN]"},
+			hooks: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp()
+			var got []int
+			rule := tt.rule
+			if tt.hooks != 0 {
+				rule = Escalation{Threshold: 1, Window: time.Minute, Hook: func(e Entry, count int) {
+					if e.Message != "x" || e.Level != WARN {
+						t.Errorf("hook entry = %+v", e)
+					}
+					got = append(got, count)
+				}}
+			}
+			g.AddEscalation(rule)
+			for _, msg := range tt.msgs {
+				g.out(tt.level, "%s", msg)
+			}
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("got %d lines: %q", len(lines), buf.String())
+			}
+			for i, w := range tt.want {
+				if !strings.HasPrefix(lines[i], w) {
+					t.Errorf("line %d = %q, want prefix %q", i, lines[i], w)
+				}
+			}
+			if len(got) != tt.hooks || (tt.hooks != 0 && got[0] != 2) {
+				t.Errorf("hook counts = %v, want %d call(s) with 2", got, tt.hooks)
+			}
+		})
+	}
+}
+
+func TestGlg_EscalationWindow(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().
+		AddEscalation(Escalation{Threshold: 1, Window: 50 * time.Millisecond, Level: ERR})
+	g.Warn("x")
+	time.Sleep(100 * time.Millisecond)
+	g.Warn("x")
+	if strings.Contains(buf.String(), "[ERR]") {
+		t.Errorf("escalated after the window: %q", buf.String())
+	}
+	g.Warn("x")
+	if !strings.Contains(buf.String(), "[ERR]") {
+		t.Errorf("not escalated within the window: %q", buf.String())
+	}
+	g.ResetEscalations()
+	buf.Reset()
+	g.Warn("x")
+	if !strings.HasPrefix(buf.String(), "[WARN]") {
+		t.Errorf("escalated after ResetEscalations: %q", buf.String())
+	}
+}
+
+func TestGlg_EscalationDisabled(t *testing.T) {
+	buf := new(bytes.Buffer)
+	var calls int
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetLevel(ERR).
+		AddEscalation(Escalation{Threshold: 0, Window: time.Minute, Level: ERR, Hook: func(Entry, int) { calls++ }})
+	g.Warn("x")
+	g.Warn("x")
+	if buf.Len() != 0 || calls != 0 {
+		t.Errorf("output = %q, hook calls = %d, want the disabled entries not counted", buf.String(), calls)
+	}
+}
+
+func TestGlg_EscalationLazy(t *testing.T) {
+	var evals int
+	lazy := Lazy(func() interface{} {
+		evals++
+		return "x"
+	})
+	g := New().SetMode(WRITER).SetWriter(new(bytes.Buffer)).
+		AddEscalation(Escalation{Threshold: 1, Window: time.Minute, Level: ERR, Levels: []LEVEL{WARN}})
+	g.Info(lazy)
+	g.Warn(lazy)
+	if evals != 2 {
+		t.Errorf("Lazy is evaluated %d times for 2 entries, want 2", evals)
+	}
+	if len(g.escalation.windows) != 1 {
+		t.Errorf("windows = %d, want only the WARN entry counted", len(g.escalation.windows))
+	}
+}
+
+func TestGlg_EscalationBounded(t *testing.T) {
+	g := New().SetMode(WRITER).SetWriter(new(bytes.Buffer)).
+		AddEscalation(Escalation{Threshold: 1, Window: time.Hour, Level: ERR})
+	for i := 0; i < maxEscalationKeys+100; i++ {
+		g.Warnf("request %d failed", i)
+	}
+	if n := len(g.escalation.windows); n > maxEscalationKeys {
+		t.Errorf("windows = %d, want at most %d", n, maxEscalationKeys)
+	}
+}
@@ -0,0 +1,81 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"errors"
+	"io"
+)
+
+// WriteError is the error of one destination of the entry written to multiple writers
+type WriteError struct {
+	// Writer is the Name of the writer or its type
+	Writer string
+	Err    error
+}
+
+// Error implements error
+func (e *WriteError) Error() string {
+	return "error:\twriter " + e.Writer + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error of the writer
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// fanout writes the entry to every writer even when some of them fail,
+// the errors are joined as WriteError so that the failed destinations are identified
+type fanout []io.Writer
+
+// newFanout returns the writer writing to w and then to add
+func newFanout(w, add io.Writer) io.Writer {
+	if f, ok := w.(fanout); ok {
+		return append(f[:len(f):len(f)], add)
+	}
+	return fanout{w, add}
+}
+
+// Write implements io.Writer
+func (f fanout) Write(p []byte) (int, error) {
+	var errs []error
+	for _, w := range f {
+		n, err := w.Write(p)
+		if err == nil && n != len(p) {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			errs = append(errs, writeError(w, err))
+		}
+	}
+	return len(p), errors.Join(errs...)
+}
+
+// writeError identifies err of w as WriteError, the errors of fanout are already identified
+func writeError(w io.Writer, err error) error {
+	if _, ok := w.(fanout); ok || err == nil {
+		return err
+	}
+	return &WriteError{Writer: writerName(w), Err: err}
+}
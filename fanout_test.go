@@ -0,0 +1,102 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type namedErrWriter struct {
+	name string
+	err  error
+}
+
+func (w namedErrWriter) Name() string {
+	return w.name
+}
+
+func (w namedErrWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func TestGlg_AddWriter_Errors(t *testing.T) {
+	errBroken := errors.New("broken pipe")
+	errFull := errors.New("disk full")
+	first, last := new(bytes.Buffer), new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetLineTraceMode(TraceLineNone).
+		SetWriter(first).
+		AddWriter(namedErrWriter{name: "syslog", err: errBroken}).
+		AddWriter(namedErrWriter{name: "/var/log/app.log", err: errFull}).
+		AddWriter(last)
+
+	err := g.Info("delivered")
+	if !strings.Contains(first.String(), "delivered") || !strings.Contains(last.String(), "delivered") {
+		t.Errorf("healthy writers output = %q, %q", first.String(), last.String())
+	}
+	if !errors.Is(err, errBroken) || !errors.Is(err, errFull) {
+		t.Fatalf("Info() error = %v, want both writer errors", err)
+	}
+	var we *WriteError
+	if !errors.As(err, &we) || we.Writer != "syslog" {
+		t.Errorf("Info() error = %v, want WriteError of syslog", err)
+	}
+	for _, want := range []string{"writer syslog: broken pipe", "writer /var/log/app.log: disk full"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Info() error = %q, want %q", err, want)
+		}
+	}
+
+	if err = New().SetMode(WRITER).SetWriter(first).AddWriter(last).Info("ok"); err != nil {
+		t.Errorf("Info() error = %v, want nil", err)
+	}
+}
+
+func TestGlg_BOTH_Errors(t *testing.T) {
+	errBroken := errors.New("broken pipe")
+	w := new(bytes.Buffer)
+	g := New().SetMode(BOTH).SetLineTraceMode(TraceLineNone).SetWriter(w)
+	g.logger.Range(func(lev LEVEL, l *logger) bool {
+		l.std = namedErrWriter{name: "stdout", err: errBroken}
+		g.logger.Store(lev, l)
+		return true
+	})
+	for _, color := range []bool{true, false} {
+		w.Reset()
+		if color {
+			g.EnableColor()
+		} else {
+			g.DisableColor()
+		}
+		err := g.Info("delivered")
+		if !strings.Contains(w.String(), "delivered") {
+			t.Errorf("color %v: writer output = %q, want written despite std error", color, w.String())
+		}
+		var we *WriteError
+		if !errors.As(err, &we) || we.Writer != "stdout" || !errors.Is(err, errBroken) {
+			t.Errorf("color %v: Info() error = %v, want WriteError of stdout", color, err)
+		}
+	}
+}
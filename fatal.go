@@ -0,0 +1,114 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kpango/fastime"
+)
+
+// DefaultFatalHookTimeout is the default time the Fatal functions wait for the hooks added by OnFatal
+const DefaultFatalHookTimeout = 5 * time.Second
+
+// OnFatal adds the hook run with the entry of Fatal, Fatalf and Fatalln before the program exits, e.g. to send an alert,
+// flush the traces or write a crash marker file. The hooks run in the added order after the entry is written,
+// the exit waits for them up to the timeout set by SetFatalHookTimeout and a panic of the hook is recovered
+func (g *Glg) OnFatal(hook func(Entry)) *Glg {
+	if hook != nil {
+		g.setSwitches(func(s *switches) {
+			s.fatalHooks = append(s.fatalHooks[:len(s.fatalHooks):len(s.fatalHooks)], hook)
+		})
+	}
+	return g
+}
+
+// SetFatalHookTimeout sets the time the Fatal functions wait for the hooks, d <= 0 means DefaultFatalHookTimeout
+func (g *Glg) SetFatalHookTimeout(d time.Duration) *Glg {
+	g.setSwitches(func(s *switches) {
+		s.fatalTimeout = d
+	})
+	return g
+}
+
+// OnFatal adds the hook run with the entry of Fatal before the program exits to the global instance
+func OnFatal(hook func(Entry)) *Glg {
+	return Get().OnFatal(hook)
+}
+
+// SetFatalHookTimeout sets the time the Fatal functions of the global instance wait for the hooks
+func SetFatalHookTimeout(d time.Duration) *Glg {
+	return Get().SetFatalHookTimeout(d)
+}
+
+// runFatalHooks runs the fatal hooks with the entry of format and val logged at caller
+func (g *Glg) runFatalHooks(caller, format string, val []interface{}) {
+	o := g.opts()
+	if len(o.fatalHooks) == 0 {
+		return
+	}
+	e := Entry{
+		Time:   fastime.Now(),
+		Level:  FATAL,
+		Caller: caller,
+	}
+	if o.enableUTC {
+		e.Time = e.Time.UTC()
+	}
+	if log, ok := g.logger.Load(FATAL); ok {
+		e.Tag = log.tag
+	}
+	format, val, e.Fields = g.splitFields(o, format, val)
+	if format == "" {
+		format = spaceFormat(len(val))
+	}
+	e.Message = fmt.Sprintf(format, val...)
+
+	timeout := o.fatalTimeout
+	if timeout <= 0 {
+		timeout = DefaultFatalHookTimeout
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, hook := range o.fatalHooks {
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						g.Errorf("fatal hook panic: %v", r)
+					}
+				}()
+				hook(e)
+			}()
+		}
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		g.Warn("fatal hooks timed out", Dur("timeout", timeout))
+	}
+}
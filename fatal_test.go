@@ -0,0 +1,114 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGlg_OnFatal(t *testing.T) {
+	defer ReplaceExitFunc(exit)
+	ReplaceExitFunc(func(n int) {
+		panic(ExitError(n))
+	})
+	tests := []struct {
+		name  string
+		fatal func(g *Glg)
+		want  string
+	}{
+		{
+			name:  "Fatal",
+			fatal: func(g *Glg) { g.Fatal("disk", "full", String("path", "/var")) },
+			want:  "disk full",
+		},
+		{
+			name:  "Fatalf",
+			fatal: func(g *Glg) { g.With(String("path", "/var")).Fatalf("disk %s", "full") },
+			want:  "disk full",
+		},
+		{
+			name:  "Fatalln",
+			fatal: func(g *Glg) { g.Fatalln("disk", String("path", "/var")) },
+			want:  "disk",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			var order []string
+			var got Entry
+			g := New().SetMode(WRITER).SetWriter(buf).
+				OnFatal(func(e Entry) {
+					order = append(order, "first")
+					got = e
+					if !strings.Contains(buf.String(), tt.want) {
+						t.Errorf("hook ran before the entry was written: %q", buf.String())
+					}
+				}).
+				OnFatal(nil).
+				OnFatal(func(Entry) {
+					order = append(order, "panic")
+					panic("hook failure")
+				}).
+				OnFatal(func(Entry) { order = append(order, "last") })
+			if err := testExit(1, func() { tt.fatal(g) }); err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(order, ",") != "first,panic,last" {
+				t.Errorf("hooks ran %v", order)
+			}
+			if got.Level != FATAL || got.Tag != "FATAL" || got.Message != tt.want ||
+				!strings.HasPrefix(got.Caller, "fatal_test.go:") || got.Time.IsZero() {
+				t.Errorf("entry = %+v", got)
+			}
+			if len(got.Fields) != 1 || got.Fields[0].Key != "path" {
+				t.Errorf("entry fields = %v", got.Fields)
+			}
+			if !strings.Contains(buf.String(), "fatal hook panic: hook failure") {
+				t.Errorf("output = %q, want the recovered panic", buf.String())
+			}
+		})
+	}
+}
+
+func TestGlg_SetFatalHookTimeout(t *testing.T) {
+	defer ReplaceExitFunc(exit)
+	ReplaceExitFunc(func(n int) {
+		panic(ExitError(n))
+	})
+	buf := new(bytes.Buffer)
+	release := make(chan struct{})
+	defer close(release)
+	g := New().SetMode(WRITER).SetWriter(buf).
+		SetFatalHookTimeout(10 * time.Millisecond).
+		OnFatal(func(Entry) { <-release })
+	if err := testExit(1, func() { g.Fatal("stuck") }); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "fatal hooks timed out") {
+		t.Errorf("output = %q, want the timeout warning", buf.String())
+	}
+}
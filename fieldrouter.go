@@ -0,0 +1,321 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRouterIdleTimeout is the default duration after which FieldRouter closes the unused destination
+	DefaultRouterIdleTimeout = 5 * time.Minute
+	// DefaultRouterMaxOpen is the default number of the destinations FieldRouter keeps open
+	DefaultRouterMaxOpen = 256
+)
+
+// FieldRouter is the writer set to the levels which writes each entry to the destination selected by the value of the field,
+// e.g. the file of the tenant of the entry. The destinations are opened on the first entry of the value and cached,
+// the ones unused for the idle timeout or the least recently used ones beyond the max open count are closed,
+// and they are opened again by the next entry of the value. The entries without the field are written to the fallback writer.
+// The fields in groups are keyed by the group names and the key joined by dots, e.g. "request.tenant"
+type FieldRouter struct {
+	key  string
+	open func(value string) (io.Writer, error)
+
+	mu       sync.Mutex
+	handles  map[string]*routeHandle
+	fallback io.Writer
+	idle     time.Duration
+	maxOpen  int
+	closed   bool
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// routeHandle is the cached destination of the value
+type routeHandle struct {
+	w    io.Writer
+	used time.Time
+}
+
+// NewFieldRouter returns FieldRouter opening the destination of the value of the field key by open
+func NewFieldRouter(key string, open func(value string) (io.Writer, error)) *FieldRouter {
+	return &FieldRouter{
+		key:     key,
+		open:    open,
+		handles: make(map[string]*routeHandle),
+		idle:    DefaultRouterIdleTimeout,
+		maxOpen: DefaultRouterMaxOpen,
+	}
+}
+
+// NewFileRouter returns FieldRouter writing to the LogFile of the path made by replacing {value} of the pattern
+// with the value of the field key, e.g. NewFileRouter("tenant", "/var/log/app/{value}.log", 0o644).
+// The characters of the value except letters, digits, '-', '_' and '.' are replaced with '_' and
+// the values starting with '.' are prefixed with '_', so the value never escapes the directory
+func NewFileRouter(key, pattern string, perm os.FileMode) *FieldRouter {
+	return NewFieldRouter(key, func(value string) (io.Writer, error) {
+		return NewLogFile(strings.ReplaceAll(pattern, "{value}", routeFileName(value)), perm)
+	})
+}
+
+// routeFileName makes the value safe as the file name
+func routeFileName(value string) string {
+	b := []byte(value)
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			b[i] = '_'
+		}
+	}
+	if len(b) == 0 || b[0] == '.' {
+		b = append([]byte{'_'}, b...)
+	}
+	return string(b)
+}
+
+// SetFallback sets the writer of the entries without the field, they are discarded by default
+func (r *FieldRouter) SetFallback(w io.Writer) *FieldRouter {
+	r.mu.Lock()
+	r.fallback = w
+	r.mu.Unlock()
+	return r
+}
+
+// SetIdleTimeout sets the duration after which the unused destination is closed, zero or negative d keeps them open
+func (r *FieldRouter) SetIdleTimeout(d time.Duration) *FieldRouter {
+	r.mu.Lock()
+	r.idle = d
+	r.mu.Unlock()
+	return r
+}
+
+// SetMaxOpen sets the number of the destinations kept open, zero or negative n removes the limit
+func (r *FieldRouter) SetMaxOpen(n int) *FieldRouter {
+	r.mu.Lock()
+	r.maxOpen = n
+	r.mu.Unlock()
+	return r
+}
+
+// Name returns the name of the router
+func (r *FieldRouter) Name() string {
+	return "router:" + r.key
+}
+
+// Len returns the number of the open destinations
+func (r *FieldRouter) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.handles)
+}
+
+// Write writes p to the fallback writer, the entries logged through the instance are routed by their fields
+func (r *FieldRouter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	fb, closed := r.fallback, r.closed
+	r.mu.Unlock()
+	if closed {
+		return 0, ErrWriterClosed
+	}
+	if fb == nil {
+		return len(p), nil
+	}
+	return fb.Write(p)
+}
+
+// route returns the writer of the entry of the fields
+func (r *FieldRouter) route(fields []Field) io.Writer {
+	value, ok := routeValue(fields, r.key)
+	if !ok || value == "" {
+		return r
+	}
+	return routedWriter{r: r, value: value}
+}
+
+// routeValue returns the text of the last field of the dotted key
+func routeValue(fields []Field, key string) (string, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		f := fields[i]
+		if f.Key == key && f.kind != fieldGroup {
+			return string(f.appendText(nil, false)), true
+		}
+		if f.kind == fieldGroup && strings.HasPrefix(key, f.Key+".") {
+			if v, ok := routeValue(f.iface.([]Field), key[len(f.Key)+1:]); ok {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
+
+// routedWriter looks up the destination when the entry is written, so the entry queued by the asynchronous writing
+// reopens the destination closed meanwhile
+type routedWriter struct {
+	r     *FieldRouter
+	value string
+}
+
+func (rw routedWriter) Write(p []byte) (int, error) {
+	return rw.r.writeValue(rw.value, p)
+}
+
+// writeValue writes p to the destination of value, the router lock serializes the writes and the close of the destination
+func (r *FieldRouter) writeValue(value string, p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return 0, ErrWriterClosed
+	}
+	h, ok := r.handles[value]
+	if !ok {
+		w, err := r.open(value)
+		if err != nil {
+			return 0, err
+		}
+		if r.maxOpen > 0 && len(r.handles) >= r.maxOpen {
+			r.evictLocked()
+		}
+		h = &routeHandle{w: w}
+		r.handles[value] = h
+		if r.stop == nil && r.idle > 0 {
+			r.stop, r.done = make(chan struct{}), make(chan struct{})
+			go r.run(r.stop, r.done)
+		}
+	}
+	h.used = time.Now()
+	return h.w.Write(p)
+}
+
+// evictLocked closes the least recently used destination
+func (r *FieldRouter) evictLocked() {
+	var (
+		lru    string
+		oldest time.Time
+	)
+	for v, h := range r.handles {
+		if oldest.IsZero() || h.used.Before(oldest) {
+			lru, oldest = v, h.used
+		}
+	}
+	if h, ok := r.handles[lru]; ok {
+		delete(r.handles, lru)
+		closeWriter(context.Background(), h.w)
+	}
+}
+
+// run closes the idle destinations
+func (r *FieldRouter) run(stop, done chan struct{}) {
+	defer close(done)
+	r.mu.Lock()
+	interval := r.idle / 2
+	r.mu.Unlock()
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			r.closeIdle(now)
+		}
+	}
+}
+
+// closeIdle closes the destinations unused since now minus the idle timeout
+func (r *FieldRouter) closeIdle(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.idle <= 0 {
+		return
+	}
+	for v, h := range r.handles {
+		if now.Sub(h.used) >= r.idle {
+			delete(r.handles, v)
+			closeWriter(context.Background(), h.w)
+		}
+	}
+}
+
+// Close closes the open destinations and the fallback writer
+func (r *FieldRouter) Close() error {
+	return r.CloseContext(context.Background())
+}
+
+// CloseContext closes the open destinations and the fallback writer with ctx, it is called by Shutdown
+func (r *FieldRouter) CloseContext(ctx context.Context) error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	stop, done := r.stop, r.done
+	handles := r.handles
+	r.handles = make(map[string]*routeHandle)
+	fb := r.fallback
+	r.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	var errs []error
+	for _, h := range handles {
+		errs = append(errs, writeError(h.w, closeWriter(ctx, h.w)))
+	}
+	if fb != nil {
+		errs = append(errs, writeError(fb, closeWriter(ctx, fb)))
+	}
+	return errors.Join(errs...)
+}
+
+// routeWriter returns the writer of the entry of the fields, the routers of w are replaced by their destinations
+func routeWriter(w io.Writer, fields []Field) io.Writer {
+	switch t := w.(type) {
+	case *FieldRouter:
+		return t.route(fields)
+	case fanout:
+		var rf fanout
+		for i, fw := range t {
+			if r, ok := fw.(*FieldRouter); ok {
+				if rf == nil {
+					rf = append(fanout(nil), t...)
+				}
+				rf[i] = r.route(fields)
+			}
+		}
+		if rf != nil {
+			return rf
+		}
+	}
+	return w
+}
@@ -0,0 +1,122 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileRouter(t *testing.T) {
+	dir := t.TempDir()
+	fallback := new(closeBuffer)
+	r := NewFileRouter("tenant", filepath.Join(dir, "{value}.log"), 0o600).SetFallback(fallback)
+	g := New().SetMode(WRITER).SetWriter(r).SetLineTraceMode(TraceLineNone).DisableTimestamp()
+
+	g.With(String("tenant", "acme")).Info("acme entry")
+	g.Info("routed by the argument", String("tenant", "globex"))
+	g.With(Group("request", String("tenant", "nested"))).Info("not routed")
+	g.Info("no tenant")
+	g.Info("escape", String("tenant", "../../etc/passwd"))
+	if n := r.Len(); n != 3 {
+		t.Errorf("Len() = %d, want 3", n)
+	}
+	if err := g.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		"acme.log":              "[INFO]:\tacme entry\ttenant=acme\n",
+		"globex.log":            "[INFO]:\trouted by the argument\ttenant=globex\n",
+		"_.._.._etc_passwd.log": "[INFO]:\tescape\ttenant=../../etc/passwd\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v, want %q", name, got, err, want)
+		}
+	}
+	if got := fallback.String(); got != "[INFO]:\tnot routed\trequest.tenant=nested\n[INFO]:\tno tenant\n" || fallback.closed != 1 {
+		t.Errorf("fallback = %q, closed %d", got, fallback.closed)
+	}
+	if _, err := r.writeValue("acme", []byte("x")); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("write after Close error = %v", err)
+	}
+}
+
+func TestFieldRouter_Cache(t *testing.T) {
+	opened := make(map[string]int)
+	bufs := make(map[string]*closeBuffer)
+	r := NewFieldRouter("shard", func(value string) (io.Writer, error) {
+		if value == "bad" {
+			return nil, errors.New("no such shard")
+		}
+		opened[value]++
+		bufs[value] = new(closeBuffer)
+		return bufs[value], nil
+	}).SetMaxOpen(2).SetIdleTimeout(0)
+	g := New().SetMode(WRITER).SetWriter(new(bytes.Buffer)).AddWriter(r).SetLineTraceMode(TraceLineNone)
+	for _, shard := range []string{"1", "2", "1", "3", "1", "2"} {
+		g.Info("entry", String("shard", shard), String("group", "request"))
+	}
+	// 2 is evicted by 3 and opened again evicting 3
+	if opened["1"] != 1 || opened["2"] != 2 || opened["3"] != 1 || r.Len() != 2 {
+		t.Errorf("opened = %v, open %d", opened, r.Len())
+	}
+	if bufs["3"].closed != 1 || strings.Count(bufs["1"].String(), "entry") != 3 {
+		t.Errorf("shard 3 closed %d, shard 1 = %q", bufs["3"].closed, bufs["1"].String())
+	}
+	if err := g.Info("entry", String("shard", "bad")); err == nil || !strings.Contains(err.Error(), "no such shard") {
+		t.Errorf("Info() error = %v", err)
+	}
+	r.Close()
+}
+
+func TestFieldRouter_IdleTimeout(t *testing.T) {
+	buf := new(closeBuffer)
+	r := NewFieldRouter("tenant", func(string) (io.Writer, error) {
+		return buf, nil
+	}).SetIdleTimeout(20 * time.Millisecond)
+	defer r.Close()
+	g := New().SetMode(WRITER).SetWriter(r).SetLineTraceMode(TraceLineNone)
+	g.Info("entry", String("tenant", "a"))
+	deadline := time.Now().Add(5 * time.Second)
+	for r.Len() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the idle destination is not closed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	r.mu.Lock()
+	closed := buf.closed
+	r.mu.Unlock()
+	if closed != 1 {
+		t.Errorf("closed %d times, want 1", closed)
+	}
+}
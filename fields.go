@@ -0,0 +1,605 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+	"unsafe"
+
+	json "github.com/goccy/go-json"
+)
+
+type fieldKind uint8
+
+const (
+	fieldAny fieldKind = iota
+	fieldString
+	fieldInt
+	fieldUint
+	fieldFloat
+	fieldBool
+	fieldDuration
+	fieldError
+	fieldGroup
+	fieldBytes
+	fieldPercent
+)
+
+// Field is structured key value pair attached to the log entry.
+// Fields can be passed to the logging functions along with message values
+// or attached to the derived logger by With
+type Field struct {
+	Key   string
+	kind  fieldKind
+	num   int64
+	str   string
+	iface interface{}
+}
+
+// Lazy is deferred value which is evaluated only when the entry is actually written,
+// e.g. glg.Debug("state", glg.F("dump", glg.Lazy(func() interface{} { return expensive() })))
+type Lazy func() interface{}
+
+// F returns Field of the key and value
+func F(key string, val interface{}) Field {
+	return Field{
+		Key:   key,
+		iface: val,
+	}
+}
+
+// Format implements fmt.Formatter
+func (l Lazy) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, directive(f, verb), l.value())
+}
+
+// String implements fmt.Stringer
+func (l Lazy) String() string {
+	return fmt.Sprint(l.value())
+}
+
+// MarshalJSON implements json.Marshaler
+func (l Lazy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.value())
+}
+
+func (l Lazy) value() interface{} {
+	if l == nil {
+		return nil
+	}
+	return l()
+}
+
+// once returns Lazy evaluating l at most once, the entry written to several writers, encoders and routes shares the value
+func (l Lazy) once() Lazy {
+	var (
+		o sync.Once
+		v interface{}
+	)
+	return func() interface{} {
+		o.Do(func() {
+			v = l.value()
+		})
+		return v
+	}
+}
+
+// memoizeLazy returns val and fields whose Lazy values are evaluated once per entry, they are returned as they are without Lazy
+func memoizeLazy(val []interface{}, fields []Field) ([]interface{}, []Field) {
+	var vals []interface{}
+	for i, v := range val {
+		if l, ok := v.(Lazy); ok {
+			if vals == nil {
+				vals = append([]interface{}(nil), val...)
+			}
+			vals[i] = l.once()
+		}
+	}
+	if vals != nil {
+		val = vals
+	}
+	if fs, ok := memoizeLazyFields(fields); ok {
+		fields = fs
+	}
+	return val, fields
+}
+
+// memoizeLazyFields returns the copy of fields with the memoized Lazy values and true, or false when fields have no Lazy
+func memoizeLazyFields(fields []Field) ([]Field, bool) {
+	var fs []Field
+	for i, f := range fields {
+		switch v := f.iface.(type) {
+		case Lazy:
+			f.iface = v.once()
+		case []Field:
+			gfs, ok := memoizeLazyFields(v)
+			if f.kind != fieldGroup || !ok {
+				continue
+			}
+			f.iface = gfs
+		default:
+			continue
+		}
+		if fs == nil {
+			fs = append([]Field(nil), fields...)
+		}
+		fs[i] = f
+	}
+	return fs, fs != nil
+}
+
+// String returns string Field
+func String(key, val string) Field {
+	return Field{Key: key, kind: fieldString, str: val}
+}
+
+// Int returns int Field
+func Int(key string, val int) Field {
+	return Field{Key: key, kind: fieldInt, num: int64(val)}
+}
+
+// Int64 returns int64 Field
+func Int64(key string, val int64) Field {
+	return Field{Key: key, kind: fieldInt, num: val}
+}
+
+// Uint64 returns uint64 Field
+func Uint64(key string, val uint64) Field {
+	return Field{Key: key, kind: fieldUint, num: int64(val)}
+}
+
+// Float64 returns float64 Field
+func Float64(key string, val float64) Field {
+	return Field{Key: key, kind: fieldFloat, num: int64(math.Float64bits(val))}
+}
+
+// Bool returns bool Field
+func Bool(key string, val bool) Field {
+	var n int64
+	if val {
+		n = 1
+	}
+	return Field{Key: key, kind: fieldBool, num: n}
+}
+
+// Dur returns time.Duration Field
+func Dur(key string, val time.Duration) Field {
+	return Field{Key: key, kind: fieldDuration, num: int64(val)}
+}
+
+// Bytes returns byte size Field, it is rendered by HumanBytes in text mode when human readable output is enabled
+func Bytes(key string, n int64) Field {
+	return Field{Key: key, kind: fieldBytes, num: n}
+}
+
+// Percent returns ratio Field, 0.5 is rendered as 50% in text mode when human readable output is enabled
+func Percent(key string, ratio float64) Field {
+	return Field{Key: key, kind: fieldPercent, num: int64(math.Float64bits(ratio))}
+}
+
+// Err returns error Field keyed "error"
+func Err(err error) Field {
+	return Field{Key: "error", kind: fieldError, iface: err}
+}
+
+// Group returns Field which nests the fields under the key, e.g. {"http":{"method":"GET"}} in JSON
+// and http.method=GET in text
+func Group(key string, fields ...Field) Field {
+	return Field{Key: key, kind: fieldGroup, iface: fields}
+}
+
+// Any returns Field of any typed value, the strings, the numbers, bool and time.Duration are stored in the typed slots
+// like their typed constructors, the other values are stored as F does.
+// Only the value is kept unboxed, the Field itself is boxed when it is passed to the logging functions as interface{}
+func Any[T any](key string, val T) Field {
+	// the interface of the type switch does not escape, so it is not allocated for the known types
+	switch v := interface{}(val).(type) {
+	case string:
+		return String(key, v)
+	case int:
+		return Int(key, v)
+	case int8:
+		return Int64(key, int64(v))
+	case int16:
+		return Int64(key, int64(v))
+	case int32:
+		return Int64(key, int64(v))
+	case int64:
+		return Int64(key, v)
+	case uint:
+		return Uint64(key, uint64(v))
+	case uint8:
+		return Uint64(key, uint64(v))
+	case uint16:
+		return Uint64(key, uint64(v))
+	case uint32:
+		return Uint64(key, uint64(v))
+	case uint64:
+		return Uint64(key, v)
+	case float32:
+		return Float64(key, float64(v))
+	case float64:
+		return Float64(key, v)
+	case bool:
+		return Bool(key, v)
+	case time.Duration:
+		return Dur(key, v)
+	}
+	boxed := interface{}(val)
+	if err, ok := boxed.(error); ok {
+		return Field{Key: key, kind: fieldError, iface: err}
+	}
+	return F(key, boxed)
+}
+
+// Value returns the value of the field
+func (f Field) Value() interface{} {
+	switch f.kind {
+	case fieldString:
+		return f.str
+	case fieldInt, fieldBytes:
+		return f.num
+	case fieldUint:
+		return uint64(f.num)
+	case fieldFloat, fieldPercent:
+		return math.Float64frombits(uint64(f.num))
+	case fieldBool:
+		return f.num == 1
+	case fieldDuration:
+		return time.Duration(f.num)
+	}
+	return f.iface
+}
+
+// appendText appends the text representation of the value, human selects human readable durations, byte sizes and ratios
+func (f Field) appendText(b []byte, human bool) []byte {
+	switch f.kind {
+	case fieldString:
+		return append(b, f.str...)
+	case fieldInt:
+		return strconv.AppendInt(b, f.num, 10)
+	case fieldUint:
+		return strconv.AppendUint(b, uint64(f.num), 10)
+	case fieldFloat:
+		return strconv.AppendFloat(b, math.Float64frombits(uint64(f.num)), 'g', -1, 64)
+	case fieldBool:
+		return strconv.AppendBool(b, f.num == 1)
+	case fieldDuration:
+		if human {
+			return append(b, HumanDuration(time.Duration(f.num))...)
+		}
+		return append(b, time.Duration(f.num).String()...)
+	case fieldBytes:
+		if human {
+			return append(b, HumanBytes(f.num)...)
+		}
+		return strconv.AppendInt(b, f.num, 10)
+	case fieldPercent:
+		if human {
+			return append(b, HumanPercent(math.Float64frombits(uint64(f.num)))...)
+		}
+		return strconv.AppendFloat(b, math.Float64frombits(uint64(f.num)), 'g', -1, 64)
+	case fieldError:
+		if f.iface == nil {
+			return append(b, "<nil>"...)
+		}
+		return append(b, f.iface.(error).Error()...)
+	case fieldGroup:
+		buf := new(bytes.Buffer)
+		buf.WriteByte('{')
+		writeFields(buf, f.iface.([]Field), 0, human)
+		buf.WriteByte('}')
+		return append(b, buf.Bytes()...)
+	}
+	v, _ := resolve(f.iface, false, 0)
+	return append(b, fmt.Sprint(v)...)
+}
+
+// appendJSON appends the JSON representation of the value
+func (f Field) appendJSON(b []byte, max int) ([]byte, error) {
+	switch f.kind {
+	case fieldString:
+		return appendJSONString(b, truncate(f.str, max)), nil
+	case fieldInt, fieldDuration, fieldBytes:
+		return strconv.AppendInt(b, f.num, 10), nil
+	case fieldUint:
+		return strconv.AppendUint(b, uint64(f.num), 10), nil
+	case fieldFloat, fieldPercent:
+		v := math.Float64frombits(uint64(f.num))
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return appendJSONString(b, strconv.FormatFloat(v, 'g', -1, 64)), nil
+		}
+		return strconv.AppendFloat(b, v, 'g', -1, 64), nil
+	case fieldBool:
+		return strconv.AppendBool(b, f.num == 1), nil
+	case fieldError:
+		if f.iface == nil {
+			return append(b, "null"...), nil
+		}
+		return appendJSONString(b, truncate(f.iface.(error).Error(), max)), nil
+	case fieldGroup:
+		buf, err := jsonFields{fields: f.iface.([]Field), max: max}.MarshalJSON()
+		if err != nil {
+			return b, err
+		}
+		return append(b, buf...), nil
+	}
+	v, _ := resolve(f.iface, true, 0)
+	if max > 0 {
+		v = truncateDetail(v, max)
+	}
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return b, err
+	}
+	return append(b, buf...), nil
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends JSON quoted string, invalid UTF-8 is replaced with U+FFFD and U+2028 and U+2029 are escaped
+func appendJSONString(b []byte, str string) []byte {
+	b = append(b, '"')
+	for i := 0; i < len(str); {
+		c := str[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				b = append(b, '\\', c)
+			case c == '\n':
+				b = append(b, '\\', 'n')
+			case c == '\r':
+				b = append(b, '\\', 'r')
+			case c == '\t':
+				b = append(b, '\\', 't')
+			case c < 0x20:
+				b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			default:
+				b = append(b, c)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(str[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b = append(b, `\ufffd`...)
+		case r == '\u2028' || r == '\u2029':
+			// the line and paragraph separators are escaped as encoding/json does, the raw ones break the JSON compaction
+			b = append(b, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+		default:
+			b = append(b, str[i:i+size]...)
+		}
+		i += size
+	}
+	return append(b, '"')
+}
+
+// jsonFields is JSON object of fields encoded without reflection for typed fields
+type jsonFields struct {
+	fields []Field
+	max    int
+}
+
+// MarshalJSON implements json.Marshaler
+func (jf jsonFields) MarshalJSON() ([]byte, error) {
+	b := make([]byte, 0, len(jf.fields)*32)
+	b = append(b, '{')
+	var n int
+	for i, f := range jf.fields {
+		if jf.overridden(i) {
+			continue
+		}
+		if f.kind == fieldGroup {
+			var ok bool
+			if f, ok = jf.mergeGroup(i); !ok {
+				continue
+			}
+		}
+		if n != 0 {
+			b = append(b, ',')
+		}
+		n++
+		b = appendJSONString(b, f.Key)
+		b = append(b, ':')
+		var err error
+		b, err = f.appendJSON(b, jf.max)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append(b, '}'), nil
+}
+
+func (o *switches) jsonFields(fields []Field) *jsonFields {
+	if len(fields) == 0 {
+		return nil
+	}
+	return &jsonFields{
+		fields: fields,
+		max:    o.maxFieldSize,
+	}
+}
+
+// overridden reports the field is overridden by the later field of the same key,
+// groups of the same key are merged instead
+func (jf jsonFields) overridden(i int) bool {
+	group := jf.fields[i].kind == fieldGroup
+	for _, f := range jf.fields[i+1:] {
+		if f.Key == jf.fields[i].Key && (!group || f.kind != fieldGroup) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeGroup merges the group at i with the following groups of the same key,
+// ok is false when the group is already merged into the former one
+func (jf jsonFields) mergeGroup(i int) (group Field, ok bool) {
+	group = jf.fields[i]
+	for j, f := range jf.fields[:i] {
+		if f.Key == group.Key && f.kind == fieldGroup && !jf.overridden(j) {
+			return group, false
+		}
+	}
+	var fields []Field
+	for _, f := range jf.fields[i+1:] {
+		if f.Key == group.Key && f.kind == fieldGroup {
+			if fields == nil {
+				fields = append(fields, group.iface.([]Field)...)
+			}
+			fields = append(fields, f.iface.([]Field)...)
+		}
+	}
+	if fields != nil {
+		group.iface = fields
+	}
+	return group, true
+}
+
+// With returns derived logger which outputs the fields with every entry.
+// The derived logger shares the configuration with g
+func (g *Glg) With(fields ...Field) *Glg {
+	fields = g.group(fields)
+	fs := make([]Field, 0, len(g.fields)+len(fields))
+	fs = append(append(fs, g.fields...), fields...)
+	return &Glg{
+		core:   g.core,
+		fields: fs,
+		groups: g.groups,
+		batch:  g.batch,
+		to:     g.to,
+	}
+}
+
+// With returns derived logger which outputs the fields with every entry
+func With(fields ...Field) *Glg {
+	return Get().With(fields...)
+}
+
+// WithGroup returns derived logger which nests the fields added after it, by With or by the logging functions,
+// under the group name. The fields already attached are kept as they are
+func (g *Glg) WithGroup(name string) *Glg {
+	if name == "" {
+		return g
+	}
+	groups := make([]string, 0, len(g.groups)+1)
+	return &Glg{
+		core:   g.core,
+		fields: g.fields,
+		groups: append(append(groups, g.groups...), name),
+		batch:  g.batch,
+		to:     g.to,
+	}
+}
+
+// WithGroup returns derived logger which nests the fields added after it under the group name
+func WithGroup(name string) *Glg {
+	return Get().WithGroup(name)
+}
+
+// group nests fields under the groups of g, empty groups are omitted
+func (g *Glg) group(fields []Field) []Field {
+	if len(g.groups) == 0 || len(fields) == 0 {
+		return fields
+	}
+	for i := len(g.groups) - 1; i >= 0; i-- {
+		fields = []Field{Group(g.groups[i], fields...)}
+	}
+	return fields
+}
+
+// splitFields separates Field values from message values, o is the switches of the entry
+func (g *Glg) splitFields(o *switches, format string, val []interface{}) (string, []interface{}, []Field) {
+	fields := g.fields
+	var n int
+	for _, v := range val {
+		if _, ok := v.(Field); ok {
+			n++
+		}
+	}
+	if n == 0 {
+		return format, val, fields
+	}
+	blank := format == o.blankFormat(len(val))
+	fs := make([]Field, 0, n)
+	vals := make([]interface{}, 0, len(val)-n)
+	for _, v := range val {
+		if f, ok := v.(Field); ok {
+			fs = append(fs, f)
+		} else {
+			vals = append(vals, v)
+		}
+	}
+	if blank {
+		format = o.blankFormat(len(vals))
+	}
+	fs = g.group(fs)
+	return format, vals, append(fields[:len(fields):len(fields)], fs...)
+}
+
+// writeFields writes fields as space separated key=value pairs
+func (o *switches) writeFields(b *bytes.Buffer, fields []Field) {
+	writeFields(b, fields, o.maxFieldSize, o.enableHuman)
+}
+
+func writeFields(b *bytes.Buffer, fields []Field, max int, human bool) {
+	writeGroupFields(b, "", fields, max, human, false)
+}
+
+// writeGroupFields writes fields with the dotted group prefix, it returns true when something is written
+func writeGroupFields(b *bytes.Buffer, prefix string, fields []Field, max int, human, sep bool) bool {
+	var buf []byte
+	for _, f := range fields {
+		if f.kind == fieldGroup {
+			sep = writeGroupFields(b, prefix+f.Key+".", f.iface.([]Field), max, human, sep)
+			continue
+		}
+		if sep {
+			b.WriteString(spw)
+		}
+		sep = true
+		b.WriteString(prefix)
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		buf = f.appendText(buf[:0], human)
+		str := truncate(*(*string)(unsafe.Pointer(&buf)), max)
+		if str == "" || strings.IndexFunc(str, needsQuote) >= 0 {
+			str = strconv.Quote(str)
+		}
+		b.WriteString(str)
+	}
+	return sep
+}
+
+func needsQuote(r rune) bool {
+	return r == ' ' || r == '=' || r == '"' || needsEscape(r) || r == '\t'
+}
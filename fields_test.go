@@ -0,0 +1,389 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	json "github.com/goccy/go-json"
+)
+
+func TestGlg_With(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []Field
+		layout string
+		val    []interface{}
+		want   string
+	}{
+		{
+			name:   "derived fields",
+			fields: []Field{F("request_id", "abc"), F("user", "bob")},
+			val:    []interface{}{"hello"},
+			want:   "[INFO]:\thello\trequest_id=abc user=bob\n",
+		},
+		{
+			name:   "call fields",
+			fields: []Field{F("request_id", "abc")},
+			val:    []interface{}{"hello", F("latency", 12), "glg"},
+			want:   "[INFO]:\thello glg\trequest_id=abc latency=12\n",
+		},
+		{
+			name: "quoted value",
+			val:  []interface{}{"hello", F("msg", "a b"), F("empty", "")},
+			want: "[INFO]:\thello\tmsg=\"a b\" empty=\"\"\n",
+		},
+		{
+			name:   "layout",
+			fields: []Field{F("id", 1)},
+			layout: "{{level}} {{fields}} {{msg}}",
+			val:    []interface{}{"hello"},
+			want:   "INFO id=1 hello\n",
+		},
+		{
+			name:   "layout without fields placeholder",
+			fields: []Field{F("id", 1)},
+			layout: "{{level}}: {{msg}}",
+			val:    []interface{}{"hello"},
+			want:   "INFO: hello id=1\n",
+		},
+		{
+			name: "only fields",
+			val:  []interface{}{F("id", 1)},
+			want: "[INFO]:\t\tid=1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetLineFormat(tt.layout)
+			if err := g.With(tt.fields...).Info(tt.val...); err != nil {
+				t.Error(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Glg.With() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_With_Shared(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp()
+	d := g.With(F("component", "db"))
+	g.EnableJSON()
+	if err := d.Info("hello", F("rows", 3)); err != nil {
+		t.Error(err)
+	}
+	var dec JSONFormat
+	if err := json.Unmarshal(buf.Bytes(), &dec); err != nil {
+		t.Fatal(err)
+	}
+	if dec.Detail != "hello" || dec.Fields["component"] != "db" || dec.Fields["rows"] != float64(3) {
+		t.Errorf("Glg.With() json = %s", buf.String())
+	}
+	if len(g.fields) != 0 {
+		t.Errorf("parent fields = %v", g.fields)
+	}
+}
+
+func TestLazy(t *testing.T) {
+	var called int
+	lazy := Lazy(func() interface{} {
+		called++
+		return "expensive"
+	})
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp()
+
+	g.SetLevel(INFO).Debug("state", F("dump", lazy), lazy)
+	if called != 0 {
+		t.Errorf("Lazy evaluated %d times for disabled level", called)
+	}
+
+	g.Info("state", F("dump", lazy))
+	g.Infof("state %-10s|", lazy)
+	if called != 2 {
+		t.Errorf("Lazy evaluated %d times, want 2", called)
+	}
+	want := "[INFO]:\tstate\tdump=expensive\n[INFO]:\tstate expensive |\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Lazy = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	g.EnableJSON().Info("state", F("dump", lazy))
+	if !strings.Contains(buf.String(), `"fields":{"dump":"expensive"}`) {
+		t.Errorf("Lazy json = %s", buf.String())
+	}
+}
+
+func TestLazy_Once(t *testing.T) {
+	var called int
+	lazy := Lazy(func() interface{} {
+		called++
+		return called
+	})
+	text, route := new(bytes.Buffer), new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(text).DisableTimestamp().With(F("with", lazy))
+	g.Route(Route{Writer: route, JSON: LevelJSONOn})
+
+	g.Info("state", F("dump", lazy), Group("g", F("nested", lazy)), lazy)
+	if called != 4 {
+		t.Errorf("Lazy evaluated %d times, want once per value", called)
+	}
+	if got, want := text.String(), "[INFO]:\tstate 1\twith=2 dump=3 g.nested=4\n"; got != want {
+		t.Errorf("entry = %q, want %q", got, want)
+	}
+	if got, want := route.String(), `"fields":{"with":2,"dump":3,"g":{"nested":4}}`; !strings.Contains(got, want) {
+		t.Errorf("routed entry = %s, want %s", got, want)
+	}
+}
+
+func TestGlg_SetPrefix_Fields(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetPrefix(INFO, "tenant:{{tenant}}")
+	g.With(F("tenant", "acme")).Info("hello")
+	if got, want := buf.String(), "[tenant:acme]:\thello\ttenant=acme\n"; got != want {
+		t.Errorf("Glg.SetPrefix() = %q, want %q", got, want)
+	}
+}
+
+func TestTypedFields(t *testing.T) {
+	err := errors.New("failed")
+	tests := []struct {
+		name  string
+		field Field
+		value interface{}
+		text  string
+		json  string
+	}{
+		{
+			name:  "String",
+			field: String("k", "v\"1"),
+			value: "v\"1",
+			text:  `k="v\"1"`,
+			json:  `{"k":"v\"1"}`,
+		},
+		{
+			name:  "Int",
+			field: Int("k", -3),
+			value: int64(-3),
+			text:  "k=-3",
+			json:  `{"k":-3}`,
+		},
+		{
+			name:  "Uint64",
+			field: Uint64("k", math.MaxUint64),
+			value: uint64(math.MaxUint64),
+			text:  "k=18446744073709551615",
+			json:  `{"k":18446744073709551615}`,
+		},
+		{
+			name:  "Float64",
+			field: Float64("k", 1.5),
+			value: 1.5,
+			text:  "k=1.5",
+			json:  `{"k":1.5}`,
+		},
+		{
+			name:  "Float64 NaN",
+			field: Float64("k", math.NaN()),
+			text:  "k=NaN",
+			json:  `{"k":"NaN"}`,
+		},
+		{
+			name:  "Bool",
+			field: Bool("k", true),
+			value: true,
+			text:  "k=true",
+			json:  `{"k":true}`,
+		},
+		{
+			name:  "Dur",
+			field: Dur("k", 1500*time.Millisecond),
+			value: 1500 * time.Millisecond,
+			text:  "k=1.5s",
+			json:  `{"k":1500000000}`,
+		},
+		{
+			name:  "Err",
+			field: Err(err),
+			value: err,
+			text:  "error=failed",
+			json:  `{"error":"failed"}`,
+		},
+		{
+			name:  "Any string",
+			field: Any("k", "v"),
+			value: "v",
+			text:  "k=v",
+			json:  `{"k":"v"}`,
+		},
+		{
+			name:  "Any struct",
+			field: Any("k", struct{ A int }{A: 1}),
+			value: struct{ A int }{A: 1},
+			text:  "k={1}",
+			json:  `{"k":{"A":1}}`,
+		},
+		{
+			name:  "Any error",
+			field: Any("k", err),
+			value: err,
+			text:  "k=failed",
+			json:  `{"k":"failed"}`,
+		},
+		{
+			name:  "invalid utf8",
+			field: String("k", "a\xffb\n"),
+			value: "a\xffb\n",
+			text:  `k="a\xffb\n"`,
+			json:  `{"k":"a\ufffdb\n"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.value != nil && !reflect.DeepEqual(tt.field.Value(), tt.value) {
+				t.Errorf("Field.Value() = %#v, want %#v", tt.field.Value(), tt.value)
+			}
+			b := new(bytes.Buffer)
+			New().opts().writeFields(b, []Field{tt.field})
+			if got := b.String(); got != tt.text {
+				t.Errorf("writeFields() = %s, want %s", got, tt.text)
+			}
+			got, err := jsonFields{fields: []Field{tt.field}}.MarshalJSON()
+			if err != nil {
+				t.Error(err)
+			}
+			if string(got) != tt.json {
+				t.Errorf("jsonFields.MarshalJSON() = %s, want %s", got, tt.json)
+			}
+		})
+	}
+}
+
+func TestJSONFields_Override(t *testing.T) {
+	got, err := jsonFields{fields: []Field{Int("a", 1), Int("b", 2), Int("a", 3)}}.MarshalJSON()
+	if err != nil {
+		t.Error(err)
+	}
+	if want := `{"b":2,"a":3}`; string(got) != want {
+		t.Errorf("jsonFields.MarshalJSON() = %s, want %s", got, want)
+	}
+}
+
+func TestGlg_WithGroup(t *testing.T) {
+	tests := []struct {
+		name string
+		json bool
+		log  func(g *Glg) error
+		want string
+	}{
+		{
+			name: "text",
+			log: func(g *Glg) error {
+				return g.With(F("app", "api")).WithGroup("http").With(F("method", "GET")).Info("done", F("status", 200))
+			},
+			want: "[INFO]:\tdone\tapp=api http.method=GET http.status=200\n",
+		},
+		{
+			name: "json",
+			json: true,
+			log: func(g *Glg) error {
+				return g.With(F("app", "api")).WithGroup("http").With(F("method", "GET")).Info("done", F("status", 200))
+			},
+			want: `{"level":"INFO","detail":"done","fields":{"app":"api","http":{"method":"GET","status":200}}}` + "\n",
+		},
+		{
+			name: "json nested",
+			json: true,
+			log: func(g *Glg) error {
+				return g.WithGroup("http").WithGroup("req").Info("done", Int("size", 10))
+			},
+			want: `{"level":"INFO","detail":"done","fields":{"http":{"req":{"size":10}}}}` + "\n",
+		},
+		{
+			name: "json empty group",
+			json: true,
+			log: func(g *Glg) error {
+				return g.WithGroup("http").Info("done")
+			},
+			want: `{"level":"INFO","detail":"done"}` + "\n",
+		},
+		{
+			name: "json overridden group",
+			json: true,
+			log: func(g *Glg) error {
+				return g.WithGroup("http").With(F("method", "GET")).Info("done", F("http", "-"), Group("http", F("status", 200)))
+			},
+			want: `{"level":"INFO","detail":"done","fields":{"http":{"method":"GET","http":{"status":200}}}}` + "\n",
+		},
+		{
+			name: "json group value",
+			json: true,
+			log: func(g *Glg) error {
+				return g.Info("done", F("http", "-"), Group("http", F("status", 200)))
+			},
+			want: `{"level":"INFO","detail":"done","fields":{"http":{"status":200}}}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp()
+			if tt.json {
+				g.EnableJSON()
+			}
+			if err := tt.log(g); err != nil {
+				t.Error(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Glg.WithGroup() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+var anyField Field
+
+func TestAny_Allocs(t *testing.T) {
+	n, f, s := 123456, 1.5, strings.Repeat("v", 32)
+	tests := map[string]func(){
+		"int":      func() { anyField = Any("k", n) },
+		"float64":  func() { anyField = Any("k", f) },
+		"string":   func() { anyField = Any("k", s) },
+		"duration": func() { anyField = Any("k", time.Duration(n)) },
+	}
+	for name, fn := range tests {
+		if allocs := testing.AllocsPerRun(100, fn); allocs != 0 {
+			t.Errorf("Any(%s) allocs = %v, want 0", name, allocs)
+		}
+	}
+}
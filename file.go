@@ -0,0 +1,392 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kpango/fastime"
+)
+
+const (
+	// segmentTimeFormat is the suffix of the rotated segments, e.g. app.log.20060102T150405.000000000
+	segmentTimeFormat = "20060102T150405.000000000"
+
+	// DefaultReopenCheckInterval is the default interval to check the file is moved or removed by external rotation
+	DefaultReopenCheckInterval = time.Second
+)
+
+// LogFile is the log file managed by glg, it is opened with O_APPEND
+// and rotated to the timestamped segment when it exceeds the max size.
+// The file is reopened when it is moved or removed by external rotation such as logrotate.
+// When the file fails to be opened again after the rotation, Write returns the error and retries to open it.
+// Each Write is one entry, so processes sharing the file with EnableLock never interleave partial lines
+type LogFile struct {
+	mu       sync.Mutex
+	path     string
+	perm     os.FileMode
+	file     *os.File
+	size     int64
+	maxSize  int64
+	quota    *Quota
+	interval int64
+	checked  int64
+	lock     bool
+	closed   bool
+	stats    sinkStats
+}
+
+// NewLogFile opens the log file of the path, the parent directory is created if not exists
+func NewLogFile(path string, perm os.FileMode) (*LogFile, error) {
+	f := &LogFile{
+		path:     path,
+		perm:     perm,
+		interval: int64(DefaultReopenCheckInterval),
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens the file of the path, f.file is kept when it fails
+func (f *LogFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, f.perm)
+	if err != nil {
+		return err
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, fi.Size()
+	return nil
+}
+
+// SetMaxSize sets the size to rotate the file, 0 disables rotation
+func (f *LogFile) SetMaxSize(size int64) *LogFile {
+	f.mu.Lock()
+	f.maxSize = size
+	f.mu.Unlock()
+	return f
+}
+
+// SetReopenCheckInterval sets the interval to check the file is moved or removed on Write, 0 disables the check
+func (f *LogFile) SetReopenCheckInterval(d time.Duration) *LogFile {
+	f.mu.Lock()
+	f.interval = int64(d)
+	f.mu.Unlock()
+	return f
+}
+
+// EnableLock takes the exclusive advisory lock (flock) of the file for each Write,
+// so multiple processes can safely share the file. It is not supported on Windows.
+// The lock covers the writes only, the rotation by SetMaxSize is not coordinated between the processes,
+// so only one of them should rotate the file, or it is rotated externally and reopened by each process
+func (f *LogFile) EnableLock() *LogFile {
+	f.mu.Lock()
+	f.lock = true
+	f.mu.Unlock()
+	return f
+}
+
+// DisableLock disables the advisory lock
+func (f *LogFile) DisableLock() *LogFile {
+	f.mu.Lock()
+	f.lock = false
+	f.mu.Unlock()
+	return f
+}
+
+// SetQuota adds the file and its segments to the quota
+func (f *LogFile) SetQuota(q *Quota) *LogFile {
+	f.mu.Lock()
+	f.quota = q
+	f.mu.Unlock()
+	q.add(f)
+	return f
+}
+
+// Write appends p to the file, the file is rotated before p when p exceeds the max size
+func (f *LogFile) Write(p []byte) (n int, err error) {
+	n, err = f.write(p)
+	f.stats.record(n, err)
+	return n, err
+}
+
+// Name returns the path of the file
+func (f *LogFile) Name() string {
+	return f.path
+}
+
+// Status implements StatusReporter
+func (f *LogFile) Status() SinkStatus {
+	return f.stats.status()
+}
+
+func (f *LogFile) write(p []byte) (n int, err error) {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		// the former reopen or rotation failed to open the file
+		if err = f.open(); err != nil {
+			f.mu.Unlock()
+			return 0, err
+		}
+	}
+	if f.interval > 0 {
+		if now := fastime.UnixNanoNow(); now-f.checked >= f.interval {
+			f.checked = now
+			if f.moved() {
+				if err = f.reopen(); err != nil {
+					f.mu.Unlock()
+					return 0, err
+				}
+			}
+		}
+	}
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err = f.rotate(); err != nil {
+			f.mu.Unlock()
+			return 0, err
+		}
+	}
+	if f.lock {
+		if err = lockFile(f.file); err != nil {
+			f.mu.Unlock()
+			return 0, err
+		}
+		n, err = f.file.Write(p)
+		if uerr := unlockFile(f.file); err == nil {
+			err = uerr
+		}
+	} else {
+		n, err = f.file.Write(p)
+	}
+	f.size += int64(n)
+	q := f.quota
+	f.mu.Unlock()
+	if q != nil {
+		q.grow(int64(n))
+	}
+	return n, err
+}
+
+// Reopen closes and opens the file of the path, e.g. after it is renamed by logrotate.
+// The former file is kept when the path fails to be opened
+func (f *LogFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return os.ErrClosed
+	}
+	if f.file == nil {
+		return f.open()
+	}
+	return f.reopen()
+}
+
+func (f *LogFile) reopen() error {
+	old := f.file
+	if err := f.open(); err != nil {
+		return err
+	}
+	return old.Close()
+}
+
+// moved reports the path does not point the open file any more
+func (f *LogFile) moved() bool {
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		return true
+	}
+	cur, err := f.file.Stat()
+	return err != nil || !os.SameFile(fi, cur)
+}
+
+// Rotate renames the file to the segment and opens the new file
+func (f *LogFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return os.ErrClosed
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+	return f.rotate()
+}
+
+// rotate renames the file to the segment, the file is closed before the rename for Windows.
+// When the new file fails to be opened, f.file is left nil and the next Write retries to open it
+func (f *LogFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	if err := os.Rename(f.path, f.path+"."+time.Now().Format(segmentTimeFormat)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if f.quota != nil {
+		f.quota.rotated()
+	}
+	return f.open()
+}
+
+// Close closes the file
+func (f *LogFile) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return os.ErrClosed
+	}
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+	}
+	f.file, f.closed = nil, true
+	q := f.quota
+	f.mu.Unlock()
+	if q != nil {
+		q.remove(f)
+	}
+	return err
+}
+
+// Size returns the size of the current file
+func (f *LogFile) Size() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.size
+}
+
+// Segments returns the paths of the rotated segments, oldest first
+func (f *LogFile) Segments() ([]string, error) {
+	return segments(f.path)
+}
+
+// segments returns the timestamped segments of the path, oldest first
+func segments(path string) ([]string, error) {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var segs []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, base+".") {
+			continue
+		}
+		if _, err := time.Parse(segmentTimeFormat, name[len(base)+1:]); err != nil {
+			continue
+		}
+		segs = append(segs, filepath.Join(dir, name))
+	}
+	sort.Strings(segs)
+	return segs, nil
+}
+
+// ReopenFiles reopens the open LogFiles set to the instance by SetWriter, AddWriter, SetLevelWriter, AddLevelWriter
+// and Route, it is called after external log rotation. The LogFiles wrapped by other writers are reopened by themselves
+func (g *Glg) ReopenFiles() error {
+	g.writersMu.Lock()
+	writers := append([]io.Writer(nil), g.writers...)
+	g.writersMu.Unlock()
+	var err error
+	for _, w := range writers {
+		f, ok := w.(*LogFile)
+		if !ok {
+			continue
+		}
+		if rerr := f.Reopen(); rerr != nil && rerr != os.ErrClosed && err == nil {
+			err = rerr
+		}
+	}
+	return err
+}
+
+// ReopenFiles reopens the open LogFiles set to the global instance
+func ReopenFiles() error {
+	return Get().ReopenFiles()
+}
+
+// EnableReopenOnSignal reopens the open LogFiles of the instance when the process receives the signals,
+// SIGHUP by default on Unix. It does nothing without the signals on the platforms without SIGHUP
+func (g *Glg) EnableReopenOnSignal(sigs ...os.Signal) *Glg {
+	if len(sigs) == 0 {
+		sigs = reopenSignals
+	}
+	if len(sigs) == 0 {
+		// signal.Notify without the signals relays all the signals
+		return g
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	g.sigMu.Lock()
+	g.stopReopenSignal()
+	g.reopenSig = ch
+	g.sigMu.Unlock()
+	go func() {
+		for range ch {
+			g.ReopenFiles()
+		}
+	}()
+	return g
+}
+
+// DisableReopenOnSignal stops reopening LogFiles on the signals
+func (g *Glg) DisableReopenOnSignal() *Glg {
+	g.sigMu.Lock()
+	g.stopReopenSignal()
+	g.sigMu.Unlock()
+	return g
+}
+
+func (g *Glg) stopReopenSignal() {
+	if g.reopenSig != nil {
+		signal.Stop(g.reopenSig)
+		close(g.reopenSig)
+		g.reopenSig = nil
+	}
+}
@@ -0,0 +1,247 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLogFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "app.log")
+	f, err := NewLogFile(path, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.SetMaxSize(10)
+	g := New().SetMode(WRITER).SetWriter(f).DisableTimestamp()
+	g.Info("first")
+	g.Info("second")
+	g.Info("third")
+	if err = f.Close(); err != nil {
+		t.Error(err)
+	}
+
+	segs, err := f.Segments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segs) != 2 {
+		t.Fatalf("LogFile.Segments() = %v", segs)
+	}
+	for i, want := range []string{"[INFO]:\tfirst\n", "[INFO]:\tsecond\n"} {
+		if b, _ := os.ReadFile(segs[i]); string(b) != want {
+			t.Errorf("LogFile segment %d = %q, want %q", i, b, want)
+		}
+	}
+	if b, _ := os.ReadFile(path); string(b) != "[INFO]:\tthird\n" {
+		t.Errorf("LogFile current = %q", b)
+	}
+	if _, err = f.Write([]byte("closed")); err != os.ErrClosed {
+		t.Errorf("LogFile.Write() error = %v", err)
+	}
+}
+
+func TestLogFile_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("existing\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := NewLogFile(path, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if f.Size() != 9 {
+		t.Errorf("LogFile.Size() = %d, want 9", f.Size())
+	}
+	f.Write([]byte("appended\n"))
+	if b, _ := os.ReadFile(path); string(b) != "existing\nappended\n" {
+		t.Errorf("LogFile = %q", b)
+	}
+}
+
+func TestLogFile_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	rotated := filepath.Join(dir, "app.log.1")
+	tests := []struct {
+		name   string
+		reopen func(f *LogFile) error
+	}{
+		{
+			name: "Reopen",
+			reopen: func(f *LogFile) error {
+				return f.Reopen()
+			},
+		},
+		{
+			name: "ReopenFiles",
+			reopen: func(f *LogFile) error {
+				return New().SetWriter(f).ReopenFiles()
+			},
+		},
+		{
+			name: "moved",
+			reopen: func(f *LogFile) error {
+				f.SetReopenCheckInterval(time.Nanosecond)
+				return nil
+			},
+		},
+		{
+			name: "signal",
+			reopen: func(f *LogFile) error {
+				if len(reopenSignals) == 0 {
+					t.Skip("SIGHUP is not supported")
+				}
+				g := New().SetWriter(f).EnableReopenOnSignal()
+				defer g.DisableReopenOnSignal()
+				p, _ := os.FindProcess(os.Getpid())
+				if err := p.Signal(reopenSignals[0]); err != nil {
+					return err
+				}
+				for i := 0; i < 100; i++ {
+					if _, err := os.Stat(path); err == nil {
+						return nil
+					}
+					time.Sleep(10 * time.Millisecond)
+				}
+				return errors.New("not reopened")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewLogFile(path, 0o644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			f.SetReopenCheckInterval(0)
+			f.Write([]byte("before\n"))
+			if err = os.Rename(path, rotated); err != nil {
+				t.Fatal(err)
+			}
+			if err = tt.reopen(f); err != nil {
+				t.Fatal(err)
+			}
+			f.Write([]byte("after\n"))
+			if b, _ := os.ReadFile(rotated); string(b) != "before\n" {
+				t.Errorf("rotated file = %q", b)
+			}
+			if b, _ := os.ReadFile(path); string(b) != "after\n" {
+				t.Errorf("reopened file = %q", b)
+			}
+			os.Remove(path)
+			os.Remove(rotated)
+		})
+	}
+}
+
+func TestLogFile_ReopenFailed(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	rotated := filepath.Join(dir, "app.log.1")
+	f, err := NewLogFile(path, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.SetReopenCheckInterval(0)
+	if err = os.Rename(path, rotated); err != nil {
+		t.Fatal(err)
+	}
+	// the directory in place of the file fails the open
+	if err = os.Mkdir(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Reopen(); err == nil {
+		t.Error("LogFile.Reopen() error = nil")
+	}
+	if _, err = f.Write([]byte("kept\n")); err != nil {
+		t.Errorf("LogFile.Write() error = %v after the failed reopen", err)
+	}
+	if b, _ := os.ReadFile(rotated); string(b) != "kept\n" {
+		t.Errorf("former file = %q", b)
+	}
+
+	os.Remove(path)
+	// as if the new file failed to be opened by the rotation
+	f.mu.Lock()
+	f.file.Close()
+	f.file = nil
+	f.mu.Unlock()
+	if _, err = f.Write([]byte("retried\n")); err != nil {
+		t.Errorf("LogFile.Write() error = %v, want the file opened again", err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "retried\n" {
+		t.Errorf("reopened file = %q", b)
+	}
+}
+
+func TestLogFile_EnableLock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("flock is not supported")
+	}
+	path := filepath.Join(t.TempDir(), "shared.log")
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		// each LogFile has own open file description, as if it is another process
+		f, err := NewLogFile(path, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.EnableLock().SetReopenCheckInterval(0)
+		defer f.Close()
+		line := append(bytes.Repeat([]byte{byte('a' + i)}, 64<<10), '\n')
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if _, err := f.Write(line); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := bytes.Split(bytes.TrimSuffix(b, []byte("\n")), []byte("\n"))
+	if len(lines) != 40 {
+		t.Fatalf("LogFile lines = %d, want 40", len(lines))
+	}
+	for i, l := range lines {
+		if len(l) != 64<<10 || bytes.Count(l, l[:1]) != len(l) {
+			t.Errorf("LogFile line %d is interleaved", i)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"fmt"
+	"regexp"
+	"sync/atomic"
+
+	"github.com/kpango/fastime"
+)
+
+// AddFilter adds the filter deciding whether the entry is written, the entries the filter returns false for are suppressed
+// and counted by Suppressed and the metrics hook. The entry has the formatted message, the fields and the caller when
+// the line trace is enabled. The filters may be added while logging, they are shared by the instances derived by With
+func (g *Glg) AddFilter(filter func(e Entry) bool) *Glg {
+	if filter != nil {
+		g.setSwitches(func(s *switches) {
+			s.filters = append(s.filters[:len(s.filters):len(s.filters)], filter)
+		})
+	}
+	return g
+}
+
+// DropMatching suppresses the entries whose message matches pattern, e.g. the access logs of the health checks.
+// The entries of levels are suppressed when levels are given, pattern is compiled by regexp.MustCompile
+func (g *Glg) DropMatching(pattern string, levels ...LEVEL) *Glg {
+	re := regexp.MustCompile(pattern)
+	return g.AddFilter(func(e Entry) bool {
+		return !hasLevel(levels, e.Level) || !re.MatchString(e.Message)
+	})
+}
+
+// OnlyMatching suppresses the entries whose message does not match pattern.
+// The entries of levels are suppressed when levels are given, pattern is compiled by regexp.MustCompile
+func (g *Glg) OnlyMatching(pattern string, levels ...LEVEL) *Glg {
+	re := regexp.MustCompile(pattern)
+	return g.AddFilter(func(e Entry) bool {
+		return !hasLevel(levels, e.Level) || re.MatchString(e.Message)
+	})
+}
+
+// ResetFilters removes the filters
+func (g *Glg) ResetFilters() *Glg {
+	g.setSwitches(func(s *switches) {
+		s.filters = nil
+	})
+	return g
+}
+
+// Suppressed returns the number of the entries suppressed by the filters
+func (g *Glg) Suppressed() uint64 {
+	return atomic.LoadUint64(&g.losses[lossSuppressed])
+}
+
+// AddFilter adds the filter deciding whether the entry of the global instance is written
+func AddFilter(filter func(e Entry) bool) *Glg {
+	return Get().AddFilter(filter)
+}
+
+// DropMatching suppresses the entries of the global instance whose message matches pattern
+func DropMatching(pattern string, levels ...LEVEL) *Glg {
+	return Get().DropMatching(pattern, levels...)
+}
+
+// OnlyMatching suppresses the entries of the global instance whose message does not match pattern
+func OnlyMatching(pattern string, levels ...LEVEL) *Glg {
+	return Get().OnlyMatching(pattern, levels...)
+}
+
+// ResetFilters removes the filters of the global instance
+func ResetFilters() *Glg {
+	return Get().ResetFilters()
+}
+
+// filter reports the entry passes the filters, the suppressed entry is counted
+func (g *Glg) filter(filters []func(Entry) bool, level LEVEL, tag, fl, format string, val []interface{}, fields []Field) bool {
+	if format == "" {
+		format = spaceFormat(len(val))
+	}
+	e := Entry{
+		Time:    fastime.Now(),
+		Level:   level,
+		Tag:     tag,
+		Caller:  fl,
+		Message: fmt.Sprintf(format, val...),
+		Fields:  fields,
+	}
+	for _, f := range filters {
+		if !f(e) {
+			g.lose(lossSuppressed, level)
+			return false
+		}
+	}
+	return true
+}
+
+// hasLevel reports levels is empty or contains lv
+func hasLevel(levels []LEVEL, lv LEVEL) bool {
+	if len(levels) == 0 {
+		return true
+	}
+	for _, l := range levels {
+		if l == lv {
+			return true
+		}
+	}
+	return false
+}
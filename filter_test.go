@@ -0,0 +1,140 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGlg_AddFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter func(g *Glg) *Glg
+		want   []string
+		drop   []string
+	}{
+		{
+			name:   "filter on fields",
+			filter: func(g *Glg) *Glg { return g.AddFilter(func(e Entry) bool { return len(e.Fields) == 0 }).AddFilter(nil) },
+			want:   []string{"GET /api", "GET /healthz", "dependency spam"},
+			drop:   []string{"with fields"},
+		},
+		{
+			name:   "drop matching",
+			filter: func(g *Glg) *Glg { return g.DropMatching(`^GET /healthz\b`) },
+			want:   []string{"GET /api", "dependency spam", "with fields"},
+			drop:   []string{"healthz"},
+		},
+		{
+			name:   "drop matching of levels",
+			filter: func(g *Glg) *Glg { return g.DropMatching(`spam|healthz`, DEBG) },
+			want:   []string{"GET /api", "GET /healthz", "with fields"},
+			drop:   []string{"spam"},
+		},
+		{
+			name:   "only matching",
+			filter: func(g *Glg) *Glg { return g.OnlyMatching(`^GET `, INFO) },
+			want:   []string{"GET /api", "GET /healthz", "dependency spam", "with fields"},
+		},
+		{
+			name:   "only matching of all levels",
+			filter: func(g *Glg) *Glg { return g.OnlyMatching(`^GET /api`) },
+			want:   []string{"GET /api"},
+			drop:   []string{"healthz", "spam", "with fields"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := tt.filter(New().SetMode(WRITER).SetWriter(buf))
+			g.Infof("GET %s", "/api")
+			g.Info("GET", "/healthz")
+			g.Debug("dependency spam")
+			g.Warn("with fields", String("k", "v"))
+			got := buf.String()
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("output = %q, want %q", got, w)
+				}
+			}
+			for _, d := range tt.drop {
+				if strings.Contains(got, d) {
+					t.Errorf("output = %q, want %q suppressed", got, d)
+				}
+			}
+			if n := g.Suppressed(); n != uint64(len(tt.drop)) {
+				t.Errorf("Suppressed() = %d, want %d", n, len(tt.drop))
+			}
+		})
+	}
+}
+
+func TestGlg_ResetFilters(t *testing.T) {
+	buf := new(bytes.Buffer)
+	var seen Entry
+	g := New().SetMode(WRITER).SetWriter(buf).SetLevelLineTraceMode(ERR, TraceLineShort).EnableJSON().
+		AddFilter(func(e Entry) bool {
+			seen = e
+			return false
+		})
+	g.Error("failed", 1)
+	if buf.Len() != 0 {
+		t.Errorf("output = %q, want suppressed", buf.String())
+	}
+	if seen.Level != ERR || seen.Tag != "ERR" || seen.Message != "failed 1" ||
+		!strings.HasPrefix(seen.Caller, "filter_test.go:") || seen.Time.IsZero() {
+		t.Errorf("filtered entry = %+v", seen)
+	}
+	g.ResetFilters().Error("failed")
+	if !strings.Contains(buf.String(), "failed") {
+		t.Errorf("output = %q after ResetFilters", buf.String())
+	}
+}
+
+func TestGlg_AddFilter_WhileLogging(t *testing.T) {
+	g := New().SetMode(WRITER).SetWriter(io.Discard).SetLineTraceMode(TraceLineNone)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				g.Error(errors.New("failed"))
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		g.AddFilter(func(Entry) bool { return true }).
+			AddErrorRule(ErrorIs(io.EOF, DEBG)).
+			OnFatal(func(Entry) {}).
+			SetMetricsHook(func(string, LEVEL, int64) {}).
+			Route(Route{Writer: io.Discard})
+	}
+	g.ResetFilters().ResetErrorRules().ResetRoutes()
+	wg.Wait()
+}
@@ -0,0 +1,148 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+const (
+	// LevelFlagName is the flag name registered by RegisterFlags for LevelVar
+	LevelFlagName = "log-level"
+	// FormatFlagName is the flag name registered by RegisterFlags for FormatVar
+	FormatFlagName = "log-format"
+
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// LevelValue is flag.Value setting the level of the instance by SetLevel, the level is parsed by Atol including the custom tags.
+// Type makes it usable as pflag.Value as well
+type LevelValue struct {
+	g     *Glg
+	level LEVEL
+}
+
+// FormatValue is flag.Value switching the instance to "text" or "json" output, Type makes it usable as pflag.Value as well
+type FormatValue struct {
+	g    *Glg
+	json bool
+}
+
+// LevelVar returns flag.Value setting the level of the instance, e.g. flag.Var(g.LevelVar(), "log-level", "minimum log level")
+func (g *Glg) LevelVar() *LevelValue {
+	return &LevelValue{g: g}
+}
+
+// FormatVar returns flag.Value setting the output format of the instance
+func (g *Glg) FormatVar() *FormatValue {
+	return &FormatValue{g: g, json: g.opts().enableJSON}
+}
+
+// RegisterFlags registers -log-level and -log-format of the instance to fs, flag.CommandLine when fs is nil
+func (g *Glg) RegisterFlags(fs *flag.FlagSet) *Glg {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	fs.Var(g.LevelVar(), LevelFlagName, "minimum log level such as debug, info, warn or the custom tag")
+	fs.Var(g.FormatVar(), FormatFlagName, "log output format, text or json")
+	return g
+}
+
+// LevelVar returns flag.Value setting the level of the global instance
+func LevelVar() *LevelValue {
+	return Get().LevelVar()
+}
+
+// FormatVar returns flag.Value setting the output format of the global instance
+func FormatVar() *FormatValue {
+	return Get().FormatVar()
+}
+
+// RegisterFlags registers -log-level and -log-format of the global instance to fs, flag.CommandLine when fs is nil
+func RegisterFlags(fs *flag.FlagSet) *Glg {
+	return Get().RegisterFlags(fs)
+}
+
+// String implements flag.Value
+func (v *LevelValue) String() string {
+	if v == nil || v.g == nil || v.level == 0 {
+		return ""
+	}
+	if l, ok := v.g.logger.Load(v.level); ok {
+		return l.tag
+	}
+	return v.level.String()
+}
+
+// Set implements flag.Value
+func (v *LevelValue) Set(s string) error {
+	lv := v.g.Atol(s)
+	if lv == UNKNOWN {
+		return fmt.Errorf("error:\tunknown log level %q", s)
+	}
+	v.level = lv
+	v.g.SetLevel(lv)
+	return nil
+}
+
+// Type implements pflag.Value
+func (v *LevelValue) Type() string {
+	return "level"
+}
+
+// Level returns the level set by the flag, 0 before it is set
+func (v *LevelValue) Level() LEVEL {
+	return v.level
+}
+
+// String implements flag.Value
+func (v *FormatValue) String() string {
+	if v == nil || v.g == nil || !v.json {
+		return formatText
+	}
+	return formatJSON
+}
+
+// Set implements flag.Value
+func (v *FormatValue) Set(s string) error {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case formatText:
+		v.json = false
+		v.g.DisableJSON()
+	case formatJSON:
+		v.json = true
+		v.g.EnableJSON()
+	default:
+		return fmt.Errorf("error:\tunknown log format %q, text or json", s)
+	}
+	return nil
+}
+
+// Type implements pflag.Value
+func (v *FormatValue) Type() string {
+	return "format"
+}
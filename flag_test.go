@@ -0,0 +1,110 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"flag"
+	"io"
+	"testing"
+)
+
+func TestGlg_RegisterFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantErr   bool
+		wantLevel LEVEL
+		wantJSON  bool
+		enabled   map[LEVEL]bool
+	}{
+		{
+			name:      "built-in level and json",
+			args:      []string{"-log-level=warn", "-log-format=JSON"},
+			wantLevel: WARN,
+			wantJSON:  true,
+			enabled:   map[LEVEL]bool{INFO: false, WARN: true, ERR: true},
+		},
+		{
+			name:      "custom level",
+			args:      []string{"-log-level", "notice"},
+			wantLevel: FATAL + 1,
+			enabled:   map[LEVEL]bool{LOG: false, INFO: true, WARN: true},
+		},
+		{
+			name:    "unknown level",
+			args:    []string{"-log-level=verbose"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown format",
+			args:    []string{"-log-format=yaml"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New().SetMode(WRITER).SetWriter(io.Discard).
+				AddStdLevel("NOTICE", WRITER, false, LevelOptions{Rank: INFO})
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			g.RegisterFlags(fs)
+			if err := fs.Parse(tt.args); (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := fs.Lookup(LevelFlagName).Value.(*LevelValue).Level(); got != tt.wantLevel {
+				t.Errorf("Level() = %d, want %d", got, tt.wantLevel)
+			}
+			if g.opts().enableJSON != tt.wantJSON {
+				t.Errorf("enableJSON = %v, want %v", g.opts().enableJSON, tt.wantJSON)
+			}
+			for lv, want := range tt.enabled {
+				if got := g.isModeEnable(lv); got != want {
+					t.Errorf("isModeEnable(%d) = %v, want %v", lv, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLevelValue_String(t *testing.T) {
+	g := New().AddStdLevel("notice", STD, false)
+	v := g.LevelVar()
+	if v.String() != "" || new(LevelValue).String() != "" || v.Type() != "level" {
+		t.Errorf("String() of unset value = %q", v.String())
+	}
+	v.Set("Notice")
+	if got := v.String(); got != "NOTICE" {
+		t.Errorf("String() = %q, want NOTICE", got)
+	}
+	v.Set("e")
+	if got := v.String(); got != "ERR" {
+		t.Errorf("String() = %q, want ERR", got)
+	}
+	f := g.FormatVar()
+	if f.String() != "text" || new(FormatValue).String() != "text" || f.Type() != "format" {
+		t.Errorf("FormatValue.String() = %q, want text", f.String())
+	}
+}
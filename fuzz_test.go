@@ -0,0 +1,163 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	json "github.com/goccy/go-json"
+)
+
+var fuzzSeeds = []string{
+	"",
+	"Hello Glg",
+	"a\xffb",
+	"\xe2\x82",
+	"\x1b[31mred\x1b[0m",
+	"line\nbreak\r\n",
+	"\"quoted\\\"",
+	"\u2028\u2029\u0000\u007f\u0085",
+	"]:\t[INFO]:\t",
+	"%s %d %!",
+	strings.Repeat("x\xff", 1<<10),
+}
+
+func FuzzRawString(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add([]byte(s))
+		f.Add([]byte("2019-01-01 00:00:00\t[" + INFO.String() + sep + s + rc))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		got := RawString(data)
+		if !strings.Contains(string(data), got) {
+			t.Errorf("RawString(%q) = %q, not a part of the data", data, got)
+		}
+	})
+}
+
+func FuzzAtol(f *testing.F) {
+	for _, s := range append(fuzzSeeds, "info", " WARNING ", "debug", "FTL") {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, tag string) {
+		lv := Atol(tag)
+		if lv == UNKNOWN {
+			return
+		}
+		if got := Atol(lv.String()); got != lv {
+			t.Errorf("Atol(%q) = %v, Atol(%v) = %v", tag, lv, lv, got)
+		}
+	})
+}
+
+func FuzzJSONString(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		b := appendJSONString(nil, s)
+		if !stdjson.Valid(b) || !utf8.Valid(b) || bytes.ContainsAny(b, "\r\n") {
+			t.Fatalf("appendJSONString(%q) = %q, invalid", s, b)
+		}
+		want, _ := json.Marshal(s)
+		var got, exp string
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(want, &exp); err != nil {
+			t.Fatal(err)
+		}
+		if got != exp {
+			t.Errorf("appendJSONString(%q) decodes to %q, want %q", s, got, exp)
+		}
+	})
+}
+
+// FuzzJSONEntry validates the entries with encoding/json, json.Valid of go-json rejects some valid entries
+func FuzzJSONEntry(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		buf := new(bytes.Buffer)
+		g := New().SetMode(WRITER).SetWriter(buf).EnableJSON()
+		if err := g.With(String(s, s)).Info(s, []byte(s), Group(s, String("k", s))); err != nil {
+			t.Fatal(err)
+		}
+		if err := g.Warnf(s, s); err != nil {
+			t.Fatal(err)
+		}
+		for _, line := range strings.SplitAfter(buf.String(), rc) {
+			if line == "" {
+				continue
+			}
+			if !strings.HasSuffix(line, rc) || !stdjson.Valid([]byte(line)) || !utf8.ValidString(line) {
+				t.Fatalf("JSON entry of %q = %q, invalid", s, line)
+			}
+		}
+		if n := strings.Count(buf.String(), rc); n != 2 {
+			t.Errorf("JSON entries of %q = %d lines, want 2", s, n)
+		}
+	})
+}
+
+func FuzzTextEntry(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		buf := new(bytes.Buffer)
+		g := New().SetMode(WRITER).SetWriter(buf).EnableSanitize().SetInvalidUTF8("?")
+		if err := g.Info(s, s); err != nil {
+			t.Fatal(err)
+		}
+		out := buf.String()
+		if strings.Count(out, rc) != 1 || !strings.HasSuffix(out, rc) || !utf8.ValidString(out) {
+			t.Errorf("text entry of %q = %q, want one valid line", s, out)
+		}
+	})
+}
+
+func FuzzBinaryReader(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		e := &Entry{Level: INFO, Message: s, Fields: []Field{String(s, s)}}
+		f.Add(CBOR.Append(nil, e), true)
+		f.Add(MsgPack.Append(nil, e), false)
+	}
+	f.Fuzz(func(t *testing.T, data []byte, cbor bool) {
+		format := MsgPack
+		if cbor {
+			format = CBOR
+		}
+		r := NewBinaryReader(bytes.NewReader(data), format)
+		for i := 0; i < 100; i++ {
+			if _, err := r.Read(); err != nil {
+				return
+			}
+		}
+	})
+}
@@ -25,6 +25,7 @@ package glg
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -43,30 +44,120 @@ import (
 	"github.com/kpango/fastime"
 )
 
-// Glg is glg base struct
+// Glg is glg base struct.
+// The settings of the levels such as the modes, writers, colors, tags, timestamps, line traces and layouts are replaced
+// copy-on-write, they can be changed while other goroutines are logging and every entry is written with either the former
+// or the new settings of its level, never with a mix of them. The instance-wide switches such as EnableJSON, EnableUTC
+// and SetCallerDepth are replaced copy-on-write in the same way
 type Glg struct {
-	bs           *uint64
-	logger       loggers
-	levelCounter *uint32
-	levelMap     levelMap
-	buffer       sync.Pool
-	callerDepth  int
-	enableJSON   bool
+	*core
+	fields []Field
+	groups []string
+	batch  *Batch
+	to     []io.Writer
+}
+
+// core is the configuration shared between Glg and the loggers derived by With
+type core struct {
+	bs            *uint64
+	logger        loggers
+	levelCounter  *uint32
+	levelMap      levelMap
+	buffer        sync.Pool
+	switches      atomic.Pointer[switches]
+	escalation    escalations
+	spans         spans
+	httpOptions   atomic.Pointer[HTTPOptions]
+	prefixVars    sync.Map
+	writerGroups  sync.Map // map[string]*WriterGroup
+	sigMu         sync.Mutex
+	reopenSig     chan os.Signal
+	logSig        chan os.Signal
+	lastSig       os.Signal
+	inMain        int32
+	async         atomic.Value // *asyncer
+	asyncMu       sync.Mutex
+	asyncSize     int
+	asyncLevels   map[LEVEL]asyncConfig
+	asyncOverflow OverflowPolicy
+	losses        [lossKinds]uint64
+	reportMu      sync.Mutex
+	reportStop    chan struct{}
+	statsMu       sync.Mutex
+	statsStop     chan struct{}
+	writersMu     sync.Mutex
+	writers       []io.Writer
+	shutdown      int32
+	term          terminal
+	ordered       int32
+	orderMu       sync.Mutex
+	configMu      sync.Mutex
+	recent        *RecentBuffer
+	tail          *tailHub
+	poolStats     poolStats
+}
+
+// switches are the instance-wide settings read by every entry, they are replaced copy-on-write by setSwitches
+// and each entry loads them once, so they can be changed while other goroutines are logging
+type switches struct {
+	callerDepth    int
+	enableJSON     bool
+	enableUTC      bool
+	enableEpoch    bool
+	enableSanitize bool
+	enableHuman    bool
+	enablePriority bool
+	levelToken     string
+	resource       map[string]string
+	strictFormat   bool
+	fatalTimeout   time.Duration
+	panicPolicy    PanicPolicy
+	cloudLogging   bool
+	gcpProjectID   string
+	maxMessageSize int
+	maxFieldSize   int
+	multiLineMode  multiLineMode
+	contMarker     string
+	stdFlush       *stdFlusher
+	invalidUTF8    *string
+	maxDumpSize    int
+	timerThreshold time.Duration
+	timerLevel     LEVEL
+	errorRules     []ErrorRule
+	filters        []func(Entry) bool
+	fatalHooks     []func(Entry)
+	metricsHook    MetricsHook
+	routes         []Route
 }
 
 // JSONFormat is json object structure for logging
 type JSONFormat struct {
-	Date   string      `json:"date,omitempty"`
-	Level  string      `json:"level,omitempty"`
-	File   string      `json:"file,omitempty"`
-	Detail interface{} `json:"detail,omitempty"`
+	Date      string                 `json:"date,omitempty"`
+	Timestamp int64                  `json:"ts,omitempty"`
+	Level     string                 `json:"level,omitempty"`
+	File      string                 `json:"file,omitempty"`
+	Detail    interface{}            `json:"detail,omitempty"`
+	Fields    map[string]interface{} `json:"fields,omitempty"`
+	Resource  map[string]string      `json:"resource,omitempty"`
+}
+
+// jsonEntry is the encoding structure of JSONFormat
+type jsonEntry struct {
+	Date      string            `json:"date,omitempty"`
+	Timestamp int64             `json:"ts,omitempty"`
+	Level     string            `json:"level,omitempty"`
+	File      string            `json:"file,omitempty"`
+	Detail    interface{}       `json:"detail,omitempty"`
+	Fields    *jsonFields       `json:"fields,omitempty"`
+	Resource  map[string]string `json:"resource,omitempty"`
 }
 
 // MODE is logging mode (std only, writer only, std & writer)
 type MODE uint8
 
-// LEVEL is log level
-type LEVEL uint8
+// LEVEL is log level, the number of custom levels is not limited.
+// LEVEL used to be uint8, the code converting it to uint8 or comparing it with 255 must use UNKNOWN instead
+type LEVEL uint32
 
 type wMode uint8
 
@@ -84,6 +175,12 @@ type logger struct {
 	prevMode         MODE
 	writeMode        wMode
 	disableTimestamp bool
+	layout           layout
+	prefix           prefixTemplate
+	json             LevelJSON
+	rank             LEVEL
+	routes           []Route
+	encoder          Encoder
 }
 
 const (
@@ -108,8 +205,8 @@ const (
 	// FATAL is fatal log level
 	FATAL
 
-	// UNKNOWN is unknown log level
-	UNKNOWN LEVEL = LEVEL(math.MaxUint8)
+	// UNKNOWN is unknown log level, it is the largest LEVEL so no custom level takes it
+	UNKNOWN LEVEL = LEVEL(math.MaxUint32)
 
 	// NONE is disable Logging
 	NONE MODE = iota + 1
@@ -137,8 +234,7 @@ const (
 	timeFormat = "2006-01-02 15:04:05"
 
 	// return code
-	rc  = "\n"
-	rcl = len(rc)
+	rc = "\n"
 
 	tab   = "\t"
 	lsep  = tab + "["
@@ -151,6 +247,9 @@ const (
 	TraceLineLong
 
 	DefaultCallerDepth = 2
+
+	// minBufferSize is the initial size of the pooled buffers
+	minBufferSize = uint64(len(timeFormat) + lsepl + sepl)
 )
 
 var (
@@ -158,8 +257,9 @@ var (
 	df  = strings.Repeat(dw, 50)
 	dfl = len(df) / dwl
 
-	glg  *Glg
-	once sync.Once
+	// instance is the global instance returned by Get, see Replace
+	instance atomic.Pointer[Glg]
+	once     sync.Once
 
 	// exit for Faltal error
 	exit = os.Exit
@@ -195,6 +295,40 @@ func (l LEVEL) String() string {
 	return ""
 }
 
+// ShortString returns fixed width abbreviated level string
+func (l LEVEL) ShortString() string {
+	switch l {
+	case DEBG:
+		return "DBG"
+	case TRACE:
+		return "TRC"
+	case PRINT:
+		return "PNT"
+	case LOG:
+		return "LOG"
+	case INFO:
+		return "INF"
+	case OK:
+		return "OK "
+	case WARN:
+		return "WRN"
+	case ERR:
+		return "ERR"
+	case FAIL:
+		return "FAL"
+	case FATAL:
+		return "FTL"
+	}
+	return ""
+}
+
+func (l *logger) setTag(tag string) *logger {
+	l.tag = tag
+	l.rawtag = []byte(lsep + tag + sep)
+	l.prefix = nil
+	return l
+}
+
 func (l *logger) updateMode() *logger {
 	switch {
 	case l.mode == WRITER && l.writer != nil:
@@ -216,15 +350,18 @@ func (l *logger) updateMode() *logger {
 // New returns plain glg instance
 func New() *Glg {
 	g := &Glg{
-		levelCounter: new(uint32),
-		callerDepth:  DefaultCallerDepth,
+		core: &core{
+			levelCounter: new(uint32),
+		},
 	}
 	g.bs = new(uint64)
+	g.switches.Store(&switches{callerDepth: DefaultCallerDepth})
 
-	atomic.StoreUint64(g.bs, uint64(len(timeFormat)+lsepl+sepl))
+	atomic.StoreUint64(g.bs, minBufferSize)
 
 	g.buffer = sync.Pool{
 		New: func() interface{} {
+			atomic.AddUint64(&g.poolStats.misses, 1)
 			return bytes.NewBuffer(make([]byte, 0, int(atomic.LoadUint64(g.bs))))
 		},
 	}
@@ -305,8 +442,7 @@ func New() *Glg {
 			traceMode: TraceLineLong,
 		},
 	} {
-		log.tag = lev.String()
-		log.rawtag = []byte(lsep + log.tag + sep)
+		log.setTag(lev.String())
 		log.prevMode = log.mode
 		log.updateMode()
 		g.logger.Store(lev, log)
@@ -315,56 +451,113 @@ func New() *Glg {
 	return g
 }
 
-// Get returns singleton glg instance
+// Get returns singleton glg instance, or the instance of TestMode on the goroutine of the test
 func Get() *Glg {
 	once.Do(func() {
 		fastime.SetFormat(timeFormat)
-		glg = New()
+		instance.Store(New())
 	})
-	return glg
+	if g, ok := testInstance(); ok {
+		return g
+	}
+	return instance.Load()
 }
 
+// EnableJSON enables JSON output
 func (g *Glg) EnableJSON() *Glg {
-	g.enableJSON = true
+	g.setSwitches(func(s *switches) {
+		s.enableJSON = true
+	})
 	return g
 }
 
+// DisableJSON disables JSON output
 func (g *Glg) DisableJSON() *Glg {
-	g.enableJSON = false
+	g.setSwitches(func(s *switches) {
+		s.enableJSON = false
+	})
+	return g
+}
+
+// EnableUTC enables UTC timestamp output instead of local time
+func (g *Glg) EnableUTC() *Glg {
+	g.setSwitches(func(s *switches) {
+		s.enableUTC = true
+	})
+	return g
+}
+
+// DisableUTC disables UTC timestamp output
+func (g *Glg) DisableUTC() *Glg {
+	g.setSwitches(func(s *switches) {
+		s.enableUTC = false
+	})
 	return g
 }
 
+// EnableEpochMillis enables epoch milliseconds "ts" field in JSON output alongside the formatted date
+func (g *Glg) EnableEpochMillis() *Glg {
+	g.setSwitches(func(s *switches) {
+		s.enableEpoch = true
+	})
+	return g
+}
+
+// DisableEpochMillis disables epoch milliseconds field in JSON output
+func (g *Glg) DisableEpochMillis() *Glg {
+	g.setSwitches(func(s *switches) {
+		s.enableEpoch = false
+	})
+	return g
+}
+
+func (o *switches) formattedNow() []byte {
+	if o.enableUTC {
+		return fastime.Now().UTC().AppendFormat(make([]byte, 0, len(timeFormat)), timeFormat)
+	}
+	return fastime.FormattedNow()
+}
+
+// EnablePoolBuffer fills the buffer pool with size buffers of the current buffer size.
+// The buffer size follows the high-water mark of the recent entries and the oversized buffers are trimmed, see PoolStats
 func (g *Glg) EnablePoolBuffer(size int) *Glg {
+	bufs := make([]*bytes.Buffer, 0, size)
 	for range make([]struct{}, size) {
-		g.buffer.Put(g.buffer.Get().(*bytes.Buffer))
+		bufs = append(bufs, g.getBuffer())
+	}
+	for _, b := range bufs {
+		g.putBuffer(b)
 	}
 	return g
 }
 
-// SetLevel sets glg global log level
+// SetLevel sets glg global log level, the levels ranked below lv are disabled.
+// Custom levels are ranked by SetLevelRank, unranked ones are ranked above FATAL in the order of addition
 func (g *Glg) SetLevel(lv LEVEL) *Glg {
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
-		if lev < lv {
-			l.prevMode = l.mode
+	min := lv
+	if l, ok := g.logger.Load(lv); ok {
+		min = l.rankOf(lv)
+	}
+	g.updateLoggers(func(lev LEVEL, l *logger) {
+		if l.rankOf(lev) < min {
+			if l.mode != NONE {
+				l.prevMode = l.mode
+			}
 			l.mode = NONE
 		} else {
 			l.mode = l.prevMode
 		}
 		l.updateMode()
-		g.logger.Store(lev, l)
-		return true
 	})
 	return g
 }
 
 // SetMode sets glg logging mode
 func (g *Glg) SetMode(mode MODE) *Glg {
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(_ LEVEL, l *logger) {
 		l.mode = mode
 		l.prevMode = mode
 		l.updateMode()
-		g.logger.Store(lev, l)
-		return true
 	})
 
 	return g
@@ -372,29 +565,68 @@ func (g *Glg) SetMode(mode MODE) *Glg {
 
 // SetLevelMode sets glg logging mode* per level
 func (g *Glg) SetLevelMode(level LEVEL, mode MODE) *Glg {
-	l, ok := g.logger.Load(level)
-	if ok {
+	g.updateLogger(level, func(l *logger) {
 		l.mode = mode
 		l.prevMode = mode
 		l.updateMode()
-		g.logger.Store(level, l)
-	}
+	})
 	return g
 }
 
-// SetPrefix sets Print logger prefix
+// SetPrefix sets Print logger prefix.
+// The prefix may contain placeholders such as {{hostname}}, {{pid}}, {{app}}
+// and variables registered by SetPrefixVar, which are evaluated per entry
 func SetPrefix(lev LEVEL, pref string) *Glg {
-	return glg.SetPrefix(lev, pref)
+	return Get().SetPrefix(lev, pref)
 }
 
-// SetPrefix sets Print logger prefix
+// SetPrefix sets Print logger prefix.
+// The prefix may contain placeholders such as {{hostname}}, {{pid}}, {{app}}
+// and variables registered by SetPrefixVar, which are evaluated per entry
 func (g *Glg) SetPrefix(lev LEVEL, pref string) *Glg {
-	l, ok := g.logger.Load(lev)
-	if ok {
-		l.tag = pref
-		l.rawtag = []byte(lsep + l.tag + sep)
-		g.logger.Store(lev, l)
+	g.updateLogger(lev, func(l *logger) {
+		l.setTag(pref)
+		l.prefix = parsePrefix(pref)
+	})
+	return g
+}
+
+// SetLevelString overrides the printed string of the level such as "WARNING" or localized label.
+// The string is used for both text and JSON output and accepted by Atol
+func (g *Glg) SetLevelString(lv LEVEL, str string) *Glg {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return g
 	}
+	g.updateLogger(lv, func(l *logger) {
+		old := strings.ToUpper(l.tag)
+		if olv, ok := g.levelMap.Load(old); ok && olv == lv {
+			g.levelMap.Delete(old)
+		}
+		g.levelMap.Store(strings.ToUpper(str), lv)
+		l.setTag(str)
+	})
+	return g
+}
+
+// EnableShortLevel enables fixed width abbreviated level tags such as INF, ERR and DBG.
+// Custom levels keep their own tags
+func (g *Glg) EnableShortLevel() *Glg {
+	g.updateLoggers(func(lev LEVEL, l *logger) {
+		if str := lev.ShortString(); str != "" {
+			l.setTag(str)
+		}
+	})
+	return g
+}
+
+// DisableShortLevel restores default level tags
+func (g *Glg) DisableShortLevel() *Glg {
+	g.updateLoggers(func(lev LEVEL, l *logger) {
+		if str := lev.String(); str != "" {
+			l.setTag(str)
+		}
+	})
 	return g
 }
 
@@ -409,12 +641,11 @@ func (g *Glg) GetCurrentMode(level LEVEL) MODE {
 
 // InitWriter is initialize glg writer
 func (g *Glg) InitWriter() *Glg {
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(_ LEVEL, l *logger) {
 		l.writer = nil
 		l.updateMode()
-		g.logger.Store(lev, l)
-		return true
 	})
+	g.trackWriter(nil, true)
 	return g
 }
 
@@ -424,43 +655,40 @@ func (g *Glg) SetWriter(writer io.Writer) *Glg {
 		return g
 	}
 
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(_ LEVEL, l *logger) {
 		l.writer = writer
 		l.updateMode()
-		g.logger.Store(lev, l)
-		return true
 	})
+	g.trackWriter(writer, true)
 
 	return g
 }
 
-// AddWriter adds writer to glg std writers
+// AddWriter adds writer to glg std writers.
+// The entry is written to all the writers even when some of them fail, the errors are joined as WriteError
 func (g *Glg) AddWriter(writer io.Writer) *Glg {
 	if writer == nil {
 		return g
 	}
 
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(_ LEVEL, l *logger) {
 		if l.writer == nil {
 			l.writer = writer
 		} else {
-			l.writer = io.MultiWriter(l.writer, writer)
+			l.writer = newFanout(l.writer, writer)
 		}
 		l.updateMode()
-		g.logger.Store(lev, l)
-		return true
 	})
+	g.trackWriter(writer, false)
 
 	return g
 }
 
 // SetLevelColor sets the color for each level
 func (g *Glg) SetLevelColor(level LEVEL, color func(string) string) *Glg {
-	l, ok := g.logger.Load(level)
-	if ok {
+	g.updateLogger(level, func(l *logger) {
 		l.color = color
-		g.logger.Store(level, l)
-	}
+	})
 
 	return g
 }
@@ -471,11 +699,11 @@ func (g *Glg) SetLevelWriter(level LEVEL, writer io.Writer) *Glg {
 		return g
 	}
 
-	l, ok := g.logger.Load(level)
-	if ok {
+	if g.updateLogger(level, func(l *logger) {
 		l.writer = writer
 		l.updateMode()
-		g.logger.Store(level, l)
+	}) {
+		g.trackWriter(writer, false)
 	}
 
 	return g
@@ -487,15 +715,15 @@ func (g *Glg) AddLevelWriter(level LEVEL, writer io.Writer) *Glg {
 		return g
 	}
 
-	l, ok := g.logger.Load(level)
-	if ok {
+	if g.updateLogger(level, func(l *logger) {
 		if l.writer != nil {
-			l.writer = io.MultiWriter(l.writer, writer)
+			l.writer = newFanout(l.writer, writer)
 		} else {
 			l.writer = writer
 		}
 		l.updateMode()
-		g.logger.Store(level, l)
+	}) {
+		g.trackWriter(writer, false)
 	}
 
 	return g
@@ -526,17 +754,17 @@ func (g *Glg) AddLevelWriters(minLevel uint8, infolog, errlog io.Writer) *Glg {
 	return g
 }
 
-// AddStdLevel adds std log level and returns LEVEL
-func (g *Glg) AddStdLevel(tag string, mode MODE, isColor bool) *Glg {
-	return g.addLevel(tag, mode, isColor, os.Stdout)
+// AddStdLevel adds std log level, the level is configured by opts when they are given
+func (g *Glg) AddStdLevel(tag string, mode MODE, isColor bool, opts ...LevelOptions) *Glg {
+	return g.addLevel(tag, mode, isColor, os.Stdout, opts)
 }
 
-// AddErrLevel adds error log level and returns LEVEL
-func (g *Glg) AddErrLevel(tag string, mode MODE, isColor bool) *Glg {
-	return g.addLevel(tag, mode, isColor, os.Stderr)
+// AddErrLevel adds error log level, the level is configured by opts when they are given
+func (g *Glg) AddErrLevel(tag string, mode MODE, isColor bool, opts ...LevelOptions) *Glg {
+	return g.addLevel(tag, mode, isColor, os.Stderr, opts)
 }
 
-func (g *Glg) addLevel(tag string, mode MODE, isColor bool, std io.Writer) *Glg {
+func (g *Glg) addLevel(tag string, mode MODE, isColor bool, std io.Writer, opts []LevelOptions) *Glg {
 	lev := LEVEL(atomic.AddUint32(g.levelCounter, 1))
 	tag = strings.ToUpper(tag)
 	g.levelMap.Store(tag, lev)
@@ -551,16 +779,56 @@ func (g *Glg) addLevel(tag string, mode MODE, isColor bool, std io.Writer) *Glg
 		rawtag:   []byte(lsep + tag + sep),
 	}
 	l.updateMode()
+	g.configMu.Lock()
+	l.routes = g.levelRoutes(lev)
 	g.logger.Store(lev, l)
+	g.configMu.Unlock()
+	for _, opt := range opts {
+		g.setLevelOptions(lev, opt)
+	}
+	return g
+}
+
+// RemoveLevel removes the custom level of tag and frees the tag for AddStdLevel and AddErrLevel.
+// The writers of the level are detached without closing, built-in levels and unknown tags are ignored
+func (g *Glg) RemoveLevel(tag string) *Glg {
+	lv := g.TagStringToLevel(tag)
+	if lv <= FATAL || lv == UNKNOWN {
+		return g
+	}
+	g.levelMap.DeleteLevel(lv)
+	// updateLoggers stores the copies of the loggers it ranged, the delete waits for it not to revive the level
+	g.configMu.Lock()
+	g.logger.Delete(lv)
+	g.configMu.Unlock()
+	g.asyncMu.Lock()
+	defer g.asyncMu.Unlock()
+	if _, ok := g.asyncLevels[lv]; ok {
+		delete(g.asyncLevels, lv)
+		if g.asyncer() != nil {
+			g.restartAsync()
+		}
+	}
 	return g
 }
 
+// RenameLevel changes the tag of the custom level keeping its writers and settings.
+// Built-in levels, unknown tags and the new tag already in use are ignored
+func (g *Glg) RenameLevel(oldTag, newTag string) *Glg {
+	lv := g.TagStringToLevel(oldTag)
+	if lv <= FATAL || lv == UNKNOWN {
+		return g
+	}
+	if _, ok := g.levelMap.Load(strings.ToUpper(strings.TrimSpace(newTag))); ok {
+		return g
+	}
+	return g.SetLevelString(lv, newTag)
+}
+
 // EnableTimestamp enables timestamp output
 func (g *Glg) EnableTimestamp() *Glg {
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(_ LEVEL, l *logger) {
 		l.disableTimestamp = false
-		g.logger.Store(lev, l)
-		return true
 	})
 
 	return g
@@ -568,10 +836,8 @@ func (g *Glg) EnableTimestamp() *Glg {
 
 // DisableTimestamp disables timestamp output
 func (g *Glg) DisableTimestamp() *Glg {
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(_ LEVEL, l *logger) {
 		l.disableTimestamp = true
-		g.logger.Store(lev, l)
-		return true
 	})
 
 	return g
@@ -579,59 +845,51 @@ func (g *Glg) DisableTimestamp() *Glg {
 
 // EnableLevelTimestamp enables timestamp output
 func (g *Glg) EnableLevelTimestamp(lv LEVEL) *Glg {
-	l, ok := g.logger.Load(lv)
-	if ok {
+	g.updateLogger(lv, func(l *logger) {
 		l.disableTimestamp = false
-		g.logger.Store(lv, l)
-	}
+	})
 	return g
 }
 
 // DisableLevelTimestamp disables timestamp output
 func (g *Glg) DisableLevelTimestamp(lv LEVEL) *Glg {
-	l, ok := g.logger.Load(lv)
-	if ok {
+	g.updateLogger(lv, func(l *logger) {
 		l.disableTimestamp = true
-		g.logger.Store(lv, l)
-	}
+	})
 	return g
 }
 
 // SetCallerDepth configures output line trace caller depth
 func (g *Glg) SetCallerDepth(depth int) *Glg {
 	if depth > DefaultCallerDepth {
-		g.callerDepth = depth
+		g.setSwitches(func(s *switches) {
+			s.callerDepth = depth
+		})
 	}
 	return g
 }
 
 // SetLineTraceMode configures output line traceFlag
 func (g *Glg) SetLineTraceMode(mode traceMode) *Glg {
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(_ LEVEL, l *logger) {
 		l.traceMode = mode
-		g.logger.Store(lev, l)
-		return true
 	})
 	return g
 }
 
 // SetLevelLineTraceMode configures output line traceFlag
 func (g *Glg) SetLevelLineTraceMode(lv LEVEL, mode traceMode) *Glg {
-	l, ok := g.logger.Load(lv)
-	if ok {
+	g.updateLogger(lv, func(l *logger) {
 		l.traceMode = mode
-		g.logger.Store(lv, l)
-	}
+	})
 	return g
 }
 
 // EnableColor enables color output
 func (g *Glg) EnableColor() *Glg {
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(_ LEVEL, l *logger) {
 		l.isColor = true
 		l.updateMode()
-		g.logger.Store(lev, l)
-		return true
 	})
 
 	return g
@@ -639,11 +897,9 @@ func (g *Glg) EnableColor() *Glg {
 
 // DisableColor disables color output
 func (g *Glg) DisableColor() *Glg {
-	g.logger.Range(func(lev LEVEL, l *logger) bool {
+	g.updateLoggers(func(_ LEVEL, l *logger) {
 		l.isColor = false
 		l.updateMode()
-		g.logger.Store(lev, l)
-		return true
 	})
 
 	return g
@@ -651,35 +907,35 @@ func (g *Glg) DisableColor() *Glg {
 
 // EnableLevelColor enables color output
 func (g *Glg) EnableLevelColor(lv LEVEL) *Glg {
-	l, ok := g.logger.Load(lv)
-	if ok {
+	g.updateLogger(lv, func(l *logger) {
 		l.isColor = true
 		l.updateMode()
-		g.logger.Store(lv, l)
-	}
+	})
 	return g
 }
 
 // DisableLevelColor disables color output
 func (g *Glg) DisableLevelColor(lv LEVEL) *Glg {
-	l, ok := g.logger.Load(lv)
-	if ok {
+	g.updateLogger(lv, func(l *logger) {
 		l.isColor = false
 		l.updateMode()
-		g.logger.Store(lv, l)
-	}
+	})
 	return g
 }
 
-// RawString returns raw log string exclude time & tags
+// RawString returns raw log string exclude time & tags and the line break,
+// the data without the tag is returned as it is so the malformed data never panics
 func (g *Glg) RawString(data []byte) string {
-	str := *(*string)(unsafe.Pointer(&data))
-	return str[strings.Index(str, sep)+sepl : len(str)-rcl]
+	str := strings.TrimSuffix(*(*string)(unsafe.Pointer(&data)), rc)
+	if i := strings.Index(str, sep); i >= 0 {
+		return str[i+sepl:]
+	}
+	return str
 }
 
 // RawString returns raw log string exclude time & tags
 func RawString(data []byte) string {
-	return glg.RawString(data)
+	return Get().RawString(data)
 }
 
 // Atol converts level string to Glg.LEVEL
@@ -689,12 +945,16 @@ func (g *Glg) Atol(tag string) LEVEL {
 
 // Atol converts level string to Glg.LEVEL
 func Atol(tag string) LEVEL {
-	return glg.TagStringToLevel(tag)
+	return Get().TagStringToLevel(tag)
 
 }
 
-// TagStringToLevel converts level string to Glg.LEVEL
+// TagStringToLevel converts level string to Glg.LEVEL, tags are case-insensitive
 func (g *Glg) TagStringToLevel(tag string) LEVEL {
+	// tags are stored in upper case, so the exact tag is found without allocation
+	if lv, ok := g.levelMap.Load(tag); ok {
+		return lv
+	}
 	tag = strings.TrimSpace(strings.ToUpper(tag))
 	lv, ok := g.levelMap.Load(tag)
 	if ok {
@@ -717,9 +977,9 @@ func (g *Glg) TagStringToLevel(tag string) LEVEL {
 		return WARN
 	case ERR.String(), "ERROR", "ER", "E":
 		return ERR
-	case FAIL.String(), "FAILED", "FI":
+	case FAIL.String(), "FAILED", "FAL", "FI":
 		return FAIL
-	case FATAL.String(), "FAT", "FL", "F":
+	case FATAL.String(), "FAT", "FTL", "FL", "F":
 		return FATAL
 	}
 	return UNKNOWN
@@ -727,37 +987,24 @@ func (g *Glg) TagStringToLevel(tag string) LEVEL {
 
 // TagStringToLevel converts level string to glg.LEVEL
 func TagStringToLevel(tag string) LEVEL {
-	return glg.TagStringToLevel(tag)
+	return Get().TagStringToLevel(tag)
 }
 
-// FileWriter generates *osFile -> io.Writer
+// FileWriter generates *osFile -> io.Writer.
+// The file is always opened with O_APPEND, so each entry is appended to the end even when the file is shared by multiple processes
 func FileWriter(path string, perm os.FileMode) *os.File {
 	if path == "" {
 		return nil
 	}
 
-	var err error
-	var file *os.File
-	if _, err = os.Stat(path); err != nil {
-		if _, err = os.Stat(filepath.Dir(path)); err != nil {
-			err = os.MkdirAll(filepath.Dir(path), perm)
-			if err != nil {
-				return nil
-			}
-		}
-		file, err = os.Create(path)
-		if err != nil {
-			return nil
-		}
-
-		err = file.Close()
+	if _, err := os.Stat(filepath.Dir(path)); err != nil {
+		err = os.MkdirAll(filepath.Dir(path), perm)
 		if err != nil {
 			return nil
 		}
 	}
 
-	file, err = os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
-
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
 	if err != nil {
 		return nil
 	}
@@ -770,17 +1017,52 @@ func (g *Glg) HTTPLogger(name string, handler http.Handler) http.Handler {
 	return g.HTTPLoggerFunc(name, handler.ServeHTTP)
 }
 
-// HTTPLoggerFunc is simple http access logger
+// HTTPLoggerFunc is simple http access logger, the requests logged are configured by SetHTTPOptions.
+// The access log carries the remote address and the client IP resolved through HTTPOptions.TrustedProxies.
+// The request is correlated by RequestIDHeader or NewID and the trace headers of TraceFields,
+// the handler gets the logger with the IDs by FromContext(r.Context()).
+// The invalid or longer than 128 bytes RequestIDHeader is replaced, the skipped requests get no new ID
 func (g *Glg) HTTPLoggerFunc(name string, hf http.HandlerFunc) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := fastime.UnixNanoNow()
-
-		hf(w, r)
+		start := time.Now()
+
+		opts := g.httpOpts()
+		skip := opts.skip(r)
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = ""
+			if !skip {
+				id = NewID()
+			}
+		}
+		fields := TraceFields(r.Header)
+		if id != "" {
+			w.Header().Set(RequestIDHeader, id)
+			fields = append(fields, String(RequestIDKey, id))
+		}
+		rg := g.With(fields...)
+		sw := &statusWriter{ResponseWriter: w}
+		var reqBody *bodyCapture
+		if !skip && opts.MaxBodySize > 0 {
+			reqBody, sw.opts = opts.captureRequest(r), opts
+		}
+		hf(sw, r.WithContext(NewContext(r.Context(), rg)))
 
-		start -= fastime.UnixNanoNow()
+		if skip {
+			return
+		}
+		elapsed := time.Since(start)
+		lv, escalated := opts.level(g, elapsed, sw.Status())
+		if !escalated && !opts.sampled() {
+			g.lose(lossSampled, lv)
+			return
+		}
 
-		err := g.Logf("Method: %s\tURI: %s\tName: %s\tTime: %s",
-			r.Method, r.RequestURI, name, (*(*time.Duration)(unsafe.Pointer(&start))).String())
+		fields = append([]Field{String(RemoteAddrKey, r.RemoteAddr), String(ClientIPKey, opts.clientIP(r))},
+			bodyFields(reqBody, sw.body, r.Header.Get("Content-Type"), sw.ctype)...)
+		fields = append(fields, sw.fields...)
+		err := rg.With(fields...).out(lv, "Method: %s\tURI: %s\tName: %s\tTime: %s",
+			r.Method, r.RequestURI, name, elapsed.String())
 		if err != nil {
 			err = g.Error(err)
 			if err != nil {
@@ -792,12 +1074,12 @@ func (g *Glg) HTTPLoggerFunc(name string, hf http.HandlerFunc) http.Handler {
 
 // HTTPLogger is simple http access logger
 func HTTPLogger(name string, handler http.Handler) http.Handler {
-	return glg.HTTPLogger(name, handler)
+	return Get().HTTPLogger(name, handler)
 }
 
 // HTTPLoggerFunc is simple http access logger
 func HTTPLoggerFunc(name string, hf http.HandlerFunc) http.Handler {
-	return glg.HTTPLoggerFunc(name, hf)
+	return Get().HTTPLoggerFunc(name, hf)
 }
 
 // Colorless returns colorless string
@@ -856,7 +1138,19 @@ func White(str string) string {
 }
 
 func (g *Glg) out(level LEVEL, format string, val ...interface{}) error {
+	return g.output(level, nil, format, val...)
+}
+
+// output writes the entry, re is the original time and caller of the replayed entry, nil for the new entries
+func (g *Glg) output(level LEVEL, re *replayEntry, format string, val ...interface{}) (err error) {
+	if atomic.LoadInt32(&g.shutdown) != 0 {
+		g.drop(level)
+		return nil
+	}
 	log, ok := g.logger.Load(level)
+	if re != nil && re.log != nil {
+		log, ok = re.log, true
+	}
 	if !ok {
 		return fmt.Errorf("error:\tLog Level %d Not Found", level)
 	}
@@ -864,10 +1158,58 @@ func (g *Glg) out(level LEVEL, format string, val ...interface{}) error {
 	if log.mode == NONE {
 		return nil
 	}
+	o := g.opts()
+	if re != nil && re.opts != nil {
+		o = re.opts
+	}
+	rawFormat, rawVal := format, val
+	var fields []Field
+	if re != nil && re.log != nil {
+		// the routed entry is rendered again from the fields split and memoized for the levels
+		fields = re.fields
+	} else {
+		format, val, fields = g.splitFields(o, format, val)
+		val, fields = memoizeLazy(val, fields)
+	}
+	if re == nil {
+		// the entries of the disabled levels are neither classified nor counted by the escalation rules,
+		// the escalation formats the memoized values so the Lazy values are evaluated once
+		lv := level
+		if len(o.errorRules) != 0 {
+			lv = g.classify(o.errorRules, lv, rawVal)
+		}
+		if lv = g.escalate(lv, format, val); lv != level {
+			level = lv
+			if log, ok = g.logger.Load(level); !ok {
+				return fmt.Errorf("error:\tLog Level %d Not Found", level)
+			}
+			if log.mode == NONE {
+				return nil
+			}
+		}
+	}
+	if o.strictFormat && re == nil {
+		if warn := g.checkFormat(log.tag, rawFormat, rawVal...); warn != nil {
+			defer warn()
+		}
+	}
+
+	isJSON := log.isJSON(o.enableJSON) && log.encoder == nil
+	routeFormat, routeVal, routeFields := format, val, fields
+	if !isJSON && format == "" {
+		// the format of the level writing text is left blank by the instance writing JSON
+		format = spaceFormat(len(val))
+	}
+	val = resolveArgs(val, isJSON && format == "")
+	if o.invalidUTF8 != nil {
+		format, val, fields = validUTF8(*o.invalidUTF8, format, val, fields)
+	}
 
 	var fl string
-	if log.traceMode&(TraceLineLong|TraceLineShort) != 0 {
-		_, file, line, ok := runtime.Caller(g.callerDepth)
+	if re != nil {
+		fl = re.caller
+	} else if log.traceMode&(TraceLineLong|TraceLineShort) != 0 {
+		_, file, line, ok := runtime.Caller(o.callerDepth + 1)
 		switch {
 		case !ok:
 			fl = "???:0"
@@ -911,87 +1253,215 @@ func (g *Glg) out(level LEVEL, format string, val ...interface{}) error {
 		}
 	}
 
-	if g.enableJSON {
-		var w io.Writer
-		switch log.writeMode {
-		case writeStd, writeColorStd:
-			w = log.std
-		case writeWriter:
-			w = log.writer
-		case writeBoth, writeColorBoth:
-			w = io.MultiWriter(log.std, log.writer)
-		default:
+	tag := log.tag
+	if log.prefix != nil {
+		tag = log.prefix.render(g, o, fields)
+	}
+	if len(o.filters) != 0 && (re == nil || re.log == nil) && !g.filter(o.filters, level, tag, fl, format, val, fields) {
+		return nil
+	}
+
+	var (
+		ts  []byte
+		now time.Time
+	)
+	if atomic.LoadInt32(&g.ordered) != 0 && (re == nil || re.log == nil) {
+		g.orderMu.Lock()
+		defer g.orderMu.Unlock()
+	}
+	if !log.disableTimestamp {
+		if re != nil && !re.time.IsZero() {
+			now = re.time
+			if o.enableUTC {
+				now = now.UTC()
+			}
+			ts = now.AppendFormat(make([]byte, 0, len(timeFormat)), timeFormat)
+		} else {
+			ts = o.formattedNow()
+		}
+	}
+	if (len(log.routes) != 0 || len(g.to) != 0) && (re == nil || re.log == nil) {
+		defer func() {
+			err = errors.Join(err, g.writeRoutes(o, level, log, isJSON, fl, now, routeFormat, routeVal, routeFields))
+		}()
+	}
+
+	std, writer := log.std, log.writer
+	if writer != nil {
+		writer = routeWriter(writer, fields)
+	}
+	if o.stdFlush != nil {
+		std = o.stdFlush.writer(std, log.rankOf(level))
+	}
+	if o.enablePriority {
+		std = priorityStd(std, log.rankOf(level))
+	}
+	if g.batch != nil {
+		std, writer = g.batch.writer(level, std, true), g.batch.writer(level, writer, false)
+	} else {
+		std = g.terminal(level, std)
+		if a := g.asyncer(); a != nil {
+			std, writer = a.writer(level, std), a.writer(level, writer)
+		}
+	}
+
+	if log.encoder != nil {
+		w := log.entryWriter(std, writer)
+		if w == nil {
+			return nil
+		}
+		if !log.disableTimestamp && now.IsZero() {
+			now = fastime.Now()
+			if o.enableUTC {
+				now = now.UTC()
+			}
+		}
+		b := g.getBuffer()
+		o.writeMessage(b, format, val...)
+		err = log.encoder.Encode(w, &Entry{
+			Time:    now,
+			Level:   level,
+			Tag:     tag,
+			Caller:  fl,
+			Message: b.String(),
+			Fields:  fields,
+		})
+		g.putBuffer(b)
+		return err
+	}
+
+	if isJSON {
+		w := log.entryWriter(std, writer)
+		if w == nil {
 			return nil
 		}
 		var detail interface{}
 		if format != "" {
-			detail = fmt.Sprintf(format, val...)
+			if o.maxFieldSize > 0 {
+				val = truncateArgs(format, val, o.maxFieldSize)
+			}
+			detail = truncate(fmt.Sprintf(format, val...), o.maxMessageSize)
+		} else if len(val) == 0 {
+			detail = nil
 		} else if len(val) > 1 {
+			if o.maxFieldSize > 0 {
+				vals := make([]interface{}, len(val))
+				for i, v := range val {
+					vals[i] = truncateDetail(v, o.maxFieldSize)
+				}
+				val = vals
+			}
 			detail = val
 		} else {
 			detail = val[0]
+			if o.maxFieldSize > 0 {
+				detail = truncateDetail(detail, o.maxFieldSize)
+			}
+			if o.maxMessageSize > 0 {
+				detail = truncateDetail(detail, o.maxMessageSize)
+			}
 		}
-		var timestamp string
-		if !log.disableTimestamp {
-			fn := fastime.FormattedNow()
-			timestamp = *(*string)(unsafe.Pointer(&fn))
+		if !log.disableTimestamp && now.IsZero() && (o.cloudLogging || o.enableEpoch) {
+			now = fastime.Now()
+			if o.enableUTC {
+				now = now.UTC()
+			}
 		}
-		return json.NewEncoder(w).Encode(JSONFormat{
-			Date:   timestamp,
-			Level:  log.tag,
-			File:   fl,
-			Detail: detail,
+		if o.cloudLogging {
+			return g.writeCloudLogging(o, w, level, fl, now, detail, fields)
+		}
+		var epoch int64
+		if o.enableEpoch && !log.disableTimestamp {
+			epoch = now.UnixNano() / int64(time.Millisecond)
+		}
+		return json.NewEncoder(w).Encode(jsonEntry{
+			Date:      *(*string)(unsafe.Pointer(&ts)),
+			Timestamp: epoch,
+			Level:     tag,
+			File:      fl,
+			Detail:    detail,
+			Fields:    o.jsonFields(fields),
+			Resource:  o.resource,
 		})
 	}
 
-	var (
-		buf []byte
-		err error
-		b   = g.buffer.Get().(*bytes.Buffer)
-	)
+	if o.maxFieldSize > 0 {
+		val = truncateArgs(format, val, o.maxFieldSize)
+	}
+	if o.enableHuman {
+		val = humanizeArgs(format, val)
+	}
+	if o.enableSanitize {
+		val = sanitizeArgs(format, val)
+	}
 
-	if log.disableTimestamp {
-		b.Write(log.rawtag[len(tab):])
+	b := g.getBuffer()
+
+	if o.levelToken != "" {
+		b.WriteString(o.levelToken + "=" + levelSeverity(log.rankOf(level), log.tag) + tab)
+	}
+	if log.layout != nil {
+		log.layout.write(g, o, b, ts, tag, fl, fields, format, val...)
 	} else {
-		b.Write(fastime.FormattedNow())
-		b.Write(log.rawtag)
+		if ts != nil {
+			b.Write(ts)
+			b.WriteString(tab)
+		}
+		if log.prefix != nil {
+			b.WriteString("[" + tag + sep)
+		} else {
+			b.Write(log.rawtag[len(tab):])
+		}
+		if len(fl) != 0 {
+			b.WriteString("(" + fl + "):\t")
+		}
+		o.writeMessage(b, format, val...)
+		if len(fields) != 0 {
+			b.WriteString(tab)
+			o.writeFields(b, fields)
+		}
 	}
-	if len(fl) != 0 {
-		b.WriteString("(" + fl + "):\t")
+
+	err = log.writeLine(b, std, writer)
+	g.putBuffer(b)
+
+	return err
+}
+
+// entryWriter returns the destination of the entry written at once by the write mode, or nil when it is not written
+func (l *logger) entryWriter(std, writer io.Writer) io.Writer {
+	switch l.writeMode {
+	case writeStd, writeColorStd:
+		return std
+	case writeWriter:
+		return writer
+	case writeBoth, writeColorBoth:
+		return fanout{std, writer}
 	}
-	b.WriteString(format)
+	return nil
+}
 
-	switch {
-	case log.writeMode^writeColorStd == 0:
-		buf = b.Bytes()
-		_, err = fmt.Fprintf(log.std, log.color(*(*string)(unsafe.Pointer(&buf)))+rc, val...)
-	case log.writeMode^writeStd == 0:
+// writeLine writes the rendered line held by b to std and writer, the destinations of the logger or their asynchronous queues
+func (l *logger) writeLine(b *bytes.Buffer, std, writer io.Writer) (err error) {
+	buf := b.Bytes()
+	switch l.writeMode {
+	case writeColorStd:
+		_, err = io.WriteString(std, l.color(*(*string)(unsafe.Pointer(&buf)))+rc)
+	case writeStd:
 		b.WriteString(rc)
-		buf = b.Bytes()
-		_, err = fmt.Fprintf(log.std, *(*string)(unsafe.Pointer(&buf)), val...)
-	case log.writeMode^writeWriter == 0:
+		_, err = std.Write(b.Bytes())
+	case writeWriter:
 		b.WriteString(rc)
-		buf = b.Bytes()
-		_, err = fmt.Fprintf(log.writer, *(*string)(unsafe.Pointer(&buf)), val...)
-	case log.writeMode^writeColorBoth == 0:
-		buf = b.Bytes()
-		str := *(*string)(unsafe.Pointer(&buf))
-		_, err = fmt.Fprintf(log.std, log.color(str)+rc, val...)
-		if err == nil {
-			_, err = fmt.Fprintf(log.writer, str+rc, val...)
-		}
-	case log.writeMode^writeBoth == 0:
+		_, err = writer.Write(b.Bytes())
+	case writeColorBoth:
+		_, err = io.WriteString(std, l.color(*(*string)(unsafe.Pointer(&buf)))+rc)
 		b.WriteString(rc)
-		buf = b.Bytes()
-		_, err = fmt.Fprintf(io.MultiWriter(log.std, log.writer), *(*string)(unsafe.Pointer(&buf)), val...)
-	}
-	bl := uint64(len(buf))
-	if atomic.LoadUint64(g.bs) < bl {
-		atomic.StoreUint64(g.bs, bl)
+		_, werr := writer.Write(b.Bytes())
+		err = errors.Join(writeError(std, err), writeError(writer, werr))
+	case writeBoth:
+		b.WriteString(rc)
+		_, err = fanout{std, writer}.Write(b.Bytes())
 	}
-	b.Reset()
-	g.buffer.Put(b)
-
 	return err
 }
 
@@ -1015,18 +1485,19 @@ func (g *Glg) LogFunc(f func() string) error {
 
 // Log writes std log event
 func Log(val ...interface{}) error {
-	return glg.out(LOG, glg.blankFormat(len(val)), val...)
+	g := Get()
+	return g.out(LOG, g.blankFormat(len(val)), val...)
 }
 
 // Logf writes std log event with format
 func Logf(format string, val ...interface{}) error {
-	return glg.out(LOG, format, val...)
+	return Get().out(LOG, format, val...)
 }
 
 // LogFunc outputs Log level log returned from the function
 func LogFunc(f func() string) error {
 	if isModeEnable(LOG) {
-		return glg.out(LOG, "%s", f())
+		return Get().out(LOG, "%s", f())
 	}
 	return nil
 }
@@ -1051,18 +1522,19 @@ func (g *Glg) InfoFunc(f func() string) error {
 
 // Info outputs Info level log
 func Info(val ...interface{}) error {
-	return glg.out(INFO, glg.blankFormat(len(val)), val...)
+	g := Get()
+	return g.out(INFO, g.blankFormat(len(val)), val...)
 }
 
 // Infof outputs formatted Info level log
 func Infof(format string, val ...interface{}) error {
-	return glg.out(INFO, format, val...)
+	return Get().out(INFO, format, val...)
 }
 
 // InfoFunc outputs Info level log returned from the function
 func InfoFunc(f func() string) error {
 	if isModeEnable(INFO) {
-		return glg.out(INFO, "%s", f())
+		return Get().out(INFO, "%s", f())
 	}
 	return nil
 }
@@ -1087,18 +1559,19 @@ func (g *Glg) SuccessFunc(f func() string) error {
 
 // Success outputs Success level log
 func Success(val ...interface{}) error {
-	return glg.out(OK, glg.blankFormat(len(val)), val...)
+	g := Get()
+	return g.out(OK, g.blankFormat(len(val)), val...)
 }
 
 // Successf outputs formatted Success level log
 func Successf(format string, val ...interface{}) error {
-	return glg.out(OK, format, val...)
+	return Get().out(OK, format, val...)
 }
 
 // SuccessFunc outputs Success level log returned from the function
 func SuccessFunc(f func() string) error {
 	if isModeEnable(OK) {
-		return glg.out(OK, "%s", f())
+		return Get().out(OK, "%s", f())
 	}
 	return nil
 }
@@ -1123,18 +1596,19 @@ func (g *Glg) DebugFunc(f func() string) error {
 
 // Debug outputs Debug level log
 func Debug(val ...interface{}) error {
-	return glg.out(DEBG, glg.blankFormat(len(val)), val...)
+	g := Get()
+	return g.out(DEBG, g.blankFormat(len(val)), val...)
 }
 
 // Debugf outputs formatted Debug level log
 func Debugf(format string, val ...interface{}) error {
-	return glg.out(DEBG, format, val...)
+	return Get().out(DEBG, format, val...)
 }
 
 // DebugFunc outputs Debug level log returned from the function
 func DebugFunc(f func() string) error {
 	if isModeEnable(DEBG) {
-		return glg.out(DEBG, "%s", f())
+		return Get().out(DEBG, "%s", f())
 	}
 	return nil
 }
@@ -1159,18 +1633,19 @@ func (g *Glg) WarnFunc(f func() string) error {
 
 // Warn outputs Warn level log
 func Warn(val ...interface{}) error {
-	return glg.out(WARN, glg.blankFormat(len(val)), val...)
+	g := Get()
+	return g.out(WARN, g.blankFormat(len(val)), val...)
 }
 
 // Warnf outputs formatted Warn level log
 func Warnf(format string, val ...interface{}) error {
-	return glg.out(WARN, format, val...)
+	return Get().out(WARN, format, val...)
 }
 
 // WarnFunc outputs Warn level log returned from the function
 func WarnFunc(f func() string) error {
 	if isModeEnable(WARN) {
-		return glg.out(WARN, "%s", f())
+		return Get().out(WARN, "%s", f())
 	}
 	return nil
 }
@@ -1196,19 +1671,21 @@ func (g *Glg) CustomLogFunc(level string, f func() string) error {
 
 // CustomLog outputs custom level log
 func CustomLog(level string, val ...interface{}) error {
-	return glg.out(glg.TagStringToLevel(level), glg.blankFormat(len(val)), val...)
+	g := Get()
+	return g.out(g.TagStringToLevel(level), g.blankFormat(len(val)), val...)
 }
 
 // CustomLogf outputs formatted custom level log
 func CustomLogf(level string, format string, val ...interface{}) error {
-	return glg.out(glg.TagStringToLevel(level), format, val...)
+	g := Get()
+	return g.out(g.TagStringToLevel(level), format, val...)
 }
 
 // CustomLogFunc outputs custom level log returned from the function
 func CustomLogFunc(level string, f func() string) error {
 	lv := TagStringToLevel(level)
 	if isModeEnable(lv) {
-		return glg.out(lv, "%s", f())
+		return Get().out(lv, "%s", f())
 	}
 	return nil
 }
@@ -1233,18 +1710,19 @@ func (g *Glg) TraceFunc(f func() string) error {
 
 // Trace outputs Trace level log
 func Trace(val ...interface{}) error {
-	return glg.out(TRACE, glg.blankFormat(len(val)), val...)
+	g := Get()
+	return g.out(TRACE, g.blankFormat(len(val)), val...)
 }
 
 // Tracef outputs formatted Trace level log
 func Tracef(format string, val ...interface{}) error {
-	return glg.out(TRACE, format, val...)
+	return Get().out(TRACE, format, val...)
 }
 
 // TraceFunc outputs Trace log returned from the function
 func TraceFunc(f func() string) error {
 	if isModeEnable(TRACE) {
-		return glg.out(TRACE, "%s", f())
+		return Get().out(TRACE, "%s", f())
 	}
 	return nil
 }
@@ -1278,23 +1756,25 @@ func (g *Glg) PrintFunc(f func() string) error {
 
 // Print outputs Print log
 func Print(val ...interface{}) error {
-	return glg.out(PRINT, glg.blankFormat(len(val)), val...)
+	g := Get()
+	return g.out(PRINT, g.blankFormat(len(val)), val...)
 }
 
 // Println outputs fixed line Print log
 func Println(val ...interface{}) error {
-	return glg.out(PRINT, glg.blankFormat(len(val)), val...)
+	g := Get()
+	return g.out(PRINT, g.blankFormat(len(val)), val...)
 }
 
 // Printf outputs formatted Print log
 func Printf(format string, val ...interface{}) error {
-	return glg.out(PRINT, format, val...)
+	return Get().out(PRINT, format, val...)
 }
 
 // PrintFunc outputs Print log returned from the function
 func PrintFunc(f func() string) error {
 	if isModeEnable(PRINT) {
-		return glg.out(PRINT, "%s", f())
+		return Get().out(PRINT, "%s", f())
 	}
 	return nil
 }
@@ -1319,18 +1799,19 @@ func (g *Glg) ErrorFunc(f func() string) error {
 
 // Error outputs Error log
 func Error(val ...interface{}) error {
-	return glg.out(ERR, glg.blankFormat(len(val)), val...)
+	g := Get()
+	return g.out(ERR, g.blankFormat(len(val)), val...)
 }
 
 // Errorf outputs formatted Error log
 func Errorf(format string, val ...interface{}) error {
-	return glg.out(ERR, format, val...)
+	return Get().out(ERR, format, val...)
 }
 
 // ErrorFunc outputs Error level log returned from the function
 func ErrorFunc(f func() string) error {
 	if isModeEnable(ERR) {
-		return glg.out(ERR, "%s", f())
+		return Get().out(ERR, "%s", f())
 	}
 	return nil
 }
@@ -1355,18 +1836,19 @@ func (g *Glg) FailFunc(f func() string) error {
 
 // Fail outputs Failed log
 func Fail(val ...interface{}) error {
-	return glg.out(FAIL, glg.blankFormat(len(val)), val...)
+	g := Get()
+	return g.out(FAIL, g.blankFormat(len(val)), val...)
 }
 
 // Failf outputs formatted Failed log
 func Failf(format string, val ...interface{}) error {
-	return glg.out(FAIL, format, val...)
+	return Get().out(FAIL, format, val...)
 }
 
 // FailFunc outputs Fail level log returned from the function
 func FailFunc(f func() string) error {
 	if isModeEnable(FAIL) {
-		return glg.out(FAIL, "%s", f())
+		return Get().out(FAIL, "%s", f())
 	}
 	return nil
 }
@@ -1380,6 +1862,8 @@ func (g *Glg) Fatal(val ...interface{}) {
 			panic(err)
 		}
 	}
+	g.runFatalHooks(shortCaller(1), g.blankFormat(len(val)), val)
+	g.Flush()
 	exit(1)
 }
 
@@ -1392,6 +1876,8 @@ func (g *Glg) Fatalln(val ...interface{}) {
 			panic(err)
 		}
 	}
+	g.runFatalHooks(shortCaller(1), g.blankFormat(len(val)), val)
+	g.Flush()
 	exit(1)
 }
 
@@ -1404,22 +1890,24 @@ func (g *Glg) Fatalf(format string, val ...interface{}) {
 			panic(err)
 		}
 	}
+	g.runFatalHooks(shortCaller(1), format, val)
+	g.Flush()
 	exit(1)
 }
 
 // Fatal outputs Failed log and exit program
 func Fatal(val ...interface{}) {
-	glg.Fatal(val...)
+	Get().Fatal(val...)
 }
 
 // Fatalf outputs formatted Failed log and exit program
 func Fatalf(format string, val ...interface{}) {
-	glg.Fatalf(format, val...)
+	Get().Fatalf(format, val...)
 }
 
 // Fatalln outputs line fixed Failed log and exit program
 func Fatalln(val ...interface{}) {
-	glg.Fatalln(val...)
+	Get().Fatalln(val...)
 }
 
 // ReplaceExitFunc replaces exit function.
@@ -1431,8 +1919,9 @@ func ReplaceExitFunc(fn func(i int)) {
 
 // Reset provides parameter reset function for glg struct instance
 func Reset() *Glg {
-	glg = glg.Reset()
-	return glg
+	g := Get().Reset()
+	instance.Store(g)
+	return g
 }
 
 // Reset provides parameter reset function for glg struct instance
@@ -1442,7 +1931,29 @@ func (g *Glg) Reset() *Glg {
 }
 
 func (g *Glg) blankFormat(l int) string {
-	if g.enableJSON {
+	return g.opts().blankFormat(l)
+}
+
+// blankFormat returns the format of l values without the format, the instance writing JSON leaves it blank
+func (o *switches) blankFormat(l int) string {
+	if o.enableJSON {
+		return ""
+	}
+	return spaceFormat(l)
+}
+
+// shortCaller returns the file:line of the caller skip frames above the caller of shortCaller
+func shortCaller(skip int) string {
+	_, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return "???:0"
+	}
+	return file[strings.LastIndexByte(file, '/')+1:] + ":" + strconv.Itoa(line)
+}
+
+// spaceFormat returns the format of l space separated values
+func spaceFormat(l int) string {
+	if l <= 0 {
 		return ""
 	}
 	if dfl > l {
@@ -30,6 +30,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"sync"
@@ -80,6 +81,21 @@ func TestLEVEL_String(t *testing.T) {
 	}
 }
 
+func TestLEVEL_ShortString(t *testing.T) {
+	for _, lv := range []LEVEL{DEBG, TRACE, PRINT, LOG, INFO, OK, WARN, ERR, FAIL, FATAL} {
+		str := lv.ShortString()
+		if len(str) != 3 {
+			t.Errorf("%s.ShortString() = %q, want 3 chars", lv, str)
+		}
+		if got := Get().Atol(str); got != lv {
+			t.Errorf("Atol(%q) = %v, want %v", str, got, lv)
+		}
+	}
+	if LEVEL(100).ShortString() != "" {
+		t.Error("invalid value")
+	}
+}
+
 func TestNew(t *testing.T) {
 	t.Run("Comparing simple instances", func(t *testing.T) {
 		ins1 := New()
@@ -767,6 +783,79 @@ func TestGlg_SetPrefix(t *testing.T) {
 	}
 }
 
+func TestGlg_SetLevelString(t *testing.T) {
+	tests := []struct {
+		name  string
+		level LEVEL
+		str   string
+		json  bool
+		want  string
+	}{
+		{
+			name:  "WARNING",
+			level: WARN,
+			str:   "WARNING",
+			want:  "[WARNING]:",
+		},
+		{
+			name:  "localized",
+			level: INFO,
+			str:   "情報",
+			want:  "[情報]:",
+		},
+		{
+			name:  "json",
+			level: ERR,
+			str:   "ERROR",
+			json:  true,
+			want:  `"level":"ERROR"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).SetLevelString(tt.level, tt.str)
+			if tt.json {
+				g.EnableJSON()
+			}
+			g.out(tt.level, "%s", "sample")
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("SetLevelString = got %v want %v", buf.String(), tt.want)
+			}
+			if got := g.Atol(tt.str); got != tt.level {
+				t.Errorf("Glg.Atol = %v, want %v", got, tt.level)
+			}
+		})
+	}
+}
+
+func TestGlg_SetLevelString_Replace(t *testing.T) {
+	g := New().SetLevelString(INFO, "NOTICE").SetLevelString(INFO, "NOTE")
+	if got := g.Atol("NOTICE"); got != UNKNOWN {
+		t.Errorf("Glg.Atol = %v, want %v", got, UNKNOWN)
+	}
+	if got := g.Atol("note"); got != INFO {
+		t.Errorf("Glg.Atol = %v, want %v", got, INFO)
+	}
+}
+
+func TestGlg_EnableShortLevel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().EnableShortLevel()
+	g.Info("info")
+	g.Success("ok")
+	want := "[INF]:\tinfo\n[OK ]:\tok\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Glg.EnableShortLevel() = %q, want %q", got, want)
+	}
+	buf.Reset()
+	g.DisableShortLevel().Info("info")
+	want = "[INFO]:\tinfo\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Glg.DisableShortLevel() = %q, want %q", got, want)
+	}
+}
+
 func TestGlg_EnableColor(t *testing.T) {
 	tests := []struct {
 		name string
@@ -939,7 +1028,7 @@ func TestGlg_TagStringToLevel(t *testing.T) {
 			if tt.createFlg {
 				tt.g.AddStdLevel(tt.tag, STD, false)
 			}
-			got := glg.TagStringToLevel(tt.tag)
+			got := Get().TagStringToLevel(tt.tag)
 			if got != tt.want {
 				t.Errorf("Glg.TagStringToLevel = %v, want %v", got, tt.want)
 			}
@@ -948,6 +1037,7 @@ func TestGlg_TagStringToLevel(t *testing.T) {
 }
 
 func TestFileWriter(t *testing.T) {
+	dir := t.TempDir()
 	tests := []struct {
 		name  string
 		path  string
@@ -957,14 +1047,14 @@ func TestFileWriter(t *testing.T) {
 
 		{
 			name:  "sample file log",
-			path:  "./sample.log",
-			want:  "./sample.log",
+			path:  filepath.Join(dir, "sample.log"),
+			want:  filepath.Join(dir, "sample.log"),
 			isErr: false,
 		},
 		{
 			name:  "error file log",
-			path:  "./error.log",
-			want:  "./error.log",
+			path:  filepath.Join(dir, "error.log"),
+			want:  filepath.Join(dir, "error.log"),
 			isErr: false,
 		},
 		{
@@ -990,6 +1080,7 @@ func TestFileWriter(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			f := FileWriter(tt.path, 0o755)
 			if f != nil {
+				defer f.Close()
 				got := f.Name()
 				if !tt.isErr && !reflect.DeepEqual(got, tt.want) {
 					t.Errorf("FileWriter() = %v, want %v", got, tt.want)
@@ -4214,7 +4305,7 @@ func (d dumpWriter) Read(p []byte) (n int, err error) {
 }
 
 func TestGlg_EnableJSON(t *testing.T) {
-	if Get().EnableJSON().enableJSON != true {
+	if Get().EnableJSON().opts().enableJSON != true {
 		t.Error("json mode is not enabled")
 	}
 	var d dumpWriter
@@ -4238,11 +4329,58 @@ func TestGlg_EnableJSON(t *testing.T) {
 }
 
 func TestGlg_DisableJSON(t *testing.T) {
-	if Get().DisableJSON().enableJSON != false {
+	if Get().DisableJSON().opts().enableJSON != false {
 		t.Error("json mode is not disables")
 	}
 }
 
+func TestGlg_EnableUTC(t *testing.T) {
+	var d dumpWriter
+	g := New().SetWriter(&d).SetMode(WRITER).EnableJSON().EnableUTC()
+	if !g.opts().enableUTC {
+		t.Error("utc mode is not enabled")
+	}
+	before := time.Now().UTC().Add(-time.Second)
+	if err := g.Info("hello"); err != nil {
+		t.Error(err)
+	}
+	var dec JSONFormat
+	if err := json.NewDecoder(d).Decode(&dec); err != nil {
+		t.Error(err)
+	}
+	got, err := time.ParseInLocation(timeFormat, dec.Date, time.UTC)
+	if err != nil {
+		t.Error(err)
+	}
+	if got.Before(before.Truncate(time.Second)) || got.After(time.Now().UTC().Add(time.Second)) {
+		t.Errorf("date %v is not UTC now", dec.Date)
+	}
+	if g.DisableUTC().opts().enableUTC {
+		t.Error("utc mode is not disabled")
+	}
+}
+
+func TestGlg_EnableEpochMillis(t *testing.T) {
+	var d dumpWriter
+	g := New().SetWriter(&d).SetMode(WRITER).EnableJSON().EnableEpochMillis()
+	if err := g.Info("hello"); err != nil {
+		t.Error(err)
+	}
+	var dec JSONFormat
+	if err := json.NewDecoder(d).Decode(&dec); err != nil {
+		t.Error(err)
+	}
+	if dec.Date == "" {
+		t.Error("date is empty")
+	}
+	if diff := time.Now().UnixNano()/int64(time.Millisecond) - dec.Timestamp; diff < -1000 || diff > 1000 {
+		t.Errorf("ts %d is not epoch millis", dec.Timestamp)
+	}
+	if g.DisableEpochMillis().opts().enableEpoch {
+		t.Error("epoch mode is not disabled")
+	}
+}
+
 func TestGlg_EnablePoolBuffer(t *testing.T) {
 	g := Get().EnablePoolBuffer(100)
 	_, ok := g.buffer.Get().(*bytes.Buffer)
@@ -4352,13 +4490,15 @@ func TestGlg_EnableTimestamp(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := &Glg{
-				bs:           tt.fields.bs,
-				logger:       tt.fields.logger,
-				levelCounter: tt.fields.levelCounter,
-				levelMap:     tt.fields.levelMap,
-				buffer:       tt.fields.buffer,
-				enableJSON:   tt.fields.enableJSON,
-			}
+				core: &core{
+					bs:           tt.fields.bs,
+					logger:       tt.fields.logger,
+					levelCounter: tt.fields.levelCounter,
+					levelMap:     tt.fields.levelMap,
+					buffer:       tt.fields.buffer,
+				},
+			}
+			g.switches.Store(&switches{callerDepth: DefaultCallerDepth, enableJSON: tt.fields.enableJSON})
 			if got := g.EnableTimestamp(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Glg.EnableTimestamp() = %v, want %v", got, tt.want)
 			}
@@ -4385,13 +4525,15 @@ func TestGlg_DisableTimestamp(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := &Glg{
-				bs:           tt.fields.bs,
-				logger:       tt.fields.logger,
-				levelCounter: tt.fields.levelCounter,
-				levelMap:     tt.fields.levelMap,
-				buffer:       tt.fields.buffer,
-				enableJSON:   tt.fields.enableJSON,
-			}
+				core: &core{
+					bs:           tt.fields.bs,
+					logger:       tt.fields.logger,
+					levelCounter: tt.fields.levelCounter,
+					levelMap:     tt.fields.levelMap,
+					buffer:       tt.fields.buffer,
+				},
+			}
+			g.switches.Store(&switches{callerDepth: DefaultCallerDepth, enableJSON: tt.fields.enableJSON})
 			if got := g.DisableTimestamp(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Glg.DisableTimestamp() = %v, want %v", got, tt.want)
 			}
@@ -4422,13 +4564,15 @@ func TestGlg_EnableLevelTimestamp(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := &Glg{
-				bs:           tt.fields.bs,
-				logger:       tt.fields.logger,
-				levelCounter: tt.fields.levelCounter,
-				levelMap:     tt.fields.levelMap,
-				buffer:       tt.fields.buffer,
-				enableJSON:   tt.fields.enableJSON,
-			}
+				core: &core{
+					bs:           tt.fields.bs,
+					logger:       tt.fields.logger,
+					levelCounter: tt.fields.levelCounter,
+					levelMap:     tt.fields.levelMap,
+					buffer:       tt.fields.buffer,
+				},
+			}
+			g.switches.Store(&switches{callerDepth: DefaultCallerDepth, enableJSON: tt.fields.enableJSON})
 			if got := g.EnableLevelTimestamp(tt.args.lv); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Glg.EnableLevelTimestamp() = %v, want %v", got, tt.want)
 			}
@@ -4459,13 +4603,15 @@ func TestGlg_DisableLevelTimestamp(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := &Glg{
-				bs:           tt.fields.bs,
-				logger:       tt.fields.logger,
-				levelCounter: tt.fields.levelCounter,
-				levelMap:     tt.fields.levelMap,
-				buffer:       tt.fields.buffer,
-				enableJSON:   tt.fields.enableJSON,
-			}
+				core: &core{
+					bs:           tt.fields.bs,
+					logger:       tt.fields.logger,
+					levelCounter: tt.fields.levelCounter,
+					levelMap:     tt.fields.levelMap,
+					buffer:       tt.fields.buffer,
+				},
+			}
+			g.switches.Store(&switches{callerDepth: DefaultCallerDepth, enableJSON: tt.fields.enableJSON})
 			if got := g.DisableLevelTimestamp(tt.args.lv); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Glg.DisableLevelTimestamp() = %v, want %v", got, tt.want)
 			}
@@ -4496,13 +4642,15 @@ func TestGlg_blankFormat(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := &Glg{
-				bs:           tt.fields.bs,
-				logger:       tt.fields.logger,
-				levelCounter: tt.fields.levelCounter,
-				levelMap:     tt.fields.levelMap,
-				buffer:       tt.fields.buffer,
-				enableJSON:   tt.fields.enableJSON,
-			}
+				core: &core{
+					bs:           tt.fields.bs,
+					logger:       tt.fields.logger,
+					levelCounter: tt.fields.levelCounter,
+					levelMap:     tt.fields.levelMap,
+					buffer:       tt.fields.buffer,
+				},
+			}
+			g.switches.Store(&switches{callerDepth: DefaultCallerDepth, enableJSON: tt.fields.enableJSON})
 			if got := g.blankFormat(tt.args.l); got != tt.want {
 				t.Errorf("Glg.blankFormat() = %v, want %v", got, tt.want)
 			}
@@ -4553,13 +4701,15 @@ func TestGlg_isModeEnable(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			g := &Glg{
-				bs:           tt.fields.bs,
-				logger:       tt.fields.logger,
-				levelCounter: tt.fields.levelCounter,
-				levelMap:     tt.fields.levelMap,
-				buffer:       tt.fields.buffer,
-				enableJSON:   tt.fields.enableJSON,
-			}
+				core: &core{
+					bs:           tt.fields.bs,
+					logger:       tt.fields.logger,
+					levelCounter: tt.fields.levelCounter,
+					levelMap:     tt.fields.levelMap,
+					buffer:       tt.fields.buffer,
+				},
+			}
+			g.switches.Store(&switches{callerDepth: DefaultCallerDepth, enableJSON: tt.fields.enableJSON})
 			if got := g.isModeEnable(tt.args.l); got != tt.want {
 				t.Errorf("Glg.isModeEnable() = %v, want %v", got, tt.want)
 			}
@@ -4673,7 +4823,7 @@ func TestGlg_Atol(t *testing.T) {
 			if tt.createFlg {
 				tt.g.AddStdLevel(tt.tag, STD, false)
 			}
-			got := glg.Atol(tt.tag)
+			got := Get().Atol(tt.tag)
 			if got != tt.want {
 				t.Errorf("Glg.Atol = %v, want %v", got, tt.want)
 			}
@@ -1,6 +1,6 @@
 module github.com/gmazay/glg
 
-go 1.17
+go 1.20
 
 require (
 	github.com/goccy/go-json v0.9.4
@@ -0,0 +1,245 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	json "github.com/goccy/go-json"
+)
+
+const (
+	// GraphQLKey is the field key of the group of the GraphQL operation logged by GraphQLLogger,
+	// e.g. graphql.operation=GetUser graphql.type=query graphql.errors=0
+	GraphQLKey = "graphql"
+
+	// DefaultGraphQLMaxSize is the max size of the GraphQL requests and responses parsed by GraphQLLogger,
+	// the operation or the error count of the larger ones is not logged
+	DefaultGraphQLMaxSize = 1 << 20
+)
+
+// graphQLRequest is the body of the GraphQL POST request
+type graphQLRequest struct {
+	Query         string `json:"query"`
+	OperationName string `json:"operationName"`
+}
+
+// graphQLResponse is the body of the GraphQL response
+type graphQLResponse struct {
+	Errors []json.RawMessage `json:"errors"`
+}
+
+// graphQLWriter captures the response to count the GraphQL errors
+type graphQLWriter struct {
+	*statusWriter
+	body bodyCapture
+}
+
+func (w *graphQLWriter) Write(b []byte) (int, error) {
+	n, err := w.statusWriter.Write(b)
+	w.body.Write(b[:n])
+	return n, err
+}
+
+// GraphQLLogger is HTTPLogger of the GraphQL endpoint, the access log carries the operation name, the operation type
+// and the number of the errors of the response in the GraphQLKey group. The handler gets the logger with the group by FromContext
+func (g *Glg) GraphQLLogger(name string, handler http.Handler) http.Handler {
+	return g.GraphQLLoggerFunc(name, handler.ServeHTTP)
+}
+
+// GraphQLLoggerFunc is HTTPLoggerFunc of the GraphQL endpoint
+func (g *Glg) GraphQLLoggerFunc(name string, hf http.HandlerFunc) http.Handler {
+	return g.HTTPLoggerFunc(name, func(w http.ResponseWriter, r *http.Request) {
+		sw, ok := w.(*statusWriter)
+		if !ok {
+			hf(w, r)
+			return
+		}
+		var op []Field
+		if ops := graphQLOperations(r); len(ops) != 0 {
+			op = ops
+			r = r.WithContext(NewContext(r.Context(), FromContext(r.Context()).With(Group(GraphQLKey, op...))))
+		}
+		gw := &graphQLWriter{statusWriter: sw, body: bodyCapture{max: DefaultGraphQLMaxSize}}
+		hf(gw, r)
+		if n, ok := graphQLErrors(&gw.body); ok {
+			op = append(op, Int("errors", n))
+		}
+		if len(op) != 0 {
+			sw.fields = append(sw.fields, Group(GraphQLKey, op...))
+		}
+	})
+}
+
+// GraphQLLogger is HTTPLogger of the GraphQL endpoint of the global instance
+func GraphQLLogger(name string, handler http.Handler) http.Handler {
+	return Get().GraphQLLogger(name, handler)
+}
+
+// GraphQLLoggerFunc is HTTPLoggerFunc of the GraphQL endpoint of the global instance
+func GraphQLLoggerFunc(name string, hf http.HandlerFunc) http.Handler {
+	return Get().GraphQLLoggerFunc(name, hf)
+}
+
+// graphQLOperations returns the operation and type fields of the GraphQL request,
+// the names and types of the batched operations are joined by ","
+func graphQLOperations(r *http.Request) []Field {
+	var reqs []graphQLRequest
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		reqs = []graphQLRequest{{Query: q.Get("query"), OperationName: q.Get("operationName")}}
+	case http.MethodPost:
+		if r.Body == nil || r.Body == http.NoBody {
+			return nil
+		}
+		buf, err := io.ReadAll(io.LimitReader(r.Body, DefaultGraphQLMaxSize+1))
+		r.Body = replayBody{Reader: io.MultiReader(bytes.NewReader(buf), errReader{err}, r.Body), Closer: r.Body}
+		if err != nil || len(buf) > DefaultGraphQLMaxSize {
+			return nil
+		}
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/graphql") {
+			reqs = []graphQLRequest{{Query: string(buf), OperationName: r.URL.Query().Get("operationName")}}
+			break
+		}
+		if buf = bytes.TrimSpace(buf); len(buf) != 0 && buf[0] == '[' {
+			if json.Unmarshal(buf, &reqs) != nil {
+				return nil
+			}
+			break
+		}
+		reqs = make([]graphQLRequest, 1)
+		if json.Unmarshal(buf, &reqs[0]) != nil {
+			return nil
+		}
+	default:
+		return nil
+	}
+	names, types := make([]string, 0, len(reqs)), make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		typ, name := graphQLOperation(req.Query, req.OperationName)
+		if typ == "" {
+			continue
+		}
+		names, types = append(names, name), append(types, typ)
+	}
+	if len(types) == 0 {
+		return nil
+	}
+	return []Field{String("operation", strings.Join(names, ",")), String("type", strings.Join(types, ","))}
+}
+
+// graphQLOperation returns the type and the name of the operation of the document executed by operationName,
+// the first operation is executed without operationName. The shorthand "{ ... }" is the anonymous query
+func graphQLOperation(doc, operationName string) (typ, name string) {
+	depth, def := 0, false
+	for i := 0; i < len(doc); i++ {
+		switch c := doc[i]; {
+		case c == '#':
+			for i < len(doc) && doc[i] != '\n' {
+				i++
+			}
+		case c == '"':
+			if strings.HasPrefix(doc[i:], `"""`) {
+				end := strings.Index(doc[i+3:], `"""`)
+				if end < 0 {
+					return "", ""
+				}
+				i += end + 5
+				continue
+			}
+			for i++; i < len(doc) && doc[i] != '"'; i++ {
+				if doc[i] == '\\' {
+					i++
+				}
+			}
+		case c == '{' || c == '(' || c == '[':
+			if depth == 0 && c == '{' && !def && operationName == "" {
+				return "query", ""
+			}
+			depth++
+		case c == '}' || c == ')' || c == ']':
+			if depth--; depth == 0 && c == '}' {
+				def = false
+			}
+		case depth == 0 && isNameByte(c):
+			j := i
+			for j < len(doc) && isNameByte(doc[j]) {
+				j++
+			}
+			word := doc[i:j]
+			i = j - 1
+			if word == "fragment" {
+				def = true
+			}
+			if word != "query" && word != "mutation" && word != "subscription" {
+				continue
+			}
+			def = true
+			for j < len(doc) && (doc[j] == ' ' || doc[j] == '\t' || doc[j] == '\n' || doc[j] == '\r' || doc[j] == ',') {
+				j++
+			}
+			k := j
+			for k < len(doc) && isNameByte(doc[k]) {
+				k++
+			}
+			if operationName == "" || operationName == doc[j:k] {
+				return word, doc[j:k]
+			}
+			i = k - 1
+		}
+	}
+	return "", ""
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// graphQLErrors returns the number of the errors of the captured GraphQL response or the batched responses
+func graphQLErrors(c *bodyCapture) (int, bool) {
+	if c.total == 0 || c.total > int64(len(c.buf)) {
+		return 0, false
+	}
+	buf := bytes.TrimSpace(c.buf)
+	if len(buf) != 0 && buf[0] == '[' {
+		var res []graphQLResponse
+		if json.Unmarshal(buf, &res) != nil {
+			return 0, false
+		}
+		n := 0
+		for _, r := range res {
+			n += len(r.Errors)
+		}
+		return n, true
+	}
+	var res graphQLResponse
+	if json.Unmarshal(buf, &res) != nil {
+		return 0, false
+	}
+	return len(res.Errors), true
+}
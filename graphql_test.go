@@ -0,0 +1,159 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestGraphQLOperation(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		op       string
+		wantType string
+		wantName string
+	}{
+		{
+			name:     "shorthand",
+			doc:      "{ user(id: 1) { name } }",
+			wantType: "query",
+		},
+		{
+			name:     "named query",
+			doc:      "query GetUser($id: ID!) { user(id: $id) { name } }",
+			wantType: "query",
+			wantName: "GetUser",
+		},
+		{
+			name:     "selected by operation name",
+			doc:      "# comment query Bad\nquery A { a(s: \"}{\") }\nfragment F on User { id }\nmutation B($in: In = {x: 1}) { b(d: \"\"\"query C\"\"\") }",
+			op:       "B",
+			wantType: "mutation",
+			wantName: "B",
+		},
+		{
+			name:     "fragment first",
+			doc:      "fragment F on User { id } subscription OnUser { user { ...F } }",
+			wantType: "subscription",
+			wantName: "OnUser",
+		},
+		{
+			name: "unknown operation name",
+			doc:  "query A { a }",
+			op:   "B",
+		},
+		{
+			name: "empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typ, name := graphQLOperation(tt.doc, tt.op)
+			if typ != tt.wantType || name != tt.wantName {
+				t.Errorf("graphQLOperation() = %q, %q, want %q, %q", typ, name, tt.wantType, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestGlg_GraphQLLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     func() *http.Request
+		res     string
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "post",
+			req: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/graphql",
+					strings.NewReader(`{"query":"query GetUser { user { name } }","operationName":"GetUser"}`))
+			},
+			res:  `{"data":null,"errors":[{"message":"a"},{"message":"b"}]}`,
+			want: []string{"graphql.operation=GetUser", "graphql.type=query", "graphql.errors=2"},
+		},
+		{
+			name: "get",
+			req: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape("mutation Save { save }"), nil)
+			},
+			res:  `{"data":{"save":true}}`,
+			want: []string{"graphql.operation=Save", "graphql.type=mutation", "graphql.errors=0"},
+		},
+		{
+			name: "batch",
+			req: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/graphql",
+					strings.NewReader(`[{"query":"query A { a }"},{"query":"mutation B { b }"}]`))
+			},
+			res:  `[{"data":{}},{"errors":[{"message":"x"}]}]`,
+			want: []string{"graphql.operation=A,B", "graphql.type=query,mutation", "graphql.errors=1"},
+		},
+		{
+			name: "not graphql",
+			req: func() *http.Request {
+				return httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader("nope"))
+			},
+			res:     "nope",
+			notWant: []string{GraphQLKey + "."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp()
+			var body []byte
+			var handlerLog bool
+			r := tt.req()
+			g.GraphQLLoggerFunc("api", func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				handlerLog = len(FromContext(r.Context()).fields) == 2+len(TraceFields(r.Header))
+				io.WriteString(w, tt.res)
+			}).ServeHTTP(httptest.NewRecorder(), r)
+			if r.Method == http.MethodPost && len(body) == 0 {
+				t.Error("handler read the empty body")
+			}
+			if tt.notWant == nil && !handlerLog {
+				t.Error("handler logger does not carry the operation")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("access log = %q, want %q", buf.String(), want)
+				}
+			}
+			for _, nw := range tt.notWant {
+				if strings.Contains(buf.String(), nw) {
+					t.Errorf("access log = %q, must not contain %q", buf.String(), nw)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	json "github.com/goccy/go-json"
+)
+
+const (
+	// RequestBodyKey is the field key of the request body captured by HTTPOptions.MaxBodySize
+	RequestBodyKey = "request_body"
+	// ResponseBodyKey is the field key of the response body captured by HTTPOptions.MaxBodySize
+	ResponseBodyKey = "response_body"
+)
+
+// DefaultBodyContentTypes are the media types of the bodies captured when HTTPOptions.BodyContentTypes is empty
+var DefaultBodyContentTypes = []string{
+	"application/json",
+	"application/*+json",
+	"application/x-www-form-urlencoded",
+	"application/xml",
+	"text/*",
+}
+
+// bodyCapture keeps the first max bytes of the body and counts the rest
+type bodyCapture struct {
+	buf   []byte
+	max   int
+	total int64
+}
+
+func (c *bodyCapture) Write(p []byte) (int, error) {
+	if room := c.max - len(c.buf); room > 0 {
+		if room > len(p) {
+			room = len(p)
+		}
+		c.buf = append(c.buf, p[:room]...)
+	}
+	c.total += int64(len(p))
+	return len(p), nil
+}
+
+// replayBody reads the captured prefix and then the rest of the original body
+type replayBody struct {
+	io.Reader
+	io.Closer
+}
+
+// captureRequest reads up to MaxBodySize bytes of the request body of the captured content type
+// and replaces the body so the handler reads it from the start
+func (o *HTTPOptions) captureRequest(r *http.Request) *bodyCapture {
+	if r.Body == nil || r.Body == http.NoBody || !o.captures(r.Header.Get("Content-Type")) {
+		return nil
+	}
+	c := &bodyCapture{max: o.MaxBodySize}
+	buf, err := io.ReadAll(io.LimitReader(r.Body, int64(o.MaxBodySize)+1))
+	c.Write(buf)
+	r.Body = replayBody{Reader: io.MultiReader(bytes.NewReader(buf), errReader{err}, r.Body), Closer: r.Body}
+	if r.ContentLength > c.total {
+		c.total = r.ContentLength
+	} else if len(buf) > o.MaxBodySize {
+		c.total = -1
+	}
+	return c
+}
+
+// errReader returns the error of the capture to the handler, nil reads as the end
+type errReader struct {
+	err error
+}
+
+func (e errReader) Read([]byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	return 0, io.EOF
+}
+
+// captures reports the body of the content type is captured
+func (o *HTTPOptions) captures(contentType string) bool {
+	if o.MaxBodySize <= 0 {
+		return false
+	}
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	types := o.BodyContentTypes
+	if len(types) == 0 {
+		types = DefaultBodyContentTypes
+	}
+	for _, t := range types {
+		if ok, _ := path.Match(strings.ToLower(t), mt); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyFields returns the fields of the captured bodies
+func bodyFields(req, res *bodyCapture, reqType, resType string) []Field {
+	var fields []Field
+	if req != nil && req.total != 0 {
+		fields = append(fields, String(RequestBodyKey, req.String(reqType)))
+	}
+	if res != nil && res.total != 0 {
+		fields = append(fields, String(ResponseBodyKey, res.String(resType)))
+	}
+	return fields
+}
+
+// String returns the captured body redacted by the content type.
+// The values of the secret keys of JSON and form bodies are replaced by SecretMask,
+// the JSON body which cannot be redacted, e.g. truncated by the max size, is not logged
+func (c *bodyCapture) String(contentType string) string {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	body := c.buf
+	switch {
+	case mt == "application/json" || strings.HasSuffix(mt, "+json"):
+		if c.total < 0 || c.total > int64(len(c.buf)) {
+			return "(" + strconv.FormatInt(int64(len(c.buf)), 10) + "+ bytes of JSON not logged, the body is larger than the max size)"
+		}
+		b := new(bytes.Buffer)
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := writeMaskedJSON(b, dec, "", 0); err != nil {
+			return "(" + strconv.Itoa(len(body)) + " bytes of malformed JSON not logged)"
+		}
+		return b.String()
+	case mt == "application/x-www-form-urlencoded":
+		body = maskForm(body)
+	}
+	if c.total < 0 || c.total > int64(len(c.buf)) {
+		body = body[:runeCut(body)]
+		if c.total < 0 {
+			return string(body) + "...(truncated)"
+		}
+		return string(body) + truncatedPrefix + strconv.FormatInt(c.total-int64(len(c.buf)), 10) + truncatedSuffix
+	}
+	return string(body)
+}
+
+// maskForm replaces the values of the secret keys of the form body by SecretMask, keeping the order of the pairs
+func maskForm(body []byte) []byte {
+	pairs := strings.Split(string(body), "&")
+	for i, pair := range pairs {
+		k, _, ok := strings.Cut(pair, "=")
+		if key, err := url.QueryUnescape(k); ok && err == nil && isSecretKey(key) {
+			pairs[i] = k + "=" + url.QueryEscape(SecretMask)
+		}
+	}
+	return []byte(strings.Join(pairs, "&"))
+}
+
+// runeCut returns the length of b without the incomplete rune cut at the end
+func runeCut(b []byte) int {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(b)
+}
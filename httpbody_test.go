@@ -0,0 +1,145 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPOptions_MaxBodySize(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    HTTPOptions
+		reqType string
+		req     string
+		resType string
+		res     string
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "disabled",
+			reqType: "application/json",
+			req:     `{"a":1}`,
+			res:     "ok",
+			notWant: []string{RequestBodyKey, ResponseBodyKey},
+		},
+		{
+			name:    "json redacted",
+			opts:    HTTPOptions{MaxBodySize: 1024},
+			reqType: "application/json; charset=utf-8",
+			req:     `{"user":"bob","password":"hunter2"}`,
+			resType: "application/json",
+			res:     `{"token":"t0k3n","ok":true}`,
+			want:    []string{RequestBodyKey, "bob", SecretMask, ResponseBodyKey},
+			notWant: []string{"hunter2", "t0k3n"},
+		},
+		{
+			name:    "form redacted",
+			opts:    HTTPOptions{MaxBodySize: 1024},
+			reqType: "application/x-www-form-urlencoded",
+			req:     "user=bob&api_key=k3y",
+			res:     "done",
+			want:    []string{"user=bob", ResponseBodyKey + "=done"},
+			notWant: []string{"k3y"},
+		},
+		{
+			name:    "truncated text",
+			opts:    HTTPOptions{MaxBodySize: 4},
+			reqType: "text/plain",
+			req:     "0123456789",
+			want:    []string{"0123" + truncatedPrefix + "6" + truncatedSuffix},
+			notWant: []string{"456"},
+		},
+		{
+			name:    "truncated json not logged",
+			opts:    HTTPOptions{MaxBodySize: 8},
+			reqType: "application/json",
+			req:     `{"password":"hunter2"}`,
+			want:    []string{RequestBodyKey},
+			notWant: []string{"hunter2"},
+		},
+		{
+			name:    "content type not captured",
+			opts:    HTTPOptions{MaxBodySize: 1024},
+			reqType: "application/octet-stream",
+			req:     "binary",
+			resType: "image/png",
+			res:     "png",
+			notWant: []string{RequestBodyKey, ResponseBodyKey},
+		},
+		{
+			name:    "custom content types",
+			opts:    HTTPOptions{MaxBodySize: 1024, BodyContentTypes: []string{"application/octet-stream"}},
+			reqType: "application/octet-stream",
+			req:     "binary",
+			resType: "text/plain",
+			res:     "text",
+			want:    []string{RequestBodyKey + "=binary"},
+			notWant: []string{ResponseBodyKey},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).SetHTTPOptions(tt.opts)
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.req))
+			r.Header.Set("Content-Type", tt.reqType)
+			var got []byte
+			g.HTTPLoggerFunc("api", func(w http.ResponseWriter, r *http.Request) {
+				got, _ = io.ReadAll(r.Body)
+				if tt.resType != "" {
+					w.Header().Set("Content-Type", tt.resType)
+				}
+				io.WriteString(w, tt.res)
+			}).ServeHTTP(httptest.NewRecorder(), r)
+			if string(got) != tt.req {
+				t.Errorf("handler read %q, want %q", got, tt.req)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("access log = %q, want %q", buf.String(), want)
+				}
+			}
+			for _, nw := range tt.notWant {
+				if strings.Contains(buf.String(), nw) {
+					t.Errorf("access log = %q, must not contain %q", buf.String(), nw)
+				}
+			}
+		})
+	}
+}
+
+func TestBodyCapture_String(t *testing.T) {
+	c := &bodyCapture{max: 5}
+	c.Write([]byte("abcdé"))
+	c.Write([]byte("fg"))
+	if got, want := c.String("text/plain"), "abcd"+truncatedPrefix+"3"+truncatedSuffix; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
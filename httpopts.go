@@ -0,0 +1,284 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bufio"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+const (
+	// RemoteAddrKey is the field key of the address of the peer connected to the server, added by HTTPLogger
+	RemoteAddrKey = "remote_addr"
+	// ClientIPKey is the field key of the client IP resolved through the trusted proxies, added by HTTPLogger
+	ClientIPKey = "client_ip"
+)
+
+// HTTPOptions configures the access logs of HTTPLogger and HTTPLoggerFunc, zero values keep the defaults
+type HTTPOptions struct {
+	// SkipPaths are the request paths not logged, e.g. "/healthz". The path ending with "*" matches the paths it prefixes, e.g. "/metrics/*"
+	SkipPaths []string
+	// SkipMethods are the request methods not logged, e.g. "OPTIONS"
+	SkipMethods []string
+	// SampleRate is the fraction of the requests logged in (0, 1], zero logs every request.
+	// The requests sampled out are counted by MetricSampled, the escalated slow and failed requests are always logged
+	SampleRate float64
+	// Level is the level of the access logs, LOG by default
+	Level LEVEL
+	// SlowThreshold escalates the access logs of the requests taking longer to SlowLevel, zero disables the escalation
+	SlowThreshold time.Duration
+	// SlowLevel is the level of the slow requests, WARN by default
+	SlowLevel LEVEL
+	// ServerErrorLevel is the level of the responses with the status 500 and above, zero keeps Level
+	ServerErrorLevel LEVEL
+	// TrustedProxies are the IPs and CIDRs of the proxies, e.g. "10.0.0.0/8". The client IP is taken from
+	// the Forwarded, X-Forwarded-For or X-Real-IP headers only when the request comes through them,
+	// the invalid entries are ignored
+	TrustedProxies []string
+	// MaxBodySize captures up to the size of the request and response bodies into the access log, zero disables the capture.
+	// The handler still reads the whole request body, the values of the secret keys of JSON and form bodies are masked
+	MaxBodySize int
+	// BodyContentTypes are the media types of the captured bodies, e.g. "application/json" or "text/*", DefaultBodyContentTypes by default
+	BodyContentTypes []string
+
+	trusted []netip.Prefix
+}
+
+// noHTTPOptions are the default options of the instance without SetHTTPOptions
+var noHTTPOptions = new(HTTPOptions)
+
+// SetHTTPOptions sets the options of the access logs, the sampled out requests are still correlated by the request ID.
+// The options may be changed while serving, each request uses the options set when it started
+func (g *Glg) SetHTTPOptions(opts HTTPOptions) *Glg {
+	opts.trusted = nil
+	for _, p := range opts.TrustedProxies {
+		if pf, err := netip.ParsePrefix(p); err == nil {
+			opts.trusted = append(opts.trusted, pf.Masked())
+		} else if ip, err := netip.ParseAddr(p); err == nil {
+			ip = ip.Unmap()
+			opts.trusted = append(opts.trusted, netip.PrefixFrom(ip, ip.BitLen()))
+		}
+	}
+	g.httpOptions.Store(&opts)
+	return g
+}
+
+// httpOpts returns the options of the access logs
+func (g *Glg) httpOpts() *HTTPOptions {
+	if opts := g.httpOptions.Load(); opts != nil {
+		return opts
+	}
+	return noHTTPOptions
+}
+
+// SetHTTPOptions sets the options of the access logs of the global instance
+func SetHTTPOptions(opts HTTPOptions) *Glg {
+	return Get().SetHTTPOptions(opts)
+}
+
+// skip reports the access log of r is not written
+func (o *HTTPOptions) skip(r *http.Request) bool {
+	for _, m := range o.SkipMethods {
+		if strings.EqualFold(m, r.Method) {
+			return true
+		}
+	}
+	for _, p := range o.SkipPaths {
+		if p == r.URL.Path || (strings.HasSuffix(p, "*") && strings.HasPrefix(r.URL.Path, p[:len(p)-1])) {
+			return true
+		}
+	}
+	return false
+}
+
+// level returns the level of the access log of the request taking elapsed and responded with status,
+// escalated reports the level is escalated by SlowThreshold or ServerErrorLevel.
+// The higher ranked level wins when the request is both slow and failed
+func (o *HTTPOptions) level(g *Glg, elapsed time.Duration, status int) (lv LEVEL, escalated bool) {
+	lv = o.Level
+	if lv == 0 {
+		lv = LOG
+	}
+	if o.SlowThreshold > 0 && elapsed > o.SlowThreshold {
+		lv, escalated = o.SlowLevel, true
+		if lv == 0 {
+			lv = WARN
+		}
+	}
+	if status >= http.StatusInternalServerError && o.ServerErrorLevel != 0 && (!escalated || g.rank(o.ServerErrorLevel) >= g.rank(lv)) {
+		lv, escalated = o.ServerErrorLevel, true
+	}
+	return lv, escalated
+}
+
+// sampled reports the access log is written by the sample rate
+func (o *HTTPOptions) sampled() bool {
+	return o.SampleRate <= 0 || o.SampleRate >= 1 || rand.Float64() < o.SampleRate
+}
+
+// clientIP returns the IP of the client of r, the forwarding headers are read
+// only when the peer is the trusted proxy. The forwarded addresses are walked from the nearest one
+// and the first address which is not the trusted proxy is the client
+func (o *HTTPOptions) clientIP(r *http.Request) string {
+	remote := hostIP(r.RemoteAddr)
+	if len(o.trusted) == 0 || !o.isTrusted(remote) {
+		return remote
+	}
+	hops := forwardedFor(r.Header.Values("Forwarded"))
+	if len(hops) == 0 {
+		for _, v := range r.Header.Values("X-Forwarded-For") {
+			for _, hop := range strings.Split(v, ",") {
+				if hop = strings.TrimSpace(hop); hop != "" {
+					hops = append(hops, hop)
+				}
+			}
+		}
+	}
+	if len(hops) == 0 {
+		if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+			return hostIP(ip)
+		}
+		return remote
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		if ip := hostIP(hops[i]); i == 0 || !o.isTrusted(ip) {
+			return ip
+		}
+	}
+	return remote
+}
+
+// isTrusted reports ip is the trusted proxy
+func (o *HTTPOptions) isTrusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range o.trusted {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor returns the for parameters of the RFC 7239 Forwarded headers in order
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, v := range values {
+		for _, elem := range strings.Split(v, ",") {
+			for _, pair := range strings.Split(elem, ";") {
+				k, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if ok && strings.EqualFold(k, "for") {
+					hops = append(hops, strings.Trim(val, `"`))
+				}
+			}
+		}
+	}
+	return hops
+}
+
+// hostIP strips the port and the brackets from addr, e.g. "[::1]:8080" is "::1"
+func hostIP(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+}
+
+// statusWriter records the response status of the handler and captures the response body when body is set,
+// the fields are added to the access log by the wrapping middleware such as GraphQLLogger
+type statusWriter struct {
+	http.ResponseWriter
+	status  int
+	opts    *HTTPOptions
+	body    *bodyCapture
+	ctype   string
+	checked bool
+	fields  []Field
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.opts != nil && !w.checked {
+		w.checked = true
+		if w.ctype = w.Header().Get("Content-Type"); w.ctype == "" {
+			w.ctype = http.DetectContentType(b)
+		}
+		if w.opts.captures(w.ctype) {
+			w.body = &bodyCapture{max: w.opts.MaxBodySize}
+		}
+	}
+	n, err := w.ResponseWriter.Write(b)
+	if w.body != nil {
+		w.body.Write(b[:n])
+	}
+	return n, err
+}
+
+// Flush implements http.Flusher when the wrapped writer does
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker when the wrapped writer does
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Status returns the recorded status, 200 when the handler writes nothing
+func (w *statusWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
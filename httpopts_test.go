@@ -0,0 +1,294 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGlg_SetHTTPOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   HTTPOptions
+		method string
+		path   string
+		want   bool
+	}{
+		{
+			name:   "default",
+			method: http.MethodGet,
+			path:   "/users",
+			want:   true,
+		},
+		{
+			name:   "skip path",
+			opts:   HTTPOptions{SkipPaths: []string{"/healthz"}},
+			method: http.MethodGet,
+			path:   "/healthz",
+		},
+		{
+			name:   "other path",
+			opts:   HTTPOptions{SkipPaths: []string{"/healthz"}},
+			method: http.MethodGet,
+			path:   "/healthz/deep",
+			want:   true,
+		},
+		{
+			name:   "skip path prefix",
+			opts:   HTTPOptions{SkipPaths: []string{"/metrics/*"}},
+			method: http.MethodGet,
+			path:   "/metrics/go",
+		},
+		{
+			name:   "skip method",
+			opts:   HTTPOptions{SkipMethods: []string{"options"}},
+			method: http.MethodOptions,
+			path:   "/users",
+		},
+		{
+			name:   "sample all",
+			opts:   HTTPOptions{SampleRate: 1},
+			method: http.MethodGet,
+			path:   "/users",
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).SetHTTPOptions(tt.opts)
+			called := false
+			rr := httptest.NewRecorder()
+			g.HTTPLoggerFunc("api", func(w http.ResponseWriter, r *http.Request) {
+				called = FromContext(r.Context()) != nil
+			}).ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+			if !called {
+				t.Error("handler is not called with the logger")
+			}
+			// the skipped requests get no new request ID
+			if got := rr.Header().Get(RequestIDHeader) != ""; got != tt.want {
+				t.Errorf("request ID set = %v, want %v", got, tt.want)
+			}
+			if got := buf.Len() != 0; got != tt.want {
+				t.Errorf("logged = %v, want %v: %q", got, tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestHTTPOptions_SampleRate(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetHTTPOptions(HTTPOptions{SampleRate: 0.25})
+	h := g.HTTPLoggerFunc("api", func(w http.ResponseWriter, r *http.Request) {})
+	const n = 4000
+	for i := 0; i < n; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	logged := bytes.Count(buf.Bytes(), []byte("\n"))
+	if logged < n/8 || logged > n*3/8 {
+		t.Errorf("logged %d of %d requests, want about %d", logged, n, n/4)
+	}
+	if got := g.losses[lossSampled]; got != uint64(n-logged) {
+		t.Errorf("sampled out = %d, want %d", got, n-logged)
+	}
+}
+
+func TestHTTPOptions_Level(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   HTTPOptions
+		sleep  time.Duration
+		status int
+		want   string
+	}{
+		{
+			name: "default",
+			want: "[LOG]",
+		},
+		{
+			name: "level",
+			opts: HTTPOptions{Level: INFO},
+			want: "[INFO]",
+		},
+		{
+			name:  "slow",
+			opts:  HTTPOptions{Level: INFO, SlowThreshold: time.Millisecond},
+			sleep: 5 * time.Millisecond,
+			want:  "[WARN]",
+		},
+		{
+			name: "not slow",
+			opts: HTTPOptions{Level: INFO, SlowThreshold: time.Hour},
+			want: "[INFO]",
+		},
+		{
+			name:   "server error",
+			opts:   HTTPOptions{Level: INFO, ServerErrorLevel: ERR},
+			status: http.StatusBadGateway,
+			want:   "[ERR]",
+		},
+		{
+			name:   "client error",
+			opts:   HTTPOptions{Level: INFO, ServerErrorLevel: ERR},
+			status: http.StatusNotFound,
+			want:   "[INFO]",
+		},
+		{
+			name:   "slow server error",
+			opts:   HTTPOptions{Level: INFO, SlowThreshold: time.Millisecond, SlowLevel: FAIL, ServerErrorLevel: ERR},
+			sleep:  5 * time.Millisecond,
+			status: http.StatusInternalServerError,
+			want:   "[FAIL]",
+		},
+		{
+			name:   "escalated are not sampled out",
+			opts:   HTTPOptions{Level: INFO, ServerErrorLevel: ERR, SampleRate: 1e-9},
+			status: http.StatusInternalServerError,
+			want:   "[ERR]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetHTTPOptions(tt.opts)
+			rr := httptest.NewRecorder()
+			g.HTTPLoggerFunc("api", func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(tt.sleep)
+				if tt.status != 0 {
+					w.WriteHeader(tt.status)
+				}
+				w.Write([]byte("ok"))
+			}).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+			if !strings.HasPrefix(buf.String(), tt.want) {
+				t.Errorf("access log = %q, want level %s", buf.String(), tt.want)
+			}
+			if want := tt.status; want != 0 && rr.Code != want {
+				t.Errorf("status = %d, want %d", rr.Code, want)
+			}
+		})
+	}
+}
+
+func TestStatusWriter(t *testing.T) {
+	rr := httptest.NewRecorder()
+	sw := &statusWriter{ResponseWriter: rr}
+	if got := sw.Status(); got != http.StatusOK {
+		t.Errorf("Status() = %d, want 200", got)
+	}
+	if err := http.NewResponseController(sw).Flush(); err != nil {
+		t.Errorf("Flush() error = %v", err)
+	}
+	if !rr.Flushed {
+		t.Error("Flush() is not passed to the wrapped writer")
+	}
+	sw.WriteHeader(http.StatusTeapot)
+	if got := sw.Status(); got != http.StatusOK {
+		t.Errorf("Status() after flush = %d, want 200", got)
+	}
+	if _, _, err := sw.Hijack(); err != http.ErrNotSupported {
+		t.Errorf("Hijack() error = %v, want ErrNotSupported", err)
+	}
+}
+
+func TestHTTPOptions_ClientIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		trusted []string
+		remote  string
+		header  http.Header
+		want    string
+	}{
+		{
+			name:   "no proxies",
+			remote: "203.0.113.7:5000",
+			header: http.Header{"X-Forwarded-For": {"198.51.100.1"}},
+			want:   "203.0.113.7",
+		},
+		{
+			name:    "untrusted peer",
+			trusted: []string{"10.0.0.0/8"},
+			remote:  "203.0.113.7:5000",
+			header:  http.Header{"X-Forwarded-For": {"198.51.100.1"}},
+			want:    "203.0.113.7",
+		},
+		{
+			name:    "x-forwarded-for",
+			trusted: []string{"10.0.0.0/8"},
+			remote:  "10.0.0.2:5000",
+			header:  http.Header{"X-Forwarded-For": {"1.1.1.1, 198.51.100.1, 10.0.0.3"}},
+			want:    "198.51.100.1",
+		},
+		{
+			name:    "x-forwarded-for all trusted",
+			trusted: []string{"10.0.0.0/8"},
+			remote:  "10.0.0.2:5000",
+			header:  http.Header{"X-Forwarded-For": {"10.0.0.4", "10.0.0.3"}},
+			want:    "10.0.0.4",
+		},
+		{
+			name:    "forwarded",
+			trusted: []string{"10.0.0.2"},
+			remote:  "10.0.0.2:5000",
+			header: http.Header{
+				"Forwarded":       {`for="[2001:db8::1]:4711";proto=https, for=10.0.0.2`},
+				"X-Forwarded-For": {"198.51.100.1"},
+			},
+			want: "2001:db8::1",
+		},
+		{
+			name:    "x-real-ip",
+			trusted: []string{"10.0.0.0/8", "invalid"},
+			remote:  "10.0.0.2:5000",
+			header:  http.Header{"X-Real-Ip": {"198.51.100.9"}},
+			want:    "198.51.100.9",
+		},
+		{
+			name:    "no headers",
+			trusted: []string{"::1"},
+			remote:  "[::1]:5000",
+			want:    "::1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).SetHTTPOptions(HTTPOptions{TrustedProxies: tt.trusted})
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			for k, v := range tt.header {
+				r.Header[k] = v
+			}
+			g.HTTPLoggerFunc("api", func(w http.ResponseWriter, r *http.Request) {}).ServeHTTP(httptest.NewRecorder(), r)
+			for _, want := range []string{RemoteAddrKey + "=" + tt.remote, ClientIPKey + "=" + tt.want} {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("access log = %q, want %s", buf.String(), want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const byteUnits = "KMGTPE"
+
+// HumanDuration returns d rounded to about 3 significant digits, e.g. 1.23ms, 4.5s, 2h30m
+func HumanDuration(d time.Duration) string {
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs >= time.Hour:
+		d = d.Round(time.Minute)
+	case abs >= time.Minute:
+		d = d.Round(time.Second)
+	case abs >= time.Second:
+		d = d.Round(10 * time.Millisecond)
+	case abs >= time.Millisecond:
+		d = d.Round(10 * time.Microsecond)
+	case abs >= time.Microsecond:
+		d = d.Round(10 * time.Nanosecond)
+	}
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+// HumanBytes returns the byte size in IEC units, e.g. 512B, 1.5KiB, 3GiB
+func HumanBytes(n int64) string {
+	abs := uint64(n)
+	if n < 0 {
+		abs = uint64(-n)
+	}
+	if abs < 1024 {
+		return strconv.FormatInt(n, 10) + "B"
+	}
+	div, exp := uint64(1024), 0
+	for m := abs / 1024; m >= 1024 && exp < len(byteUnits)-1; m /= 1024 {
+		div *= 1024
+		exp++
+	}
+	return humanFloat(float64(n)/float64(div)) + byteUnits[exp:exp+1] + "iB"
+}
+
+// HumanPercent returns the ratio as percentage, e.g. 0.125 is 12.5%
+func HumanPercent(ratio float64) string {
+	return humanFloat(ratio*100) + "%"
+}
+
+func humanFloat(f float64) string {
+	return strings.TrimSuffix(strconv.FormatFloat(f, 'f', 1, 64), ".0")
+}
+
+// humanDuration formats time.Duration message argument by HumanDuration for %v and %s
+type humanDuration time.Duration
+
+// Format implements fmt.Formatter
+func (d humanDuration) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'v', 's':
+		fmt.Fprintf(f, directive(f, verb), HumanDuration(time.Duration(d)))
+	default:
+		fmt.Fprintf(f, directive(f, verb), time.Duration(d))
+	}
+}
+
+func humanizeArgs(format string, val []interface{}) []interface{} {
+	var (
+		vals  []interface{}
+		verbs []rune
+	)
+	for i, v := range val {
+		d, ok := v.(time.Duration)
+		if !ok {
+			continue
+		}
+		if verbs == nil {
+			verbs = argVerbs(format, len(val))
+		}
+		if typeVerb(verbs[i]) {
+			continue
+		}
+		if vals == nil {
+			vals = make([]interface{}, len(val))
+			copy(vals, val)
+		}
+		vals[i] = humanDuration(d)
+	}
+	if vals == nil {
+		return val
+	}
+	return vals
+}
+
+// EnableHumanReadable enables human readable durations, byte sizes and ratios in text mode,
+// JSON mode always outputs them as numbers (nanoseconds, bytes and ratio)
+func (g *Glg) EnableHumanReadable() *Glg {
+	g.setSwitches(func(s *switches) {
+		s.enableHuman = true
+	})
+	return g
+}
+
+// DisableHumanReadable disables human readable durations, byte sizes and ratios
+func (g *Glg) DisableHumanReadable() *Glg {
+	g.setSwitches(func(s *switches) {
+		s.enableHuman = false
+	})
+	return g
+}
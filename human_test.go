@@ -0,0 +1,138 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestHumanDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 0, want: "0s"},
+		{d: 999, want: "999ns"},
+		{d: 1234567, want: "1.23ms"},
+		{d: 4567 * time.Millisecond, want: "4.57s"},
+		{d: 90*time.Second + 400*time.Millisecond, want: "1m30s"},
+		{d: 5 * time.Minute, want: "5m"},
+		{d: 2*time.Hour + 30*time.Minute + 10*time.Second, want: "2h30m"},
+		{d: 3 * time.Hour, want: "3h"},
+		{d: -1500 * time.Millisecond, want: "-1.5s"},
+	}
+	for _, tt := range tests {
+		if got := HumanDuration(tt.d); got != tt.want {
+			t.Errorf("HumanDuration(%d) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestHumanBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{n: 0, want: "0B"},
+		{n: 1023, want: "1023B"},
+		{n: 1024, want: "1KiB"},
+		{n: 1536, want: "1.5KiB"},
+		{n: 5 << 20, want: "5MiB"},
+		{n: 3 << 30, want: "3GiB"},
+		{n: -2048, want: "-2KiB"},
+		{n: 1 << 62, want: "4EiB"},
+	}
+	for _, tt := range tests {
+		if got := HumanBytes(tt.n); got != tt.want {
+			t.Errorf("HumanBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestHumanPercent(t *testing.T) {
+	if got := HumanPercent(0.125); got != "12.5%" {
+		t.Errorf("HumanPercent() = %q, want %q", got, "12.5%")
+	}
+	if got := HumanPercent(1); got != "100%" {
+		t.Errorf("HumanPercent() = %q, want %q", got, "100%")
+	}
+}
+
+func TestGlg_EnableHumanReadable(t *testing.T) {
+	tests := []struct {
+		name  string
+		human bool
+		json  bool
+		want  string
+	}{
+		{
+			name: "disabled",
+			want: "[INFO]:\ttook 1.5s\tlatency=1.5s size=1536 cpu=0.25\n",
+		},
+		{
+			name:  "enabled",
+			human: true,
+			want:  "[INFO]:\ttook 1.5s\tlatency=1.5s size=1.5KiB cpu=25%\n",
+		},
+		{
+			name:  "json",
+			human: true,
+			json:  true,
+			want:  `{"level":"INFO","detail":["took",1500000000],"fields":{"latency":1500000000,"size":1536,"cpu":0.25}}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp()
+			if tt.human {
+				g.EnableHumanReadable()
+			}
+			if tt.json {
+				g.EnableJSON()
+			}
+			d := 1500 * time.Millisecond
+			if err := g.Info("took", d, Dur("latency", d), Bytes("size", 1536), Percent("cpu", 0.25)); err != nil {
+				t.Error(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Glg.EnableHumanReadable() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().EnableHumanReadable()
+	g.Infof("%v %d", 1234567*time.Nanosecond, time.Second)
+	if got, want := buf.String(), "[INFO]:\t1.23ms 1000000000\n"; got != want {
+		t.Errorf("Glg.Infof() = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	g.Infof("%T=%v", time.Second, time.Second)
+	if got, want := buf.String(), "[INFO]:\ttime.Duration=1s\n"; got != want {
+		t.Errorf("Glg.Infof() %%T = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+const (
+	// RequestIDHeader is the request header of the correlation ID reused by HTTPLogger
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDKey is the field key of the correlation ID added by HTTPLogger
+	RequestIDKey = "request_id"
+
+	// maxRequestIDSize is the longest RequestIDHeader reused by HTTPLogger
+	maxRequestIDSize = 128
+)
+
+// idGen keeps the IDs of the same millisecond increasing by the 12 bit counter
+var idGen struct {
+	mu  sync.Mutex
+	ms  int64
+	seq uint16
+}
+
+// NewID returns the UUIDv7 such as "01890a5d-ac96-774b-bcce-b302099a8057" for the correlation fields.
+// The IDs are time ordered, the ones generated by the process are increasing
+func NewID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		binary.BigEndian.PutUint64(b[8:], uint64(time.Now().UnixNano()))
+	}
+	ms := time.Now().UnixMilli()
+	idGen.mu.Lock()
+	if ms > idGen.ms {
+		// the counter starts at random below the half to leave room for the burst
+		idGen.ms, idGen.seq = ms, binary.BigEndian.Uint16(b[6:])&0x7ff
+	} else if idGen.seq++; idGen.seq > 0xfff {
+		idGen.ms, idGen.seq = idGen.ms+1, 0
+	}
+	ms, seq := idGen.ms, idGen.seq
+	idGen.mu.Unlock()
+
+	b[0], b[1], b[2], b[3], b[4], b[5] = byte(ms>>40), byte(ms>>32), byte(ms>>24), byte(ms>>16), byte(ms>>8), byte(ms)
+	binary.BigEndian.PutUint16(b[6:], 0x7000|seq)
+	b[8] = b[8]&0x3f | 0x80
+
+	var s [36]byte
+	hex.Encode(s[0:8], b[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], b[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], b[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], b[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], b[10:])
+	return string(s[:])
+}
+
+// validRequestID reports id of RequestIDHeader is reused, the ID is echoed to the response and the logs
+// so only the letters, digits and "-", "_", ".", ":", "/", "+", "=" are accepted
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDSize {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		switch c := id[i]; {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '/', c == '+', c == '=':
+		default:
+			return false
+		}
+	}
+	return true
+}
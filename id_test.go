@@ -0,0 +1,107 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var uuidv7 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNewID(t *testing.T) {
+	start := time.Now().UnixMilli()
+	prev := ""
+	seen := make(map[string]bool)
+	for i := 0; i < 10000; i++ {
+		id := NewID()
+		if !uuidv7.MatchString(id) {
+			t.Fatalf("NewID() = %s, not UUIDv7", id)
+		}
+		if id <= prev {
+			t.Fatalf("NewID() = %s, not after %s", id, prev)
+		}
+		if seen[id] {
+			t.Fatalf("NewID() = %s, duplicated", id)
+		}
+		seen[id] = true
+		prev = id
+	}
+	var ms int64
+	for _, c := range strings.Replace(prev[:13], "-", "", 1) {
+		ms = ms<<4 | int64(strings.IndexRune("0123456789abcdef", c))
+	}
+	if ms < start || ms > time.Now().UnixMilli()+10 {
+		t.Errorf("NewID() timestamp = %d, want around %d", ms, start)
+	}
+}
+
+func TestGlg_HTTPLogger_RequestID(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{
+			name: "generated",
+		},
+		{
+			name: "from header",
+			id:   "req-42",
+		},
+		{
+			name: "invalid header",
+			id:   "req 42\nforged=1",
+		},
+		{
+			name: "too long header",
+			id:   strings.Repeat("a", maxRequestIDSize+1),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone)
+			var got string
+			h := g.HTTPLoggerFunc("id", func(w http.ResponseWriter, r *http.Request) {
+				FromContext(r.Context()).Info("handled")
+				got = w.Header().Get(RequestIDHeader)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.id != "" {
+				req.Header.Set(RequestIDHeader, tt.id)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+			if validRequestID(tt.id) && got != tt.id || !validRequestID(tt.id) && !uuidv7.MatchString(got) {
+				t.Errorf("%s = %q", RequestIDHeader, got)
+			}
+			if n := strings.Count(buf.String(), RequestIDKey+"="+got); n != 2 {
+				t.Errorf("request ID is logged %d times, want 2: %q", n, buf.String())
+			}
+		})
+	}
+}
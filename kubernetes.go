@@ -0,0 +1,77 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import "os"
+
+const (
+	// KubernetesKey is the field key of the group of the pod fields added by NewKubernetes,
+	// e.g. {"k8s":{"pod":"api-7d9f","namespace":"prod","node":"node-1"}}
+	KubernetesKey = "k8s"
+
+	// KubernetesPodEnv is the environment variable of the pod name exposed by the Downward API
+	KubernetesPodEnv = "POD_NAME"
+	// KubernetesNamespaceEnv is the environment variable of the pod namespace exposed by the Downward API
+	KubernetesNamespaceEnv = "POD_NAMESPACE"
+	// KubernetesNodeEnv is the environment variable of the node name exposed by the Downward API
+	KubernetesNodeEnv = "NODE_NAME"
+)
+
+// NewKubernetes returns the instance configured for the containers on Kubernetes. Every level is written to stdout
+// without colors as the JSON object with the severity, message and time keys recognized by the common collectors
+// such as Fluent Bit, Datadog and Cloud Logging (see EnableCloudLogging), the caller is written for ERR and above.
+// The pod, namespace and node are added in the KubernetesKey group when the Downward API sets the environment variables:
+//
+//	env:
+//	- name: POD_NAME
+//	  valueFrom: {fieldRef: {fieldPath: metadata.name}}
+//	- name: POD_NAMESPACE
+//	  valueFrom: {fieldRef: {fieldPath: metadata.namespace}}
+//	- name: NODE_NAME
+//	  valueFrom: {fieldRef: {fieldPath: spec.nodeName}}
+func NewKubernetes() *Glg {
+	g := New().EnableCloudLogging("").DisableColor()
+	g.updateLoggers(func(lv LEVEL, l *logger) {
+		l.std = os.Stdout
+		if l.rankOf(lv) >= ERR {
+			l.traceMode = TraceLineShort
+		} else {
+			l.traceMode = TraceLineNone
+		}
+	})
+	var fields []Field
+	for _, env := range []struct{ key, name string }{
+		{"pod", KubernetesPodEnv},
+		{"namespace", KubernetesNamespaceEnv},
+		{"node", KubernetesNodeEnv},
+	} {
+		if v := os.Getenv(env.name); v != "" {
+			fields = append(fields, String(env.key, v))
+		}
+	}
+	if len(fields) == 0 {
+		return g
+	}
+	return g.With(Group(KubernetesKey, fields...))
+}
@@ -0,0 +1,107 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	json "github.com/goccy/go-json"
+)
+
+func TestNewKubernetes(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want map[string]string
+	}{
+		{
+			name: "downward api",
+			env: map[string]string{
+				KubernetesPodEnv:       "api-7d9f",
+				KubernetesNamespaceEnv: "prod",
+				KubernetesNodeEnv:      "node-1",
+			},
+			want: map[string]string{"pod": "api-7d9f", "namespace": "prod", "node": "node-1"},
+		},
+		{
+			name: "outside kubernetes",
+			env: map[string]string{
+				KubernetesPodEnv:       "",
+				KubernetesNamespaceEnv: "",
+				KubernetesNodeEnv:      "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			g := NewKubernetes()
+			c := g.Config()
+			if !c.JSON || !c.CloudLogging {
+				t.Errorf("Config() = %+v, want JSON in Cloud Logging format", c)
+			}
+			for _, l := range c.Levels {
+				wantTrace := "none"
+				if l.Rank >= ERR {
+					wantTrace = "short"
+				}
+				if l.Color || l.Trace != wantTrace || l.Std != os.Stdout.Name() {
+					t.Errorf("level %s = %+v, want no color, %s trace and stdout", l.Tag, l, wantTrace)
+				}
+			}
+
+			buf := new(bytes.Buffer)
+			g.SetMode(WRITER).SetWriter(buf)
+			g.Warn("disk slow")
+			g.Error("disk failed")
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("output = %q", buf.String())
+			}
+			var e struct {
+				Severity string            `json:"severity"`
+				Message  string            `json:"message"`
+				K8s      map[string]string `json:"k8s"`
+			}
+			if err := json.Unmarshal([]byte(lines[0]), &e); err != nil {
+				t.Fatal(err)
+			}
+			if e.Severity != "WARNING" || e.Message != "disk slow" || len(e.K8s) != len(tt.want) {
+				t.Errorf("entry = %+v, want WARNING with %v", e, tt.want)
+			}
+			for k, v := range tt.want {
+				if e.K8s[k] != v {
+					t.Errorf("k8s.%s = %q, want %q", k, e.K8s[k], v)
+				}
+			}
+			if strings.Contains(lines[0], "sourceLocation") || !strings.Contains(lines[1], "kubernetes_test.go") {
+				t.Errorf("caller is not written only for ERR: %q", lines)
+			}
+		})
+	}
+}
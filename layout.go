@@ -0,0 +1,189 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"unsafe"
+)
+
+type layoutKind uint8
+
+const (
+	layoutLiteral layoutKind = iota
+	layoutTime
+	layoutLevel
+	layoutCaller
+	layoutMsg
+	layoutFields
+)
+
+type layoutToken struct {
+	kind  layoutKind
+	lit   string
+	width int
+}
+
+// layout is parsed line format template
+type layout []layoutToken
+
+var layoutPlaceholders = map[string]layoutKind{
+	"time":   layoutTime,
+	"level":  layoutLevel,
+	"prefix": layoutLevel,
+	"caller": layoutCaller,
+	"msg":    layoutMsg,
+	"fields": layoutFields,
+}
+
+// parseLayout parses line format template such as "{{time}} {{level:-5}} {{msg}}".
+// The optional number after colon is padding width, negative value pads right side.
+// Unknown placeholders are written as it is.
+func parseLayout(format string) layout {
+	if format == "" {
+		return nil
+	}
+	ly := make(layout, 0, strings.Count(format, "{{")*2+1)
+	for len(format) > 0 {
+		i := strings.Index(format, "{{")
+		if i < 0 {
+			ly = append(ly, layoutToken{lit: format})
+			break
+		}
+		j := strings.Index(format[i:], "}}")
+		if j < 0 {
+			ly = append(ly, layoutToken{lit: format})
+			break
+		}
+		if i > 0 {
+			ly = append(ly, layoutToken{lit: format[:i]})
+		}
+		ph := format[i : i+j+2]
+		format = format[i+j+2:]
+
+		name, width := ph[2:len(ph)-2], 0
+		if k := strings.IndexByte(name, ':'); k >= 0 {
+			w, err := strconv.Atoi(name[k+1:])
+			if err != nil {
+				ly = append(ly, layoutToken{lit: ph})
+				continue
+			}
+			name, width = name[:k], w
+		}
+		kind, ok := layoutPlaceholders[strings.TrimSpace(name)]
+		if !ok {
+			ly = append(ly, layoutToken{lit: ph})
+			continue
+		}
+		ly = append(ly, layoutToken{kind: kind, width: width})
+	}
+	return ly
+}
+
+// write renders the entry by the layout, o is the switches loaded once for the entry
+func (ly layout) write(g *Glg, o *switches, b *bytes.Buffer, ts []byte, tag, fl string, fields []Field, format string, val ...interface{}) {
+	var wf bool
+	for _, t := range ly {
+		switch t.kind {
+		case layoutLiteral:
+			b.WriteString(t.lit)
+		case layoutTime:
+			t.pad(b, *(*string)(unsafe.Pointer(&ts)))
+		case layoutLevel:
+			t.pad(b, tag)
+		case layoutCaller:
+			t.pad(b, fl)
+		case layoutMsg:
+			if t.width == 0 {
+				o.writeMessage(b, format, val...)
+			} else {
+				mb := g.getBuffer()
+				o.writeMessage(mb, format, val...)
+				t.pad(b, mb.String())
+				g.putBuffer(mb)
+			}
+		case layoutFields:
+			wf = true
+			if t.width == 0 {
+				o.writeFields(b, fields)
+			} else {
+				mb := g.getBuffer()
+				o.writeFields(mb, fields)
+				t.pad(b, mb.String())
+				g.putBuffer(mb)
+			}
+		}
+	}
+	// fields are never dropped silently, the layout without {{fields}} outputs them at the end of line
+	if !wf && len(fields) != 0 {
+		b.WriteString(spw)
+		o.writeFields(b, fields)
+	}
+}
+
+func (t layoutToken) pad(b *bytes.Buffer, str string) {
+	width := t.width
+	if width == 0 {
+		b.WriteString(str)
+		return
+	}
+	left := width > 0
+	if !left {
+		width = -width
+	}
+	n := width - utf8.RuneCountInString(str)
+	if !left {
+		b.WriteString(str)
+	}
+	for ; n > 0; n-- {
+		b.WriteByte(' ')
+	}
+	if left {
+		b.WriteString(str)
+	}
+}
+
+// SetLineFormat sets text output layout for all levels.
+// Available placeholders are {{time}}, {{level}} ({{prefix}} is alias of it, because SetPrefix replaces level tag), {{caller}}, {{msg}} and {{fields}},
+// padding width can be set like {{level:-5}}.
+// Empty format restores default layout.
+func (g *Glg) SetLineFormat(format string) *Glg {
+	ly := parseLayout(format)
+	g.updateLoggers(func(_ LEVEL, l *logger) {
+		l.layout = ly
+	})
+	return g
+}
+
+// SetLevelLineFormat sets text output layout per level
+func (g *Glg) SetLevelLineFormat(lv LEVEL, format string) *Glg {
+	ly := parseLayout(format)
+	g.updateLogger(lv, func(l *logger) {
+		l.layout = ly
+	})
+	return g
+}
@@ -0,0 +1,139 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_parseLayout(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		want   layout
+	}{
+		{
+			name:   "empty",
+			format: "",
+			want:   nil,
+		},
+		{
+			name:   "placeholders",
+			format: "{{time}} [{{level:-5}}] {{msg}}",
+			want: layout{
+				{kind: layoutTime},
+				{lit: " ["},
+				{kind: layoutLevel, width: -5},
+				{lit: "] "},
+				{kind: layoutMsg},
+			},
+		},
+		{
+			name:   "unknown placeholder",
+			format: "{{host}} {{msg}}",
+			want: layout{
+				{lit: "{{host}}"},
+				{lit: " "},
+				{kind: layoutMsg},
+			},
+		},
+		{
+			name:   "unclosed placeholder",
+			format: "{{msg",
+			want: layout{
+				{lit: "{{msg"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseLayout(tt.format)
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseLayout() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("parseLayout()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGlg_SetLineFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		level  LEVEL
+		val    []interface{}
+		want   string
+	}{
+		{
+			name:   "reorder",
+			format: "{{msg}} <{{level}}>",
+			level:  INFO,
+			val:    []interface{}{"hello"},
+			want:   "hello <INFO>\n",
+		},
+		{
+			name:   "padding",
+			format: "[{{level:-5}}]|{{level:5}}| {{msg}}",
+			level:  OK,
+			val:    []interface{}{"hello"},
+			want:   "[OK   ]|   OK| hello\n",
+		},
+		{
+			name:   "default",
+			format: "",
+			level:  INFO,
+			val:    []interface{}{"hello"},
+			want:   "[INFO]:\thello\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetLineFormat(tt.format)
+			err := g.out(tt.level, g.blankFormat(len(tt.val)), tt.val...)
+			if err != nil {
+				t.Error(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Glg.SetLineFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_SetLevelLineFormat(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().
+		SetLevelLineFormat(WARN, "{{level}}: {{msg}}")
+	g.Warn("warn")
+	g.Info("info")
+	want := "WARN: warn\n[INFO]:\tinfo\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Glg.SetLevelLineFormat() = %q, want %q", got, want)
+	}
+}
@@ -23,137 +23,42 @@
 // Package glg can quickly output that are colored and leveled logs with simple syntax
 package glg
 
-import (
-	"sync"
-	"sync/atomic"
-	"unsafe"
-)
+import "sync"
 
+// levelMap maps the upper case tags to the levels, registering many levels does not copy the map
 type levelMap struct {
-	mu     sync.Mutex
-	read   atomic.Value
-	dirty  map[string]*entryLevelMap
-	misses int
-}
-
-type readOnlyLevelMap struct {
-	m       map[string]*entryLevelMap
-	amended bool
-}
-
-var expungedLevelMap = unsafe.Pointer(new(LEVEL))
-
-type entryLevelMap struct {
-	p unsafe.Pointer
-}
-
-func newEntryLevelMap(i LEVEL) *entryLevelMap {
-	return &entryLevelMap{p: unsafe.Pointer(&i)}
+	m sync.Map // map[string]LEVEL
 }
 
 func (m *levelMap) Load(key string) (value LEVEL, ok bool) {
-	read, _ := m.read.Load().(readOnlyLevelMap)
-	e, ok := read.m[key]
-	if !ok && read.amended {
-		m.mu.Lock()
-		read, _ = m.read.Load().(readOnlyLevelMap)
-		e, ok = read.m[key]
-		if !ok && read.amended {
-			e, ok = m.dirty[key]
-			m.missLocked()
-		}
-		m.mu.Unlock()
-	}
+	v, ok := m.m.Load(key)
 	if !ok {
 		return value, false
 	}
-	return e.load()
-}
-
-func (e *entryLevelMap) load() (value LEVEL, ok bool) {
-	p := atomic.LoadPointer(&e.p)
-	if p == nil || p == expungedLevelMap {
-		return value, false
-	}
-	return *(*LEVEL)(p), true
+	return v.(LEVEL), true
 }
 
 func (m *levelMap) Store(key string, value LEVEL) {
-	read, _ := m.read.Load().(readOnlyLevelMap)
-	if e, ok := read.m[key]; ok && e.tryStore(&value) {
-		return
-	}
-
-	m.mu.Lock()
-	read, _ = m.read.Load().(readOnlyLevelMap)
-	if e, ok := read.m[key]; ok {
-		if e.unexpungeLocked() {
-			m.dirty[key] = e
-		}
-		e.storeLocked(&value)
-	} else if e, ok := m.dirty[key]; ok {
-		e.storeLocked(&value)
-	} else {
-		if !read.amended {
-			m.dirtyLocked()
-			m.read.Store(readOnlyLevelMap{m: read.m, amended: true})
-		}
-		m.dirty[key] = newEntryLevelMap(value)
-	}
-	m.mu.Unlock()
-}
-
-func (e *entryLevelMap) tryStore(i *LEVEL) bool {
-	for {
-		p := atomic.LoadPointer(&e.p)
-		if p == expungedLevelMap {
-			return false
-		}
-		if atomic.CompareAndSwapPointer(&e.p, p, unsafe.Pointer(i)) {
-			return true
-		}
-	}
-}
-
-func (e *entryLevelMap) unexpungeLocked() (wasExpunged bool) {
-	return atomic.CompareAndSwapPointer(&e.p, expungedLevelMap, nil)
+	m.m.Store(key, value)
 }
 
-func (e *entryLevelMap) storeLocked(i *LEVEL) {
-	atomic.StorePointer(&e.p, unsafe.Pointer(i))
+func (m *levelMap) Delete(key string) {
+	m.m.Delete(key)
 }
 
-func (m *levelMap) missLocked() {
-	m.misses++
-	if m.misses < len(m.dirty) {
-		return
-	}
-	m.read.Store(readOnlyLevelMap{m: m.dirty})
-	m.dirty = nil
-	m.misses = 0
-}
-
-func (m *levelMap) dirtyLocked() {
-	if m.dirty != nil {
-		return
-	}
-
-	read, _ := m.read.Load().(readOnlyLevelMap)
-	m.dirty = make(map[string]*entryLevelMap, len(read.m))
-	for k, e := range read.m {
-		if !e.tryExpungeLocked() {
-			m.dirty[k] = e
+// DeleteLevel removes every tag mapped to value including the aliases
+func (m *levelMap) DeleteLevel(value LEVEL) {
+	m.m.Range(func(k, v interface{}) bool {
+		if v.(LEVEL) == value {
+			m.m.Delete(k)
 		}
-	}
+		return true
+	})
 }
 
-func (e *entryLevelMap) tryExpungeLocked() (isExpunged bool) {
-	p := atomic.LoadPointer(&e.p)
-	for p == nil {
-		if atomic.CompareAndSwapPointer(&e.p, nil, expungedLevelMap) {
-			return true
-		}
-		p = atomic.LoadPointer(&e.p)
-	}
-	return p == expungedLevelMap
+// Range calls f for each tag and level
+func (m *levelMap) Range(f func(key string, value LEVEL) bool) {
+	m.m.Range(func(k, v interface{}) bool {
+		return f(k.(string), v.(LEVEL))
+	})
 }
@@ -0,0 +1,158 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGlg_AddStdLevel_Unbounded(t *testing.T) {
+	g := New().SetMode(WRITER).SetWriter(io.Discard)
+	levels := make(map[LEVEL]string)
+	for i := 0; i < 300; i++ {
+		tag := "Plugin" + strconv.Itoa(i)
+		g.AddStdLevel(tag, WRITER, false)
+		lv := g.TagStringToLevel(strings.ToLower(tag))
+		if lv <= FATAL || lv == UNKNOWN {
+			t.Fatalf("TagStringToLevel(%s) = %d", tag, lv)
+		}
+		if prev, ok := levels[lv]; ok {
+			t.Fatalf("TagStringToLevel(%s) = %d, same as %s", tag, lv, prev)
+		}
+		levels[lv] = tag
+	}
+	for lv, tag := range levels {
+		if got := g.TagStringToLevel(" " + tag + " "); got != lv {
+			t.Errorf("TagStringToLevel(%s) = %d, want %d", tag, got, lv)
+		}
+	}
+	if g.TagStringToLevel("info") != INFO || g.TagStringToLevel("none") != UNKNOWN {
+		t.Error("TagStringToLevel() built-in levels are broken")
+	}
+}
+
+func TestLevelMap(t *testing.T) {
+	var m levelMap
+	if _, ok := m.Load("A"); ok {
+		t.Error("levelMap.Load() of zero value found the key")
+	}
+	m.Store("A", 11)
+	m.Store("B", 12)
+	m.Delete("A")
+	m.Delete("C")
+	if _, ok := m.Load("A"); ok {
+		t.Error("levelMap.Load() found the deleted key")
+	}
+	if lv, ok := m.Load("B"); !ok || lv != 12 {
+		t.Errorf("levelMap.Load() = %d, %v, want 12", lv, ok)
+	}
+}
+
+func TestGlg_RemoveLevel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(io.Discard)
+	g.AddStdLevel("PLUGIN", WRITER, false).
+		SetLevelWriter(g.TagStringToLevel("PLUGIN"), buf).
+		SetLineTraceMode(TraceLineNone)
+	lv := g.TagStringToLevel("PLUGIN")
+	g.SetLevelString(lv, "PLG")
+	if err := g.CustomLog("plg", "loaded"); err != nil {
+		t.Fatalf("CustomLog() error = %v", err)
+	}
+
+	g.RemoveLevel("plg").RemoveLevel("missing").RemoveLevel("INFO")
+	if got := g.TagStringToLevel("PLG"); got != UNKNOWN {
+		t.Errorf("TagStringToLevel() = %d after RemoveLevel, want UNKNOWN", got)
+	}
+	if _, ok := g.levelMap.Load("PLUGIN"); ok {
+		t.Error("RemoveLevel() kept the original tag")
+	}
+	if _, ok := g.logger.Load(lv); ok {
+		t.Error("RemoveLevel() kept the logger")
+	}
+	if err := g.CustomLog("PLG", "unloaded"); err == nil {
+		t.Error("CustomLog() of removed level succeeded")
+	}
+	if _, ok := g.logger.Load(INFO); !ok {
+		t.Error("RemoveLevel() removed the built-in level")
+	}
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("removed level output = %q", buf.String())
+	}
+
+	g.AddStdLevel("PLG", WRITER, false)
+	if g.TagStringToLevel("PLG") == UNKNOWN {
+		t.Error("AddStdLevel() could not register the removed tag again")
+	}
+}
+
+func TestGlg_RenameLevel(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(io.Discard).
+		AddStdLevel("OLD", WRITER, false).
+		AddStdLevel("USED", WRITER, false)
+	lv := g.TagStringToLevel("OLD")
+	g.SetLevelWriter(lv, buf).SetLineTraceMode(TraceLineNone)
+
+	g.RenameLevel("OLD", "used").RenameLevel("INFO", "information")
+	if g.TagStringToLevel("OLD") != lv || g.TagStringToLevel("INFORMATION") != UNKNOWN {
+		t.Fatal("RenameLevel() renamed to the tag in use or the built-in level")
+	}
+
+	g.RenameLevel("old", "New")
+	if got := g.TagStringToLevel("NEW"); got != lv {
+		t.Errorf("TagStringToLevel(NEW) = %d, want %d", got, lv)
+	}
+	if got := g.TagStringToLevel("OLD"); got != UNKNOWN {
+		t.Errorf("TagStringToLevel(OLD) = %d, want UNKNOWN", got)
+	}
+	if err := g.CustomLog("NEW", "renamed"); err != nil {
+		t.Fatalf("CustomLog() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "[New]") {
+		t.Errorf("renamed level output = %q, want tag [New]", buf.String())
+	}
+}
+
+func TestGlg_RemoveLevel_Concurrent(t *testing.T) {
+	g := New().SetMode(WRITER).SetWriter(io.Discard)
+	for i := 0; i < 100; i++ {
+		tag := "PLUGIN" + strconv.Itoa(i)
+		g.AddStdLevel(tag, WRITER, false)
+		lv := g.TagStringToLevel(tag)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			g.DisableTimestamp()
+		}()
+		g.RemoveLevel(tag)
+		<-done
+		if _, ok := g.logger.Load(lv); ok {
+			t.Fatalf("RemoveLevel(%q) was revived by the concurrent update", tag)
+		}
+	}
+}
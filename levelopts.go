@@ -0,0 +1,137 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import "io"
+
+// LevelJSON selects the output format of the level
+type LevelJSON uint8
+
+const (
+	// LevelJSONDefault follows EnableJSON and DisableJSON of the instance
+	LevelJSONDefault LevelJSON = iota
+	// LevelJSONOn writes the level in JSON
+	LevelJSONOn
+	// LevelJSONOff writes the level in text
+	LevelJSONOff
+)
+
+// LevelOptions configures the custom level added by AddStdLevel or AddErrLevel in one call,
+// zero values keep the defaults
+type LevelOptions struct {
+	// Color colors the level, color output is enabled when it is set
+	Color func(string) string
+	// Writers are added to the level writers
+	Writers []io.Writer
+	// JSON selects the output format of the level
+	JSON LevelJSON
+	// DisableTimestamp disables the timestamp of the level
+	DisableTimestamp bool
+	// Prefix is the tag template of the level, see SetPrefix
+	Prefix string
+	// Rank is the level the custom level is filtered as by SetLevel, see SetLevelRank
+	Rank LEVEL
+}
+
+func (g *Glg) setLevelOptions(lv LEVEL, opt LevelOptions) {
+	if opt.Color != nil {
+		g.SetLevelColor(lv, opt.Color).EnableLevelColor(lv)
+	}
+	for _, w := range opt.Writers {
+		g.AddLevelWriter(lv, w)
+	}
+	if opt.JSON != LevelJSONDefault {
+		g.setLevelJSON(lv, opt.JSON)
+	}
+	if opt.DisableTimestamp {
+		g.DisableLevelTimestamp(lv)
+	}
+	if opt.Prefix != "" {
+		g.SetPrefix(lv, opt.Prefix)
+	}
+	if opt.Rank != 0 {
+		g.SetLevelRank(lv, opt.Rank)
+	}
+}
+
+// EnableLevelJSON writes the level in JSON regardless of EnableJSON
+func (g *Glg) EnableLevelJSON(lv LEVEL) *Glg {
+	return g.setLevelJSON(lv, LevelJSONOn)
+}
+
+// DisableLevelJSON writes the level in text regardless of EnableJSON
+func (g *Glg) DisableLevelJSON(lv LEVEL) *Glg {
+	return g.setLevelJSON(lv, LevelJSONOff)
+}
+
+func (g *Glg) setLevelJSON(lv LEVEL, mode LevelJSON) *Glg {
+	g.updateLogger(lv, func(l *logger) {
+		l.json = mode
+	})
+	return g
+}
+
+func (l *logger) isJSON(def bool) bool {
+	switch l.json {
+	case LevelJSONOn:
+		return true
+	case LevelJSONOff:
+		return false
+	}
+	return def
+}
+
+// levelJSON reports whether the entries of lv are written as JSON like output does
+func (g *Glg) levelJSON(lv LEVEL) bool {
+	l, ok := g.logger.Load(lv)
+	if !ok {
+		return g.opts().enableJSON
+	}
+	return l.isJSON(g.opts().enableJSON) && l.encoder == nil
+}
+
+// SetLevelRank makes SetLevel filter the custom level as rank,
+// e.g. SetLevelRank(notice, INFO) enables notice while INFO is enabled and disables it by SetLevel(WARN).
+// The rank of the custom level is inherited, built-in levels keep their own rank
+func (g *Glg) SetLevelRank(lv, rank LEVEL) *Glg {
+	if lv <= FATAL || rank == lv {
+		return g
+	}
+	if r, ok := g.logger.Load(rank); ok {
+		rank = r.rankOf(rank)
+	}
+	g.updateLogger(lv, func(l *logger) {
+		l.rank = rank
+		l.routes = g.levelRoutes(l.rankOf(lv))
+	})
+	return g
+}
+
+// rankOf returns the rank of the logger of lv
+func (l *logger) rankOf(lv LEVEL) LEVEL {
+	if l.rank != 0 {
+		return l.rank
+	}
+	return lv
+}
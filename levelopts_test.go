@@ -0,0 +1,122 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestGlg_AddStdLevel_Options(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().AddStdLevel("CRIT", WRITER, false, LevelOptions{
+		Color:            Red,
+		Writers:          []io.Writer{buf},
+		JSON:             LevelJSONOn,
+		DisableTimestamp: true,
+		Prefix:           "CRITICAL",
+	})
+	lv := g.TagStringToLevel("CRIT")
+	if err := g.CustomLog("CRIT", "disk full", F("free", 0)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), `{"level":"CRITICAL","detail":"disk full","fields":{"free":0}}`+"\n"; got != want {
+		t.Errorf("AddStdLevel() = %q, want %q", got, want)
+	}
+	l, _ := g.logger.Load(lv)
+	if !l.isColor || l.color("x") != Red("x") {
+		t.Error("AddStdLevel() color is not set")
+	}
+}
+
+func TestGlg_DisableLevelJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().EnableJSON().DisableLevelJSON(WARN)
+	g.Warn("slow", 3, F("path", "/a"))
+	g.Info("ok")
+	want := "[WARN]:\tslow 3\tpath=/a\n" + `{"level":"INFO","detail":"ok"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("DisableLevelJSON() = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	g.DisableJSON().EnableLevelJSON(INFO)
+	g.Info("ok")
+	if got, want := buf.String(), `{"level":"INFO","detail":"ok"}`+"\n"; got != want {
+		t.Errorf("EnableLevelJSON() = %q, want %q", got, want)
+	}
+}
+
+func TestGlg_SetLevelRank(t *testing.T) {
+	g := New().SetMode(WRITER).SetWriter(io.Discard).
+		AddStdLevel("NOTICE", WRITER, false, LevelOptions{Rank: INFO}).
+		AddStdLevel("AUDIT", WRITER, false).
+		AddStdLevel("SECURITY", WRITER, false)
+	notice := g.TagStringToLevel("NOTICE")
+	audit := g.TagStringToLevel("AUDIT")
+	security := g.TagStringToLevel("SECURITY")
+	g.SetLevelRank(security, notice).SetLevelRank(INFO, FATAL)
+
+	tests := []struct {
+		name  string
+		level LEVEL
+		want  map[LEVEL]bool
+	}{
+		{
+			name:  "INFO enables ranked custom level",
+			level: INFO,
+			want:  map[LEVEL]bool{DEBG: false, INFO: true, notice: true, security: true, WARN: true, audit: true},
+		},
+		{
+			name:  "WARN disables level ranked as INFO",
+			level: WARN,
+			want:  map[LEVEL]bool{INFO: false, notice: false, security: false, WARN: true, audit: true},
+		},
+		{
+			name:  "custom level is filtered by its rank",
+			level: notice,
+			want:  map[LEVEL]bool{LOG: false, INFO: true, notice: true, security: true, ERR: true, audit: true},
+		},
+		{
+			name:  "unranked custom level is above FATAL",
+			level: audit,
+			want:  map[LEVEL]bool{FATAL: false, notice: false, audit: true},
+		},
+		{
+			name:  "DEBG enables all",
+			level: DEBG,
+			want:  map[LEVEL]bool{DEBG: true, notice: true, security: true, audit: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g.SetLevel(tt.level)
+			for lv, want := range tt.want {
+				if got := g.isModeEnable(lv); got != want {
+					t.Errorf("isModeEnable(%d) = %v, want %v", lv, got, want)
+				}
+			}
+		})
+	}
+}
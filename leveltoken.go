@@ -0,0 +1,95 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import "strings"
+
+// DefaultLevelTokenKey is the key of the level token recognized by the common log parsers such as Loki and Grafana
+const DefaultLevelTokenKey = "level"
+
+// SetLevelToken starts the text entries with the key=severity token followed by a tab, e.g. SetLevelToken("level") writes
+//
+//	level=warn	2026-01-02 15:04:05	[WARN]:	disk is almost full
+//
+// so the parsers of the container logs written by the Docker json-file driver detect the severity without the glg format.
+// The severities are debug, trace, info, warn, error, critical and fatal, the custom levels follow their ranks
+// and the unranked ones are their tags in lower case. The JSON entries have the level field already,
+// the empty key disables the token
+func (g *Glg) SetLevelToken(key string) *Glg {
+	g.setSwitches(func(s *switches) {
+		s.levelToken = key
+	})
+	return g
+}
+
+// SetLevelToken starts the text entries of the global instance with the key=severity token
+func SetLevelToken(key string) *Glg {
+	return Get().SetLevelToken(key)
+}
+
+// levelSeverity returns the severity of the level token
+func levelSeverity(rank LEVEL, tag string) string {
+	switch rank {
+	case DEBG:
+		return "debug"
+	case TRACE:
+		return "trace"
+	case PRINT, LOG, INFO, OK:
+		return "info"
+	case WARN:
+		return "warn"
+	case ERR:
+		return "error"
+	case FAIL:
+		return "critical"
+	case FATAL:
+		return "fatal"
+	}
+	return strings.ToLower(tag)
+}
+
+// trimLevelToken removes the leading key=severity token of the text entry written by SetLevelToken
+func trimLevelToken(line string) string {
+	i := strings.Index(line, tab)
+	if i <= 0 {
+		return line
+	}
+	key, val, ok := strings.Cut(line[:i], "=")
+	if !ok || !isTokenWord(key) || !isTokenWord(val) {
+		return line
+	}
+	return line[i+len(tab):]
+}
+
+func isTokenWord(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range []byte(s) {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestGlg_SetLevelToken(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone).DisableTimestamp().
+		AddStdLevel("NOTICE", WRITER, false, LevelOptions{Rank: OK, DisableTimestamp: true}).
+		AddStdLevel("AUDIT", WRITER, false, LevelOptions{DisableTimestamp: true}).
+		SetLevelToken(DefaultLevelTokenKey)
+	g.SetWriter(buf)
+
+	g.Debug("debug")
+	g.Info("info")
+	g.Warn("warn", String("k", "v"))
+	g.Error("error")
+	g.Fail("fail")
+	g.CustomLog("NOTICE", "notice")
+	g.CustomLog("AUDIT", "audit")
+	g.SetLevelToken("severity").SetLevelLineFormat(INFO, "{{level}} {{msg}}").Info("layout")
+	g.SetLevelToken("").Warn("plain")
+
+	want := "level=debug\t[DEBG]:\tdebug\nlevel=info\t[INFO]:\tinfo\nlevel=warn\t[WARN]:\twarn\tk=v\nlevel=error\t[ERR]:\terror\n" +
+		"level=critical\t[FAIL]:\tfail\nlevel=info\t[NOTICE]:\tnotice\nlevel=audit\t[AUDIT]:\taudit\nseverity=info\tINFO layout\n[WARN]:\tplain\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestReader_LevelToken(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone).SetLevelToken("level")
+	g.Warn("first", Int("n", 1))
+	g.Info("second\ncontinued")
+	r := NewReader(buf)
+	e, err := r.Read()
+	if err != nil || e.Level != WARN || e.Message != "first" || e.Time.IsZero() || len(e.Fields) != 1 {
+		t.Errorf("Read() = %+v, %v", e, err)
+	}
+	e, err = r.Read()
+	if err != nil || e.Level != INFO || e.Message != "second\ncontinued" {
+		t.Errorf("Read() = %+v, %v", e, err)
+	}
+	if _, err = r.Read(); err != io.EOF {
+		t.Errorf("Read() error = %v, want EOF", err)
+	}
+}
@@ -123,6 +123,35 @@ func (e *entryLoggers) storeLocked(i **logger) {
 	atomic.StorePointer(&e.p, unsafe.Pointer(i))
 }
 
+func (m *loggers) Delete(key LEVEL) {
+	read, _ := m.read.Load().(readOnlyLoggers)
+	e, ok := read.m[key]
+	if !ok && read.amended {
+		m.mu.Lock()
+		read, _ = m.read.Load().(readOnlyLoggers)
+		e, ok = read.m[key]
+		if !ok && read.amended {
+			delete(m.dirty, key)
+		}
+		m.mu.Unlock()
+	}
+	if ok {
+		e.delete()
+	}
+}
+
+func (e *entryLoggers) delete() (hadValue bool) {
+	for {
+		p := atomic.LoadPointer(&e.p)
+		if p == nil || p == expungedLoggers {
+			return false
+		}
+		if atomic.CompareAndSwapPointer(&e.p, p, nil) {
+			return true
+		}
+	}
+}
+
 func (m *loggers) Range(f func(key LEVEL, value *logger) bool) {
 	read, _ := m.read.Load().(readOnlyLoggers)
 	if read.amended {
@@ -182,3 +211,44 @@ func (e *entryLoggers) tryExpungeLocked() (isExpunged bool) {
 	}
 	return p == expungedLoggers
 }
+
+// updateLogger replaces the logger of lv by the copy modified by fn, it returns false when lv is not found.
+// The entries being written keep the logger they loaded, so they never see a partially applied configuration
+func (g *Glg) updateLogger(lv LEVEL, fn func(l *logger)) bool {
+	g.configMu.Lock()
+	defer g.configMu.Unlock()
+	l, ok := g.logger.Load(lv)
+	if !ok {
+		return false
+	}
+	cl := *l
+	fn(&cl)
+	g.logger.Store(lv, &cl)
+	return true
+}
+
+// updateLoggers replaces every logger by the copy modified by fn
+func (g *Glg) updateLoggers(fn func(lv LEVEL, l *logger)) {
+	g.configMu.Lock()
+	defer g.configMu.Unlock()
+	g.logger.Range(func(lv LEVEL, l *logger) bool {
+		cl := *l
+		fn(lv, &cl)
+		g.logger.Store(lv, &cl)
+		return true
+	})
+}
+
+// opts returns the instance-wide switches, the entry loads them once to be written with one consistent set
+func (c *core) opts() *switches {
+	return c.switches.Load()
+}
+
+// setSwitches replaces the instance-wide switches by the copy modified by fn
+func (g *Glg) setSwitches(fn func(s *switches)) {
+	g.configMu.Lock()
+	defer g.configMu.Unlock()
+	s := *g.switches.Load()
+	fn(&s)
+	g.switches.Store(&s)
+}
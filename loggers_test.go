@@ -0,0 +1,150 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	json "github.com/goccy/go-json"
+)
+
+type lockedBuffer struct {
+	mu sync.Mutex
+	bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.Buffer.Write(p)
+}
+
+func TestGlg_updateLoggers_Concurrent(t *testing.T) {
+	a, b := new(lockedBuffer), new(lockedBuffer)
+	g := New().SetMode(WRITER).SetWriter(a).SetLineTraceMode(TraceLineNone)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					g.Info("entry")
+				}
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		if i%2 == 0 {
+			g.SetWriter(b).DisableTimestamp().SetLevelString(INFO, "NOTICE")
+		} else {
+			g.SetWriter(a).EnableTimestamp().SetLevelString(INFO, "INFO")
+		}
+		g.SetLevelMode(INFO, WRITER).EnableLevelColor(INFO).DisableLevelColor(INFO)
+	}
+	close(stop)
+	wg.Wait()
+
+	for name, buf := range map[string]*lockedBuffer{"a": a, "b": b} {
+		for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+			if line == "" {
+				continue
+			}
+			if !strings.HasSuffix(line, "]:\tentry") || strings.Contains(line, "\033[") {
+				t.Fatalf("writer %s line = %q", name, line)
+			}
+		}
+	}
+}
+
+func TestGlg_setSwitches_Concurrent(t *testing.T) {
+	buf := new(lockedBuffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					g.Infof("entry %s", "x")
+					g.blankFormat(1)
+				}
+			}
+		}()
+	}
+	for i := 0; i < 200; i++ {
+		if i%2 == 0 {
+			g.EnableJSON().EnableUTC().EnableEpochMillis().EnableStrictFormat().EnableStdPriority().
+				EnableSanitize().EnableHumanReadable().SetLevelToken("severity").SetMaxMessageSize(64).
+				SetResource(map[string]string{ResourceServiceName: "svc"}).SetStdFlushInterval(time.Millisecond)
+		} else {
+			g.DisableJSON().DisableUTC().DisableEpochMillis().DisableStrictFormat().DisableStdPriority().
+				DisableSanitize().DisableHumanReadable().SetLevelToken("").SetMaxMessageSize(0).
+				SetResource(nil).SetStdFlushInterval(0)
+		}
+		g.SetCallerDepth(DefaultCallerDepth + i%2).SetMultiLineMode(MultiLineFold)
+	}
+	close(stop)
+	wg.Wait()
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "{") {
+			if !json.Valid([]byte(line)) || !strings.Contains(line, `"detail":"entry x"`) {
+				t.Fatalf("json line = %q", line)
+			}
+		} else if !strings.HasSuffix(line, "]:\tentry x") {
+			t.Fatalf("text line = %q", line)
+		}
+	}
+}
+
+func TestGlg_updateLogger(t *testing.T) {
+	g := New()
+	before, _ := g.logger.Load(WARN)
+	if !g.updateLogger(WARN, func(l *logger) { l.isColor = false }) {
+		t.Fatal("updateLogger() of WARN = false")
+	}
+	after, _ := g.logger.Load(WARN)
+	if before == after || !before.isColor || after.isColor {
+		t.Error("updateLogger() modified the logger in place")
+	}
+	if g.updateLogger(UNKNOWN, func(*logger) { t.Error("updateLogger() called fn for the unknown level") }) {
+		t.Error("updateLogger() of UNKNOWN = true")
+	}
+}
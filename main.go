@@ -0,0 +1,151 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// DefaultMainTimeout is the time Main waits for Shutdown writing the pending entries before the exit
+const DefaultMainTimeout = 10 * time.Second
+
+// ExitCoder is the error deciding the exit status of Main, e.g. *exec.ExitError
+type ExitCoder interface {
+	ExitCode() int
+}
+
+// errPanicked is returned by the run function of Main which panicked
+var errPanicked = errors.New("error:\tpanicked")
+
+// Main runs the main function of the command and exits, it never returns.
+// The error returned by run is logged at FATAL with the OnFatal hooks and exits with the status of ExitCoder in the chain,
+// 1 by default. SIGINT and SIGTERM exit with 128 + the signal number, the panic of run is logged by CapturePanics and exits with 2.
+// The pending entries are written and the writers are closed by Shutdown before the exit, e.g.
+//
+//	func main() {
+//		glg.Main(run)
+//	}
+func (g *Glg) Main(run func() error) {
+	g.runMain(func(context.Context) error { return run() }, false)
+}
+
+// MainContext runs the main function of the command like Main, the first SIGINT or SIGTERM cancels ctx and waits for run,
+// the second one exits immediately. run returning an error of the canceled ctx exits with 128 + the signal number
+func (g *Glg) MainContext(run func(ctx context.Context) error) {
+	g.runMain(run, true)
+}
+
+// Main runs the main function of the command with the global instance and exits
+func Main(run func() error) {
+	Get().Main(run)
+}
+
+// MainContext runs the main function of the command canceled by the signals with the global instance and exits
+func MainContext(run func(ctx context.Context) error) {
+	Get().MainContext(run)
+}
+
+func (g *Glg) runMain(run func(context.Context) error, graceful bool) {
+	atomic.StoreInt32(&g.inMain, 1)
+	defer atomic.StoreInt32(&g.inMain, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				g.handlePanic(r)
+				done <- errPanicked
+			}
+		}()
+		done <- run(ctx)
+	}()
+
+	var sig os.Signal
+	for sig == nil || graceful {
+		select {
+		case err := <-done:
+			g.exitMain(g.mainCode(err, sig))
+			return
+		case s := <-sigs:
+			if sig != nil {
+				graceful = false
+				break
+			}
+			sig = s
+			if graceful {
+				g.Warn("shutting down", String("signal", s.String()))
+				cancel()
+			}
+		}
+	}
+	g.Warn("exiting", String("signal", sig.String()))
+	g.exitMain(signalCode(sig))
+}
+
+// mainCode logs err returned by the run function of Main and returns the exit status
+func (g *Glg) mainCode(err error, sig os.Signal) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errPanicked):
+		return 2
+	case sig != nil && errors.Is(err, context.Canceled):
+		return signalCode(sig)
+	}
+	g.out(FATAL, g.blankFormat(1), err)
+	g.runFatalHooks(shortCaller(3), g.blankFormat(1), []interface{}{err})
+	var ec ExitCoder
+	if errors.As(err, &ec) && ec.ExitCode() > 0 {
+		return ec.ExitCode()
+	}
+	return 1
+}
+
+// exitMain logs the exit entry of LogSignals, writes the pending entries, closes the writers and exits with code
+func (g *Glg) exitMain(code int) {
+	g.logExit(code)
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultMainTimeout)
+	g.Shutdown(ctx)
+	cancel()
+	exit(code)
+}
+
+// signalCode returns the exit status of the process terminated by sig
+func signalCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
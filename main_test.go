@@ -0,0 +1,126 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+type exitCodeError int
+
+func (e exitCodeError) Error() string { return fmt.Sprintf("exit status %d", int(e)) }
+func (e exitCodeError) ExitCode() int { return int(e) }
+
+func TestGlg_Main(t *testing.T) {
+	defer ReplaceExitFunc(exit)
+	ReplaceExitFunc(func(n int) {
+		panic(ExitError(n))
+	})
+	tests := []struct {
+		name string
+		run  func() error
+		code int
+		want string
+	}{
+		{
+			name: "success",
+			run:  func() error { return nil },
+		},
+		{
+			name: "error",
+			run:  func() error { return errors.New("config not found") },
+			code: 1,
+			want: "[FATAL]",
+		},
+		{
+			name: "exit code",
+			run:  func() error { return fmt.Errorf("lint: %w", exitCodeError(3)) },
+			code: 3,
+			want: "lint: exit status 3",
+		},
+		{
+			name: "panic",
+			run:  func() error { panic("broken") },
+			code: 2,
+			want: "broken",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(closeBuffer)
+			var hooked bool
+			g := New().SetMode(WRITER).SetWriter(buf).OnFatal(func(Entry) { hooked = true })
+			if err := testExit(tt.code, func() { g.Main(tt.run) }); err != nil {
+				t.Fatal(err)
+			}
+			if buf.closed != 1 {
+				t.Error("Main() did not close the writer")
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+			if hooked != (tt.name == "error" || tt.name == "exit code") {
+				t.Errorf("fatal hooks ran = %v", hooked)
+			}
+		})
+	}
+}
+
+func TestGlg_MainContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals are not sent on windows")
+	}
+	defer ReplaceExitFunc(exit)
+	ReplaceExitFunc(func(n int) {
+		panic(ExitError(n))
+	})
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf)
+	err := testExit(128+int(syscall.SIGTERM), func() {
+		g.MainContext(func(ctx context.Context) error {
+			p, err := os.FindProcess(os.Getpid())
+			if err == nil {
+				err = p.Signal(syscall.SIGTERM)
+			}
+			if err != nil {
+				return err
+			}
+			<-ctx.Done()
+			return fmt.Errorf("stopped: %w", ctx.Err())
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); !strings.Contains(got, "shutting down") || strings.Contains(got, "[FATAL]") {
+		t.Errorf("output = %q", got)
+	}
+}
@@ -0,0 +1,367 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	json "github.com/goccy/go-json"
+)
+
+// LogValuer is implemented by the types which control their logged representation,
+// e.g. to hide secrets. LogValue is called only when the entry is written
+type LogValuer interface {
+	LogValue() interface{}
+}
+
+// maxLogValueDepth limits LogValue and nested struct resolution to avoid infinite recursion
+const maxLogValueDepth = 8
+
+type structField struct {
+	index     int
+	name      string
+	omitempty bool
+	secret    bool
+}
+
+// structInfo is cached marshaling rule of the struct type
+type structInfo struct {
+	tagged bool
+	fields []structField
+}
+
+var (
+	structInfos sync.Map
+	// taggedTypes caches the tagKind of the types
+	taggedTypes sync.Map
+
+	ifaceType     = reflect.TypeOf((*interface{})(nil)).Elem()
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textType      = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	valuerType    = reflect.TypeOf((*LogValuer)(nil)).Elem()
+)
+
+// structValue is the struct resolved by glg tags
+type structValue []Field
+
+// MarshalJSON implements json.Marshaler
+func (s structValue) MarshalJSON() ([]byte, error) {
+	return jsonFields{fields: s}.MarshalJSON()
+}
+
+// Format implements fmt.Formatter
+func (s structValue) Format(f fmt.State, verb rune) {
+	b := new(bytes.Buffer)
+	b.WriteByte('{')
+	writeFields(b, s, 0, false)
+	b.WriteByte('}')
+	f.Write(b.Bytes())
+}
+
+func getStructInfo(t reflect.Type) *structInfo {
+	if si, ok := structInfos.Load(t); ok {
+		return si.(*structInfo)
+	}
+	si := new(structInfo)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		tag, ok := sf.Tag.Lookup("glg")
+		if ok {
+			si.tagged = true
+		} else {
+			tag = sf.Tag.Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+		f := structField{
+			index: i,
+			name:  sf.Name,
+		}
+		opts := strings.Split(tag, ",")
+		if ok && isTagOption(opts[0]) {
+			// the bare `glg:"secret"` is the option of the field keeping its name, not the name "secret"
+			opts = append([]string{""}, opts...)
+		}
+		if opts[0] != "" {
+			f.name = opts[0]
+		}
+		for _, opt := range opts[1:] {
+			switch strings.TrimSpace(opt) {
+			case "omitempty":
+				f.omitempty = true
+			case "secret":
+				f.secret = true
+			}
+		}
+		si.fields = append(si.fields, f)
+	}
+	act, _ := structInfos.LoadOrStore(t, si)
+	return act.(*structInfo)
+}
+
+// isTagOption reports the first element of the glg tag is the option rather than the field name
+func isTagOption(name string) bool {
+	switch strings.TrimSpace(name) {
+	case "secret", "omitempty":
+		return true
+	}
+	return false
+}
+
+// kinds of the types containing the structs tagged by glg
+const (
+	// tagStatic is the struct tagged by glg or the type containing it by the fields, the elements or the map values
+	tagStatic uint8 = 1 << iota
+	// tagDynamic is the type containing the interfaces whose dynamic values may be the tagged structs
+	tagDynamic
+)
+
+// tagKind returns the tagStatic and tagDynamic bits of t, so the secrets of the nested structs are masked as well
+func tagKind(t reflect.Type) uint8 {
+	if k, ok := taggedTypes.Load(t); ok {
+		return k.(uint8)
+	}
+	k := findTagged(t, make(map[reflect.Type]bool))
+	taggedTypes.Store(t, k)
+	return k
+}
+
+func findTagged(t reflect.Type, seen map[reflect.Type]bool) (k uint8) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if seen[t] {
+		return 0
+	}
+	seen[t] = true
+	// the types controlling their representation are written as they are
+	for _, it := range []reflect.Type{marshalerType, textType, valuerType} {
+		if t.Implements(it) || reflect.PtrTo(t).Implements(it) {
+			return 0
+		}
+	}
+	switch t.Kind() {
+	case reflect.Interface:
+		return tagDynamic
+	case reflect.Struct:
+		si := getStructInfo(t)
+		if si.tagged {
+			k = tagStatic
+		}
+		for _, f := range si.fields {
+			k |= findTagged(t.Field(f.index).Type, seen)
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return findTagged(t.Elem(), seen)
+	}
+	return k
+}
+
+// resolve applies LogValuer, glg struct tags and, for JSON, error and fmt.Stringer rules to v.
+// ok is false when v is returned as it is
+func resolve(v interface{}, isJSON bool, depth int) (rv interface{}, ok bool) {
+	for ; depth < maxLogValueDepth; depth++ {
+		lv, isValuer := v.(LogValuer)
+		if !isValuer {
+			break
+		}
+		v, ok = lv.LogValue(), true
+	}
+	switch t := v.(type) {
+	case nil, string, []byte, bool, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, float32, float64, Lazy, Field, rawMessage:
+		return v, ok
+	case error:
+		if isJSON {
+			return t.Error(), true
+		}
+		return v, ok
+	case json.Marshaler, encoding.TextMarshaler:
+		return v, ok
+	}
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return v, ok
+		}
+		val = val.Elem()
+	}
+	if depth < maxLogValueDepth {
+		// the containers of the interfaces are copied only when their dynamic values are resolved
+		if tk := tagKind(val.Type()); val.Kind() == reflect.Struct {
+			si := getStructInfo(val.Type())
+			if sv, changed := resolveStruct(val, si, isJSON, depth, si.tagged || tk&tagStatic != 0); changed {
+				return sv, true
+			}
+		} else if tk != 0 {
+			if ev, changed := resolveElems(val, isJSON, depth, tk&tagStatic != 0); changed {
+				return ev, true
+			}
+		}
+	}
+	if s, isStringer := v.(fmt.Stringer); isStringer && isJSON && val.Kind() == reflect.Struct {
+		return s.String(), true
+	}
+	return v, ok
+}
+
+// resolveStruct resolves the fields of the struct by the glg tags, changed is false when neither force is set
+// nor any field is resolved
+func resolveStruct(val reflect.Value, si *structInfo, isJSON bool, depth int, force bool) (sv structValue, changed bool) {
+	if !force && tagKind(val.Type())&tagDynamic == 0 {
+		return nil, false
+	}
+	for i, f := range si.fields {
+		fv := val.Field(f.index)
+		var fi interface{}
+		var ok bool
+		if f.secret {
+			fi, ok = SecretMask, true
+		} else {
+			fi, ok = resolve(fv.Interface(), isJSON, depth+1)
+		}
+		if sv == nil {
+			if !ok && !force {
+				continue
+			}
+			// the fields before the first resolved one are written as they are
+			sv = make(structValue, 0, len(si.fields))
+			for _, pf := range si.fields[:i] {
+				if pv := val.Field(pf.index); !pf.omitempty || !pv.IsZero() {
+					sv = append(sv, F(pf.name, pv.Interface()))
+				}
+			}
+		}
+		if f.omitempty && fv.IsZero() {
+			continue
+		}
+		if f.secret {
+			sv = append(sv, String(f.name, SecretMask))
+			continue
+		}
+		sv = append(sv, F(f.name, fi))
+	}
+	if sv == nil && force {
+		sv = structValue{}
+	}
+	return sv, sv != nil
+}
+
+// resolveElems resolves the elements of the slice or the array and the values of the map containing the tagged structs,
+// changed is false when neither force is set nor any element is resolved
+func resolveElems(val reflect.Value, isJSON bool, depth int, force bool) (rv interface{}, changed bool) {
+	switch val.Kind() {
+	case reflect.Map:
+		if val.IsNil() {
+			return val.Interface(), force
+		}
+		var (
+			m          reflect.Value
+			first      interface{}
+			firstValue interface{}
+		)
+		iter := val.MapRange()
+		for iter.Next() {
+			ev, ok := resolve(iter.Value().Interface(), isJSON, depth+1)
+			if !ok && !force {
+				continue
+			}
+			first, firstValue = iter.Key().Interface(), ev
+			m = reflect.MakeMapWithSize(reflect.MapOf(val.Type().Key(), ifaceType), val.Len())
+			break
+		}
+		if !m.IsValid() {
+			return val.Interface(), false
+		}
+		// the values before the first resolved one were returned as they are, resolving them again has no effect
+		for iter = val.MapRange(); iter.Next(); {
+			var ev interface{}
+			if k := iter.Key(); k.Interface() == first {
+				ev = firstValue
+			} else {
+				ev, _ = resolve(iter.Value().Interface(), isJSON, depth+1)
+			}
+			if ev == nil {
+				m.SetMapIndex(iter.Key(), reflect.Zero(ifaceType))
+				continue
+			}
+			m.SetMapIndex(iter.Key(), reflect.ValueOf(ev))
+		}
+		return m.Interface(), true
+	case reflect.Slice:
+		if val.IsNil() {
+			return val.Interface(), force
+		}
+	}
+	var vals []interface{}
+	for i := 0; i < val.Len(); i++ {
+		ev, ok := resolve(val.Index(i).Interface(), isJSON, depth+1)
+		if vals == nil {
+			if !ok && !force {
+				continue
+			}
+			vals = make([]interface{}, val.Len())
+			for j := 0; j < i; j++ {
+				vals[j] = val.Index(j).Interface()
+			}
+		}
+		vals[i] = ev
+	}
+	if vals == nil {
+		if !force {
+			return val.Interface(), false
+		}
+		vals = []interface{}{}
+	}
+	return vals, true
+}
+
+// resolveArgs resolves message values, it returns val itself when nothing is changed
+func resolveArgs(val []interface{}, isJSON bool) []interface{} {
+	var vals []interface{}
+	for i, v := range val {
+		rv, ok := resolve(v, isJSON, 0)
+		if vals == nil {
+			if !ok {
+				continue
+			}
+			vals = make([]interface{}, len(val))
+			copy(vals, val[:i])
+		}
+		vals[i] = rv
+	}
+	if vals == nil {
+		return val
+	}
+	return vals
+}
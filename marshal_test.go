@@ -0,0 +1,252 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type marshalUser struct {
+	Name     string `glg:"name"`
+	Email    string `glg:"email,omitempty"`
+	Password string `glg:"-"`
+	Addr     *marshalAddr
+}
+
+type marshalAddr struct {
+	City string `json:"city"`
+	Zip  string `glg:"zip,omitempty"`
+}
+
+type marshalPlain struct {
+	Name string `json:"name"`
+}
+
+type marshalCred struct {
+	User string `glg:"user"`
+	Pass string `glg:"pass,secret"`
+}
+
+type marshalBare struct {
+	User string
+	Pass string `glg:"secret"`
+	Note string `glg:"omitempty"`
+}
+
+type marshalHolder struct {
+	Name string
+	Any  interface{}
+}
+
+type marshalOuter struct {
+	Name  string
+	Inner marshalCred
+}
+
+type marshalToken string
+
+func (t marshalToken) LogValue() interface{} {
+	if len(t) < 4 {
+		return "***"
+	}
+	return string(t[:4]) + "***"
+}
+
+func TestResolve(t *testing.T) {
+	user := marshalUser{
+		Name:     "bob",
+		Password: "secret",
+		Addr:     &marshalAddr{City: "tokyo"},
+	}
+	tests := []struct {
+		name string
+		json bool
+		val  []interface{}
+		want string
+	}{
+		{
+			name: "glg tags",
+			val:  []interface{}{user},
+			want: "[INFO]:\t{name=bob Addr=\"{city=tokyo}\"}\n",
+		},
+		{
+			name: "glg tags pointer",
+			val:  []interface{}{&user},
+			want: "[INFO]:\t{name=bob Addr=\"{city=tokyo}\"}\n",
+		},
+		{
+			name: "untagged struct",
+			val:  []interface{}{marshalPlain{Name: "bob"}},
+			want: "[INFO]:\t{bob}\n",
+		},
+		{
+			name: "log valuer",
+			val:  []interface{}{marshalToken("abcdefgh")},
+			want: "[INFO]:\tabcd***\n",
+		},
+		{
+			name: "log valuer field",
+			val:  []interface{}{"login", F("token", marshalToken("abcdefgh"))},
+			want: "[INFO]:\tlogin\ttoken=abcd***\n",
+		},
+		{
+			name: "json glg tags",
+			json: true,
+			val:  []interface{}{user},
+			want: `{"level":"INFO","detail":{"name":"bob","Addr":{"city":"tokyo"}}}` + "\n",
+		},
+		{
+			name: "json error",
+			json: true,
+			val:  []interface{}{errors.New("failed")},
+			want: `{"level":"INFO","detail":"failed"}` + "\n",
+		},
+		{
+			name: "json field",
+			json: true,
+			val:  []interface{}{"login", F("user", &user), F("token", marshalToken("ab"))},
+			want: `{"level":"INFO","detail":"login","fields":{"user":{"name":"bob","Addr":{"city":"tokyo"}},"token":"***"}}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp()
+			if tt.json {
+				g.EnableJSON()
+			}
+			if err := g.Info(tt.val...); err != nil {
+				t.Error(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Glg.Info() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolve_Nested(t *testing.T) {
+	cred := marshalCred{User: "u", Pass: "hunter2"}
+	tests := []struct {
+		name string
+		val  interface{}
+		text string
+		json string
+	}{
+		{
+			name: "struct",
+			val:  marshalOuter{Name: "x", Inner: cred},
+			text: "[INFO]:\t{Name=x Inner=\"{user=u pass=***}\"}\n",
+			json: `{"level":"INFO","detail":{"Name":"x","Inner":{"user":"u","pass":"***"}}}` + "\n",
+		},
+		{
+			name: "slice",
+			val:  []marshalCred{cred},
+			text: "[INFO]:\t[{user=u pass=***}]\n",
+			json: `{"level":"INFO","detail":[{"user":"u","pass":"***"}]}` + "\n",
+		},
+		{
+			name: "array of pointers",
+			val:  [2]*marshalCred{&cred, nil},
+			text: "[INFO]:\t[{user=u pass=***} <nil>]\n",
+			json: `{"level":"INFO","detail":[{"user":"u","pass":"***"},null]}` + "\n",
+		},
+		{
+			name: "map",
+			val:  map[string]marshalCred{"a": cred},
+			text: "[INFO]:\tmap[a:{user=u pass=***}]\n",
+			json: `{"level":"INFO","detail":{"a":{"user":"u","pass":"***"}}}` + "\n",
+		},
+		{
+			name: "bare secret tag",
+			val:  marshalBare{User: "u", Pass: "hunter2"},
+			text: "[INFO]:\t{User=u Pass=***}\n",
+			json: `{"level":"INFO","detail":{"User":"u","Pass":"***"}}` + "\n",
+		},
+		{
+			name: "interface slice",
+			val:  []interface{}{cred, 1},
+			text: "[INFO]:\t[{user=u pass=***} 1]\n",
+			json: `{"level":"INFO","detail":[{"user":"u","pass":"***"},1]}` + "\n",
+		},
+		{
+			name: "interface map",
+			val:  map[string]interface{}{"c": &cred},
+			text: "[INFO]:\tmap[c:{user=u pass=***}]\n",
+			json: `{"level":"INFO","detail":{"c":{"user":"u","pass":"***"}}}` + "\n",
+		},
+		{
+			name: "interface field",
+			val:  marshalHolder{Name: "x", Any: cred},
+			text: "[INFO]:\t{Name=x Any=\"{user=u pass=***}\"}\n",
+			json: `{"level":"INFO","detail":{"Name":"x","Any":{"user":"u","pass":"***"}}}` + "\n",
+		},
+		{
+			name: "interface map without tagged values",
+			val:  map[string]interface{}{"a": 1},
+			text: "[INFO]:\tmap[a:1]\n",
+			json: `{"level":"INFO","detail":{"a":1}}` + "\n",
+		},
+		{
+			name: "interface field without tagged values",
+			val:  marshalHolder{Name: "x", Any: 1},
+			text: "[INFO]:\t{x 1}\n",
+			json: `{"level":"INFO","detail":{"Name":"x","Any":1}}` + "\n",
+		},
+		{
+			name: "map of slices in struct",
+			val:  struct{ Creds map[string][]marshalCred }{Creds: map[string][]marshalCred{"a": {cred}}},
+			text: "[INFO]:\t{Creds=\"map[a:[{user=u pass=***}]]\"}\n",
+			json: `{"level":"INFO","detail":{"Creds":{"a":[{"user":"u","pass":"***"}]}}}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp()
+			g.Info(tt.val)
+			if got := buf.String(); got != tt.text {
+				t.Errorf("Glg.Info() = %q, want %q", got, tt.text)
+			}
+			buf.Reset()
+			g.EnableJSON().Info(tt.val)
+			if got := buf.String(); got != tt.json {
+				t.Errorf("Glg.Info() json = %q, want %q", got, tt.json)
+			}
+		})
+	}
+}
+
+func TestResolve_FieldInterfaceMap(t *testing.T) {
+	cred := marshalCred{User: "u", Pass: "hunter2"}
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp()
+	g.Info("login", F("m", map[string]interface{}{"c": cred}))
+	g.EnableJSON().Info("login", F("m", map[string]interface{}{"c": cred}))
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("Glg.Info() leaked the secret: %q", buf.String())
+	}
+}
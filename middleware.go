@@ -0,0 +1,215 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+)
+
+// WriterMiddleware wraps the writer by the transform of the written entries, e.g. the encryption or the byte counter
+type WriterMiddleware func(io.Writer) io.Writer
+
+// middlewareWriter is the writer wrapped by the middleware chain, the layers are flushed and closed from the outermost one
+type middlewareWriter struct {
+	io.Writer
+	layers []io.Writer
+	base   io.Writer
+}
+
+// WrapWriter returns w wrapped by mws, the first middleware is the outermost one which gets the entries first,
+// e.g. WrapWriter(file, MeterMiddleware(count), encrypt) counts the plain entries and writes them encrypted to file.
+// Close of the returned writer closes or flushes the layers and then w
+func WrapWriter(w io.Writer, mws ...WriterMiddleware) io.Writer {
+	if w == nil {
+		return nil
+	}
+	m := &middlewareWriter{Writer: w, base: w}
+	for i := len(mws) - 1; i >= 0; i-- {
+		if mws[i] == nil {
+			continue
+		}
+		if mw := mws[i](m.Writer); mw != nil {
+			m.Writer = mw
+			m.layers = append(m.layers, mw)
+		}
+	}
+	if len(m.layers) == 0 {
+		return w
+	}
+	return m
+}
+
+// Name returns the name of the wrapped writer
+func (m *middlewareWriter) Name() string {
+	return writerName(m.base)
+}
+
+// Flush flushes the layers having Flush and then the wrapped writer
+func (m *middlewareWriter) Flush() error {
+	var errs []error
+	for i := len(m.layers) - 1; i >= 0; i-- {
+		if f, ok := m.layers[i].(interface{ Flush() error }); ok {
+			errs = append(errs, f.Flush())
+		}
+	}
+	if f, ok := m.base.(interface{ Flush() error }); ok {
+		errs = append(errs, f.Flush())
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the layers and the wrapped writer
+func (m *middlewareWriter) Close() error {
+	return m.CloseContext(context.Background())
+}
+
+// CloseContext closes the layers and the wrapped writer with ctx, it is called by Shutdown
+func (m *middlewareWriter) CloseContext(ctx context.Context) error {
+	var errs []error
+	for i := len(m.layers) - 1; i >= 0; i-- {
+		errs = append(errs, closeWriter(ctx, m.layers[i]))
+	}
+	errs = append(errs, closeWriter(ctx, m.base))
+	return errors.Join(errs...)
+}
+
+// MeterMiddleware calls fn with the size of each entry written successfully, e.g. for the byte counter or the rate meter
+func MeterMiddleware(fn func(n int)) WriterMiddleware {
+	return func(w io.Writer) io.Writer {
+		return meterWriter{w: w, fn: fn}
+	}
+}
+
+type meterWriter struct {
+	w  io.Writer
+	fn func(n int)
+}
+
+func (m meterWriter) Write(p []byte) (int, error) {
+	n, err := m.w.Write(p)
+	if err == nil {
+		m.fn(n)
+	}
+	return n, err
+}
+
+// EncryptMiddleware returns the middleware encrypting the entries by EncryptWriter of the key
+func EncryptMiddleware(keyID string, key []byte) (WriterMiddleware, error) {
+	if _, err := NewEncryptWriter(io.Discard, keyID, key); err != nil {
+		return nil, err
+	}
+	return func(w io.Writer) io.Writer {
+		e, _ := NewEncryptWriter(w, keyID, key)
+		return e
+	}, nil
+}
+
+// UseMiddleware wraps the current writers of all the levels by mws like WrapWriter.
+// Each destination, including each one added by AddWriter, is wrapped once even when it is shared by the levels,
+// and the writers set after UseMiddleware are not wrapped
+func (g *Glg) UseMiddleware(mws ...WriterMiddleware) *Glg {
+	wrapped := make(map[io.Writer]io.Writer)
+	g.updateLoggers(func(_ LEVEL, l *logger) {
+		l.writer = g.wrapWriter(l.writer, mws, wrapped)
+		l.updateMode()
+	})
+	return g
+}
+
+// UseLevelMiddleware wraps the current writer of the level by mws like WrapWriter
+func (g *Glg) UseLevelMiddleware(lv LEVEL, mws ...WriterMiddleware) *Glg {
+	wrapped := make(map[io.Writer]io.Writer)
+	g.updateLogger(lv, func(l *logger) {
+		l.writer = g.wrapWriter(l.writer, mws, wrapped)
+		l.updateMode()
+	})
+	return g
+}
+
+// UseGroupMiddleware sets mws to the group named name, the members set after it are wrapped by mws like WrapWriter
+func (g *Glg) UseGroupMiddleware(name string, mws ...WriterMiddleware) *Glg {
+	g.writerGroup(name).Use(mws...)
+	return g
+}
+
+// wrapWriter wraps each destination of w once and replaces the tracked destination by the wrapped one,
+// so that Shutdown closes the layers before the destination
+func (g *Glg) wrapWriter(w io.Writer, mws []WriterMiddleware, wrapped map[io.Writer]io.Writer) io.Writer {
+	if w == nil {
+		return nil
+	}
+	if f, ok := w.(fanout); ok {
+		wf := make(fanout, len(f))
+		for i, w := range f {
+			wf[i] = g.wrapWriter(w, mws, wrapped)
+		}
+		return wf
+	}
+	canMap := reflect.TypeOf(w).Comparable()
+	if canMap {
+		if ww, ok := wrapped[w]; ok {
+			return ww
+		}
+	}
+	ww := WrapWriter(w, mws...)
+	if canMap {
+		wrapped[w] = ww
+	}
+	g.retrackWriter(w, ww)
+	return ww
+}
+
+// retrackWriter replaces the tracked writer old by w, w is tracked when old is not
+func (g *Glg) retrackWriter(old, w io.Writer) {
+	if reflect.TypeOf(old).Comparable() {
+		g.writersMu.Lock()
+		for i, tw := range g.writers {
+			if tw == old {
+				g.writers[i] = w
+				g.writersMu.Unlock()
+				return
+			}
+		}
+		g.writersMu.Unlock()
+	}
+	g.trackWriter(w, false)
+}
+
+// UseMiddleware wraps the current writers of all the levels of the global instance by mws
+func UseMiddleware(mws ...WriterMiddleware) *Glg {
+	return Get().UseMiddleware(mws...)
+}
+
+// UseLevelMiddleware wraps the current writer of the level of the global instance by mws
+func UseLevelMiddleware(lv LEVEL, mws ...WriterMiddleware) *Glg {
+	return Get().UseLevelMiddleware(lv, mws...)
+}
+
+// UseGroupMiddleware sets mws to the group named name of the global instance
+func UseGroupMiddleware(name string, mws ...WriterMiddleware) *Glg {
+	return Get().UseGroupMiddleware(name, mws...)
+}
@@ -0,0 +1,141 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// orderLayer records its name to closed when it is closed
+type orderLayer struct {
+	io.Writer
+	name   string
+	closed *[]string
+}
+
+func (o orderLayer) Close() error {
+	*o.closed = append(*o.closed, o.name)
+	return nil
+}
+
+func orderMiddleware(name string, closed *[]string) WriterMiddleware {
+	return func(w io.Writer) io.Writer {
+		return orderLayer{Writer: w, name: name, closed: closed}
+	}
+}
+
+func TestWrapWriter(t *testing.T) {
+	var closed []string
+	base := new(closeBuffer)
+	var n int
+	w := WrapWriter(base, orderMiddleware("outer", &closed), nil, MeterMiddleware(func(s int) { n += s }), orderMiddleware("inner", &closed))
+	w.Write([]byte("entry\n"))
+	if base.String() != "entry\n" || n != 6 {
+		t.Errorf("WrapWriter() wrote %q, metered %d", base.String(), n)
+	}
+	if err := w.(io.Closer).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(closed, ",") != "outer,inner" || base.closed != 1 {
+		t.Errorf("Close() order = %v, base closed %d times", closed, base.closed)
+	}
+	if WrapWriter(base) != io.Writer(base) || WrapWriter(nil, MeterMiddleware(func(int) {})) != nil {
+		t.Error("WrapWriter() without middleware wrapped the writer")
+	}
+}
+
+func TestGlg_UseMiddleware(t *testing.T) {
+	var closed []string
+	var n int64
+	main, extra := new(closeBuffer), new(closeBuffer)
+	g := New().SetMode(WRITER).SetLineTraceMode(TraceLineNone).DisableTimestamp().
+		SetWriter(main).AddLevelWriter(ERR, extra).
+		UseMiddleware(MeterMiddleware(func(s int) { atomic.AddInt64(&n, int64(s)) }), orderMiddleware("layer", &closed))
+	g.Info("info")
+	g.Error("error")
+	if main.String() != "[INFO]:\tinfo\n[ERR]:\terror\n" || extra.String() != "[ERR]:\terror\n" {
+		t.Errorf("writers = %q, %q", main.String(), extra.String())
+	}
+	if n != int64(main.Len()+extra.Len()) {
+		t.Errorf("metered %d bytes, want %d", n, main.Len()+extra.Len())
+	}
+	if err := g.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// main is shared by the levels and wrapped once
+	if len(closed) != 2 || main.closed != 1 || extra.closed != 1 {
+		t.Errorf("Shutdown() closed layers %v, main %d, extra %d", closed, main.closed, extra.closed)
+	}
+}
+
+func TestGlg_UseLevelMiddleware(t *testing.T) {
+	keys := map[string][]byte{"k": bytes.Repeat([]byte{1}, 32)}
+	enc, err := EncryptMiddleware("k", keys["k"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = EncryptMiddleware("k", []byte("short")); err == nil {
+		t.Error("EncryptMiddleware() error = nil for the invalid key")
+	}
+	buf, plain := new(bytes.Buffer), new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetLineTraceMode(TraceLineNone).DisableTimestamp().
+		SetLevelWriter(ERR, buf).SetLevelWriter(INFO, plain).
+		UseLevelMiddleware(ERR, enc)
+	g.Error("secret")
+	g.Info("public")
+	if strings.Contains(buf.String(), "secret") || plain.String() != "[INFO]:\tpublic\n" {
+		t.Fatalf("writers = %q, %q", buf.String(), plain.String())
+	}
+	out := new(bytes.Buffer)
+	if err = Decrypt(out, bytes.NewReader(buf.Bytes()), keys); err != nil || out.String() != "[ERR]:\tsecret\n" {
+		t.Errorf("Decrypt() = %q, %v", out.String(), err)
+	}
+}
+
+func TestGlg_UseGroupMiddleware(t *testing.T) {
+	var closed []string
+	blue, green := new(closeBuffer), new(closeBuffer)
+	g := New().SetMode(WRITER).SetLineTraceMode(TraceLineNone).DisableTimestamp().
+		AddWriterGroup("audit", blue).
+		UseGroupMiddleware("audit", orderMiddleware("green", &closed)).
+		RouteLevelToGroup(WARN, "audit")
+	g.Warn("blue")
+	for _, w := range g.SwapWriterGroup("audit", green) {
+		if err := closeWriter(context.Background(), w); err != nil {
+			t.Fatal(err)
+		}
+	}
+	g.Warn("green")
+	if blue.String() != "[WARN]:\tblue\n" || green.String() != "[WARN]:\tgreen\n" || blue.closed != 1 || len(closed) != 0 {
+		t.Errorf("group writers = %q, %q, closed %v", blue.String(), green.String(), closed)
+	}
+	g.Shutdown(context.Background())
+	if len(closed) != 1 || green.closed != 1 {
+		t.Errorf("Shutdown() closed %v, green %d", closed, green.closed)
+	}
+}
@@ -0,0 +1,288 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultMmapSegmentSize is the default preallocated size of MmapWriter segments
+const DefaultMmapSegmentSize = 64 << 20
+
+const (
+	// mmapTrailerSize is the size of the trailer after the entries of the active segment,
+	// the write offset followed by mmapMagic, so the entries ending with zero bytes are kept when the segment is reopened
+	mmapTrailerSize = 16
+	mmapMagic       = 0x676c672d6d6d6170 // "glg-mmap"
+)
+
+var errMmapUnsupported = errors.New("error:\tmmap writer is supported on Linux only")
+
+// MmapWriter is the low latency file writer which copies entries into the preallocated and memory mapped segment
+// without a syscall per write. The segment is rotated like LogFile segments when it is full.
+// Entries are durable after the page cache is written back by the kernel, Sync, SetSyncEvery or SetSyncInterval.
+// Readers see the zero filled tail and the trailer of the active segment, they are truncated on rotation and Close.
+// The segment is preallocated by fallocate, NewMmapWriter and the rotation fail on the file systems without it.
+// It is supported on Linux only, NewMmapWriter returns error on the other platforms without creating the file
+type MmapWriter struct {
+	mu        sync.Mutex
+	path      string
+	perm      os.FileMode
+	segSize   int
+	file      *os.File
+	data      []byte
+	off       int
+	syncEvery int
+	writes    int
+	closed    bool
+	ticker    *time.Ticker
+	done      chan struct{}
+	wg        sync.WaitGroup
+	stats     sinkStats
+}
+
+// NewMmapWriter opens the segment of the path, segmentSize <= 0 is DefaultMmapSegmentSize.
+// The existing segment is appended after its last entry, including the active segment left by the crashed process
+func NewMmapWriter(path string, perm os.FileMode, segmentSize int) (*MmapWriter, error) {
+	if !mmapSupported {
+		return nil, errMmapUnsupported
+	}
+	if segmentSize <= 0 {
+		segmentSize = DefaultMmapSegmentSize
+	}
+	w := &MmapWriter{
+		path:    path,
+		perm:    perm,
+		segSize: segmentSize,
+	}
+	if err := w.open(segmentSize); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// SetSyncEvery flushes the mapped pages to the disk every n writes, 0 leaves it to the kernel
+func (w *MmapWriter) SetSyncEvery(n int) *MmapWriter {
+	w.mu.Lock()
+	w.syncEvery = n
+	w.mu.Unlock()
+	return w
+}
+
+// SetSyncInterval flushes the mapped pages to the disk every interval, 0 stops it
+func (w *MmapWriter) SetSyncInterval(d time.Duration) *MmapWriter {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.done != nil {
+		close(w.done)
+		w.done = nil
+	}
+	if d <= 0 || w.closed {
+		return w
+	}
+	done := make(chan struct{})
+	w.done = done
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				w.Sync()
+			}
+		}
+	}()
+	return w
+}
+
+func (w *MmapWriter) open(size int) error {
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(w.path, os.O_RDWR|os.O_CREATE, w.perm)
+	if err != nil {
+		return err
+	}
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	size += mmapTrailerSize
+	if int64(size) < fi.Size()+mmapTrailerSize {
+		size = int(fi.Size()) + mmapTrailerSize
+	}
+	if err = preallocate(file, int64(size)); err != nil {
+		file.Close()
+		return err
+	}
+	data, err := mmap(file, size)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	off := int(fi.Size())
+	if off >= mmapTrailerSize && binary.LittleEndian.Uint64(data[off-8:off]) == mmapMagic {
+		// the active segment is not truncated by finish, its entries end at the offset of the trailer
+		if n := binary.LittleEndian.Uint64(data[off-mmapTrailerSize : off-8]); n <= uint64(off-mmapTrailerSize) {
+			off = int(n)
+			tail := data[off:]
+			for i := range tail {
+				tail[i] = 0
+			}
+		}
+	}
+	binary.LittleEndian.PutUint64(data[len(data)-8:], mmapMagic)
+	w.file, w.data, w.off = file, data, off
+	w.setTrailer()
+	return nil
+}
+
+// setTrailer stores the write offset to the trailer
+func (w *MmapWriter) setTrailer() {
+	binary.LittleEndian.PutUint64(w.data[len(w.data)-mmapTrailerSize:len(w.data)-8], uint64(w.off))
+}
+
+// sync flushes the mapped pages of the written entries and the trailer to the disk
+func (w *MmapWriter) sync() error {
+	if err := msync(w.data[:w.off]); err != nil {
+		return err
+	}
+	// msync takes the page aligned address
+	page := os.Getpagesize()
+	return msync(w.data[(len(w.data)-mmapTrailerSize)/page*page:])
+}
+
+// Write copies p into the mapped segment, the segment is rotated when p does not fit
+func (w *MmapWriter) Write(p []byte) (int, error) {
+	n, err := w.write(p)
+	w.stats.record(n, err)
+	return n, err
+}
+
+// Name returns the path of the active segment
+func (w *MmapWriter) Name() string {
+	return w.path
+}
+
+// Status implements StatusReporter
+func (w *MmapWriter) Status() SinkStatus {
+	return w.stats.status()
+}
+
+func (w *MmapWriter) write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrWriterClosed
+	}
+	if w.off+len(p) > len(w.data)-mmapTrailerSize {
+		if err := w.rotate(len(p)); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(w.data[w.off:], p)
+	w.off += n
+	w.setTrailer()
+	w.writes++
+	if w.syncEvery > 0 && w.writes%w.syncEvery == 0 {
+		return n, w.sync()
+	}
+	return n, nil
+}
+
+// Sync flushes the mapped pages of the written entries to the disk
+func (w *MmapWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return ErrWriterClosed
+	}
+	return w.sync()
+}
+
+// rotate renames the full segment and opens the new segment of at least min bytes.
+// The full segment is kept active when the new segment fails to be opened
+func (w *MmapWriter) rotate(min int) error {
+	seg := w.path + "." + time.Now().Format(segmentTimeFormat)
+	if err := os.Rename(w.path, seg); err != nil {
+		return err
+	}
+	size := w.segSize
+	if size < min {
+		size = min
+	}
+	file, data, off := w.file, w.data, w.off
+	if err := w.open(size); err != nil {
+		if rerr := os.Rename(seg, w.path); rerr != nil {
+			err = errors.Join(err, rerr)
+		}
+		return err
+	}
+	return finishSegment(file, data, off)
+}
+
+// finishSegment syncs and unmaps the segment, and truncates its zero filled tail and the trailer
+func finishSegment(file *os.File, data []byte, off int) error {
+	err := msync(data[:off])
+	if uerr := munmap(data); err == nil {
+		err = uerr
+	}
+	if terr := file.Truncate(int64(off)); err == nil {
+		err = terr
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Segments returns the paths of the rotated segments, oldest first
+func (w *MmapWriter) Segments() ([]string, error) {
+	return segments(w.path)
+}
+
+// Close syncs and closes the segment
+func (w *MmapWriter) Close() error {
+	w.SetSyncInterval(0)
+	w.wg.Wait()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return ErrWriterClosed
+	}
+	w.closed = true
+	err := finishSegment(w.file, w.data, w.off)
+	w.file, w.data, w.off = nil, nil, 0
+	return err
+}
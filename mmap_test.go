@@ -0,0 +1,154 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestMmapWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fast.log")
+	w, err := NewMmapWriter(path, 0o644, 32)
+	if runtime.GOOS != "linux" {
+		if err == nil {
+			t.Error("NewMmapWriter() error = nil")
+		}
+		if _, err = os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("NewMmapWriter() created the file, stat error = %v", err)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetSyncEvery(2).SetSyncInterval(time.Millisecond)
+	g := New().SetMode(WRITER).SetWriter(w).DisableTimestamp()
+	g.Info("first")
+	g.Info("second")
+	g.Info("third entry longer than the segment")
+	if err = w.Sync(); err != nil {
+		t.Error(err)
+	}
+	if fi, _ := os.Stat(path); fi.Size() < 32 {
+		t.Errorf("MmapWriter segment size = %d, want preallocated", fi.Size())
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	segs, err := w.Segments()
+	if err != nil || len(segs) != 1 {
+		t.Fatalf("MmapWriter.Segments() = %v, %v", segs, err)
+	}
+	if b, _ := os.ReadFile(segs[0]); string(b) != "[INFO]:\tfirst\n[INFO]:\tsecond\n" {
+		t.Errorf("MmapWriter segment = %q", b)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "[INFO]:\tthird entry longer than the segment\n" {
+		t.Errorf("MmapWriter active segment = %q", b)
+	}
+	if _, err = w.Write([]byte("closed")); err != ErrWriterClosed {
+		t.Errorf("MmapWriter.Write() error = %v", err)
+	}
+
+	// reopened segment is appended after the last entry
+	w, err = NewMmapWriter(path, 0o644, 128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("appended\n"))
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "[INFO]:\tthird entry longer than the segment\nappended\n" {
+		t.Errorf("MmapWriter reopened segment = %q", b)
+	}
+}
+
+func TestMmapWriter_Recover(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("mmap writer is supported on Linux only")
+	}
+	path := filepath.Join(t.TempDir(), "fast.log")
+	w, err := NewMmapWriter(path, 0o644, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the binary entry ending with zero bytes
+	w.Write([]byte{0xa1, 0x61, 0x00, 0x00})
+	// the process crashes without truncating the segment
+	munmap(w.data)
+	w.file.Close()
+
+	w, err = NewMmapWriter(path, 0o644, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("next"))
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "\xa1\x61\x00\x00next" {
+		t.Errorf("MmapWriter recovered segment = %q", b)
+	}
+}
+
+func TestMmapWriter_RotateFailed(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("mmap writer is supported on Linux only")
+	}
+	path := filepath.Join(t.TempDir(), "fast.log")
+	w, err := NewMmapWriter(path, 0o644, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("first\n"))
+	// the new segment fails to be preallocated
+	w.segSize = 1 << 62
+	if _, err = w.Write([]byte("second\n")); err == nil {
+		t.Error("MmapWriter.Write() error = nil")
+	}
+	if segs, _ := w.Segments(); len(segs) != 0 {
+		t.Errorf("MmapWriter segments = %v, want the full segment kept active", segs)
+	}
+	w.segSize = 8
+	if _, err = w.Write([]byte("second\n")); err != nil {
+		t.Errorf("MmapWriter.Write() error = %v after the failed rotation", err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	segs, _ := w.Segments()
+	if len(segs) != 1 {
+		t.Fatalf("MmapWriter segments = %v", segs)
+	}
+	if b, _ := os.ReadFile(segs[0]); string(b) != "first\n" {
+		t.Errorf("MmapWriter segment = %q", b)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "second\n" {
+		t.Errorf("MmapWriter active segment = %q", b)
+	}
+}
@@ -0,0 +1,104 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+type multiLineMode uint8
+
+const (
+	// MultiLineRaw writes embedded newlines as it is
+	MultiLineRaw multiLineMode = iota
+	// MultiLineEscape escapes embedded newlines as \n literal
+	MultiLineEscape
+	// MultiLineFold writes embedded newlines as continuation lines prefixed with the continuation marker
+	MultiLineFold
+
+	// DefaultContinuationMarker is default prefix of continuation lines
+	DefaultContinuationMarker = tab + "| "
+)
+
+// foldBuffer rewrites newlines of the bytes written after start
+func foldBuffer(b *bytes.Buffer, start int, mode multiLineMode, marker string) {
+	buf := b.Bytes()[start:]
+	if bytes.IndexAny(buf, "\r\n") < 0 && (mode != MultiLineEscape || utf8.Valid(buf)) {
+		return
+	}
+	msg := string(buf)
+	b.Truncate(start)
+	switch mode {
+	case MultiLineEscape:
+		for i := 0; i < len(msg); {
+			r, size := utf8.DecodeRuneInString(msg[i:])
+			switch {
+			case r == '\n':
+				b.WriteString(`\n`)
+			case r == '\r':
+				b.WriteString(`\r`)
+			case r == utf8.RuneError && size == 1:
+				// the invalid byte is kept distinguishable instead of being rewritten to U+FFFD
+				b.WriteString(`\x`)
+				b.WriteByte(hexDigits[msg[i]>>4])
+				b.WriteByte(hexDigits[msg[i]&0xf])
+			default:
+				b.WriteString(msg[i : i+size])
+			}
+			i += size
+		}
+	case MultiLineFold:
+		msg = strings.TrimRight(strings.ReplaceAll(msg, "\r\n", rc), "\r\n")
+		for i, line := range strings.Split(msg, rc) {
+			if i != 0 {
+				b.WriteString(rc)
+				b.WriteString(marker)
+			}
+			b.WriteString(line)
+		}
+	default:
+		b.WriteString(msg)
+	}
+}
+
+// SetMultiLineMode configures how embedded newlines of the message are written in text output
+func (g *Glg) SetMultiLineMode(mode multiLineMode) *Glg {
+	g.setSwitches(func(s *switches) {
+		s.multiLineMode = mode
+		if s.contMarker == "" {
+			s.contMarker = DefaultContinuationMarker
+		}
+	})
+	return g
+}
+
+// SetContinuationMarker sets the prefix of continuation lines for MultiLineFold mode
+func (g *Glg) SetContinuationMarker(marker string) *Glg {
+	g.setSwitches(func(s *switches) {
+		s.contMarker = marker
+	})
+	return g
+}
@@ -0,0 +1,90 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGlg_SetMultiLineMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   multiLineMode
+		marker string
+		msg    string
+		want   string
+	}{
+		{
+			name: "raw",
+			mode: MultiLineRaw,
+			msg:  "panic\ngoroutine 1",
+			want: "[ERR]:\tpanic\ngoroutine 1\n",
+		},
+		{
+			name: "escape",
+			mode: MultiLineEscape,
+			msg:  "panic\r\ngoroutine 1",
+			want: "[ERR]:\tpanic\\r\\ngoroutine 1\n",
+		},
+		{
+			name: "escape invalid utf8",
+			mode: MultiLineEscape,
+			msg:  "a\xff\nb\xe2\x82 \u00e9",
+			want: "[ERR]:\ta\\xff\\nb\\xe2\\x82 \u00e9\n",
+		},
+		{
+			name: "escape invalid utf8 without newline",
+			mode: MultiLineEscape,
+			msg:  "a\xffb",
+			want: "[ERR]:\ta\\xffb\n",
+		},
+		{
+			name: "fold",
+			mode: MultiLineFold,
+			msg:  "SELECT *\r\nFROM users\nWHERE id = 1\n",
+			want: "[ERR]:\tSELECT *\n\t| FROM users\n\t| WHERE id = 1\n",
+		},
+		{
+			name:   "fold custom marker",
+			mode:   MultiLineFold,
+			marker: " ... ",
+			msg:    "a\nb",
+			want:   "[ERR]:\ta\n ... b\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().
+				SetLineTraceMode(TraceLineNone).SetMultiLineMode(tt.mode)
+			if tt.marker != "" {
+				g.SetContinuationMarker(tt.marker)
+			}
+			g.Error(tt.msg)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Glg.SetMultiLineMode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NATSPublisher publishes the message to the NATS subject, *nats.Conn implements it as it is.
+// data must not be retained after Publish returns
+type NATSPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSPublishFunc adapts the function to NATSPublisher, e.g. for JetStream persistence
+//
+//	glg.NATSPublishFunc(func(subj string, data []byte) error {
+//		_, err := js.Publish(subj, data)
+//		return err
+//	})
+type NATSPublishFunc func(subject string, data []byte) error
+
+// Publish implements NATSPublisher
+func (f NATSPublishFunc) Publish(subject string, data []byte) error {
+	return f(subject, data)
+}
+
+// NATSContextPublisher is NATSPublisher which stops publishing when ctx is done,
+// NATSWriter publishes by PublishContext when the publisher implements it
+type NATSContextPublisher interface {
+	NATSPublisher
+	PublishContext(ctx context.Context, subject string, data []byte) error
+}
+
+// NATSPublishContextFunc adapts the function to NATSContextPublisher, e.g. for JetStream waiting for the acknowledgement
+//
+//	glg.NATSPublishContextFunc(func(ctx context.Context, subj string, data []byte) error {
+//		_, err := js.Publish(ctx, subj, data)
+//		return err
+//	})
+type NATSPublishContextFunc func(ctx context.Context, subject string, data []byte) error
+
+// Publish implements NATSPublisher
+func (f NATSPublishContextFunc) Publish(subject string, data []byte) error {
+	return f(context.Background(), subject, data)
+}
+
+// PublishContext implements NATSContextPublisher
+func (f NATSPublishContextFunc) PublishContext(ctx context.Context, subject string, data []byte) error {
+	return f(ctx, subject, data)
+}
+
+// NATSWriter is io.Writer which publishes each entry to the NATS subject.
+// The subject is template of {{level}} (lower cased level name), {{hostname}}, {{pid}} and {{app}},
+// e.g. glg.Get().AddLevelWriter(glg.ERR, glg.NewNATSWriter(nc, "logs.{{app}}.{{level}}").Level(glg.ERR)).
+// The publisher implementing NATSContextPublisher gets the context limited by the timeout,
+// which is canceled as well when the context of CloseContext is done
+type NATSWriter struct {
+	pub     NATSPublisher
+	subject string
+	tmpl    prefixTemplate
+	timeout time.Duration
+	onError func(error)
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewNATSWriter returns NATSWriter of the subject template
+func NewNATSWriter(pub NATSPublisher, subject string) *NATSWriter {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &NATSWriter{
+		pub:     pub,
+		subject: subject,
+		tmpl:    parsePrefix(subject),
+		timeout: DefaultSinkTimeout,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	if w.tmpl != nil {
+		w.subject = w.render(UNKNOWN)
+	}
+	return w
+}
+
+// SetTimeout sets the time limit to publish one entry by NATSContextPublisher, default is DefaultSinkTimeout and 0 is unlimited.
+// The entry timed out fails with the error wrapping context.DeadlineExceeded.
+// It must be called before the first Write
+func (w *NATSWriter) SetTimeout(d time.Duration) *NATSWriter {
+	w.timeout = d
+	return w
+}
+
+// SetErrorHandler sets the handler called with the error of each entry failed to publish, including the timeouts.
+// The error is also returned by Write. It must be called before the first Write
+func (w *NATSWriter) SetErrorHandler(fn func(error)) *NATSWriter {
+	w.onError = fn
+	return w
+}
+
+// Close stops publishing, see CloseContext
+func (w *NATSWriter) Close() error {
+	return w.CloseContext(context.Background())
+}
+
+// CloseContext stops publishing and waits for the entries being published until ctx is done,
+// they are canceled then. Write returns ErrWriterClosed after it. Shutdown closes the writer by it with its context
+func (w *NATSWriter) CloseContext(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrWriterClosed
+	}
+	w.closed = true
+	w.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		w.cancel()
+		<-done
+	}
+	w.cancel()
+	return nil
+}
+
+// Write publishes p without the trailing newline, {{level}} of the subject is "unknown"
+func (w *NATSWriter) Write(p []byte) (int, error) {
+	return w.publish(w.subject, p)
+}
+
+// Level returns io.Writer which publishes the entries to the subject of the level,
+// {{level}} of the custom level is its tag registered to the global instance before Level is called
+func (w *NATSWriter) Level(level LEVEL) io.Writer {
+	return &natsLevelWriter{
+		w:       w,
+		subject: w.render(level),
+	}
+}
+
+func (w *NATSWriter) publish(subject string, p []byte) (int, error) {
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return 0, ErrWriterClosed
+	}
+	w.wg.Add(1)
+	w.mu.RUnlock()
+	defer w.wg.Done()
+	data := bytes.TrimRight(p, "\r\n")
+	var err error
+	if cp, ok := w.pub.(NATSContextPublisher); ok {
+		ctx := w.ctx
+		if w.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, w.timeout)
+			defer cancel()
+		}
+		err = cp.PublishContext(ctx, subject, data)
+		if cerr := ctx.Err(); err != nil && cerr != nil && !errors.Is(err, cerr) {
+			err = fmt.Errorf("error:\tpublishing to %s is stopped: %w: %v", subject, cerr, err)
+		}
+	} else {
+		err = w.pub.Publish(subject, data)
+	}
+	if err != nil {
+		if w.onError != nil {
+			w.onError(err)
+		}
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *NATSWriter) render(level LEVEL) string {
+	if w.tmpl == nil {
+		return w.subject
+	}
+	var sb strings.Builder
+	for _, seg := range w.tmpl {
+		switch seg.name {
+		case "":
+			sb.WriteString(seg.lit)
+		case "level":
+			name := strings.ToLower(levelName(level))
+			if name == "" {
+				name = "unknown"
+			}
+			sb.WriteString(name)
+		default:
+			if v, ok := builtinVar(seg.name); ok {
+				sb.WriteString(v)
+			} else {
+				sb.WriteString("{{" + seg.name + "}}")
+			}
+		}
+	}
+	return sb.String()
+}
+
+type natsLevelWriter struct {
+	w       *NATSWriter
+	subject string
+}
+
+func (lw *natsLevelWriter) Write(p []byte) (int, error) {
+	return lw.w.publish(lw.subject, p)
+}
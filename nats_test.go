@@ -0,0 +1,132 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type natsMessage struct {
+	subject string
+	data    string
+}
+
+func TestNATSWriter(t *testing.T) {
+	var msgs []natsMessage
+	pub := NATSPublishFunc(func(subject string, data []byte) error {
+		msgs = append(msgs, natsMessage{subject: subject, data: string(data)})
+		return nil
+	})
+	w := NewNATSWriter(pub, "logs.{{app}}.{{level}}")
+	g := New().SetMode(WRITER).DisableTimestamp().SetLineTraceMode(TraceLineNone).
+		SetLevelWriter(INFO, w.Level(INFO)).
+		SetLevelWriter(ERR, w.Level(ERR)).
+		SetLevelWriter(WARN, w)
+	g.Info("hello")
+	g.Error("failed")
+	g.Warn("slow")
+
+	want := []natsMessage{
+		{subject: "logs." + app + ".info", data: "[INFO]:\thello"},
+		{subject: "logs." + app + ".err", data: "[ERR]:\tfailed"},
+		{subject: "logs." + app + ".unknown", data: "[WARN]:\tslow"},
+	}
+	if len(msgs) != len(want) {
+		t.Fatalf("NATSWriter messages = %v, want %v", msgs, want)
+	}
+	for i := range want {
+		if msgs[i] != want[i] {
+			t.Errorf("NATSWriter message = %v, want %v", msgs[i], want[i])
+		}
+	}
+
+	w = NewNATSWriter(NATSPublishFunc(func(string, []byte) error {
+		return errors.New("no responders")
+	}), "logs")
+	if w.subject != "logs" {
+		t.Errorf("NATSWriter subject = %s", w.subject)
+	}
+	if _, err := w.Write([]byte("lost\n")); err == nil {
+		t.Error("NATSWriter.Write() error = nil")
+	}
+}
+
+func TestNATSWriter_CustomLevel(t *testing.T) {
+	Get().AddStdLevel("NATS-AUDIT", WRITER, false)
+	defer Get().RemoveLevel("NATS-AUDIT")
+	w := NewNATSWriter(NATSPublishFunc(func(string, []byte) error { return nil }), "logs.{{level}}")
+	lw := w.Level(Get().TagStringToLevel("NATS-AUDIT")).(*natsLevelWriter)
+	if lw.subject != "logs.nats-audit" {
+		t.Errorf("NATSWriter subject = %s, want logs.nats-audit", lw.subject)
+	}
+}
+
+func TestNATSWriter_Context(t *testing.T) {
+	started := make(chan struct{}, 1)
+	hang := NATSPublishContextFunc(func(ctx context.Context, subject string, data []byte) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return errors.New("nats: timeout")
+	})
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	w := NewNATSWriter(hang, "logs").SetTimeout(20 * time.Millisecond).SetErrorHandler(func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	})
+	if _, err := w.Write([]byte("slow\n")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("NATSWriter.Write() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	<-started
+	mu.Lock()
+	if len(errs) != 1 || !errors.Is(errs[0], context.DeadlineExceeded) {
+		t.Errorf("NATSWriter error handler got %v", errs)
+	}
+	mu.Unlock()
+
+	w = NewNATSWriter(hang, "logs").SetTimeout(0)
+	werr := make(chan error, 1)
+	go func() {
+		_, err := w.Write([]byte("pending\n"))
+		werr <- err
+	}()
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := w.CloseContext(ctx); err != nil {
+		t.Errorf("NATSWriter.CloseContext() error = %v", err)
+	}
+	if err := <-werr; !errors.Is(err, context.Canceled) {
+		t.Errorf("NATSWriter.Write() error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := w.Write([]byte("closed\n")); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("NATSWriter.Write() error = %v, want %v", err, ErrWriterClosed)
+	}
+}
@@ -0,0 +1,79 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+// Logger is the leveled logging methods of Glg for the libraries accepting the logger of the host application.
+// The library defaults it to Nop instead of configuring the global instance, e.g.
+//
+//	type Client struct{ log glg.Logger }
+//
+//	func NewClient(log glg.Logger) *Client {
+//		if log == nil {
+//			log = glg.Nop()
+//		}
+//		return &Client{log: log}
+//	}
+type Logger interface {
+	Debug(val ...interface{}) error
+	Debugf(format string, val ...interface{}) error
+	Info(val ...interface{}) error
+	Infof(format string, val ...interface{}) error
+	Warn(val ...interface{}) error
+	Warnf(format string, val ...interface{}) error
+	Error(val ...interface{}) error
+	Errorf(format string, val ...interface{}) error
+}
+
+// Nop returns the instance writing nothing, the levels are disabled so the entries are discarded before formatting.
+// Fatal still exits
+func Nop() *Glg {
+	return New().SetMode(NONE)
+}
+
+// SetDefault makes g the global instance used by the package-level functions and Get, and returns the former one.
+// It is called by the host application before logging, nil keeps the global instance
+func SetDefault(g *Glg) *Glg {
+	prev := Get()
+	if g != nil {
+		prev = instance.Swap(g)
+	}
+	return prev
+}
+
+// Replace atomically makes g the global instance used by the package-level functions and Get,
+// and returns the function restoring the former one, e.g. in a test
+//
+//	defer glg.Replace(glg.New().SetMode(glg.WRITER).SetWriter(buf))()
+//
+// nil keeps the global instance and the restore does nothing
+func Replace(g *Glg) (restore func()) {
+	if g == nil {
+		return func() {}
+	}
+	Get()
+	prev := instance.Swap(g)
+	return func() {
+		instance.Store(prev)
+	}
+}
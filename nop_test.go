@@ -0,0 +1,112 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestNop(t *testing.T) {
+	var l Logger = Nop()
+	for _, f := range []func(...interface{}) error{l.Debug, l.Info, l.Warn, l.Error} {
+		if err := f("x"); err != nil {
+			t.Errorf("Nop() error = %v", err)
+		}
+	}
+	if lv := Nop().GetCurrentLevel(); lv != UNKNOWN {
+		t.Errorf("Nop() enables %v", lv)
+	}
+	if n := testing.AllocsPerRun(100, func() { l.Infof("%d", 1) }); n > 1 {
+		t.Errorf("Nop() allocates %v times per entry", n)
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp()
+	prev := SetDefault(g)
+	defer SetDefault(prev)
+	if Get() != g {
+		t.Error("Get() is not the default")
+	}
+	Info("hello")
+	if buf.String() != "[INFO]:\thello\n" {
+		t.Errorf("Info() output = %q", buf.String())
+	}
+	if SetDefault(nil) != g || Get() != g {
+		t.Error("SetDefault(nil) replaced the default")
+	}
+}
+
+func TestReplace(t *testing.T) {
+	orig := Get()
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp()
+	restore := Replace(g)
+	if Get() != g {
+		t.Error("Get() is not the replaced instance")
+	}
+	Warn("replaced")
+	if buf.String() != "[WARN]:\treplaced\n" {
+		t.Errorf("Warn() output = %q", buf.String())
+	}
+
+	inner := Replace(Nop())
+	inner()
+	if Get() != g {
+		t.Error("nested restore did not restore the replaced instance")
+	}
+	restore()
+	if Get() != orig {
+		t.Error("restore did not restore the former instance")
+	}
+	Replace(nil)()
+	if Get() != orig {
+		t.Error("Replace(nil) replaced the global instance")
+	}
+}
+
+func TestReplaceConcurrent(t *testing.T) {
+	orig := Get()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if Get() == nil {
+					t.Error("Get() = nil during Replace")
+					return
+				}
+			}
+		}()
+	}
+	restore := Replace(Nop())
+	restore()
+	wg.Wait()
+	if Get() != orig {
+		t.Error("restore did not restore the former instance")
+	}
+}
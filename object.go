@@ -0,0 +1,228 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"encoding"
+	"reflect"
+	"strings"
+
+	json "github.com/goccy/go-json"
+)
+
+// ObjectFormat is json object structure of the object dumped by DebugObject
+type ObjectFormat struct {
+	Label  string          `json:"label,omitempty"`
+	Object json.RawMessage `json:"object"`
+}
+
+// secretKeys are the substrings of the object keys masked by DebugObject
+var secretKeys = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "credential", "private_key", "privatekey"}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range secretKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// object returns the format and the values of DebugObject
+func (g *Glg) object(label string, v interface{}) (string, []interface{}) {
+	data, err := json.Marshal(objectValue(v, 0))
+	if err != nil {
+		return "%s: %+v", []interface{}{label, err}
+	}
+	isJSON := g.opts().enableJSON
+	indent := "  "
+	if isJSON {
+		indent = ""
+	}
+	b := new(bytes.Buffer)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err = writeMaskedJSON(b, dec, indent, 0); err != nil {
+		return "%s: %+v", []interface{}{label, err}
+	}
+	if isJSON {
+		return "", []interface{}{ObjectFormat{
+			Label:  label,
+			Object: b.Bytes(),
+		}}
+	}
+	return "%s:" + rc + "%s", []interface{}{label, b.String()}
+}
+
+// objectValue resolves LogValuer and glg struct tags of v and its nested structs, slices and maps
+func objectValue(v interface{}, depth int) interface{} {
+	if depth >= maxLogValueDepth {
+		return v
+	}
+	switch t := v.(type) {
+	case nil, []byte, json.Marshaler, encoding.TextMarshaler:
+		return v
+	case LogValuer:
+		return objectValue(t.LogValue(), depth+1)
+	case error:
+		return t.Error()
+	}
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	switch val.Kind() {
+	case reflect.Struct:
+		si := getStructInfo(val.Type())
+		sv := make(structValue, 0, len(si.fields))
+		for _, f := range si.fields {
+			fv := val.Field(f.index)
+			if f.omitempty && fv.IsZero() {
+				continue
+			}
+			if f.secret {
+				sv = append(sv, String(f.name, SecretMask))
+				continue
+			}
+			sv = append(sv, F(f.name, objectValue(fv.Interface(), depth+1)))
+		}
+		return sv
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			return nil
+		}
+		vals := make([]interface{}, val.Len())
+		for i := range vals {
+			vals[i] = objectValue(val.Index(i).Interface(), depth+1)
+		}
+		return vals
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String || val.IsNil() {
+			return v
+		}
+		m := make(map[string]interface{}, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = objectValue(iter.Value().Interface(), depth+1)
+		}
+		return m
+	}
+	return v
+}
+
+// writeMaskedJSON re-encodes the next value of dec keeping the key order,
+// the values of the secret keys are replaced by SecretMask and the nested values are indented when indent is not empty
+func writeMaskedJSON(b *bytes.Buffer, dec *json.Decoder, indent string, depth int) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	newline := func(depth int) {
+		if indent != "" {
+			b.WriteString(rc)
+			b.WriteString(strings.Repeat(indent, depth))
+		}
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		end := byte(']')
+		if t == '{' {
+			end = '}'
+		}
+		b.WriteByte(byte(t))
+		var n int
+		for ; dec.More(); n++ {
+			if n > 0 {
+				b.WriteByte(',')
+			}
+			newline(depth + 1)
+			if t == '{' {
+				kt, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key, _ := kt.(string)
+				k, _ := json.Marshal(key)
+				b.Write(k)
+				b.WriteByte(':')
+				if indent != "" {
+					b.WriteString(spw)
+				}
+				if isSecretKey(key) {
+					var raw json.RawMessage
+					if err = dec.Decode(&raw); err != nil {
+						return err
+					}
+					b.WriteString(`"` + SecretMask + `"`)
+					continue
+				}
+			}
+			if err = writeMaskedJSON(b, dec, indent, depth+1); err != nil {
+				return err
+			}
+		}
+		if _, err = dec.Token(); err != nil {
+			return err
+		}
+		if n > 0 {
+			newline(depth)
+		}
+		b.WriteByte(end)
+	case json.Number:
+		b.WriteString(t.String())
+	case nil:
+		b.WriteString("null")
+	default:
+		v, err := json.Marshal(t)
+		if err != nil {
+			return err
+		}
+		b.Write(v)
+	}
+	return nil
+}
+
+// DebugObject outputs Debug level indented JSON of v labeled by label, JSON mode embeds v as the nested object.
+// The values keyed like password, secret, token or api_key and the secret fields of the glg tags are masked
+func (g *Glg) DebugObject(label string, v interface{}) error {
+	if g.isModeEnable(DEBG) {
+		format, vals := g.object(label, v)
+		return g.out(DEBG, format, vals...)
+	}
+	return nil
+}
+
+// DebugObject outputs Debug level indented JSON of v labeled by label, JSON mode embeds v as the nested object
+func DebugObject(label string, v interface{}) error {
+	if isModeEnable(DEBG) {
+		format, vals := Get().object(label, v)
+		return Get().out(DEBG, format, vals...)
+	}
+	return nil
+}
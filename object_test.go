@@ -0,0 +1,105 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type objectConfig struct {
+	Name     string            `json:"name"`
+	Port     int               `json:"port"`
+	Password string            `json:"password"`
+	Backends []objectBackend   `json:"backends"`
+	Labels   map[string]string `json:"labels"`
+	Empty    []string          `json:"empty"`
+}
+
+type objectBackend struct {
+	Host  string `glg:"host"`
+	Token string `glg:"auth"`
+	Key   string `glg:"key,secret"`
+}
+
+func TestGlg_DebugObject(t *testing.T) {
+	cfg := objectConfig{
+		Name:     "api",
+		Port:     8080,
+		Password: "hunter2",
+		Backends: []objectBackend{{Host: "db1", Token: "t0k", Key: "k3y"}},
+		Labels:   map[string]string{"api_token": "abc"},
+		Empty:    []string{},
+	}
+	tests := []struct {
+		name string
+		json bool
+		want string
+	}{
+		{
+			name: "text",
+			want: "cfg:\n" +
+				"{\n" +
+				"  \"name\": \"api\",\n" +
+				"  \"port\": 8080,\n" +
+				"  \"password\": \"***\",\n" +
+				"  \"backends\": [\n" +
+				"    {\n" +
+				"      \"host\": \"db1\",\n" +
+				"      \"auth\": \"t0k\",\n" +
+				"      \"key\": \"***\"\n" +
+				"    }\n" +
+				"  ],\n" +
+				"  \"labels\": {\n" +
+				"    \"api_token\": \"***\"\n" +
+				"  },\n" +
+				"  \"empty\": []\n" +
+				"}\n",
+		},
+		{
+			name: "json",
+			json: true,
+			want: `"detail":{"label":"cfg","object":{"name":"api","port":8080,"password":"***",` +
+				`"backends":[{"host":"db1","auth":"t0k","key":"***"}],"labels":{"api_token":"***"},"empty":[]}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone)
+			if tt.json {
+				g.EnableJSON()
+			}
+			if err := g.DebugObject("cfg", &cfg); err != nil {
+				t.Fatalf("DebugObject() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("DebugObject() = %q, want %q", buf.String(), tt.want)
+			}
+			if strings.Contains(buf.String(), "hunter2") || strings.Contains(buf.String(), "k3y") {
+				t.Errorf("DebugObject() leaked the secret: %q", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import "sync/atomic"
+
+// EnableOrderedWrite writes the entries of all the levels in the strict order of their timestamps,
+// even when they are logged concurrently to the shared files. The timestamp is taken and the entry is written holding one lock,
+// with EnableAsync the entry is queued instead and all the levels share the single queue, the queues of SetAsyncQueue are not used
+func (g *Glg) EnableOrderedWrite() *Glg {
+	return g.setOrderedWrite(1)
+}
+
+// DisableOrderedWrite disables the strict ordering of EnableOrderedWrite
+func (g *Glg) DisableOrderedWrite() *Glg {
+	return g.setOrderedWrite(0)
+}
+
+func (g *Glg) setOrderedWrite(ordered int32) *Glg {
+	g.asyncMu.Lock()
+	defer g.asyncMu.Unlock()
+	if atomic.SwapInt32(&g.ordered, ordered) != ordered && g.asyncer() != nil {
+		g.restartAsync()
+	}
+	return g
+}
+
+// asyncQueues returns the level queues of the asyncer, nil in the ordered mode
+func (g *Glg) asyncQueues() map[LEVEL]asyncConfig {
+	if atomic.LoadInt32(&g.ordered) != 0 {
+		return nil
+	}
+	return g.asyncLevels
+}
@@ -0,0 +1,88 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bufio"
+	"bytes"
+	"sync"
+	"testing"
+
+	json "github.com/goccy/go-json"
+)
+
+func TestGlg_EnableOrderedWrite(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone).
+		EnableJSON().EnableEpochMillis().
+		SetAsyncQueue(ERR, 16, 10).
+		EnableOrderedWrite()
+	if a := g.asyncer(); len(a.levels) != 0 {
+		t.Fatalf("ordered asyncer has %d level queues, want the single queue", len(a.levels))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if (i+j)%2 == 0 {
+					g.Info("info", j)
+				} else {
+					g.Error("error", j)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := g.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	var prev int64
+	var n int
+	sc := bufio.NewScanner(buf)
+	for sc.Scan() {
+		var e struct {
+			Timestamp int64 `json:"ts"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", sc.Text(), err)
+		}
+		if e.Timestamp < prev {
+			t.Fatalf("entry %d ts = %d, before %d", n, e.Timestamp, prev)
+		}
+		prev = e.Timestamp
+		n++
+	}
+	if n != 1600 {
+		t.Errorf("entries = %d, want 1600", n)
+	}
+
+	g.DisableOrderedWrite()
+	if a := g.asyncer(); len(a.levels) != 1 {
+		t.Errorf("asyncer has %d level queues after DisableOrderedWrite, want 1", len(a.levels))
+	}
+	g.DisableAsync()
+}
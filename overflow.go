@@ -0,0 +1,102 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import "sync/atomic"
+
+// OverflowPolicy decides what happens to the entry written to the full asynchronous queue or batching writer
+type OverflowPolicy uint8
+
+const (
+	// OverflowBlock blocks the writes until there is room, nothing is lost
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest queued entry to make room, the latest entries are kept
+	OverflowDropOldest
+	// OverflowDropNewest discards the written entry, the queued entries are kept
+	OverflowDropNewest
+)
+
+const (
+	// MetricDropped is the metric name of the entries discarded by the overflow policies or after Shutdown
+	MetricDropped = "dropped_entries"
+	// MetricSuppressed is the metric name of the entries suppressed by filters
+	MetricSuppressed = "suppressed_entries"
+	// MetricSampled is the metric name of the entries discarded by sampling
+	MetricSampled = "sampled_out_entries"
+)
+
+// lossKind is the reason why the entry is not written
+type lossKind uint8
+
+const (
+	lossDropped lossKind = iota
+	lossSuppressed
+	lossSampled
+	lossKinds
+)
+
+var lossMetrics = [lossKinds]string{MetricDropped, MetricSuppressed, MetricSampled}
+
+// MetricsHook receives the counter increments of the logging pipeline, level is UNKNOWN when the entry level is not known.
+// It is called synchronously, so it must not block nor log to the instance
+type MetricsHook func(name string, level LEVEL, delta int64)
+
+// String returns the name of the policy
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowDropNewest:
+		return "drop-newest"
+	}
+	return "unknown"
+}
+
+// SetMetricsHook sets the hook receiving the metrics of the instance, nil removes the hook
+func (g *Glg) SetMetricsHook(hook MetricsHook) *Glg {
+	g.setSwitches(func(s *switches) {
+		s.metricsHook = hook
+	})
+	return g
+}
+
+// Dropped returns the number of the entries discarded by the overflow policy of the asynchronous queues or after Shutdown
+func (g *Glg) Dropped() uint64 {
+	return atomic.LoadUint64(&g.losses[lossDropped])
+}
+
+// drop counts the entry of the level discarded by the overflow policy
+func (g *Glg) drop(level LEVEL) {
+	g.lose(lossDropped, level)
+}
+
+// lose counts the entry of the level which is not written by the reason
+func (g *Glg) lose(kind lossKind, level LEVEL) {
+	atomic.AddUint64(&g.losses[kind], 1)
+	if hook := g.opts().metricsHook; hook != nil {
+		hook(lossMetrics[kind], level, 1)
+	}
+}
@@ -0,0 +1,147 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGlg_SetAsyncOverflow(t *testing.T) {
+	tests := []struct {
+		policy OverflowPolicy
+		want   string
+	}{
+		{
+			policy: OverflowDropNewest,
+			want:   "m0,m1,m2",
+		},
+		{
+			policy: OverflowDropOldest,
+			want:   "m0,m4,m5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			w := &gateWriter{gate: make(chan struct{})}
+			var mu sync.Mutex
+			hooked := make(map[LEVEL]int64)
+			g := New().SetMode(WRITER).SetWriter(w).DisableTimestamp().SetLineFormat("{{msg}}").
+				SetMetricsHook(func(name string, level LEVEL, delta int64) {
+					if name == MetricDropped {
+						mu.Lock()
+						hooked[level] += delta
+						mu.Unlock()
+					}
+				}).
+				SetAsyncOverflow(tt.policy).EnableAsync(2)
+			defer g.DisableAsync()
+
+			g.Info("m0")
+			for len(g.asyncer().def.ch) != 0 {
+				time.Sleep(time.Millisecond)
+			}
+			for _, msg := range []string{"m1", "m2", "m3", "m4", "m5"} {
+				g.Info(msg)
+			}
+			close(w.gate)
+			if err := g.Flush(); err != nil {
+				t.Error(err)
+			}
+			if got := w.String(); got != tt.want {
+				t.Errorf("SetAsyncOverflow() = %s, want %s", got, tt.want)
+			}
+			if g.Dropped() != 3 || hooked[INFO] != 3 {
+				t.Errorf("Dropped() = %d, hook = %v, want 3", g.Dropped(), hooked)
+			}
+		})
+	}
+}
+
+func TestBatcher_Overflow(t *testing.T) {
+	tests := []struct {
+		policy OverflowPolicy
+		want   string
+	}{
+		{
+			policy: OverflowDropNewest,
+			want:   "a|b",
+		},
+		{
+			policy: OverflowDropOldest,
+			want:   "a|e",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			gate := make(chan struct{})
+			sending := make(chan struct{}, 1)
+			var mu sync.Mutex
+			var sent []string
+			b := newBatcher(func(_ context.Context, batch []batchEntry) error {
+				sending <- struct{}{}
+				<-gate
+				strs := make([]string, len(batch))
+				for i, e := range batch {
+					strs[i] = string(e.data)
+				}
+				mu.Lock()
+				sent = append(sent, strings.Join(strs, ","))
+				mu.Unlock()
+				return nil
+			}, 1, 0, 0)
+			b.interval = 0
+			b.overflow = tt.policy
+			var hooked int64
+			b.hook = func(name string, level LEVEL, delta int64) {
+				hooked += delta
+			}
+
+			b.Write([]byte("a"))
+			done := make(chan struct{})
+			go func() {
+				// the buffer is full, a is sent and blocks
+				b.Write([]byte("b"))
+				close(done)
+			}()
+			<-sending
+			b.write([]byte("c"), INFO)
+			b.write([]byte("d"), INFO)
+			b.write([]byte("e"), INFO)
+			close(gate)
+			<-done
+			if err := b.Flush(); err != nil {
+				t.Error(err)
+			}
+			if got := strings.Join(sent, "|"); got != tt.want {
+				t.Errorf("batcher.write() sent %s, want %s", got, tt.want)
+			}
+			if b.Dropped() != 3 || hooked != 3 {
+				t.Errorf("batcher.Dropped() = %d, hook = %d, want 3", b.Dropped(), hooked)
+			}
+		})
+	}
+}
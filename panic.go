@@ -0,0 +1,129 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// PanicPolicy is the behavior after CapturePanics logged the panic
+type PanicPolicy uint8
+
+const (
+	// PanicLog logs the panic at FATAL without exiting, the panicking goroutine ends and the program continues
+	PanicLog PanicPolicy = iota
+	// PanicExit logs the panic at FATAL, runs the OnFatal hooks and exits with status 2 like the uncaught panic
+	PanicExit
+	// PanicRepanic logs the panic at FATAL and panics again with the recovered value
+	PanicRepanic
+)
+
+// PanicMessage is the message of the entry logging the captured panic
+const PanicMessage = "panic"
+
+// SetPanicPolicy sets the behavior after CapturePanics logged the panic, the default is PanicLog
+func (g *Glg) SetPanicPolicy(p PanicPolicy) *Glg {
+	g.setSwitches(func(s *switches) {
+		s.panicPolicy = p
+	})
+	return g
+}
+
+// CapturePanics logs the panic of the deferring function at FATAL with the panic value, the stack trace and the file:line
+// of the panic.
+// It must be deferred directly, e.g.
+//
+//	defer glg.Get().CapturePanics()
+//
+// Go has no process-wide panic handler, use Go to start the goroutines capturing their panics
+func (g *Glg) CapturePanics() {
+	if r := recover(); r != nil {
+		g.handlePanic(r)
+	}
+}
+
+// Go runs f in a new goroutine logging its panic by CapturePanics
+func (g *Glg) Go(f func()) {
+	go func() {
+		defer g.CapturePanics()
+		f()
+	}()
+}
+
+// SetPanicPolicy sets the behavior after CapturePanics of the global instance logged the panic
+func SetPanicPolicy(p PanicPolicy) *Glg {
+	return Get().SetPanicPolicy(p)
+}
+
+// CapturePanics logs the panic of the deferring function by the global instance, it must be deferred directly
+func CapturePanics() {
+	if r := recover(); r != nil {
+		Get().handlePanic(r)
+	}
+}
+
+// Go runs f in a new goroutine logging its panic by the global instance
+func Go(f func()) {
+	Get().Go(f)
+}
+
+// handlePanic logs the recovered value r and applies the panic policy, it is called by the deferred CapturePanics
+func (g *Glg) handlePanic(r interface{}) {
+	site := panicSite()
+	stack := string(debug.Stack())
+	format := g.blankFormat(3)
+	val := []interface{}{PanicMessage, Any("panic", r), String("stack", stack)}
+	g.output(FATAL, &replayEntry{caller: site}, format, val...)
+	switch g.opts().panicPolicy {
+	case PanicExit:
+		g.runFatalHooks(site, format, val)
+		g.Flush()
+		exit(2)
+	case PanicRepanic:
+		g.Flush()
+		panic(r)
+	}
+}
+
+// panicSite returns the file:line of the function which panicked, the frame above runtime.gopanic
+func panicSite() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	var panicking bool
+	for {
+		f, more := frames.Next()
+		switch {
+		case f.Function == "runtime.gopanic":
+			panicking = true
+		case panicking && !strings.HasPrefix(f.Function, "runtime."):
+			return f.File[strings.LastIndexByte(f.File, '/')+1:] + ":" + strconv.Itoa(f.Line)
+		}
+		if !more {
+			return "???:0"
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGlg_CapturePanics(t *testing.T) {
+	defer ReplaceExitFunc(exit)
+	ReplaceExitFunc(func(n int) {
+		panic(ExitError(n))
+	})
+	tests := []struct {
+		name   string
+		policy PanicPolicy
+		exit   bool
+		panics bool
+	}{
+		{
+			name:   "log",
+			policy: PanicLog,
+		},
+		{
+			name:   "exit",
+			policy: PanicExit,
+			exit:   true,
+		},
+		{
+			name:   "repanic",
+			policy: PanicRepanic,
+			panics: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			var hooked bool
+			g := New().SetMode(WRITER).SetWriter(buf).SetPanicPolicy(tt.policy).
+				OnFatal(func(e Entry) { hooked = e.Message == PanicMessage })
+			var recovered interface{}
+			err := testExit(2, func() {
+				defer func() {
+					recovered = recover()
+					if _, ok := recovered.(ExitError); ok {
+						panic(recovered)
+					}
+					panic(ExitError(2))
+				}()
+				func() {
+					defer g.CapturePanics()
+					var m map[string]int
+					m["boom"]++
+				}()
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := recovered.(ExitError); ok != tt.exit {
+				t.Errorf("recovered = %v, exit = %v", recovered, tt.exit)
+			}
+			if _, ok := recovered.(error); ok != (tt.exit || tt.panics) {
+				t.Errorf("recovered = %v, repanic = %v", recovered, tt.panics)
+			}
+			if hooked != tt.exit {
+				t.Errorf("fatal hooks ran = %v, want %v", hooked, tt.exit)
+			}
+			got := buf.String()
+			if !strings.Contains(got, "[FATAL]") || !strings.Contains(got, "assignment to entry in nil map") ||
+				!strings.Contains(got, "panic_test.go:") || !strings.Contains(got, "stack=") {
+				t.Errorf("output = %q", got)
+			}
+		})
+	}
+}
+
+func TestGlg_Go(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	g := New().SetMode(WRITER).SetWriter(pw).SetLineTraceMode(TraceLineNone)
+	g.Go(func() {
+		panic("worker failed")
+	})
+	sc := bufio.NewScanner(pr)
+	if !sc.Scan() || !strings.Contains(sc.Text(), "[FATAL]") || !strings.Contains(sc.Text(), "worker failed") {
+		t.Fatalf("output = %q, want the panic", sc.Text())
+	}
+	go g.Info("after the panic")
+	if !sc.Scan() || !strings.Contains(sc.Text(), "after the panic") {
+		t.Errorf("output = %q, want the entry after the panic", sc.Text())
+	}
+}
@@ -0,0 +1,121 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"sync/atomic"
+)
+
+const (
+	// poolTuneWindow is the number of the returned buffers after which the buffer size is reset to their high-water mark
+	poolTuneWindow = 1024
+	// poolTrimRatio is the ratio of the capacity to the buffer size above which the returned buffer is discarded
+	poolTrimRatio = 4
+	// poolMinTrim is the capacity below which the returned buffer is always kept
+	poolMinTrim = 4096
+)
+
+// PoolStats is the statistics of the buffer pool rendering the entries
+type PoolStats struct {
+	Gets      uint64 `json:"gets"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Discarded uint64 `json:"discarded"`
+	// HighWater is the largest entry rendered so far
+	HighWater uint64 `json:"high_water"`
+	// BufferSize is the capacity of the new buffers, the high-water mark of the recent entries
+	BufferSize uint64 `json:"buffer_size"`
+}
+
+// poolStats counts the buffer pool usage and tracks the high-water marks
+type poolStats struct {
+	gets      uint64
+	misses    uint64
+	puts      uint64
+	discarded uint64
+	highWater uint64
+	windowMax uint64
+}
+
+func (g *Glg) getBuffer() *bytes.Buffer {
+	atomic.AddUint64(&g.poolStats.gets, 1)
+	return g.buffer.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns b to the pool, the buffer size grows to the entry at once and shrinks to the recent high-water mark
+// every poolTuneWindow buffers. The buffers grown far beyond the size by the rare large entries are discarded
+func (g *Glg) putBuffer(b *bytes.Buffer) {
+	s := &g.poolStats
+	n := uint64(b.Len())
+	casMax(&s.highWater, n)
+	casMax(&s.windowMax, n)
+	size := atomic.LoadUint64(g.bs)
+	if n > size {
+		size = n
+		atomic.StoreUint64(g.bs, size)
+	}
+	if atomic.AddUint64(&s.puts, 1)%poolTuneWindow == 0 {
+		if size = atomic.SwapUint64(&s.windowMax, 0); size < minBufferSize {
+			size = minBufferSize
+		}
+		atomic.StoreUint64(g.bs, size)
+	}
+	if c := uint64(b.Cap()); c > poolMinTrim && c > size*poolTrimRatio {
+		atomic.AddUint64(&s.discarded, 1)
+		return
+	}
+	b.Reset()
+	g.buffer.Put(b)
+}
+
+// PoolStats returns the statistics of the buffer pool
+func (g *Glg) PoolStats() PoolStats {
+	s := &g.poolStats
+	ps := PoolStats{
+		Gets:       atomic.LoadUint64(&s.gets),
+		Misses:     atomic.LoadUint64(&s.misses),
+		Discarded:  atomic.LoadUint64(&s.discarded),
+		HighWater:  atomic.LoadUint64(&s.highWater),
+		BufferSize: atomic.LoadUint64(g.bs),
+	}
+	if ps.Gets > ps.Misses {
+		ps.Hits = ps.Gets - ps.Misses
+	}
+	return ps
+}
+
+// GetPoolStats returns the statistics of the buffer pool of the global instance
+func GetPoolStats() PoolStats {
+	return Get().PoolStats()
+}
+
+func casMax(addr *uint64, v uint64) {
+	for {
+		cur := atomic.LoadUint64(addr)
+		if v <= cur || atomic.CompareAndSwapUint64(addr, cur, v) {
+			return
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGlg_PoolStats(t *testing.T) {
+	g := New().SetMode(WRITER).SetWriter(io.Discard).EnablePoolBuffer(4)
+	before := g.PoolStats()
+	if before.Misses < 4 || before.BufferSize != minBufferSize {
+		t.Errorf("PoolStats() after EnablePoolBuffer = %+v", before)
+	}
+	for i := 0; i < 10; i++ {
+		g.Info("entry")
+	}
+	s := g.PoolStats()
+	if s.Gets-before.Gets != 10 || s.Hits+s.Misses != s.Gets {
+		t.Errorf("PoolStats() = %+v, want 10 more gets", s)
+	}
+	if s.HighWater <= minBufferSize || s.BufferSize != s.HighWater {
+		t.Errorf("PoolStats() = %+v, want the buffer size grown to the high-water mark", s)
+	}
+}
+
+func TestGlg_putBuffer(t *testing.T) {
+	g := New()
+	large := bytes.NewBufferString(strings.Repeat("x", 64*1024))
+	g.putBuffer(large)
+	if s := g.PoolStats(); s.BufferSize != 64*1024 || s.HighWater != 64*1024 || s.Discarded != 0 {
+		t.Fatalf("PoolStats() after large entry = %+v", s)
+	}
+
+	// the recent entries are small, so the size shrinks and the large buffer is trimmed
+	for i := 0; i < 2*poolTuneWindow; i++ {
+		b := g.getBuffer()
+		b.WriteString("small")
+		g.putBuffer(b)
+	}
+	s := g.PoolStats()
+	if s.BufferSize != minBufferSize || s.HighWater != 64*1024 {
+		t.Errorf("PoolStats() after the window = %+v, want the size shrunk", s)
+	}
+	g.putBuffer(bytes.NewBuffer(make([]byte, 0, 64*1024)))
+	if got := g.PoolStats().Discarded; got != s.Discarded+1 {
+		t.Errorf("Discarded = %d, want %d", got, s.Discarded+1)
+	}
+}
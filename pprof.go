@@ -0,0 +1,146 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultProfileSeconds is the duration of the CPU profile and the trace without the seconds parameter
+const defaultProfileSeconds = 30
+
+// servePprof serves the endpoints of net/http/pprof by runtime/pprof under any prefix,
+// net/http/pprof is not imported as it registers its handlers to http.DefaultServeMux by init
+func servePprof(w http.ResponseWriter, r *http.Request, name string) {
+	switch name {
+	case "":
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			// the links of the index are relative to the directory
+			http.Redirect(w, r, r.URL.Path+"/", http.StatusMovedPermanently)
+			return
+		}
+		servePprofIndex(w)
+	case "cmdline":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, strings.Join(os.Args, "\x00"))
+	case "profile":
+		serveProfile(w, r, "application/octet-stream", "profile", pprof.StartCPUProfile, pprof.StopCPUProfile)
+	case "trace":
+		serveProfile(w, r, "application/octet-stream", "trace", trace.Start, trace.Stop)
+	case "symbol":
+		servePprofSymbol(w, r)
+	default:
+		p := pprof.Lookup(name)
+		if p == nil {
+			http.Error(w, "unknown profile "+strconv.Quote(name), http.StatusNotFound)
+			return
+		}
+		debug, _ := strconv.Atoi(r.URL.Query().Get("debug"))
+		if debug != 0 {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+		}
+		if name == "heap" && r.URL.Query().Get("gc") != "" {
+			runtime.GC()
+		}
+		p.WriteTo(w, debug)
+	}
+}
+
+// servePprofIndex lists the profiles with the links relative to the index
+func servePprofIndex(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	b := new(bytes.Buffer)
+	b.WriteString("<html><head><title>profiles</title></head><body><table>\n")
+	for _, p := range pprof.Profiles() {
+		name := html.EscapeString(p.Name())
+		fmt.Fprintf(b, "<tr><td>%d</td><td><a href=\"%s?debug=1\">%s</a></td></tr>\n", p.Count(), name, name)
+	}
+	b.WriteString("</table>\n<a href=\"cmdline\">cmdline</a> <a href=\"profile\">profile</a> ")
+	b.WriteString("<a href=\"trace?seconds=5\">trace</a> <a href=\"symbol\">symbol</a>\n</body></html>\n")
+	w.Write(b.Bytes())
+}
+
+// serveProfile records the CPU profile or the trace for the seconds parameter, one at a time in the process
+func serveProfile(w http.ResponseWriter, r *http.Request, ctype, file string, start func(io.Writer) error, stop func()) {
+	sec, err := strconv.ParseFloat(r.URL.Query().Get("seconds"), 64)
+	if err != nil || sec <= 0 {
+		sec = defaultProfileSeconds
+	}
+	buf := new(bytes.Buffer)
+	if err = start(buf); err != nil {
+		http.Error(w, "could not start "+file+": "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	select {
+	case <-time.After(time.Duration(sec * float64(time.Second))):
+	case <-r.Context().Done():
+	}
+	stop()
+	w.Header().Set("Content-Type", ctype)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+file+`"`)
+	w.Write(buf.Bytes())
+}
+
+// servePprofSymbol looks up the program counters separated by '+' in the query or the POST body, as pprof -symbolize expects
+func servePprofSymbol(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	b := new(bytes.Buffer)
+	b.WriteString("num_symbols: 1\n")
+	var rd *bufio.Reader
+	if r.Method == http.MethodPost {
+		rd = bufio.NewReader(io.LimitReader(r.Body, 1<<20))
+	} else {
+		rd = bufio.NewReader(strings.NewReader(r.URL.RawQuery))
+	}
+	for {
+		word, err := rd.ReadSlice('+')
+		if err == nil {
+			word = word[:len(word)-1]
+		}
+		if pc, perr := strconv.ParseUint(string(word), 0, 64); perr == nil && pc != 0 {
+			if fn := runtime.FuncForPC(uintptr(pc)); fn != nil {
+				fmt.Fprintf(b, "%#x %s\n", pc, fn.Name())
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	w.Write(b.Bytes())
+}
@@ -0,0 +1,133 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type prefixSegment struct {
+	lit  string
+	name string
+}
+
+// prefixTemplate is parsed SetPrefix template evaluated per entry
+type prefixTemplate []prefixSegment
+
+var (
+	hostname     string
+	hostnameOnce sync.Once
+
+	pid = strconv.Itoa(os.Getpid())
+	app = filepath.Base(os.Args[0])
+)
+
+// parsePrefix parses prefix template, returns nil when the prefix has no placeholders
+func parsePrefix(pref string) prefixTemplate {
+	if !strings.Contains(pref, "{{") {
+		return nil
+	}
+	var pt prefixTemplate
+	for len(pref) > 0 {
+		i := strings.Index(pref, "{{")
+		if i < 0 {
+			pt = append(pt, prefixSegment{lit: pref})
+			break
+		}
+		j := strings.Index(pref[i:], "}}")
+		if j < 0 {
+			pt = append(pt, prefixSegment{lit: pref})
+			break
+		}
+		if i > 0 {
+			pt = append(pt, prefixSegment{lit: pref[:i]})
+		}
+		pt = append(pt, prefixSegment{name: strings.TrimSpace(pref[i+2 : i+j])})
+		pref = pref[i+j+2:]
+	}
+	for _, seg := range pt {
+		if seg.name != "" {
+			return pt
+		}
+	}
+	return nil
+}
+
+func (pt prefixTemplate) render(g *Glg, o *switches, fields []Field) string {
+	var sb strings.Builder
+	for _, seg := range pt {
+		if seg.name == "" {
+			sb.WriteString(seg.lit)
+			continue
+		}
+		sb.WriteString(g.prefixVar(o, seg.name, fields))
+	}
+	return sb.String()
+}
+
+func (g *Glg) prefixVar(o *switches, name string, fields []Field) string {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Key == name {
+			return string(fields[i].appendText(nil, o.enableHuman))
+		}
+	}
+	if fn, ok := g.prefixVars.Load(name); ok {
+		return fn.(func() string)()
+	}
+	if v, ok := builtinVar(name); ok {
+		return v
+	}
+	return "{{" + name + "}}"
+}
+
+// builtinVar returns the value of the built-in template variable
+func builtinVar(name string) (string, bool) {
+	switch name {
+	case "hostname":
+		hostnameOnce.Do(func() {
+			hostname, _ = os.Hostname()
+		})
+		return hostname, true
+	case "pid":
+		return pid, true
+	case "app":
+		return app, true
+	}
+	return "", false
+}
+
+// SetPrefixVar registers the variable for prefix templates, fields of the entry take precedence over the variable, fn is called for each entry.
+// Registering nil fn removes the variable
+func (g *Glg) SetPrefixVar(name string, fn func() string) *Glg {
+	if fn == nil {
+		g.prefixVars.Delete(name)
+		return g
+	}
+	g.prefixVars.Store(name, fn)
+	return g
+}
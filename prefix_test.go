@@ -0,0 +1,88 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_parsePrefix(t *testing.T) {
+	tests := []struct {
+		name string
+		pref string
+		want int
+	}{
+		{
+			name: "static",
+			pref: "GLG",
+			want: 0,
+		},
+		{
+			name: "unclosed",
+			pref: "GLG {{pid",
+			want: 0,
+		},
+		{
+			name: "template",
+			pref: "[{{app}}:{{pid}}] INFO",
+			want: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parsePrefix(tt.pref); len(got) != tt.want {
+				t.Errorf("parsePrefix() = %v, want %d segments", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_SetPrefixVar(t *testing.T) {
+	tenant := "a"
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().
+		SetPrefixVar("tenant", func() string { return tenant }).
+		SetPrefix(INFO, "tenant:{{tenant}} {{pid}} {{unknown}}")
+	g.Info("first")
+	tenant = "b"
+	g.Info("second")
+	want := "[tenant:a " + pid + " {{unknown}}]:\tfirst\n" +
+		"[tenant:b " + pid + " {{unknown}}]:\tsecond\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Glg.SetPrefix() = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	g.EnableJSON().Info("json")
+	if !strings.Contains(buf.String(), `"level":"tenant:b `+pid) {
+		t.Errorf("Glg.SetPrefix() json = %s", buf.String())
+	}
+
+	buf.Reset()
+	g.DisableJSON().SetPrefixVar("tenant", nil).Info("removed")
+	if !strings.Contains(buf.String(), "tenant:{{tenant}}") {
+		t.Errorf("Glg.SetPrefixVar(nil) = %s", buf.String())
+	}
+}
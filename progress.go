@@ -0,0 +1,148 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultProgressInterval is the default maximum interval of the progress entries
+	DefaultProgressInterval = 5 * time.Second
+	// DefaultProgressStep is the default percentage of the progress logged regardless of the interval
+	DefaultProgressStep = 10
+)
+
+// Progress logs the progress of the batch job at INFO with the done count, the ratio and the ETA.
+// The entries are bounded by the interval and the percentage step, e.g. every 5s or 10% by default
+type Progress struct {
+	g        *Glg
+	name     string
+	total    int64
+	start    time.Time
+	interval time.Duration
+	step     int64
+
+	mu       sync.Mutex
+	done     int64
+	lastAt   time.Time
+	lastStep int64
+	finished bool
+}
+
+// Progress returns the progress of name to total, total <= 0 means the total is unknown and only the done count is logged
+func (g *Glg) Progress(name string, total int64) *Progress {
+	now := time.Now()
+	return &Progress{
+		g:        g,
+		name:     name,
+		total:    total,
+		start:    now,
+		interval: DefaultProgressInterval,
+		step:     DefaultProgressStep,
+		lastAt:   now,
+	}
+}
+
+// NewProgress returns the progress of name to total logged by the global logger
+func NewProgress(name string, total int64) *Progress {
+	return Get().Progress(name, total)
+}
+
+// SetInterval sets the maximum interval of the progress entries, d <= 0 disables the interval
+func (p *Progress) SetInterval(d time.Duration) *Progress {
+	p.mu.Lock()
+	p.interval = d
+	p.mu.Unlock()
+	return p
+}
+
+// SetStep sets the percentage of the progress logged regardless of the interval, percent <= 0 disables the step
+func (p *Progress) SetStep(percent int) *Progress {
+	p.mu.Lock()
+	p.step = int64(percent)
+	p.mu.Unlock()
+	return p
+}
+
+// Add adds n to the done count and logs the progress when the interval or the step is reached,
+// the finish is logged once when the done count reaches total
+func (p *Progress) Add(n int64) error {
+	p.mu.Lock()
+	p.done += n
+	now := time.Now()
+	var log bool
+	if p.total > 0 && p.done >= p.total {
+		log = !p.finished
+		p.finished = true
+	} else if p.interval > 0 && now.Sub(p.lastAt) >= p.interval {
+		log = true
+	} else if p.step > 0 && p.total > 0 && p.done*100/p.total >= p.lastStep+p.step {
+		log = true
+	}
+	if !log {
+		p.mu.Unlock()
+		return nil
+	}
+	vals := p.vals(now)
+	p.mu.Unlock()
+	return p.g.out(INFO, p.g.blankFormat(len(vals)), vals...)
+}
+
+// Done logs the finish with the done count when it is not logged yet
+func (p *Progress) Done() error {
+	p.mu.Lock()
+	if p.finished {
+		p.mu.Unlock()
+		return nil
+	}
+	p.finished = true
+	vals := p.vals(time.Now())
+	p.mu.Unlock()
+	return p.g.out(INFO, p.g.blankFormat(len(vals)), vals...)
+}
+
+// vals returns the values of the progress entry and moves the last logged point, mu must be held
+func (p *Progress) vals(now time.Time) []interface{} {
+	p.lastAt = now
+	elapsed := now.Sub(p.start)
+	msg := p.name + " in progress"
+	if p.finished {
+		msg = p.name + " finished"
+	}
+	vals := []interface{}{msg, Int64("done", p.done)}
+	if p.total <= 0 {
+		return append(vals, Dur(TimerElapsedKey, elapsed))
+	}
+	if p.step > 0 {
+		p.lastStep = p.done * 100 / p.total / p.step * p.step
+	}
+	vals = append(vals, Int64("total", p.total), Percent("progress", float64(p.done)/float64(p.total)), Dur(TimerElapsedKey, elapsed))
+	if !p.finished && p.done > 0 {
+		eta := time.Duration(float64(elapsed) * float64(p.total-p.done) / float64(p.done))
+		vals = append(vals, Dur("eta", eta.Round(time.Millisecond)))
+	}
+	return vals
+}
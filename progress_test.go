@@ -0,0 +1,121 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgress_Add(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int64
+		step     int
+		interval time.Duration
+		adds     int
+		want     []string
+	}{
+		{
+			name:  "logs every step and finish",
+			total: 100,
+			step:  25,
+			adds:  100,
+			want: []string{
+				"migrate in progress\tdone=25 total=100 progress=0.25",
+				"migrate in progress\tdone=50 total=100 progress=0.5",
+				"migrate in progress\tdone=75 total=100 progress=0.75",
+				"migrate finished\tdone=100 total=100 progress=1",
+			},
+		},
+		{
+			name:     "logs every interval",
+			total:    100,
+			interval: time.Nanosecond,
+			adds:     3,
+			want: []string{
+				"migrate in progress\tdone=1 total=100",
+				"migrate in progress\tdone=2 total=100",
+				"migrate in progress\tdone=3 total=100",
+			},
+		},
+		{
+			name: "unknown total logs no step",
+			adds: 50,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone)
+			p := g.Progress("migrate", tt.total).SetStep(tt.step).SetInterval(tt.interval)
+			if tt.interval > 0 {
+				time.Sleep(time.Millisecond)
+			}
+			for i := 0; i < tt.adds; i++ {
+				if err := p.Add(1); err != nil {
+					t.Fatalf("Add() error = %v", err)
+				}
+				if tt.interval > 0 {
+					time.Sleep(time.Millisecond)
+				}
+			}
+			var lines []string
+			if out := strings.TrimSpace(buf.String()); out != "" {
+				lines = strings.Split(out, "\n")
+			}
+			if len(lines) != len(tt.want) {
+				t.Fatalf("Add() output = %q, want %d lines", buf.String(), len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(lines[i], want) {
+					t.Errorf("Add() line %d = %q, want %q", i, lines[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestProgress_Done(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone)
+	p := g.Progress("import", 0)
+	p.Add(7)
+	if err := p.Done(); err != nil {
+		t.Fatalf("Done() error = %v", err)
+	}
+	p.Done()
+	if got := buf.String(); strings.Count(got, "\n") != 1 || !strings.Contains(got, "import finished\tdone=7 elapsed=") {
+		t.Errorf("Done() output = %q", got)
+	}
+
+	buf.Reset()
+	p = g.Progress("copy", 10)
+	p.Add(10)
+	p.Done()
+	if got := buf.String(); strings.Count(got, "\n") != 1 || strings.Contains(got, "eta=") {
+		t.Errorf("finished progress output = %q", got)
+	}
+}
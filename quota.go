@@ -0,0 +1,161 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Quota bounds the total bytes of the log files and their segments,
+// the oldest segments across all files are deleted when the total exceeds the max.
+// The current files are never deleted, so the max should leave room for their max sizes.
+// The segments are listed again only after the rotation, Usage and Enforce, the writes over the max do not scan the directories
+type Quota struct {
+	mu    sync.Mutex
+	max   int64
+	used  int64
+	files map[*LogFile]struct{}
+	segs  []segment
+	dirty int32
+}
+
+// NewQuota returns Quota of max bytes
+func NewQuota(max int64) *Quota {
+	return &Quota{
+		max:   max,
+		files: make(map[*LogFile]struct{}),
+		dirty: 1,
+	}
+}
+
+// Usage returns the total bytes of the files and their segments
+func (q *Quota) Usage() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rescan()
+	q.used = q.usage()
+	return q.used
+}
+
+// Enforce deletes the oldest segments until the total bytes is within the max
+func (q *Quota) Enforce() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rescan()
+	return q.enforce()
+}
+
+func (q *Quota) add(f *LogFile) {
+	q.mu.Lock()
+	q.files[f] = struct{}{}
+	q.rescan()
+	q.enforce()
+	q.mu.Unlock()
+}
+
+func (q *Quota) remove(f *LogFile) {
+	q.mu.Lock()
+	delete(q.files, f)
+	q.mu.Unlock()
+	q.rotated()
+}
+
+// rotated marks the segments to be listed again, it is called by the rotation holding the lock of the file
+func (q *Quota) rotated() {
+	atomic.StoreInt32(&q.dirty, 1)
+}
+
+// grow adds the written bytes, the segments are deleted only when the total may exceed the max
+func (q *Quota) grow(n int64) {
+	q.mu.Lock()
+	q.used += n
+	if q.used > q.max {
+		if atomic.LoadInt32(&q.dirty) != 0 {
+			q.rescan()
+		}
+		q.enforce()
+	}
+	q.mu.Unlock()
+}
+
+type segment struct {
+	path string
+	size int64
+}
+
+// enforce deletes the oldest listed segments until the total bytes is within the max
+func (q *Quota) enforce() error {
+	used := q.usage()
+	var err error
+	segs := q.segs[:0]
+	for _, seg := range q.segs {
+		if used > q.max {
+			rerr := os.Remove(seg.path)
+			if rerr == nil || os.IsNotExist(rerr) {
+				used -= seg.size
+				continue
+			}
+			err = rerr
+		}
+		segs = append(segs, seg)
+	}
+	q.segs = segs
+	q.used = used
+	return err
+}
+
+// usage returns the total bytes of the files and the listed segments
+func (q *Quota) usage() (used int64) {
+	for f := range q.files {
+		used += f.Size()
+	}
+	for _, seg := range q.segs {
+		used += seg.size
+	}
+	return used
+}
+
+// rescan lists the segments of the files, oldest first across the files
+func (q *Quota) rescan() {
+	atomic.StoreInt32(&q.dirty, 0)
+	q.segs = q.segs[:0]
+	for f := range q.files {
+		paths, _ := f.Segments()
+		for _, path := range paths {
+			fi, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			q.segs = append(q.segs, segment{path: path, size: fi.Size()})
+		}
+	}
+	// segment paths end with the timestamp
+	segs := q.segs
+	sort.Slice(segs, func(i, j int) bool {
+		return segs[i].path[len(segs[i].path)-len(segmentTimeFormat):] < segs[j].path[len(segs[j].path)-len(segmentTimeFormat):]
+	})
+}
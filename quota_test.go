@@ -0,0 +1,100 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQuota(t *testing.T) {
+	dir := t.TempDir()
+	q := NewQuota(100)
+	a, err := NewLogFile(filepath.Join(dir, "a.log"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, err := NewLogFile(filepath.Join(dir, "b.log"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	a.SetMaxSize(20).SetQuota(q)
+	b.SetMaxSize(20).SetQuota(q)
+
+	line := bytes.Repeat([]byte("x"), 19)
+	line = append(line, '\n')
+	for i := 0; i < 10; i++ {
+		a.Write(line)
+		b.Write(line)
+		if used := q.Usage(); used > 100 {
+			t.Fatalf("Quota.Usage() = %d after %d writes", used, i)
+		}
+	}
+	asegs, _ := a.Segments()
+	bsegs, _ := b.Segments()
+	if len(asegs)+len(bsegs) != 3 {
+		t.Errorf("Quota segments = %v %v", asegs, bsegs)
+	}
+	if q.Usage() != 100 {
+		t.Errorf("Quota.Usage() = %d, want 100", q.Usage())
+	}
+}
+
+func TestQuota_Rescan(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.log")
+	q := NewQuota(10)
+	a, err := NewLogFile(path, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	a.SetQuota(q)
+	a.Write(bytes.Repeat([]byte("x"), 20))
+
+	// the segment added behind the quota is not seen by the writes over the max
+	old := path + "." + time.Now().Add(-time.Hour).Format(segmentTimeFormat)
+	if err = os.WriteFile(old, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a.Write([]byte("y\n"))
+	if _, err = os.Stat(old); err != nil {
+		t.Errorf("segment deleted without rescan: %v", err)
+	}
+
+	if err = a.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	a.Write([]byte("z\n"))
+	if _, err = os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("segment is kept after rotation: %v", err)
+	}
+	if used := q.Usage(); used > 10 {
+		t.Errorf("Quota.Usage() = %d, want <= 10", used)
+	}
+}
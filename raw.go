@@ -0,0 +1,73 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// rawFormat is the format of the raw message written by WriteRaw
+const rawFormat = "%s"
+
+// rawMessage is the preformatted message copied after the header without fmt
+type rawMessage []byte
+
+// Format implements fmt.Formatter for the JSON, layout and sanitized outputs
+func (r rawMessage) Format(f fmt.State, verb rune) {
+	f.Write(r)
+}
+
+// writeRaw writes val when it is the raw message and reports it is written
+func writeRaw(b *bytes.Buffer, format string, val []interface{}) bool {
+	if format != rawFormat || len(val) != 1 {
+		return false
+	}
+	r, ok := val[0].(rawMessage)
+	if ok {
+		b.Write(r)
+	}
+	return ok
+}
+
+// WriteRaw outputs p as the message of the level, p is copied after the header without formatting,
+// e.g. for bridging the lines of another logger. The trailing newline of p is removed
+func (g *Glg) WriteRaw(lv LEVEL, p []byte) error {
+	return g.out(lv, rawFormat, rawMessage(bytes.TrimSuffix(p, []byte(rc))))
+}
+
+// InfoBytes outputs Info level log of the preformatted p without formatting
+func (g *Glg) InfoBytes(p []byte) error {
+	return g.out(INFO, rawFormat, rawMessage(bytes.TrimSuffix(p, []byte(rc))))
+}
+
+// WriteRaw outputs p as the message of the level without formatting
+func WriteRaw(lv LEVEL, p []byte) error {
+	return Get().out(lv, rawFormat, rawMessage(bytes.TrimSuffix(p, []byte(rc))))
+}
+
+// InfoBytes outputs Info level log of the preformatted p without formatting
+func InfoBytes(p []byte) error {
+	return Get().out(INFO, rawFormat, rawMessage(bytes.TrimSuffix(p, []byte(rc))))
+}
@@ -0,0 +1,84 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGlg_WriteRaw(t *testing.T) {
+	payload := []byte("level=info msg=\"from other logger\" 100%d\n")
+	tests := []struct {
+		name string
+		json bool
+		max  int
+		want string
+	}{
+		{
+			name: "text",
+			want: "[WARN]:\tlevel=info msg=\"from other logger\" 100%d\n",
+		},
+		{
+			name: "json",
+			json: true,
+			want: `"detail":"level=info msg=\"from other logger\" 100%d"}` + "\n",
+		},
+		{
+			name: "truncated",
+			max:  10,
+			want: "[WARN]:\tlevel=info...(truncated 30 bytes)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone).SetMaxMessageSize(tt.max)
+			if tt.json {
+				g.EnableJSON()
+			}
+			if err := g.WriteRaw(WARN, payload); err != nil {
+				t.Fatalf("WriteRaw() error = %v", err)
+			}
+			if !strings.HasSuffix(buf.String(), tt.want) {
+				t.Errorf("WriteRaw() = %q, want suffix %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_InfoBytes_Allocs(t *testing.T) {
+	g := New().SetMode(WRITER).SetWriter(io.Discard).SetLineTraceMode(TraceLineNone)
+	p := []byte("preformatted message")
+	raw := testing.AllocsPerRun(100, func() {
+		g.InfoBytes(p)
+	})
+	formatted := testing.AllocsPerRun(100, func() {
+		g.Infof("%s", p)
+	})
+	if raw > formatted {
+		t.Errorf("InfoBytes() allocs = %v, more than Infof() allocs = %v", raw, formatted)
+	}
+}
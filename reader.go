@@ -0,0 +1,419 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	json "github.com/goccy/go-json"
+)
+
+// maxReadLine is the longest line Reader accepts
+const maxReadLine = 64 << 20
+
+// Entry is the log entry parsed by Reader
+type Entry struct {
+	Time    time.Time
+	Level   LEVEL
+	Tag     string
+	Caller  string
+	Message string
+	Fields  []Field
+}
+
+// Reader parses glg text and JSON logs back into entries.
+// Text lines which do not start an entry are treated as continuation lines of the previous entry,
+// lines written with SetLineFormat layouts are returned as messages of UNKNOWN entries
+type Reader struct {
+	sc       *bufio.Scanner
+	buf      []byte
+	marker   string
+	next     *Entry
+	nextJSON bool
+}
+
+// NewReader returns the Reader reading the log from r
+func NewReader(r io.Reader) *Reader {
+	rd := &Reader{
+		buf:    make([]byte, 0, 64<<10),
+		marker: DefaultContinuationMarker,
+	}
+	rd.Reset(r)
+	return rd
+}
+
+// Reset discards the state and reads from r, the buffer and the marker are kept for the next log
+func (r *Reader) Reset(rd io.Reader) {
+	r.sc = bufio.NewScanner(rd)
+	r.sc.Buffer(r.buf[:0], maxReadLine)
+	r.next, r.nextJSON = nil, false
+}
+
+// SetContinuationMarker sets the prefix of continuation lines trimmed from the folded messages
+func (r *Reader) SetContinuationMarker(marker string) *Reader {
+	r.marker = marker
+	return r
+}
+
+// Read returns the next entry, it returns io.EOF when there are no more entries
+func (r *Reader) Read() (*Entry, error) {
+	e := r.next
+	r.next = nil
+	if e != nil && r.nextJSON {
+		return e, nil
+	}
+	for r.sc.Scan() {
+		line := stripColor(r.sc.Text())
+		n, isJSON := parseEntry(line)
+		if n != nil {
+			if e != nil {
+				r.next, r.nextJSON = n, isJSON
+				return e.split(), nil
+			}
+			if isJSON {
+				return n, nil
+			}
+			e = n
+			continue
+		}
+		if e == nil {
+			return &Entry{Level: UNKNOWN, Message: line}, nil
+		}
+		e.Message += rc + strings.TrimPrefix(line, r.marker)
+	}
+	if err := r.sc.Err(); err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, io.EOF
+	}
+	return e.split(), nil
+}
+
+// String returns the entry in the glg text format without the trailing newline
+func (e *Entry) String() string {
+	b := new(bytes.Buffer)
+	if !e.Time.IsZero() {
+		b.WriteString(e.Time.Format(timeFormat))
+		b.WriteString(tab)
+	}
+	if tag := e.tag(); tag != "" {
+		b.WriteString("[" + tag + sep)
+	}
+	if e.Caller != "" {
+		b.WriteString("(" + e.Caller + "):" + tab)
+	}
+	b.WriteString(e.Message)
+	if len(e.Fields) != 0 {
+		b.WriteString(tab)
+		writeFields(b, e.Fields, 0, false)
+	}
+	return b.String()
+}
+
+// MarshalJSON implements json.Marshaler, the entry is encoded in the glg JSON format
+func (e *Entry) MarshalJSON() ([]byte, error) {
+	je := jsonEntry{
+		Level: e.tag(),
+		File:  e.Caller,
+	}
+	if !e.Time.IsZero() {
+		je.Date = e.Time.Format(timeFormat)
+	}
+	if e.Message != "" {
+		je.Detail = e.Message
+	}
+	if len(e.Fields) != 0 {
+		je.Fields = &jsonFields{fields: e.Fields}
+	}
+	return json.Marshal(je)
+}
+
+func (e *Entry) tag() string {
+	if e.Tag == "" && e.Level != UNKNOWN {
+		return e.Level.String()
+	}
+	return e.Tag
+}
+
+// split moves the trailing logfmt fields of the text message to Fields
+func (e *Entry) split() *Entry {
+	i := strings.LastIndex(e.Message, tab)
+	if i < 0 || strings.Contains(e.Message[i:], rc) {
+		return e
+	}
+	if fields, ok := parseLogfmt(e.Message[i+len(tab):]); ok {
+		e.Message, e.Fields = e.Message[:i], fields
+	}
+	return e
+}
+
+// stripColor removes ANSI color sequences from the line
+func stripColor(line string) string {
+	if !strings.Contains(line, "\033[") {
+		return line
+	}
+	var sb strings.Builder
+	for {
+		i := strings.Index(line, "\033[")
+		if i < 0 {
+			sb.WriteString(line)
+			return sb.String()
+		}
+		sb.WriteString(line[:i])
+		line = line[i+2:]
+		j := strings.IndexByte(line, 'm')
+		if j < 0 {
+			return sb.String()
+		}
+		line = line[j+1:]
+	}
+}
+
+// parseEntry parses the line starting an entry, it returns nil for the continuation lines
+func parseEntry(line string) (e *Entry, isJSON bool) {
+	if strings.HasPrefix(line, "{") {
+		if e = parseJSONEntry(line); e != nil {
+			return e, true
+		}
+	}
+	e = &Entry{Level: UNKNOWN}
+	line = trimLevelToken(line)
+	if !strings.HasPrefix(line, "[") {
+		i := strings.Index(line, lsep)
+		if i <= 0 {
+			return nil, false
+		}
+		t, err := time.ParseInLocation(timeFormat, line[:i], time.Local)
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339Nano, line[:i]); err != nil {
+				return nil, false
+			}
+		}
+		e.Time = t
+		line = line[i+len(tab):]
+	}
+	i := strings.Index(line, sep)
+	if i < 0 {
+		return nil, false
+	}
+	e.Tag = line[1:i]
+	e.Level = TagStringToLevel(e.Tag)
+	line = line[i+sepl:]
+	if strings.HasPrefix(line, "(") {
+		if i = strings.Index(line, "):"+tab); i > 0 {
+			e.Caller = line[1:i]
+			line = line[i+len("):"+tab):]
+		}
+	}
+	e.Message = line
+	return e, false
+}
+
+// parseLogfmt parses space separated key=value pairs, ok is false unless s is entirely logfmt
+func parseLogfmt(s string) (fields []Field, ok bool) {
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '=')
+		if i <= 0 || strings.ContainsAny(s[:i], " \"") {
+			return nil, false
+		}
+		key := s[:i]
+		s = s[i+1:]
+		if strings.HasPrefix(s, `"`) {
+			end := quotedLen(s)
+			if end < 0 {
+				return nil, false
+			}
+			val, err := strconv.Unquote(s[:end])
+			if err != nil {
+				return nil, false
+			}
+			fields = append(fields, String(key, val))
+			s = s[end:]
+		} else {
+			end := strings.IndexByte(s, ' ')
+			if end < 0 {
+				end = len(s)
+			}
+			fields = append(fields, logfmtField(key, s[:end]))
+			s = s[end:]
+		}
+		if len(s) > 0 {
+			if s[0] != ' ' {
+				return nil, false
+			}
+			s = s[1:]
+		}
+	}
+	return fields, len(fields) != 0
+}
+
+// quotedLen returns the length of the Go quoted string at the head of s, or -1
+func quotedLen(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// logfmtField types the unquoted logfmt value
+func logfmtField(key, val string) Field {
+	if n, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return Int64(key, n)
+	}
+	if n, err := strconv.ParseUint(val, 10, 64); err == nil {
+		return Uint64(key, n)
+	}
+	if f, err := strconv.ParseFloat(val, 64); err == nil && strings.ContainsAny(val, ".eE") {
+		return Float64(key, f)
+	}
+	if b, err := strconv.ParseBool(val); err == nil && (val == "true" || val == "false") {
+		return Bool(key, b)
+	}
+	return String(key, val)
+}
+
+// parseJSONEntry parses the JSON entry including the Cloud Logging format
+func parseJSONEntry(line string) *Entry {
+	fields, err := decodeFields([]byte(line))
+	if err != nil {
+		return nil
+	}
+	return entryOf(fields)
+}
+
+// entryOf returns the entry of the decoded JSON or binary entry, unknown keys are kept as fields
+func entryOf(fields []Field) *Entry {
+	e := &Entry{Level: UNKNOWN}
+	var hasTS bool
+	for _, f := range fields {
+		switch f.Key {
+		case "date":
+			if s, ok := f.Value().(string); ok && !hasTS {
+				e.Time, _ = time.ParseInLocation(timeFormat, s, time.Local)
+			}
+			continue
+		case "time":
+			switch t := f.Value().(type) {
+			case string:
+				e.Time, _ = time.Parse(time.RFC3339Nano, t)
+			case time.Time:
+				e.Time = t
+			}
+			continue
+		case "ts":
+			if n, ok := f.Value().(int64); ok {
+				e.Time, hasTS = time.UnixMilli(n).UTC(), true
+			}
+			continue
+		case "level", "severity":
+			if s, ok := f.Value().(string); ok {
+				e.Tag = s
+				e.Level = TagStringToLevel(s)
+				continue
+			}
+		case "file":
+			if s, ok := f.Value().(string); ok {
+				e.Caller = s
+				continue
+			}
+		case "detail", "message":
+			e.Message = detailMessage(f.Value())
+			continue
+		case "fields":
+			if f.kind == fieldGroup {
+				e.Fields = append(e.Fields, f.iface.([]Field)...)
+				continue
+			}
+		}
+		e.Fields = append(e.Fields, f)
+	}
+	return e
+}
+
+// decodeFields decodes the JSON object keeping the order of the keys, nested objects are decoded as groups
+func decodeFields(data []byte) ([]Field, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	var fields []Field
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := tok.(string)
+		var raw json.RawMessage
+		if err = dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		if bytes.HasPrefix(raw, []byte("{")) {
+			group, err := decodeFields(raw)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, Group(key, group...))
+			continue
+		}
+		vd := json.NewDecoder(bytes.NewReader(raw))
+		vd.UseNumber()
+		var v interface{}
+		if err = vd.Decode(&v); err != nil {
+			return nil, err
+		}
+		fields = append(fields, jsonField(key, v))
+	}
+	return fields, nil
+}
+
+// jsonField types the decoded JSON value
+func jsonField(key string, v interface{}) Field {
+	switch t := v.(type) {
+	case string:
+		return String(key, t)
+	case bool:
+		return Bool(key, t)
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return Int64(key, n)
+		}
+		if n, err := strconv.ParseUint(string(t), 10, 64); err == nil {
+			return Uint64(key, n)
+		}
+		f, _ := t.Float64()
+		return Float64(key, f)
+	}
+	return Any(key, v)
+}
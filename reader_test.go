@@ -0,0 +1,209 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readAll(t *testing.T, r *Reader) []*Entry {
+	t.Helper()
+	var entries []*Entry
+	for {
+		e, err := r.Read()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+}
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(g *Glg)
+		want []Entry
+	}{
+		{
+			name: "text",
+			log: func(g *Glg) {
+				g.Info("hello", F("id", 1), F("msg", "a b"))
+				g.Warn("only message")
+				g.Info(F("ok", true))
+			},
+			want: []Entry{
+				{Level: INFO, Tag: "INFO", Message: "hello", Fields: []Field{Int64("id", 1), String("msg", "a b")}},
+				{Level: WARN, Tag: "WARN", Message: "only message"},
+				{Level: INFO, Tag: "INFO", Fields: []Field{Bool("ok", true)}},
+			},
+		},
+		{
+			name: "text caller",
+			log: func(g *Glg) {
+				g.SetLineTraceMode(TraceLineShort).Error("failed", F("rate", 0.5))
+			},
+			want: []Entry{
+				{Level: ERR, Tag: "ERR", Caller: "reader_test.go:", Message: "failed", Fields: []Field{Float64("rate", 0.5)}},
+			},
+		},
+		{
+			name: "text folded",
+			log: func(g *Glg) {
+				g.SetMultiLineMode(MultiLineFold).Info("first\nsecond", F("n", 2))
+				g.Info("next")
+			},
+			want: []Entry{
+				{Level: INFO, Tag: "INFO", Message: "first\nsecond", Fields: []Field{Int64("n", 2)}},
+				{Level: INFO, Tag: "INFO", Message: "next"},
+			},
+		},
+		{
+			name: "json",
+			log: func(g *Glg) {
+				g.EnableJSON().Info("hello", F("b", "x"), F("a", 1), Group("http", F("status", 200)))
+				g.Warn("a", "b")
+			},
+			want: []Entry{
+				{Level: INFO, Tag: "INFO", Message: "hello", Fields: []Field{String("b", "x"), Int64("a", 1), Group("http", Int64("status", 200))}},
+				{Level: WARN, Tag: "WARN", Message: "a b"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetLineTraceMode(TraceLineNone)
+			tt.log(g)
+			got := readAll(t, NewReader(buf))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Reader.Read() = %d entries, want %d", len(got), len(tt.want))
+			}
+			for i, e := range got {
+				want := tt.want[i]
+				if want.Caller != "" && strings.HasPrefix(e.Caller, want.Caller) {
+					want.Caller = e.Caller
+				}
+				if !reflect.DeepEqual(*e, want) {
+					t.Errorf("Reader.Read() = %#v, want %#v", *e, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReader_Time(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+		want time.Time
+	}{
+		{
+			name: "text",
+			log:  "2021-03-04 05:06:07\t[INFO]:\thello\n",
+			want: time.Date(2021, 3, 4, 5, 6, 7, 0, time.Local),
+		},
+		{
+			name: "json epoch",
+			log:  `{"date":"2021-03-04 05:06:07","ts":1614834367123,"level":"INFO","detail":"hello"}` + "\n",
+			want: time.Date(2021, 3, 4, 5, 6, 7, 123e6, time.UTC),
+		},
+		{
+			name: "cloud logging",
+			log:  `{"severity":"INFO","message":"hello","time":"2021-03-04T05:06:07.5Z"}` + "\n",
+			want: time.Date(2021, 3, 4, 5, 6, 7, 5e8, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readAll(t, NewReader(strings.NewReader(tt.log)))
+			if len(got) != 1 || !got[0].Time.Equal(tt.want) || got[0].Level != INFO || got[0].Message != "hello" {
+				t.Errorf("Reader.Read() = %#v, want time %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReader_LocalTime(t *testing.T) {
+	// time.Local is not replaced as it is read by the other goroutines such as fastime
+	want := time.Date(2021, 3, 4, 5, 6, 7, 0, time.Local)
+	if _, offset := want.Zone(); offset == 0 {
+		t.Skip("the local time zone is UTC, run with TZ such as Asia/Tokyo")
+	}
+	e := &Entry{Time: want, Level: INFO, Message: "hello"}
+	je, err := e.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := readAll(t, NewReader(strings.NewReader(e.String()+"\n"+string(je)+"\n")))
+	if len(got) != 2 || !got[0].Time.Equal(want) || !got[1].Time.Equal(want) {
+		t.Errorf("Reader.Read() = %#v, want time %v", got, want)
+	}
+}
+
+func TestReader_Plain(t *testing.T) {
+	log := "plain line\n" + Red("[ERR]:\tcolored") + "\n" + "raw continuation\n"
+	got := readAll(t, NewReader(strings.NewReader(log)))
+	want := []*Entry{
+		{Level: UNKNOWN, Message: "plain line"},
+		{Level: ERR, Tag: "ERR", Message: "colored\nraw continuation"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Reader.Read() = %#v, want %#v", got, want)
+	}
+}
+
+func TestEntry_String(t *testing.T) {
+	e := &Entry{
+		Time:    time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+		Level:   ERR,
+		Caller:  "main.go:10",
+		Message: "failed",
+		Fields:  []Field{String("path", "/a b"), Group("http", Int("status", 500))},
+	}
+	if got, want := e.String(), "2021-03-04 05:06:07\t[ERR]:\t(main.go:10):\tfailed\tpath=\"/a b\" http.status=500"; got != want {
+		t.Errorf("Entry.String() = %q, want %q", got, want)
+	}
+	got, err := e.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"date":"2021-03-04 05:06:07","level":"ERR","file":"main.go:10","detail":"failed","fields":{"path":"/a b","http":{"status":500}}}`
+	if string(got) != want {
+		t.Errorf("Entry.MarshalJSON() = %s, want %s", got, want)
+	}
+	r, err := NewReader(strings.NewReader(e.String())).Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.String() != e.String() {
+		t.Errorf("Reader.Read() = %q, want %q", r.String(), e.String())
+	}
+}
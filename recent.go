@@ -0,0 +1,113 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import "sync"
+
+// DefaultRecentSize is the number of the entries kept by EnableRecent when the size is not positive
+const DefaultRecentSize = 1000
+
+// RecentBuffer is the ring buffer writer keeping the last entries, each Write is one entry
+type RecentBuffer struct {
+	mu      sync.Mutex
+	entries [][]byte
+	next    int
+	full    bool
+}
+
+// NewRecentBuffer returns RecentBuffer keeping the last size entries
+func NewRecentBuffer(size int) *RecentBuffer {
+	if size <= 0 {
+		size = DefaultRecentSize
+	}
+	return &RecentBuffer{entries: make([][]byte, size)}
+}
+
+// Write keeps the copy of p as the latest entry, the oldest entry is discarded when the buffer is full
+func (r *RecentBuffer) Write(p []byte) (int, error) {
+	e := make([]byte, len(p))
+	copy(e, p)
+	r.mu.Lock()
+	r.entries[r.next] = e
+	if r.next++; r.next == len(r.entries) {
+		r.next, r.full = 0, true
+	}
+	r.mu.Unlock()
+	return len(p), nil
+}
+
+// Entries returns the last n entries oldest first, n <= 0 returns all kept entries
+func (r *RecentBuffer) Entries(n int) [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	size := r.next
+	if r.full {
+		size = len(r.entries)
+	}
+	if n <= 0 || n > size {
+		n = size
+	}
+	es := make([][]byte, 0, n)
+	for i := r.next - n; i < r.next; i++ {
+		es = append(es, r.entries[(i+len(r.entries))%len(r.entries)])
+	}
+	return es
+}
+
+// Len returns the number of the kept entries
+func (r *RecentBuffer) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.full {
+		return len(r.entries)
+	}
+	return r.next
+}
+
+// Name returns the name of the buffer in Status
+func (r *RecentBuffer) Name() string {
+	return "recent"
+}
+
+// EnableRecent keeps the last size entries of every enabled level in JSON regardless of the modes,
+// they are served by DebugHandler and returned by Recent
+func (g *Glg) EnableRecent(size int) *Glg {
+	r := NewRecentBuffer(size)
+	g.configMu.Lock()
+	g.recent = r
+	g.configMu.Unlock()
+	return g.Route(Route{Writer: r, JSON: LevelJSONOn})
+}
+
+// Recent returns the buffer of EnableRecent, nil when it is not enabled
+func (g *Glg) Recent() *RecentBuffer {
+	g.configMu.Lock()
+	defer g.configMu.Unlock()
+	return g.recent
+}
+
+// EnableRecent keeps the last size entries of the global instance
+func EnableRecent(size int) *Glg {
+	return Get().EnableRecent(size)
+}
@@ -0,0 +1,101 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRecentBuffer(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		writes int
+		n      int
+		want   []string
+	}{
+		{
+			name:   "partial",
+			size:   3,
+			writes: 2,
+			want:   []string{"0", "1"},
+		},
+		{
+			name:   "wrapped",
+			size:   3,
+			writes: 5,
+			want:   []string{"2", "3", "4"},
+		},
+		{
+			name:   "last n",
+			size:   3,
+			writes: 5,
+			n:      2,
+			want:   []string{"3", "4"},
+		},
+		{
+			name: "empty",
+			size: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRecentBuffer(tt.size)
+			for i := 0; i < tt.writes; i++ {
+				fmt.Fprint(r, i)
+			}
+			var got []string
+			for _, e := range r.Entries(tt.n) {
+				got = append(got, string(e))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Entries() = %v, want %v", got, tt.want)
+			}
+			if r.Len() != len(tt.want) && tt.n == 0 {
+				t.Errorf("Len() = %d, want %d", r.Len(), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestGlg_EnableRecent(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLevel(INFO).EnableRecent(2)
+	g.Info("one")
+	g.Debug("hidden")
+	g.Warn("two")
+	g.Error("three")
+	es := g.Recent().Entries(0)
+	if len(es) != 2 {
+		t.Fatalf("Entries() = %q, want 2", es)
+	}
+	if !strings.Contains(string(es[0]), `"detail":"two"`) || !strings.Contains(string(es[1]), `"level":"ERR"`) {
+		t.Errorf("Entries() = %q, want two and three in JSON", es)
+	}
+	if !strings.Contains(buf.String(), "[ERR]") || strings.Contains(buf.String(), "{") {
+		t.Errorf("writer output is JSON: %q", buf.String())
+	}
+}
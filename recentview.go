@@ -0,0 +1,160 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+
+	json "github.com/goccy/go-json"
+)
+
+// recentPage is the HTML viewer of RecentHandler, the entries are fetched as JSON and filtered in the browser
+var recentPage = template.Must(template.New("recent").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>recent logs</title>
+<style>
+body { font-family: monospace; margin: 1em; }
+#entries div { white-space: pre-wrap; border-bottom: 1px solid #eee; padding: 2px 0; }
+</style>
+</head>
+<body>
+<form id="filter" onsubmit="return false">
+<select id="level"><option value="0">all levels</option>{{range .}}<option value="{{printf "%d" .Rank}}">{{.Tag}}</option>{{end}}</select>
+<input id="q" type="search" placeholder="filter" size="40">
+<label><input id="follow" type="checkbox" checked> follow</label>
+</form>
+<div id="entries"></div>
+<script>
+const ranks = { {{range .}}{{.Tag}}: {{printf "%d" .Rank}}, {{end}} };
+let entries = [];
+function render() {
+	const min = Number(document.getElementById("level").value);
+	const q = document.getElementById("q").value.toLowerCase();
+	const out = document.getElementById("entries");
+	out.replaceChildren(...entries.filter(e => Number(ranks[e.level] || 0) >= min &&
+		(q === "" || JSON.stringify(e).toLowerCase().includes(q))).map(e => {
+		const d = document.createElement("div");
+		d.textContent = JSON.stringify(e);
+		return d;
+	}));
+}
+async function load() {
+	const res = await fetch(location.pathname + "?format=json" + location.search.replace(/^\?/, "&"));
+	if (res.ok) {
+		entries = await res.json();
+		render();
+	}
+}
+document.getElementById("level").onchange = render;
+document.getElementById("q").oninput = render;
+load();
+setInterval(() => { if (document.getElementById("follow").checked) load(); }, 2000);
+</script>
+</body>
+</html>
+`))
+
+// RecentHandler serves the last entries of EnableRecent, the browsers get the HTML viewer filtering them by level and substring,
+// the other clients get the JSON array. The JSON is filtered by the parameters:
+//
+//	n      the number of the last entries
+//	level  the lowest level, e.g. WARN returns WARN and the higher ranked levels
+//	q      the case-insensitive substring of the entry
+//
+// The viewer is served for ?format=html or Accept: text/html, ?format=json forces JSON
+func (g *Glg) RecentHandler() http.Handler {
+	return http.HandlerFunc(g.serveRecent)
+}
+
+// RecentHandler serves the last entries of the global instance
+func RecentHandler() http.Handler {
+	return Get().RecentHandler()
+}
+
+// serveRecent serves the last entries of EnableRecent
+func (g *Glg) serveRecent(w http.ResponseWriter, r *http.Request) {
+	rb := g.Recent()
+	if rb == nil {
+		http.Error(w, "recent entries are not enabled", http.StatusNotFound)
+		return
+	}
+	q := r.URL.Query()
+	if format := q.Get("format"); format == "html" || (format == "" && strings.Contains(r.Header.Get("Accept"), "text/html")) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := recentPage.Execute(w, g.Config().Levels); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	n, _ := strconv.Atoi(q.Get("n"))
+	min := LEVEL(0)
+	if s := q.Get("level"); s != "" {
+		lv := g.Atol(s)
+		if lv == UNKNOWN {
+			http.Error(w, "unknown log level "+strconv.Quote(s), http.StatusBadRequest)
+			return
+		}
+		min = g.rank(lv)
+	}
+	sub := []byte(strings.ToLower(q.Get("q")))
+	var es [][]byte
+	for _, e := range rb.Entries(0) {
+		if e = bytes.TrimRight(e, rc); g.entryMatch(e, min, sub) {
+			es = append(es, e)
+		}
+	}
+	if n > 0 && len(es) > n {
+		es = es[len(es)-n:]
+	}
+	b := new(bytes.Buffer)
+	b.WriteByte('[')
+	for i, e := range es {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.Write(e)
+	}
+	b.WriteString("]\n")
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b.Bytes())
+}
+
+// entryMatch reports the JSON entry e is ranked min or above and contains the lower case sub
+func (g *Glg) entryMatch(e []byte, min LEVEL, sub []byte) bool {
+	if min != 0 {
+		var v struct {
+			Level string `json:"level"`
+		}
+		if json.Unmarshal(e, &v) != nil || g.rank(g.TagStringToLevel(v.Level)) < min {
+			return false
+		}
+	}
+	return len(sub) == 0 || bytes.Contains(bytes.ToLower(e), sub)
+}
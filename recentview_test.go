@@ -0,0 +1,134 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	json "github.com/goccy/go-json"
+)
+
+func TestGlg_RecentHandler(t *testing.T) {
+	g := New().SetMode(WRITER).SetWriter(io.Discard).EnableRecent(10)
+	g.Info("alpha")
+	g.Warn("beta")
+	g.Error("gamma beta")
+	g.Info("delta")
+	h := g.RecentHandler()
+
+	tests := []struct {
+		name       string
+		target     string
+		accept     string
+		wantStatus int
+		want       []string
+	}{
+		{
+			name:       "all",
+			target:     "/recent",
+			wantStatus: http.StatusOK,
+			want:       []string{"alpha", "beta", "gamma beta", "delta"},
+		},
+		{
+			name:       "last n",
+			target:     "/recent?n=2",
+			wantStatus: http.StatusOK,
+			want:       []string{"gamma beta", "delta"},
+		},
+		{
+			name:       "level",
+			target:     "/recent?level=warn",
+			wantStatus: http.StatusOK,
+			want:       []string{"beta", "gamma beta"},
+		},
+		{
+			name:       "substring",
+			target:     "/recent?q=BETA&n=1",
+			wantStatus: http.StatusOK,
+			want:       []string{"gamma beta"},
+		},
+		{
+			name:       "json for browsers",
+			target:     "/recent?format=json&level=ERR",
+			accept:     "text/html",
+			wantStatus: http.StatusOK,
+			want:       []string{"gamma beta"},
+		},
+		{
+			name:       "unknown level",
+			target:     "/recent?level=loud",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set("Accept", tt.accept)
+			h.ServeHTTP(rr, req)
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.wantStatus, rr.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var entries []JSONFormat
+			if err := json.Unmarshal(rr.Body.Bytes(), &entries); err != nil {
+				t.Fatalf("body = %s: %v", rr.Body, err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Detail.(string))
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("entries = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_RecentHandler_HTML(t *testing.T) {
+	g := New().EnableRecent(10)
+	for _, accept := range []string{"text/html,application/xhtml+xml", ""} {
+		rr := httptest.NewRecorder()
+		target := "/recent"
+		if accept == "" {
+			target += "?format=html"
+		}
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Accept", accept)
+		g.RecentHandler().ServeHTTP(rr, req)
+		if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+			t.Errorf("Content-Type = %s, want text/html", ct)
+		}
+		for _, want := range []string{"<select", `<option value="7">WARN</option>`, `"WARN": "7"`} {
+			if !strings.Contains(rr.Body.String(), want) {
+				t.Errorf("page does not contain %s: %s", want, rr.Body)
+			}
+		}
+	}
+}
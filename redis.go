@@ -0,0 +1,331 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	// RedisEntryField is the field name of the entry in the Redis Stream
+	RedisEntryField = "entry"
+
+	redisMaxBatchEntries = 1000
+	redisMaxBatchBytes   = 1 << 20
+)
+
+// RedisWriter is io.Writer which pushes entries to the Redis Stream by XADD or to the list by RPUSH.
+// Entries are pipelined in batches, the connection is established lazily and reconnected after errors
+type RedisWriter struct {
+	*batcher
+	sinkOptions[*RedisWriter]
+	addr     string
+	key      string
+	list     bool
+	maxLen   int64
+	password string
+	db       int
+	tls      *tls.Config
+	dialer   ContextDialer
+
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// NewRedisStreamWriter returns RedisWriter which adds entries to the stream as the "entry" field
+func NewRedisStreamWriter(addr, stream string) *RedisWriter {
+	return newRedisWriter(addr, stream, false)
+}
+
+// NewRedisListWriter returns RedisWriter which appends entries to the list
+func NewRedisListWriter(addr, key string) *RedisWriter {
+	return newRedisWriter(addr, key, true)
+}
+
+func newRedisWriter(addr, key string, list bool) *RedisWriter {
+	w := &RedisWriter{
+		addr: addr,
+		key:  key,
+		list: list,
+	}
+	w.batcher = newBatcher(w.send, redisMaxBatchEntries, redisMaxBatchBytes, 0)
+	w.sinkOptions = sinkOptions[*RedisWriter]{b: w.batcher, self: w}
+	return w
+}
+
+// SetMaxLen caps the stream (MAXLEN ~) or the list (LTRIM) to about n entries, 0 is unlimited
+func (w *RedisWriter) SetMaxLen(n int64) *RedisWriter {
+	w.maxLen = n
+	return w
+}
+
+// SetAuth sets the password sent by AUTH on connect
+func (w *RedisWriter) SetAuth(password string) *RedisWriter {
+	w.password = password
+	return w
+}
+
+// SetTLSConfig connects to Redis over TLS with cfg, e.g. the configuration of NewTLSConfig presenting the client certificate.
+// The server name is taken from the address unless cfg sets it
+func (w *RedisWriter) SetTLSConfig(cfg *tls.Config) *RedisWriter {
+	w.tls = cfg
+	return w
+}
+
+// SetDialer connects to Redis by d, e.g. through the SOCKS5 proxy or NewHTTPProxyDialer, TLS is established over its connection
+func (w *RedisWriter) SetDialer(d ContextDialer) *RedisWriter {
+	w.dialer = d
+	return w
+}
+
+// SetDB sets the database selected on connect
+func (w *RedisWriter) SetDB(db int) *RedisWriter {
+	w.db = db
+	return w
+}
+
+// Close sends the buffered entries and closes the connection
+func (w *RedisWriter) Close() error {
+	return w.CloseContext(context.Background())
+}
+
+// CloseContext sends the buffered entries until ctx is done and closes the connection
+func (w *RedisWriter) CloseContext(ctx context.Context) error {
+	err := w.batcher.CloseContext(ctx)
+	w.sendMu.Lock()
+	defer w.sendMu.Unlock()
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+	return err
+}
+
+func (w *RedisWriter) send(ctx context.Context, batch []batchEntry) (err error) {
+	var cmds [][][]byte
+	if w.list {
+		cmd := make([][]byte, 0, len(batch)+2)
+		cmd = append(cmd, []byte("RPUSH"), []byte(w.key))
+		for _, e := range batch {
+			cmd = append(cmd, e.data)
+		}
+		cmds = append(cmds, cmd)
+		if w.maxLen > 0 {
+			cmds = append(cmds, [][]byte{[]byte("LTRIM"), []byte(w.key), []byte(strconv.FormatInt(-w.maxLen, 10)), []byte("-1")})
+		}
+	} else {
+		for _, e := range batch {
+			cmd := [][]byte{[]byte("XADD"), []byte(w.key)}
+			if w.maxLen > 0 {
+				cmd = append(cmd, []byte("MAXLEN"), []byte("~"), []byte(strconv.FormatInt(w.maxLen, 10)))
+			}
+			cmds = append(cmds, append(cmd, []byte("*"), []byte(RedisEntryField), e.data))
+		}
+	}
+	if err = w.connect(ctx); err != nil {
+		return err
+	}
+	defer func() {
+		var rerr redisError
+		if err != nil && !errors.As(err, &rerr) {
+			w.conn.Close()
+			w.conn = nil
+		}
+	}()
+	replies, err := w.exec(ctx, cmds...)
+	if err == nil {
+		err = firstRedisError(replies)
+	}
+	if err == nil {
+		return nil
+	}
+	// the commands replied without error stored their entries, only the others are retried
+	var rest []batchEntry
+	if w.list {
+		if len(replies) == 0 || replies[0] != nil {
+			return err
+		}
+		// RPUSH stored the batch, the failure of LTRIM is not retried
+	} else {
+		for i, e := range batch {
+			if i >= len(replies) || replies[i] != nil {
+				rest = append(rest, e)
+			}
+		}
+		if len(rest) == len(batch) {
+			return err
+		}
+	}
+	return &partialError{rest: rest, err: err}
+}
+
+func (w *RedisWriter) connect(ctx context.Context) error {
+	if w.conn != nil {
+		return nil
+	}
+	d := w.dialer
+	if d == nil {
+		d = new(net.Dialer)
+	}
+	conn, err := d.DialContext(ctx, "tcp", w.addr)
+	if err != nil {
+		return err
+	}
+	if w.tls != nil {
+		cfg := w.tls
+		if cfg.ServerName == "" {
+			cfg = cfg.Clone()
+			cfg.ServerName, _, _ = net.SplitHostPort(w.addr)
+		}
+		tc := tls.Client(conn, cfg)
+		if err = tc.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return err
+		}
+		conn = tc
+	}
+	w.conn, w.rd = conn, bufio.NewReader(conn)
+	var cmds [][][]byte
+	if w.password != "" {
+		cmds = append(cmds, [][]byte{[]byte("AUTH"), []byte(w.password)})
+	}
+	if w.db != 0 {
+		cmds = append(cmds, [][]byte{[]byte("SELECT"), []byte(strconv.Itoa(w.db))})
+	}
+	replies, err := w.exec(ctx, cmds...)
+	if err == nil {
+		err = firstRedisError(replies)
+	}
+	if err != nil {
+		conn.Close()
+		w.conn = nil
+	}
+	return err
+}
+
+// firstRedisError returns the first error reply
+func firstRedisError(replies []error) error {
+	for _, err := range replies {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exec pipelines the commands and reads the replies, replies are the error replies of the commands in order,
+// nil for success, up to the connection error. The connection is interrupted when ctx is done
+func (w *RedisWriter) exec(ctx context.Context, cmds ...[][]byte) (replies []error, err error) {
+	if len(cmds) == 0 {
+		return nil, nil
+	}
+	// the deadline is set when ctx is done, so the timed out connection reports ctx.Err()
+	conn := w.conn
+	conn.SetDeadline(time.Time{})
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.SetDeadline(time.Unix(1, 0))
+		case <-stop:
+		}
+	}()
+	bw := bufio.NewWriter(w.conn)
+	for _, cmd := range cmds {
+		bw.WriteString("*" + strconv.Itoa(len(cmd)) + "\r\n")
+		for _, arg := range cmd {
+			bw.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n")
+			bw.Write(arg)
+			bw.WriteString("\r\n")
+		}
+	}
+	if err = bw.Flush(); err != nil {
+		return nil, err
+	}
+	replies = make([]error, 0, len(cmds))
+	for range cmds {
+		err = readRedisReply(w.rd)
+		if _, ok := err.(redisError); err != nil && !ok {
+			return replies, err
+		}
+		replies = append(replies, err)
+	}
+	return replies, nil
+}
+
+// redisError is the error reply of Redis
+type redisError string
+
+func (e redisError) Error() string {
+	return "error:\tRedis replied " + string(e)
+}
+
+// readRedisReply reads and discards one RESP reply, error replies are returned as redisError
+func readRedisReply(rd *bufio.Reader) error {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if len(line) < 3 {
+		return fmt.Errorf("error:\tinvalid Redis reply %q", line)
+	}
+	line = line[:len(line)-2]
+	switch line[0] {
+	case '+', ':':
+		return nil
+	case '-':
+		return redisError(line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return err
+		}
+		_, err = rd.Discard(n + 2)
+		return err
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return err
+		}
+		var rerr error
+		for i := 0; i < n; i++ {
+			if err = readRedisReply(rd); err != nil {
+				if _, ok := err.(redisError); !ok {
+					return err
+				}
+				rerr = err
+			}
+		}
+		return rerr
+	}
+	return fmt.Errorf("error:\tinvalid Redis reply %q", line)
+}
@@ -0,0 +1,233 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis records the commands and replies +OK, or the error to the command named fail
+// and once to the command of the last argument failOnce
+type fakeRedis struct {
+	ln       net.Listener
+	fail     string
+	failOnce string
+	mu       sync.Mutex
+	cmds     [][]string
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	return serveFakeRedis(ln)
+}
+
+// serveFakeRedis serves the connections of ln, e.g. the TLS listener
+func serveFakeRedis(ln net.Listener) *fakeRedis {
+	r := &fakeRedis{ln: ln}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go r.serve(conn)
+		}
+	}()
+	return r
+}
+
+func (r *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+	for {
+		line, err := rd.ReadString('\n')
+		if err != nil {
+			return
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		cmd := make([]string, n)
+		for i := range cmd {
+			line, _ = rd.ReadString('\n')
+			l, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+			buf := make([]byte, l+2)
+			if _, err = io.ReadFull(rd, buf); err != nil {
+				return
+			}
+			cmd[i] = string(buf[:l])
+		}
+		r.mu.Lock()
+		r.cmds = append(r.cmds, cmd)
+		fail := r.fail == cmd[0]
+		if r.failOnce != "" && r.failOnce == cmd[len(cmd)-1] {
+			fail, r.failOnce = true, ""
+		}
+		r.mu.Unlock()
+		if fail {
+			conn.Write([]byte("-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"))
+		} else {
+			conn.Write([]byte("+OK\r\n"))
+		}
+	}
+}
+
+func TestRedisWriter(t *testing.T) {
+	r := newFakeRedis(t)
+	defer r.ln.Close()
+
+	w := NewRedisStreamWriter(r.ln.Addr().String(), "logs").SetMaxLen(100).SetAuth("pass").SetDB(2).SetFlushInterval(0)
+	g := New().SetMode(WRITER).SetWriter(w).DisableTimestamp()
+	g.Info("hello")
+	g.Info("multi\nline")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewRedisListWriter(r.ln.Addr().String(), "list").SetMaxLen(10).SetFlushInterval(0)
+	l.Write([]byte("a\n"))
+	l.Write([]byte("b\n"))
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"AUTH", "pass"},
+		{"SELECT", "2"},
+		{"XADD", "logs", "MAXLEN", "~", "100", "*", RedisEntryField, "[INFO]:\thello"},
+		{"XADD", "logs", "MAXLEN", "~", "100", "*", RedisEntryField, "[INFO]:\tmulti\nline"},
+		{"RPUSH", "list", "a", "b"},
+		{"LTRIM", "list", "-10", "-1"},
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.cmds) != len(want) {
+		t.Fatalf("RedisWriter commands = %q, want %q", r.cmds, want)
+	}
+	for i := range want {
+		if strings.Join(r.cmds[i], " ") != strings.Join(want[i], " ") {
+			t.Errorf("RedisWriter command = %q, want %q", r.cmds[i], want[i])
+		}
+	}
+}
+
+func TestRedisWriter_Error(t *testing.T) {
+	r := newFakeRedis(t)
+	defer r.ln.Close()
+	r.fail = "RPUSH"
+
+	w := NewRedisListWriter(r.ln.Addr().String(), "logs").SetFlushInterval(0).SetMaxRetries(0)
+	w.Write([]byte("lost"))
+	if err := w.Flush(); err == nil || !strings.Contains(err.Error(), "WRONGTYPE") {
+		t.Errorf("RedisWriter.Flush() error = %v", err)
+	}
+	w.Close()
+
+	w = NewRedisListWriter("127.0.0.1:1", "logs").SetFlushInterval(0).SetMaxRetries(0)
+	w.Write([]byte("lost"))
+	if err := w.Flush(); err == nil {
+		t.Error("RedisWriter.Flush() error = nil")
+	}
+	w.Close()
+}
+
+func TestRedisWriter_PartialRetry(t *testing.T) {
+	r := newFakeRedis(t)
+	defer r.ln.Close()
+	r.failOnce = "b"
+
+	w := NewRedisStreamWriter(r.ln.Addr().String(), "logs").SetFlushInterval(0).SetMaxRetries(1)
+	for _, e := range []string{"a", "b", "c"} {
+		w.Write([]byte(e + "\n"))
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var got []string
+	for _, cmd := range r.cmds {
+		got = append(got, cmd[len(cmd)-1])
+	}
+	if want := "a b c b"; strings.Join(got, " ") != want {
+		t.Errorf("RedisWriter entries = %q, want %q", got, want)
+	}
+	if s := w.Status(); s.BytesWritten != 3 {
+		t.Errorf("RedisWriter BytesWritten = %d, want 3", s.BytesWritten)
+	}
+}
+
+func TestRedisWriter_Timeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	go func() {
+		// the hung server accepts the connection and never replies
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	var handled []error
+	w := NewRedisListWriter(ln.Addr().String(), "logs").SetFlushInterval(0).SetMaxRetries(0).
+		SetTimeout(50 * time.Millisecond).SetErrorHandler(func(err error) {
+		handled = append(handled, err)
+	})
+	w.Write([]byte("a"))
+	err = w.Flush()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Flush() error = %v, want DeadlineExceeded", err)
+	}
+	if len(handled) != 1 || handled[0] != err {
+		t.Errorf("error handler got %v, want %v", handled, err)
+	}
+
+	w = NewRedisListWriter(ln.Addr().String(), "logs").SetFlushInterval(0).SetMaxRetries(0).SetTimeout(0)
+	w.Write([]byte("b"))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err = w.CloseContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("CloseContext() error = %v, want Canceled", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("CloseContext() returned after %v", d)
+	}
+}
@@ -0,0 +1,90 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"io"
+	"time"
+)
+
+// replayEntry is the original time and caller of the replayed entry or the entry rendered again for the routes
+type replayEntry struct {
+	time   time.Time
+	caller string
+	log    *logger   // the logger rendering the entry for the routes instead of the logger of the level
+	fields []Field   // the fields of the routed entry, its values are already separated from them
+	opts   *switches // the switches of the routed entry, so the routes render it with the same settings
+}
+
+// Replay re-emits the entries of glg text or JSON logs read from r into g, e.g. to backfill a sink
+// from the local fallback file after an outage. The original timestamps and callers are preserved,
+// entries without timestamps are stamped with the current time.
+// Levels are resolved by the tags of g, entries of unknown levels are replayed as LOG.
+// Replay stops at the first error
+func Replay(r io.Reader, g *Glg) error {
+	return replay(r, g, true)
+}
+
+// ReplayNow is the same as Replay, except that the entries are stamped with the current time
+func ReplayNow(r io.Reader, g *Glg) error {
+	return replay(r, g, false)
+}
+
+func replay(r io.Reader, g *Glg, keepTime bool) error {
+	lr := NewReader(r)
+	for {
+		e, err := lr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = g.replay(e, keepTime); err != nil {
+			return err
+		}
+	}
+}
+
+func (g *Glg) replay(e *Entry, keepTime bool) error {
+	level := e.Level
+	if e.Tag != "" {
+		level = g.TagStringToLevel(e.Tag)
+	}
+	if level == UNKNOWN {
+		level = LOG
+	}
+	re := &replayEntry{caller: e.Caller}
+	if keepTime {
+		re.time = e.Time
+	}
+	val := make([]interface{}, 0, len(e.Fields)+1)
+	if e.Message != "" {
+		val = append(val, e.Message)
+	}
+	for _, f := range e.Fields {
+		val = append(val, f)
+	}
+	return g.output(level, re, g.blankFormat(len(val)), val...)
+}
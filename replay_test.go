@@ -0,0 +1,95 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReplay(t *testing.T) {
+	const log = "orphan line\n" +
+		"2021-03-04 05:06:07\t[INFO]:\tstarted\tport=8080\n" +
+		"2021-03-04 05:06:08\t[CRIT]:\t(main.go:10):\tdisk full\n"
+	tests := []struct {
+		name   string
+		json   bool
+		replay func(r io.Reader, g *Glg) error
+		want   []string
+		suffix string
+	}{
+		{
+			name:   "text",
+			replay: Replay,
+			want: []string{
+				"2021-03-04 05:06:07\t[INFO]:\tstarted\tport=8080",
+				"2021-03-04 05:06:08\t[CRIT]:\t(main.go:10):\tdisk full",
+			},
+			suffix: "\t[LOG]:\torphan line",
+		},
+		{
+			name:   "json",
+			json:   true,
+			replay: Replay,
+			want: []string{
+				`{"date":"2021-03-04 05:06:07","level":"INFO","detail":"started","fields":{"port":8080}}`,
+				`{"date":"2021-03-04 05:06:08","level":"CRIT","file":"main.go:10","detail":"disk full"}`,
+			},
+			suffix: `"level":"LOG","detail":"orphan line"}`,
+		},
+		{
+			name:   "now",
+			replay: ReplayNow,
+			suffix: "\t[LOG]:\torphan line",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().AddStdLevel("CRIT", WRITER, false).SetMode(WRITER).SetWriter(buf)
+			if tt.json {
+				g.EnableJSON()
+			}
+			if err := tt.replay(strings.NewReader(log), g); err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			if len(lines) != 3 {
+				t.Fatalf("Replay() = %q", buf.String())
+			}
+			for i, want := range tt.want {
+				if lines[i+1] != want {
+					t.Errorf("Replay() = %q, want %q", lines[i+1], want)
+				}
+			}
+			if tt.want == nil && strings.HasPrefix(lines[1], "2021") {
+				t.Errorf("ReplayNow() = %q, want current time", lines[1])
+			}
+			if !strings.HasSuffix(lines[0], tt.suffix) || strings.HasPrefix(lines[0], "2021") {
+				t.Errorf("Replay() = %q, want suffix %q", lines[0], tt.suffix)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLossReportInterval is the default interval of EnableLossReport
+const DefaultLossReportInterval = time.Minute
+
+// LossReportMessage is the message of the entry reporting the lost entries
+const LossReportMessage = "log entries were not written"
+
+// EnableLossReport logs the numbers of the entries dropped, suppressed and sampled out since the last report
+// every interval, interval <= 0 means DefaultLossReportInterval.
+// The report is WARN when entries are dropped, INFO when they are only suppressed or sampled out, nothing is logged without losses
+func (g *Glg) EnableLossReport(interval time.Duration) *Glg {
+	if interval <= 0 {
+		interval = DefaultLossReportInterval
+	}
+	g.reportMu.Lock()
+	defer g.reportMu.Unlock()
+	if g.reportStop != nil {
+		close(g.reportStop)
+	}
+	stop := make(chan struct{})
+	g.reportStop = stop
+	var last [lossKinds]uint64
+	for i := range last {
+		last[i] = atomic.LoadUint64(&g.losses[i])
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				last = g.reportLosses(last, interval)
+			}
+		}
+	}()
+	return g
+}
+
+// DisableLossReport stops the loss report
+func (g *Glg) DisableLossReport() *Glg {
+	g.reportMu.Lock()
+	defer g.reportMu.Unlock()
+	if g.reportStop != nil {
+		close(g.reportStop)
+		g.reportStop = nil
+	}
+	return g
+}
+
+// reportLosses logs the losses since last, it returns the current counts
+func (g *Glg) reportLosses(last [lossKinds]uint64, interval time.Duration) [lossKinds]uint64 {
+	var cur, delta [lossKinds]uint64
+	var lost bool
+	for i := range cur {
+		cur[i] = atomic.LoadUint64(&g.losses[i])
+		delta[i] = cur[i] - last[i]
+		lost = lost || delta[i] != 0
+	}
+	if !lost || atomic.LoadInt32(&g.shutdown) != 0 {
+		return cur
+	}
+	level := INFO
+	if delta[lossDropped] != 0 {
+		level = WARN
+	}
+	g.out(level, g.blankFormat(5), LossReportMessage,
+		Uint64("dropped", delta[lossDropped]),
+		Uint64("suppressed", delta[lossSuppressed]),
+		Uint64("sampled_out", delta[lossSampled]),
+		Dur("interval", interval))
+	return cur
+}
@@ -0,0 +1,72 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGlg_reportLosses(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp()
+	var last [lossKinds]uint64
+
+	last = g.reportLosses(last, time.Minute)
+	if buf.Len() != 0 {
+		t.Errorf("reportLosses() without losses = %q", buf.String())
+	}
+
+	g.lose(lossSuppressed, DEBG)
+	g.lose(lossSampled, INFO)
+	g.lose(lossSampled, INFO)
+	last = g.reportLosses(last, time.Minute)
+	if got, want := buf.String(), "[INFO]:\t"+LossReportMessage+"\tdropped=0 suppressed=1 sampled_out=2 interval=1m0s\n"; got != want {
+		t.Errorf("reportLosses() = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	g.drop(INFO)
+	g.reportLosses(last, time.Minute)
+	if got, want := buf.String(), "[WARN]:\t"+LossReportMessage+"\tdropped=1 suppressed=0 sampled_out=0 interval=1m0s\n"; got != want {
+		t.Errorf("reportLosses() = %q, want %q", got, want)
+	}
+}
+
+func TestGlg_EnableLossReport(t *testing.T) {
+	w := new(gateWriter)
+	g := New().SetMode(WRITER).SetWriter(w).DisableTimestamp()
+	g.drop(INFO)
+	g.EnableLossReport(time.Millisecond)
+	defer g.DisableLossReport()
+	g.drop(INFO)
+	deadline := time.Now().Add(time.Second)
+	for w.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := w.String(); !strings.Contains(got, "dropped=1 ") {
+		t.Errorf("EnableLossReport() = %q, want the drop since enabled", got)
+	}
+}
@@ -0,0 +1,97 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"net/url"
+	"os"
+	"strings"
+)
+
+// The OpenTelemetry resource attribute keys of the service
+const (
+	ResourceServiceName    = "service.name"
+	ResourceServiceVersion = "service.version"
+	ResourceEnvironment    = "deployment.environment"
+)
+
+// SetResource sets the resource attributes of the instance, e.g. service.name, service.version and deployment.environment
+// in the OpenTelemetry semantics. They are written as the resource object of every JSON entry and as the labels
+// of the Cloud Logging entries, and Resource returns them to the exporters building their label sets.
+// attrs is copied, nil or empty attrs removes them
+func (g *Glg) SetResource(attrs map[string]string) *Glg {
+	var res map[string]string
+	if len(attrs) != 0 {
+		res = make(map[string]string, len(attrs))
+		for k, v := range attrs {
+			res[k] = v
+		}
+	}
+	g.setSwitches(func(s *switches) {
+		s.resource = res
+	})
+	return g
+}
+
+// Resource returns the copy of the resource attributes set by SetResource
+func (g *Glg) Resource() map[string]string {
+	cur := g.opts().resource
+	if len(cur) == 0 {
+		return nil
+	}
+	res := make(map[string]string, len(cur))
+	for k, v := range cur {
+		res[k] = v
+	}
+	return res
+}
+
+// SetResource sets the resource attributes of the global instance
+func SetResource(attrs map[string]string) *Glg {
+	return Get().SetResource(attrs)
+}
+
+// Resource returns the resource attributes of the global instance
+func Resource() map[string]string {
+	return Get().Resource()
+}
+
+// ResourceFromEnv returns the resource attributes of the OTEL_RESOURCE_ATTRIBUTES environment variable,
+// the comma separated key=value pairs, and OTEL_SERVICE_NAME which takes precedence over service.name of the former
+func ResourceFromEnv() map[string]string {
+	res := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv("OTEL_RESOURCE_ATTRIBUTES"), ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if k = strings.TrimSpace(k); !ok || k == "" {
+			continue
+		}
+		if uv, err := url.PathUnescape(strings.TrimSpace(v)); err == nil {
+			res[k] = uv
+		}
+	}
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		res[ResourceServiceName] = name
+	}
+	return res
+}
@@ -0,0 +1,79 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGlg_SetResource(t *testing.T) {
+	attrs := map[string]string{ResourceServiceName: "api", ResourceServiceVersion: "1.2.0", ResourceEnvironment: "prod"}
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone).DisableTimestamp().SetResource(attrs)
+	attrs[ResourceEnvironment] = "changed"
+	if got := g.Resource(); len(got) != 3 || got[ResourceEnvironment] != "prod" {
+		t.Errorf("Resource() = %v", got)
+	}
+
+	g.Info("text")
+	g.EnableJSON().Info("json")
+	g.Clone().SetResource(nil).Info("cleared")
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 || lines[0] != "[INFO]:\ttext" {
+		t.Fatalf("output = %q", buf.String())
+	}
+	var je JSONFormat
+	if err := stdjson.Unmarshal([]byte(lines[1]), &je); err != nil {
+		t.Fatal(err)
+	}
+	if je.Detail != "json" || len(je.Resource) != 3 || je.Resource[ResourceServiceName] != "api" {
+		t.Errorf("JSON entry = %+v", je)
+	}
+	if strings.Contains(lines[2], "resource") {
+		t.Errorf("JSON entry after SetResource(nil) = %s", lines[2])
+	}
+
+	buf.Reset()
+	g.EnableCloudLogging("").Info("cloud")
+	if !strings.Contains(buf.String(), `"logging.googleapis.com/labels":{"deployment.environment":"prod","service.name":"api","service.version":"1.2.0"}`) {
+		t.Errorf("Cloud Logging entry = %s", buf.String())
+	}
+}
+
+func TestResourceFromEnv(t *testing.T) {
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "service.name=ignored, deployment.environment=staging,team=a%2Cb,broken,=empty")
+	t.Setenv("OTEL_SERVICE_NAME", "billing")
+	got := ResourceFromEnv()
+	want := map[string]string{ResourceServiceName: "billing", ResourceEnvironment: "staging", "team": "a,b"}
+	if len(got) != len(want) {
+		t.Fatalf("ResourceFromEnv() = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("ResourceFromEnv()[%s] = %q, want %q", k, got[k], v)
+		}
+	}
+}
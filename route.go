@@ -0,0 +1,163 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"github.com/kpango/fastime"
+)
+
+// Route sends the levels ranked from Min to Max to Writer in the format of JSON, e.g.
+//
+//	g.Route(glg.Route{Writer: f, Min: glg.WARN, JSON: glg.LevelJSONOn})
+//
+// declares that f receives WARN and above in JSON regardless of the modes, writers and formats of the levels
+type Route struct {
+	Writer io.Writer
+	// Min and Max are compared with the ranks of the levels, see SetLevelRank.
+	// Zero Min routes from the lowest level, zero Max routes up to the highest level including the custom levels
+	Min LEVEL
+	Max LEVEL
+	// JSON selects the format written to Writer, LevelJSONDefault follows the format of each level
+	JSON LevelJSON
+}
+
+// match reports the level of rank is routed
+func (r Route) match(rank LEVEL) bool {
+	return rank >= r.Min && (r.Max == 0 || rank <= r.Max)
+}
+
+// Route adds the routes, the routed levels write the entries to the route writers in addition to their own destinations.
+// The entries of the disabled levels are not routed, the levels added later are routed as well.
+// The route writers are written without colors and closed by Shutdown
+func (g *Glg) Route(routes ...Route) *Glg {
+	for _, r := range routes {
+		if r.Writer == nil {
+			continue
+		}
+		g.setSwitches(func(s *switches) {
+			s.routes = append(s.routes[:len(s.routes):len(s.routes)], r)
+		})
+		g.updateLoggers(func(lv LEVEL, l *logger) {
+			if r.match(l.rankOf(lv)) {
+				l.routes = append(l.routes[:len(l.routes):len(l.routes)], r)
+			}
+		})
+		g.trackWriter(r.Writer, false)
+	}
+	return g
+}
+
+// ResetRoutes removes the routes added by Route, the route writers are not closed
+func (g *Glg) ResetRoutes() *Glg {
+	g.setSwitches(func(s *switches) {
+		s.routes = nil
+	})
+	g.updateLoggers(func(_ LEVEL, l *logger) {
+		l.routes = nil
+	})
+	return g
+}
+
+// AddRoute adds the routes to the global instance
+func AddRoute(routes ...Route) *Glg {
+	return Get().Route(routes...)
+}
+
+// ResetRoutes removes the routes of the global instance
+func ResetRoutes() *Glg {
+	return Get().ResetRoutes()
+}
+
+// levelRoutes returns the routes of the level of rank
+func (g *Glg) levelRoutes(rank LEVEL) (routes []Route) {
+	for _, r := range g.opts().routes {
+		if r.match(rank) {
+			routes = append(routes, r)
+		}
+	}
+	return routes
+}
+
+// writeRoutes renders the entry of log again for the route writers and the writers of To, once per format.
+// o is the switches of the entry, fl and now are its caller and time, format, val and fields are the arguments after the fields are separated
+func (g *Glg) writeRoutes(o *switches, level LEVEL, log *logger, isJSON bool, fl string, now time.Time, format string, val []interface{}, fields []Field) error {
+	var text, js io.Writer
+	for _, r := range log.routes {
+		if r.JSON == LevelJSONOn || (r.JSON == LevelJSONDefault && isJSON) {
+			js = addRouteWriter(js, r.Writer)
+		} else {
+			text = addRouteWriter(text, r.Writer)
+		}
+	}
+	for _, w := range g.to {
+		if isJSON {
+			js = addRouteWriter(js, w)
+		} else {
+			text = addRouteWriter(text, w)
+		}
+	}
+	if now.IsZero() && !log.disableTimestamp {
+		now = fastime.Now()
+	}
+	var errs []error
+	for _, rw := range []struct {
+		w    io.Writer
+		json LevelJSON
+	}{{text, LevelJSONOff}, {js, LevelJSONOn}} {
+		if rw.w == nil {
+			continue
+		}
+		rl := *log
+		rl.routes = nil
+		rl.mode, rl.prevMode = WRITER, WRITER
+		rl.writer = rw.w
+		rl.json = rw.json
+		rl.updateMode()
+		if err := g.output(level, &replayEntry{time: now, caller: fl, log: &rl, fields: fields, opts: o}, format, val...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// addRouteWriter adds w to the route writers of the format, the writer routed twice is written once
+func addRouteWriter(ws, w io.Writer) io.Writer {
+	if ws == nil {
+		return w
+	}
+	if f, ok := ws.(fanout); ok {
+		for _, fw := range f {
+			if sameWriter(fw, w) {
+				return ws
+			}
+		}
+	} else if sameWriter(ws, w) {
+		return ws
+	}
+	return newFanout(ws, w)
+}
@@ -0,0 +1,96 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGlg_Route(t *testing.T) {
+	std := new(bytes.Buffer)
+	js := new(closeBuffer)
+	text := new(bytes.Buffer)
+	g := New().SetMode(STD).SetLineTraceMode(TraceLineNone).
+		Route(
+			Route{Writer: js, Min: WARN, JSON: LevelJSONOn},
+			Route{Writer: text, Max: INFO},
+			Route{Writer: text, Min: OK, Max: OK},
+			Route{},
+		)
+	g.AddStdLevel("AUDIT", STD, false, LevelOptions{Rank: ERR})
+	g.updateLoggers(func(_ LEVEL, l *logger) { l.std = std })
+
+	g.Debug("debug", String("k", "v"))
+	g.Info("info")
+	g.Success("ok")
+	g.Warn("warn", Int("n", 1))
+	g.CustomLog("AUDIT", "audit")
+	g.SetLevelMode(ERR, NONE).Error("disabled")
+
+	lines := strings.Split(strings.TrimSuffix(std.String(), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Errorf("std output = %q, want 5 lines", std.String())
+	}
+	if got := text.String(); !strings.Contains(got, "[DEBG]:\tdebug\tk=v\n") || !strings.Contains(got, "[INFO]:\tinfo\n") ||
+		!strings.Contains(got, "[OK]:\tok\n") || strings.Contains(got, "warn") || strings.Contains(got, "\033[") {
+		t.Errorf("text route = %q", got)
+	}
+	got := js.String()
+	if !strings.Contains(got, `"level":"WARN","detail":"warn","fields":{"n":1}`) || !strings.Contains(got, `"level":"AUDIT"`) ||
+		strings.Contains(got, "info") || strings.Contains(got, "disabled") {
+		t.Errorf("JSON route = %q", got)
+	}
+	if strings.Count(got, "\n") != 2 {
+		t.Errorf("JSON route = %q, want 2 entries", got)
+	}
+
+	g.ResetRoutes().Warn("after reset")
+	if strings.Contains(js.String(), "after reset") {
+		t.Error("ResetRoutes() kept the route")
+	}
+	g.Shutdown(context.Background())
+	if js.closed != 1 {
+		t.Error("Shutdown() did not close the route writer")
+	}
+}
+
+func TestGlg_Route_Ordered(t *testing.T) {
+	buf := new(bytes.Buffer)
+	route := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).EnableOrderedWrite().EnableJSON().
+		Route(Route{Writer: route, JSON: LevelJSONOff})
+	g.Info("ordered")
+	if !strings.Contains(buf.String(), `"detail":"ordered"`) || !strings.Contains(route.String(), "[INFO]:\tordered") {
+		t.Errorf("output = %q, route = %q", buf.String(), route.String())
+	}
+	var ts string
+	if i := strings.Index(route.String(), "\t"); i > 0 {
+		ts = route.String()[:i]
+	}
+	if ts == "" || !strings.Contains(buf.String(), `"date":"`+ts+`"`) {
+		t.Errorf("route timestamp %q differs from %q", ts, buf.String())
+	}
+}
@@ -0,0 +1,145 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"os"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultRuntimeStatsInterval is the default interval of EnableRuntimeStats
+const DefaultRuntimeStatsInterval = time.Minute
+
+// RuntimeStatsMessage is the message of the entry of the runtime stats
+const RuntimeStatsMessage = "runtime stats"
+
+// EnableRuntimeStats logs the goroutine count, the heap, the GC pauses and the open file descriptors at lv every interval,
+// the lightweight telemetry of the process without the metrics stack, interval <= 0 means DefaultRuntimeStatsInterval
+//
+//	[INFO]:	runtime stats	goroutines=12 heap_alloc=4194304 heap_objects=10240 num_gc=8 gc_pause=1.2ms gc_pause_total=5.1ms open_fds=9
+//
+// gc_pause is the longest pause since the last entry and open_fds is logged only where /proc/self/fd is available.
+// The custom level registered by AddStdLevel logs the stats with its tag. The stats are stopped by DisableRuntimeStats and Shutdown
+func (g *Glg) EnableRuntimeStats(interval time.Duration, lv LEVEL) *Glg {
+	if interval <= 0 {
+		interval = DefaultRuntimeStatsInterval
+	}
+	g.statsMu.Lock()
+	defer g.statsMu.Unlock()
+	if g.statsStop != nil {
+		close(g.statsStop)
+	}
+	stop := make(chan struct{})
+	g.statsStop = stop
+	var last runtime.MemStats
+	runtime.ReadMemStats(&last)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				last = g.logRuntimeStats(lv, last)
+			}
+		}
+	}()
+	return g
+}
+
+// EnableRuntimeStats logs the runtime stats of the process at lv every interval by the global instance
+func EnableRuntimeStats(interval time.Duration, lv LEVEL) *Glg {
+	return Get().EnableRuntimeStats(interval, lv)
+}
+
+// DisableRuntimeStats stops the runtime stats
+func (g *Glg) DisableRuntimeStats() *Glg {
+	g.statsMu.Lock()
+	defer g.statsMu.Unlock()
+	if g.statsStop != nil {
+		close(g.statsStop)
+		g.statsStop = nil
+	}
+	return g
+}
+
+// DisableRuntimeStats stops the runtime stats of the global instance
+func DisableRuntimeStats() *Glg {
+	return Get().DisableRuntimeStats()
+}
+
+// logRuntimeStats logs the stats at lv with the GC pauses since last, it returns the current memory stats
+func (g *Glg) logRuntimeStats(lv LEVEL, last runtime.MemStats) runtime.MemStats {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	if atomic.LoadInt32(&g.shutdown) != 0 {
+		return ms
+	}
+	vals := timerVals(RuntimeStatsMessage, runtimeStatsFields(&ms, &last, runtime.NumGoroutine()))
+	if n, ok := openFDs(); ok {
+		vals = append(vals, Int("open_fds", n))
+	}
+	g.out(lv, g.blankFormat(len(vals)), vals...)
+	return ms
+}
+
+// runtimeStatsFields returns the fields of the stats, gc_pause is the longest pause of the collections since last
+func runtimeStatsFields(ms, last *runtime.MemStats, goroutines int) []Field {
+	var pause uint64
+	n := ms.NumGC - last.NumGC
+	if n > uint32(len(ms.PauseNs)) {
+		n = uint32(len(ms.PauseNs))
+	}
+	for i := uint32(0); i < n; i++ {
+		if p := ms.PauseNs[(ms.NumGC-i+255)%256]; p > pause {
+			pause = p
+		}
+	}
+	return []Field{
+		Int("goroutines", goroutines),
+		Uint64("heap_alloc", ms.HeapAlloc),
+		Uint64("heap_objects", ms.HeapObjects),
+		Uint64("num_gc", uint64(ms.NumGC)),
+		Dur("gc_pause", time.Duration(pause)),
+		Dur("gc_pause_total", time.Duration(ms.PauseTotalNs)),
+	}
+}
+
+// openFDs returns the number of the open file descriptors of the process, ok is false where /proc/self/fd is not available
+func openFDs() (n int, ok bool) {
+	f, err := os.Open("/proc/self/fd")
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	names, err := f.Readdirnames(-1)
+	if err != nil {
+		return 0, false
+	}
+	// the descriptor reading the directory is not counted
+	return len(names) - 1, true
+}
@@ -0,0 +1,67 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRuntimeStatsFields(t *testing.T) {
+	var last, ms runtime.MemStats
+	last.NumGC = 1
+	ms.NumGC = 3
+	ms.PauseNs[0] = uint64(time.Hour)
+	ms.PauseNs[1] = uint64(2 * time.Millisecond)
+	ms.PauseNs[2] = uint64(time.Millisecond)
+	ms.PauseTotalNs = uint64(time.Second)
+	ms.HeapAlloc = 1024
+	ms.HeapObjects = 8
+	b := new(bytes.Buffer)
+	writeFields(b, runtimeStatsFields(&ms, &last, 5), 0, false)
+	want := "goroutines=5 heap_alloc=1024 heap_objects=8 num_gc=3 gc_pause=2ms gc_pause_total=1s"
+	if b.String() != want {
+		t.Errorf("runtimeStatsFields() = %s, want %s", b.String(), want)
+	}
+}
+
+func TestGlg_EnableRuntimeStats(t *testing.T) {
+	w := new(gateWriter)
+	g := New().SetMode(WRITER).SetWriter(w).DisableTimestamp()
+	g.EnableRuntimeStats(time.Millisecond, DEBG)
+	defer g.DisableRuntimeStats()
+	deadline := time.Now().Add(time.Second)
+	for w.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := w.String(); !strings.HasPrefix(got, "[DEBG]:\t"+RuntimeStatsMessage+"\tgoroutines=") {
+		t.Errorf("EnableRuntimeStats() = %q", got)
+	}
+	g.DisableRuntimeStats()
+	if g.statsStop != nil {
+		t.Error("DisableRuntimeStats() did not stop the stats")
+	}
+}
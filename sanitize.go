@@ -0,0 +1,179 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// sanitized wraps the message argument and escapes control characters of its formatted value
+type sanitized struct {
+	v interface{}
+}
+
+func (s sanitized) Format(f fmt.State, verb rune) {
+	io.WriteString(f, sanitize(fmt.Sprintf(directive(f, verb), s.v)))
+}
+
+// directive rebuilds the format directive such as "%-8.3f" from fmt.State
+func directive(f fmt.State, verb rune) string {
+	b := make([]byte, 0, 16)
+	b = append(b, '%')
+	for _, c := range "+-# 0" {
+		if f.Flag(int(c)) {
+			b = append(b, byte(c))
+		}
+	}
+	if w, ok := f.Width(); ok {
+		b = strconv.AppendInt(b, int64(w), 10)
+	}
+	if p, ok := f.Precision(); ok {
+		b = append(b, '.')
+		b = strconv.AppendInt(b, int64(p), 10)
+	}
+	return string(append(b, string(verb)...))
+}
+
+// argVerbs returns the verbs of the arguments of format in order, the verb is '*' for the arguments
+// consumed by the * width or precision and 0 for those not referred by format
+func argVerbs(format string, n int) []rune {
+	verbs := make([]rune, n)
+	arg := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		for i++; i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0; i++ {
+		}
+	spec:
+		for ; i < len(format); i++ {
+			switch c := format[i]; {
+			case c == '[':
+				j := strings.IndexByte(format[i:], ']')
+				if j < 0 {
+					return verbs
+				}
+				if k, err := strconv.Atoi(format[i+1 : i+j]); err == nil {
+					arg = k - 1
+				}
+				i += j
+			case c == '*':
+				if arg >= 0 && arg < n {
+					verbs[arg] = '*'
+				}
+				arg++
+			case c != '.' && (c < '0' || c > '9'):
+				break spec
+			}
+		}
+		if i >= len(format) {
+			break
+		}
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		i += size - 1
+		if verb == '%' {
+			continue
+		}
+		if arg >= 0 && arg < n {
+			verbs[arg] = verb
+		}
+		arg++
+	}
+	return verbs
+}
+
+// typeVerb reports whether verb prints the type or the address of the argument itself or takes it as the * width
+// or precision, fmt handles them before fmt.Formatter so the wrapped argument must be passed as is
+func typeVerb(verb rune) bool {
+	return verb == 'T' || verb == 'p' || verb == '*'
+}
+
+func needsEscape(r rune) bool {
+	return (r < 0x20 && r != '\t') || (r >= 0x7f && r < 0xa0)
+}
+
+// sanitize escapes newlines, carriage returns, ANSI escape sequences and other control characters
+func sanitize(str string) string {
+	i := strings.IndexFunc(str, needsEscape)
+	if i < 0 {
+		return str
+	}
+	var sb strings.Builder
+	sb.Grow(len(str) + 8)
+	sb.WriteString(str[:i])
+	for _, r := range str[i:] {
+		switch {
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r < 0x80 && needsEscape(r):
+			sb.WriteString(`\x`)
+			if r < 0x10 {
+				sb.WriteByte('0')
+			}
+			sb.WriteString(strconv.FormatInt(int64(r), 16))
+		case needsEscape(r):
+			sb.WriteString(`\u00`)
+			sb.WriteString(strconv.FormatInt(int64(r), 16))
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func sanitizeArgs(format string, val []interface{}) []interface{} {
+	verbs := argVerbs(format, len(val))
+	vals := make([]interface{}, len(val))
+	for i, v := range val {
+		if typeVerb(verbs[i]) {
+			vals[i] = v
+			continue
+		}
+		vals[i] = sanitized{v: v}
+	}
+	return vals
+}
+
+// EnableSanitize enables escaping of newlines, carriage returns and ANSI escape sequences
+// inside message arguments to prevent log injection
+func (g *Glg) EnableSanitize() *Glg {
+	g.setSwitches(func(s *switches) {
+		s.enableSanitize = true
+	})
+	return g
+}
+
+// DisableSanitize disables message arguments escaping
+func (g *Glg) DisableSanitize() *Glg {
+	g.setSwitches(func(s *switches) {
+		s.enableSanitize = false
+	})
+	return g
+}
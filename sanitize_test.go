@@ -0,0 +1,146 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func Test_sanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		str  string
+		want string
+	}{
+		{
+			name: "plain",
+			str:  "hello\tglg",
+			want: "hello\tglg",
+		},
+		{
+			name: "newline",
+			str:  "user\n2021-01-01 00:00:00\t[INFO]:\tforged\r",
+			want: `user\n2021-01-01 00:00:00` + "\t" + `[INFO]:` + "\t" + `forged\r`,
+		},
+		{
+			name: "ansi",
+			str:  "\x1b[31mred\x1b[39m",
+			want: `\x1b[31mred\x1b[39m`,
+		},
+		{
+			name: "c1",
+			str:  "\u009b2J",
+			want: `\u009b2J`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitize(tt.str); got != tt.want {
+				t.Errorf("sanitize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sanitized_Format(t *testing.T) {
+	tests := []struct {
+		format string
+		val    interface{}
+	}{
+		{format: "%s", val: "a\nb"},
+		{format: "%-6s|", val: "ab"},
+		{format: "%05.1f", val: 3.14159},
+		{format: "%+v", val: struct{ A string }{A: "x\ny"}},
+		{format: "%x", val: 255},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			want := sanitize(fmt.Sprintf(tt.format, tt.val))
+			if got := fmt.Sprintf(tt.format, sanitized{v: tt.val}); got != want {
+				t.Errorf("sanitized.Format() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestGlg_EnableSanitize(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().EnableSanitize()
+	g.Infof("login user=%s", "bob\n[INFO]:\tadmin logged in")
+	want := "[INFO]:\tlogin user=bob\\n[INFO]:\tadmin logged in\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Glg.EnableSanitize() = %q, want %q", got, want)
+	}
+	buf.Reset()
+	g.DisableSanitize().Info("a\nb")
+	if got := buf.String(); got != "[INFO]:\ta\nb\n" {
+		t.Errorf("Glg.DisableSanitize() = %q", got)
+	}
+}
+
+func Test_argVerbs(t *testing.T) {
+	tests := []struct {
+		format string
+		n      int
+		want   string
+	}{
+		{format: "%v %T %p", n: 3, want: "vTp"},
+		{format: "100%% %s", n: 1, want: "s"},
+		{format: "%-8.3f %+q", n: 2, want: "fq"},
+		{format: "%*d %.*s", n: 4, want: "*d*s"},
+		{format: "%[2]*[1]d", n: 2, want: "d*"},
+		{format: "%[2]T %[1]s", n: 2, want: "sT"},
+		{format: "%d", n: 2, want: "d\x00"},
+		{format: "%d %d %d", n: 1, want: "d"},
+		{format: "%[x", n: 1, want: "\x00"},
+		{format: "trailing %", n: 1, want: "\x00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if got := string(argVerbs(tt.format, tt.n)); got != tt.want {
+				t.Errorf("argVerbs(%q) = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_EnableSanitize_TypeVerb(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().EnableSanitize()
+	g.Infof("%T %s %T", 1, "a\nb", time.Second)
+	if got, want := buf.String(), "[INFO]:\tint a\\nb time.Duration\n"; got != want {
+		t.Errorf("Glg.EnableSanitize() %%T = %q, want %q", got, want)
+	}
+}
+
+func TestGlg_EnableSanitize_StarWidth(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().EnableSanitize()
+	g.Infof("[%*d] [%.*s]", 5, 3, 2, "a\nb")
+	if got, want := buf.String(), "[INFO]:\t[    3] [a\\n]\n"; got != want {
+		t.Errorf("Glg.EnableSanitize() %%* = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,64 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import "fmt"
+
+// SecretMask is the replacement of the secret values in all encoders
+const SecretMask = "***"
+
+type secret struct {
+	v interface{}
+}
+
+// Secret wraps v so that it is rendered as SecretMask instead of its value.
+// Struct fields are masked as well by the `glg:"name,secret"` tag, or `glg:"secret"` keeping the field name
+func Secret(v interface{}) LogValuer {
+	return secret{v: v}
+}
+
+// LogValue implements LogValuer
+func (s secret) LogValue() interface{} {
+	return SecretMask
+}
+
+// String implements fmt.Stringer
+func (s secret) String() string {
+	return SecretMask
+}
+
+// GoString implements fmt.GoStringer, so %#v does not leak the value either
+func (s secret) GoString() string {
+	return SecretMask
+}
+
+// Format implements fmt.Formatter
+func (s secret) Format(f fmt.State, verb rune) {
+	f.Write([]byte(SecretMask))
+}
+
+// MarshalJSON implements json.Marshaler
+func (s secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + SecretMask + `"`), nil
+}
@@ -0,0 +1,108 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type secretCredential struct {
+	User     string `glg:"user"`
+	Password string `glg:"password,secret"`
+	Token    string `glg:",secret,omitempty"`
+}
+
+func TestSecret(t *testing.T) {
+	cred := secretCredential{User: "bob", Password: "hunter2"}
+	tests := []struct {
+		name   string
+		json   bool
+		format string
+		val    []interface{}
+		want   string
+	}{
+		{
+			name: "value",
+			val:  []interface{}{"password", Secret("hunter2")},
+			want: "[INFO]:\tpassword ***\n",
+		},
+		{
+			name:   "format",
+			format: "password=%s %q %#v",
+			val:    []interface{}{Secret("hunter2"), Secret("hunter2"), Secret("hunter2")},
+			want:   "[INFO]:\tpassword=*** \"***\" \"***\"\n",
+		},
+		{
+			name: "field",
+			val:  []interface{}{"login", F("password", Secret("hunter2"))},
+			want: "[INFO]:\tlogin\tpassword=***\n",
+		},
+		{
+			name: "struct tag",
+			val:  []interface{}{cred},
+			want: "[INFO]:\t{user=bob password=***}\n",
+		},
+		{
+			name: "json value",
+			json: true,
+			val:  []interface{}{Secret("hunter2")},
+			want: `{"level":"INFO","detail":"***"}` + "\n",
+		},
+		{
+			name: "json field",
+			json: true,
+			val:  []interface{}{"login", F("cred", &cred), F("password", Secret("hunter2"))},
+			want: `{"level":"INFO","detail":"login","fields":{"cred":{"user":"bob","password":"***"},"password":"***"}}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp()
+			if tt.json {
+				g.EnableJSON()
+			}
+			var err error
+			if tt.format != "" {
+				err = g.Infof(tt.format, tt.val...)
+			} else {
+				err = g.Info(tt.val...)
+			}
+			if err != nil {
+				t.Error(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Secret() = %q, want %q", got, tt.want)
+			}
+			if strings.Contains(buf.String(), "hunter2") {
+				t.Errorf("Secret() leaked %q", buf.String())
+			}
+		})
+	}
+	if got := fmt.Sprintf("%v %+v", Secret("hunter2"), Secret("hunter2")); got != "*** ***" {
+		t.Errorf("Secret() = %q", got)
+	}
+}
@@ -0,0 +1,131 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"sync/atomic"
+)
+
+// trackWriter records the writer to be closed by Shutdown, reset forgets the former writers
+func (g *Glg) trackWriter(w io.Writer, reset bool) {
+	g.writersMu.Lock()
+	defer g.writersMu.Unlock()
+	if reset {
+		g.writers = nil
+	}
+	if w == nil {
+		return
+	}
+	if reflect.TypeOf(w).Comparable() {
+		for _, tw := range g.writers {
+			if tw == w {
+				return
+			}
+		}
+	}
+	g.writers = append(g.writers, w)
+}
+
+// Shutdown stops accepting entries, writes the asynchronously queued and suspended entries, flushes the batching writers
+// and closes the writers set to the instance, except os.Stdout and os.Stderr.
+// The batching writers sending to the remote endpoints are closed with ctx, so the batch being sent is canceled when ctx is done.
+// Entries logged after Shutdown are discarded and counted by Dropped.
+// Shutdown returns ctx.Err() when ctx is done before all the entries are written, the rest continues in the background
+func (g *Glg) Shutdown(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&g.shutdown, 0, 1) {
+		return nil
+	}
+	g.DisableLossReport()
+	g.DisableRuntimeStats()
+	g.writersMu.Lock()
+	writers := g.writers
+	g.writers = nil
+	g.writersMu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		g.asyncMu.Lock()
+		a := g.asyncer()
+		g.async.Store((*asyncer)(nil))
+		g.asyncMu.Unlock()
+		var err error
+		if a != nil {
+			err = a.close()
+		}
+		if rerr := g.Resume(); err == nil {
+			err = rerr
+		}
+		if serr := g.flushStd(); err == nil {
+			err = serr
+		}
+		for _, w := range writers {
+			if cerr := closeWriter(ctx, w); err == nil {
+				err = cerr
+			}
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown stops accepting entries and writes the pending entries of the global instance
+func Shutdown(ctx context.Context) error {
+	return Get().Shutdown(ctx)
+}
+
+// closeWriter closes or flushes w, the writers sending the entries to the remote endpoints stop when ctx is done.
+// The writers already closed are ignored
+func closeWriter(ctx context.Context, w io.Writer) (err error) {
+	if w == os.Stdout || w == os.Stderr {
+		return nil
+	}
+	switch c := w.(type) {
+	case interface {
+		CloseContext(context.Context) error
+	}:
+		err = c.CloseContext(ctx)
+	case io.Closer:
+		err = c.Close()
+	case interface {
+		FlushContext(context.Context) error
+	}:
+		err = c.FlushContext(ctx)
+	case interface{ Flush() error }:
+		err = c.Flush()
+	}
+	if errors.Is(err, os.ErrClosed) || errors.Is(err, ErrWriterClosed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type closeBuffer struct {
+	bytes.Buffer
+	gate   chan struct{}
+	closed int
+}
+
+func (c *closeBuffer) Write(p []byte) (int, error) {
+	if c.gate != nil {
+		<-c.gate
+	}
+	return c.Buffer.Write(p)
+}
+
+func (c *closeBuffer) Close() error {
+	c.closed++
+	return nil
+}
+
+func TestGlg_Shutdown(t *testing.T) {
+	w := new(closeBuffer)
+	batch := newBatcher(func(context.Context, []batchEntry) error { return nil }, 0, 0, 0)
+	var sent []string
+	batch.send = func(_ context.Context, entries []batchEntry) error {
+		for _, e := range entries {
+			sent = append(sent, string(e.data))
+		}
+		return nil
+	}
+	g := New().SetMode(WRITER).DisableTimestamp().SetLineTraceMode(TraceLineNone).SetWriter(w).SetLevelWriter(INFO, w).
+		AddLevelWriter(ERR, levelWriter{b: batch, level: ERR}).EnableAsync(0)
+	g.Info("a")
+	g.Error("b")
+	if err := g.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	const want = "[INFO]:\ta\n[ERR]:\tb\n"
+	if got := w.String(); got != want {
+		t.Errorf("Shutdown() wrote %q, want %q", got, want)
+	}
+	if w.closed != 1 {
+		t.Errorf("Shutdown() closed the writer %d times, want 1", w.closed)
+	}
+	if len(sent) != 1 || !bytes.Contains([]byte(sent[0]), []byte("b")) {
+		t.Errorf("Shutdown() sent %q, want the flushed error", sent)
+	}
+	if err := g.Info("c"); err != nil || g.Dropped() != 1 || w.String() != want {
+		t.Errorf("Info() after Shutdown error = %v, dropped = %d", err, g.Dropped())
+	}
+	if err := g.Shutdown(context.Background()); err != nil {
+		t.Errorf("second Shutdown() error = %v", err)
+	}
+}
+
+func TestGlg_Shutdown_Deadline(t *testing.T) {
+	w := &closeBuffer{gate: make(chan struct{})}
+	defer close(w.gate)
+	g := New().SetMode(WRITER).DisableTimestamp().SetWriter(w).EnableAsync(0)
+	g.Info("blocked")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := g.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
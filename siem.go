@@ -0,0 +1,288 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// siemKind is the format of SIEMEncoder
+type siemKind uint8
+
+const (
+	siemCEF siemKind = iota
+	siemLEEF
+)
+
+// maxCEFName is the length limit of the CEF event name
+const maxCEFName = 512
+
+// leefTimeFormat is the devTime format written with devTimeFormat of the LEEF events
+const leefTimeFormat = "2006-01-02T15:04:05.000-0700"
+
+// SIEMEncoder is Encoder writing the entries in the ArcSight Common Event Format or the QRadar Log Event Extended Format,
+// one event per line, e.g. for the security relevant levels sent to the SIEM by the syslog writer
+//
+//	cef := glg.NewCEFEncoder("Acme", "api", "1.2").MapField("user", "suser").MapField("client.ip", "src")
+//	g.SetLevelEncoder(glg.ERR, cef).SetLevelWriter(glg.ERR, siem)
+//
+// The level tag is the event class ID, the first line of the message is the event name and the level is the severity.
+// The fields are the extension of CEF and the attributes of LEEF, named by MapField or by the camel cased key
+// of the nested groups joined, e.g. http.status_code is httpStatusCode
+type SIEMEncoder struct {
+	kind    siemKind
+	vendor  string
+	product string
+	version string
+
+	mu       sync.RWMutex
+	keys     map[string]string
+	severity map[LEVEL]int
+}
+
+// NewCEFEncoder returns SIEMEncoder writing CEF:0 events of the device vendor, product and version
+func NewCEFEncoder(vendor, product, version string) *SIEMEncoder {
+	return newSIEMEncoder(siemCEF, vendor, product, version)
+}
+
+// NewLEEFEncoder returns SIEMEncoder writing LEEF:2.0 events of the vendor, product and version delimited by tabs
+func NewLEEFEncoder(vendor, product, version string) *SIEMEncoder {
+	return newSIEMEncoder(siemLEEF, vendor, product, version)
+}
+
+func newSIEMEncoder(kind siemKind, vendor, product, version string) *SIEMEncoder {
+	return &SIEMEncoder{
+		kind:     kind,
+		vendor:   vendor,
+		product:  product,
+		version:  version,
+		keys:     make(map[string]string),
+		severity: make(map[LEVEL]int),
+	}
+}
+
+// MapField writes the field of the key as the extension key or the attribute name ext, e.g. MapField("user", "suser").
+// The fields in groups are keyed by the group names and the key joined by dots, e.g. "client.ip",
+// the caller is keyed "caller" and the empty ext omits the field
+func (s *SIEMEncoder) MapField(key, ext string) *SIEMEncoder {
+	s.mu.Lock()
+	s.keys[key] = ext
+	s.mu.Unlock()
+	return s
+}
+
+// SetSeverity sets the severity of the level, 0 to 10
+func (s *SIEMEncoder) SetSeverity(lv LEVEL, severity int) *SIEMEncoder {
+	if severity < 0 {
+		severity = 0
+	} else if severity > 10 {
+		severity = 10
+	}
+	s.mu.Lock()
+	s.severity[lv] = severity
+	s.mu.Unlock()
+	return s
+}
+
+// siemSeverity is the default severity of the level, the custom levels are 5
+func siemSeverity(lv LEVEL) int {
+	switch lv {
+	case DEBG, TRACE:
+		return 1
+	case PRINT, LOG, INFO, OK:
+		return 3
+	case WARN:
+		return 6
+	case ERR:
+		return 8
+	case FAIL:
+		return 9
+	case FATAL:
+		return 10
+	}
+	return 5
+}
+
+// Encode implements Encoder
+func (s *SIEMEncoder) Encode(w io.Writer, e *Entry) error {
+	s.mu.RLock()
+	sev, ok := s.severity[e.Level]
+	s.mu.RUnlock()
+	if !ok {
+		sev = siemSeverity(e.Level)
+	}
+	name, _, multiLine := strings.Cut(e.Message, rc)
+	b := make([]byte, 0, 256+len(e.Message))
+	if s.kind == siemCEF {
+		if len(name) > maxCEFName {
+			name, multiLine = name[:maxCEFName], true
+		}
+		b = append(b, "CEF:0|"...)
+		for _, h := range []string{s.vendor, s.product, s.version, e.tag(), name} {
+			b = append(appendCEFHeader(b, h), '|')
+		}
+		b = strconv.AppendInt(b, int64(sev), 10)
+		b = append(b, '|')
+		if !e.Time.IsZero() {
+			b = s.appendAttr(b, "rt", strconv.FormatInt(e.Time.UnixMilli(), 10))
+		}
+	} else {
+		b = append(b, "LEEF:2.0|"...)
+		for _, h := range []string{s.vendor, s.product, s.version, e.tag()} {
+			b = append(appendCEFHeader(b, h), '|')
+		}
+		b = append(b, "x09|"...)
+		if !e.Time.IsZero() {
+			b = s.appendAttr(b, "devTime", e.Time.Format(leefTimeFormat))
+			b = s.appendAttr(b, "devTimeFormat", "yyyy-MM-dd'T'HH:mm:ss.SSSZ")
+		}
+		b = s.appendAttr(b, "sev", strconv.Itoa(sev))
+	}
+	if multiLine || s.kind == siemLEEF {
+		b = s.appendAttr(b, "msg", e.Message)
+	}
+	if e.Caller != "" {
+		b = s.appendField(b, "caller", e.Caller)
+	}
+	b = s.appendFields(b, "", e.Fields)
+	if c := b[len(b)-1]; c == ' ' || c == '\t' {
+		b = b[:len(b)-1]
+	}
+	_, err := w.Write(append(b, '\n'))
+	return err
+}
+
+// appendFields appends the fields, the groups are flattened with the dotted keys
+func (s *SIEMEncoder) appendFields(b []byte, prefix string, fields []Field) []byte {
+	jf := jsonFields{fields: fields}
+	for i, f := range fields {
+		if jf.overridden(i) {
+			continue
+		}
+		if f.kind == fieldGroup {
+			if group, ok := jf.mergeGroup(i); ok {
+				b = s.appendFields(b, prefix+f.Key+".", group.iface.([]Field))
+			}
+			continue
+		}
+		b = s.appendField(b, prefix+f.Key, string(f.appendText(nil, false)))
+	}
+	return b
+}
+
+// appendField appends the field named by MapField or by the camel cased key
+func (s *SIEMEncoder) appendField(b []byte, key, val string) []byte {
+	s.mu.RLock()
+	ext, ok := s.keys[key]
+	s.mu.RUnlock()
+	if !ok {
+		ext = siemKey(key)
+	}
+	if ext == "" {
+		return b
+	}
+	return s.appendAttr(b, ext, val)
+}
+
+// appendAttr appends the CEF extension followed by a space or the LEEF attribute followed by a tab
+func (s *SIEMEncoder) appendAttr(b []byte, key, val string) []byte {
+	b = append(append(b, key...), '=')
+	if s.kind == siemCEF {
+		return append(appendCEFValue(b, val), ' ')
+	}
+	return append(appendLEEFValue(b, val), '\t')
+}
+
+// siemKey camel cases the key into the letters and digits allowed as the extension key, e.g. http.status_code is httpStatusCode
+func siemKey(key string) string {
+	var sb strings.Builder
+	upper := false
+	for _, r := range key {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if upper && sb.Len() != 0 {
+				r = unicode.ToUpper(r)
+			}
+			sb.WriteRune(r)
+			upper = false
+			continue
+		}
+		upper = true
+	}
+	return sb.String()
+}
+
+// appendCEFHeader appends the header field escaping pipes and backslashes, line breaks are replaced with spaces
+func appendCEFHeader(b []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '|', '\\':
+			b = append(b, '\\', c)
+		case '\n', '\r':
+			b = append(b, ' ')
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
+
+// appendCEFValue appends the extension value escaping equal signs, backslashes and line breaks
+func appendCEFValue(b []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '=', '\\':
+			b = append(b, '\\', c)
+		case '\n':
+			b = append(b, '\\', 'n')
+		case '\r':
+			b = append(b, '\\', 'r')
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
+
+// appendLEEFValue appends the attribute value escaping the tab delimiter, backslashes and line breaks
+func appendLEEFValue(b []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			b = append(b, '\\', c)
+		case '\t':
+			b = append(b, '\\', 't')
+		case '\n':
+			b = append(b, '\\', 'n')
+		case '\r':
+			b = append(b, '\\', 'r')
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
@@ -0,0 +1,101 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSIEMEncoder(t *testing.T) {
+	ts := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	e := &Entry{
+		Time:    ts,
+		Level:   ERR,
+		Caller:  "auth.go:42",
+		Message: "login failed | user=x",
+		Fields: []Field{
+			String("user", "alice"),
+			Group("client", String("ip", "10.0.0.1")),
+			Int("http.status_code", 401),
+			String("note", "a=b\\c\nd\te"),
+		},
+	}
+	tests := []struct {
+		name string
+		enc  *SIEMEncoder
+		want string
+	}{
+		{
+			name: "CEF",
+			enc:  NewCEFEncoder("Acme|Corp", "api", "1.2").MapField("user", "suser").MapField("client.ip", "src").MapField("caller", ""),
+			want: `CEF:0|Acme\|Corp|api|1.2|ERR|login failed \| user=x|8|rt=1772366400000 suser=alice src=10.0.0.1 httpStatusCode=401 note=a\=b\\c\nd` + "\te\n",
+		},
+		{
+			name: "LEEF",
+			enc:  NewLEEFEncoder("Acme", "api", "1.2").MapField("user", "usrName").SetSeverity(ERR, 42),
+			want: "LEEF:2.0|Acme|api|1.2|ERR|x09|devTime=2026-03-01T12:00:00.000+0000\tdevTimeFormat=yyyy-MM-dd'T'HH:mm:ss.SSSZ\tsev=10\t" +
+				"msg=login failed | user=x\tcaller=auth.go:42\tusrName=alice\tclientIp=10.0.0.1\thttpStatusCode=401\tnote=a=b\\\\c\\nd\\te\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := tt.enc.Encode(buf, e); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Encode() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSIEMEncoder_MultiLine(t *testing.T) {
+	buf := new(bytes.Buffer)
+	cef := NewCEFEncoder("Acme", "api", "1")
+	g := New().SetMode(WRITER).SetLineTraceMode(TraceLineNone).DisableTimestamp().
+		SetLevelWriter(WARN, buf).SetLevelEncoder(WARN, cef)
+	g.Warn("first line\nsecond line")
+	g.Warn(strings.Repeat("x", maxCEFName+1))
+	want := "CEF:0|Acme|api|1|WARN|first line|6|msg=first line\\nsecond line\n" +
+		"CEF:0|Acme|api|1|WARN|" + strings.Repeat("x", maxCEFName) + "|6|msg=" + strings.Repeat("x", maxCEFName+1) + "\n"
+	if buf.String() != want {
+		t.Errorf("CEF = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestSiemKey(t *testing.T) {
+	for key, want := range map[string]string{
+		"user":             "user",
+		"http.status_code": "httpStatusCode",
+		"_x-y":             "xY",
+		"ключ":             "",
+	} {
+		if got := siemKey(key); got != want {
+			t.Errorf("siemKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
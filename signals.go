@@ -0,0 +1,110 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"time"
+)
+
+// started is the start time of the process logged as the uptime of the exit entry
+var started = time.Now()
+
+// LogSignals logs SIGTERM, SIGINT and SIGHUP (on Unix) received by the process at WARN, and Main and MainContext log the exit entry
+// with the exit status, the last SIGTERM or SIGINT and the uptime at INFO, so the incident timeline shows why the process stopped
+//
+//	[WARN]:	signal received	signal=terminated
+//	[INFO]:	process exit	code=143 signal=terminated uptime=72h3m0.5s
+//
+// The signals are only logged and remembered, exiting is left to Main, MainContext or the signal handling of the application,
+// so SIGTERM and SIGINT do not terminate the process by themselves while LogSignals is enabled.
+// While Main or MainContext runs, they log SIGTERM and SIGINT by themselves.
+// The exit by Fatal and the return of the main function are not logged
+func (g *Glg) LogSignals() *Glg {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, logSignals...)
+	g.sigMu.Lock()
+	g.stopLogSignals()
+	g.logSig = ch
+	g.sigMu.Unlock()
+	go func() {
+		for s := range ch {
+			if s != hangupSignal {
+				g.sigMu.Lock()
+				g.lastSig = s
+				g.sigMu.Unlock()
+				if atomic.LoadInt32(&g.inMain) != 0 {
+					continue
+				}
+			}
+			g.Warn("signal received", String("signal", s.String()))
+		}
+	}()
+	return g
+}
+
+// LogSignals logs the received signals and the exit entry by the global instance
+func LogSignals() *Glg {
+	return Get().LogSignals()
+}
+
+// DisableLogSignals stops logging the signals, SIGTERM and SIGINT terminate the process by default again
+// unless the application handles them
+func (g *Glg) DisableLogSignals() *Glg {
+	g.sigMu.Lock()
+	g.stopLogSignals()
+	g.sigMu.Unlock()
+	return g
+}
+
+// DisableLogSignals stops logging the signals by the global instance
+func DisableLogSignals() *Glg {
+	return Get().DisableLogSignals()
+}
+
+func (g *Glg) stopLogSignals() {
+	if g.logSig != nil {
+		signal.Stop(g.logSig)
+		close(g.logSig)
+		g.logSig = nil
+	}
+}
+
+// logExit logs the exit entry with the last signal received when LogSignals is enabled
+func (g *Glg) logExit(code int) {
+	g.sigMu.Lock()
+	enabled, sig := g.logSig != nil, g.lastSig
+	g.sigMu.Unlock()
+	if !enabled {
+		return
+	}
+	vals := []interface{}{"process exit", Int("code", code)}
+	if sig != nil {
+		vals = append(vals, String("signal", sig.String()))
+	}
+	vals = append(vals, Dur("uptime", time.Since(started)))
+	g.out(INFO, g.blankFormat(len(vals)), vals...)
+}
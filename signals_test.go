@@ -0,0 +1,83 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestGlg_LogSignals(t *testing.T) {
+	if hangupSignal == nil {
+		t.Skip("SIGHUP is not supported")
+	}
+	defer ReplaceExitFunc(exit)
+	exited := make(chan int, 1)
+	ReplaceExitFunc(func(n int) {
+		exited <- n
+	})
+	w := new(gateWriter)
+	g := New().SetMode(WRITER).SetWriter(w).DisableTimestamp().LogSignals()
+	defer g.DisableLogSignals()
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Signal(hangupSignal); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for w.String() == "" && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got, want := w.String(), "[WARN]:\tsignal received\tsignal=hangup"; got != want {
+		t.Errorf("SIGHUP output = %q, want %q", got, want)
+	}
+	if err = p.Signal(syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	want := "[WARN]:\tsignal received\tsignal=hangup,[WARN]:\tsignal received\tsignal=terminated"
+	deadline = time.Now().Add(time.Second)
+	for w.String() != want && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := w.String(); got != want {
+		t.Errorf("SIGTERM output = %q, want %q", got, want)
+	}
+	select {
+	case code := <-exited:
+		t.Fatalf("SIGTERM exited with %d", code)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	g.exitMain(signalCode(syscall.SIGTERM))
+	if code := <-exited; code != 128+int(syscall.SIGTERM) {
+		t.Errorf("exit code = %d, want %d", code, 128+int(syscall.SIGTERM))
+	}
+	if got := w.String(); !strings.Contains(got, "[INFO]:\tprocess exit\tcode=143 signal=terminated uptime=") {
+		t.Errorf("exit output = %q", got)
+	}
+}
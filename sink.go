@@ -0,0 +1,409 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultFlushInterval is the default interval of the batching writers to send buffered entries
+	DefaultFlushInterval = time.Second
+	// DefaultMaxRetries is the default retry count of the batching writers
+	DefaultMaxRetries = 3
+	// DefaultSinkTimeout is the default time limit of the batching writers to send one batch
+	DefaultSinkTimeout = 10 * time.Second
+
+	defaultRetryBackoff = 100 * time.Millisecond
+)
+
+// ErrWriterClosed is returned by the batching writers after Close
+var ErrWriterClosed = errors.New("error:\twriter is already closed")
+
+// partialError is returned by send when the batch is stored partially, e.g. by the pipelined commands,
+// rest are the entries to retry so the stored ones are not duplicated
+type partialError struct {
+	rest []batchEntry
+	err  error
+}
+
+func (e *partialError) Error() string {
+	return e.err.Error()
+}
+
+func (e *partialError) Unwrap() error {
+	return e.err
+}
+
+// batchEntry is the buffered log entry of the batching writers, level is UNKNOWN unless the writer is bound to the level
+type batchEntry struct {
+	data  []byte
+	ts    int64
+	level LEVEL
+}
+
+// batcher buffers entries and sends them in batches by the limits or the flush interval.
+// Batches are sent in the written order, sending errors are retried with exponential backoff.
+// Each send gets the context limited by timeout, which is canceled as well when the context of CloseContext is done
+type batcher struct {
+	send     func(context.Context, []batchEntry) error
+	maxCount int
+	maxBytes int
+	overhead int
+	interval time.Duration
+	retries  int
+	backoff  time.Duration
+	timeout  time.Duration
+	overflow OverflowPolicy
+	hook     MetricsHook
+	onError  func(error)
+	dropped  uint64
+	stats    sinkStats
+	ctx      context.Context
+	cancel   context.CancelFunc
+
+	once    sync.Once
+	mu      sync.Mutex
+	sendMu  sync.Mutex
+	entries []batchEntry
+	size    int
+	err     error
+	closed  bool
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// sinkOptions are the setters of the options of the batching writers embedding batcher, W is the writer returned for chaining
+type sinkOptions[W any] struct {
+	b    *batcher
+	self W
+}
+
+// SetFlushInterval sets the interval to send buffered entries, it must be called before the first Write
+func (o sinkOptions[W]) SetFlushInterval(d time.Duration) W {
+	o.b.interval = d
+	return o.self
+}
+
+// SetMaxRetries sets the retry count of failed batches, it must be called before the first Write
+func (o sinkOptions[W]) SetMaxRetries(n int) W {
+	o.b.retries = n
+	return o.self
+}
+
+// SetOverflowPolicy sets the policy of the full buffer while the former batch is being sent, default is OverflowBlock
+func (o sinkOptions[W]) SetOverflowPolicy(policy OverflowPolicy) W {
+	o.b.mu.Lock()
+	o.b.overflow = policy
+	o.b.mu.Unlock()
+	return o.self
+}
+
+// SetMetricsHook sets the hook receiving the metrics of the writer
+func (o sinkOptions[W]) SetMetricsHook(hook MetricsHook) W {
+	o.b.mu.Lock()
+	o.b.hook = hook
+	o.b.mu.Unlock()
+	return o.self
+}
+
+// SetTimeout sets the time limit to send one batch, default is DefaultSinkTimeout and 0 is unlimited.
+// The batch timed out fails with the error wrapping context.DeadlineExceeded and is retried
+func (o sinkOptions[W]) SetTimeout(d time.Duration) W {
+	o.b.mu.Lock()
+	o.b.timeout = d
+	o.b.mu.Unlock()
+	return o.self
+}
+
+// SetErrorHandler sets the handler called with the error of each batch failed after the retries, including the timeouts.
+// The error is also returned by the next Write
+func (o sinkOptions[W]) SetErrorHandler(fn func(error)) W {
+	o.b.mu.Lock()
+	o.b.onError = fn
+	o.b.mu.Unlock()
+	return o.self
+}
+
+func newBatcher(send func(context.Context, []batchEntry) error, maxCount, maxBytes, overhead int) *batcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &batcher{
+		send:     send,
+		maxCount: maxCount,
+		maxBytes: maxBytes,
+		overhead: overhead,
+		interval: DefaultFlushInterval,
+		retries:  DefaultMaxRetries,
+		backoff:  defaultRetryBackoff,
+		timeout:  DefaultSinkTimeout,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Write buffers p as one entry, the trailing newline is removed.
+// The error of the former background flush is returned once
+func (b *batcher) Write(p []byte) (int, error) {
+	return b.write(p, UNKNOWN)
+}
+
+func (b *batcher) write(p []byte, level LEVEL) (int, error) {
+	b.once.Do(b.start)
+	data := make([]byte, len(bytes.TrimRight(p, "\r\n")))
+	copy(data, p)
+	size := len(data) + b.overhead
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return 0, ErrWriterClosed
+	}
+	err := b.err
+	b.err = nil
+	overflow, hook := b.overflow, b.hook
+	var batch []batchEntry
+	for len(b.entries) != 0 &&
+		((b.maxCount > 0 && len(b.entries) >= b.maxCount) || (b.maxBytes > 0 && b.size+size > b.maxBytes)) {
+		if overflow == OverflowBlock {
+			b.sendMu.Lock()
+		} else if !b.sendMu.TryLock() {
+			// the former batch is still being sent, the buffer is bounded by the limits
+			if overflow == OverflowDropNewest {
+				b.mu.Unlock()
+				b.drop(hook, level)
+				return len(p), err
+			}
+			b.size -= len(b.entries[0].data) + b.overhead
+			b.drop(hook, b.entries[0].level)
+			b.entries = append(b.entries[:0], b.entries[1:]...)
+			continue
+		}
+		batch, b.entries, b.size = b.entries, nil, 0
+	}
+	b.entries = append(b.entries, batchEntry{
+		data:  data,
+		ts:    time.Now().UnixNano(),
+		level: level,
+	})
+	b.size += size
+	if batch != nil {
+		b.mu.Unlock()
+		serr := b.retry(b.ctx, batch)
+		b.sendMu.Unlock()
+		b.reportError(serr)
+		if err == nil {
+			err = serr
+		}
+		return len(p), err
+	}
+	b.mu.Unlock()
+	return len(p), err
+}
+
+// drop counts the entry discarded by the overflow policy and passes it to hook read by the caller under mu
+func (b *batcher) drop(hook MetricsHook, level LEVEL) {
+	atomic.AddUint64(&b.dropped, 1)
+	if hook != nil {
+		hook(MetricDropped, level, 1)
+	}
+}
+
+// Dropped returns the number of the entries discarded by the overflow policy
+func (b *batcher) Dropped() uint64 {
+	return atomic.LoadUint64(&b.dropped)
+}
+
+// Flush sends the buffered entries immediately
+func (b *batcher) Flush() error {
+	return b.flush(b.ctx)
+}
+
+// FlushContext sends the buffered entries immediately, the batch being sent is canceled when ctx is done
+func (b *batcher) FlushContext(ctx context.Context) error {
+	return b.flush(ctx)
+}
+
+func (b *batcher) flush(ctx context.Context) error {
+	b.mu.Lock()
+	batch := b.entries
+	b.entries, b.size = nil, 0
+	err := b.err
+	b.err = nil
+	b.sendMu.Lock()
+	b.mu.Unlock()
+	if len(batch) == 0 {
+		b.sendMu.Unlock()
+		return err
+	}
+	serr := b.retry(ctx, batch)
+	b.sendMu.Unlock()
+	b.reportError(serr)
+	if serr != nil {
+		return serr
+	}
+	return err
+}
+
+// reportError passes the error of the batch given up to the error handler, it is called without the locks
+// so the handler can log through the logger writing to the same writer
+func (b *batcher) reportError(err error) {
+	b.mu.Lock()
+	onError := b.onError
+	b.mu.Unlock()
+	if err != nil && onError != nil {
+		onError(err)
+	}
+}
+
+// Close stops the background flush and sends the buffered entries
+func (b *batcher) Close() error {
+	return b.CloseContext(context.Background())
+}
+
+// CloseContext stops the background flush and sends the buffered entries until ctx is done,
+// the batch being sent is canceled then. Shutdown closes the writer by it with its context
+func (b *batcher) CloseContext(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrWriterClosed
+	}
+	b.closed = true
+	b.mu.Unlock()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			b.cancel()
+		case <-stop:
+		}
+	}()
+	close(b.done)
+	b.wg.Wait()
+	err := b.Flush()
+	b.cancel()
+	return err
+}
+
+func (b *batcher) start() {
+	if b.interval <= 0 {
+		return
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		ticker := time.NewTicker(b.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-b.done:
+				return
+			case <-ticker.C:
+				if err := b.Flush(); err != nil {
+					b.mu.Lock()
+					b.err = err
+					b.mu.Unlock()
+				}
+			}
+		}
+	}()
+}
+
+// Status implements StatusReporter, BytesWritten counts the sent entries
+func (b *batcher) Status() SinkStatus {
+	s := b.stats.status()
+	b.mu.Lock()
+	s.QueueDepth = len(b.entries)
+	b.mu.Unlock()
+	s.Dropped = b.Dropped()
+	return s
+}
+
+func (b *batcher) retry(ctx context.Context, batch []batchEntry) (err error) {
+	backoff := b.backoff
+	var n int
+	for i := 0; ; i++ {
+		err = b.sendContext(ctx, batch)
+		var pe *partialError
+		if err == nil {
+			n += entriesSize(batch)
+		} else if errors.As(err, &pe) {
+			// only the rest is retried
+			n += entriesSize(batch) - entriesSize(pe.rest)
+			batch = pe.rest
+		}
+		if err == nil || len(batch) == 0 || i >= b.retries || ctx.Err() != nil {
+			b.stats.record(n, err)
+			return err
+		}
+		select {
+		case <-b.done:
+			// the writer is closing, retry without waiting
+		case <-ctx.Done():
+		case <-time.After(backoff):
+			backoff *= 2
+		}
+	}
+}
+
+// sendContext sends the batch with ctx limited by timeout,
+// the error of the send timed out or canceled wraps context.DeadlineExceeded or context.Canceled
+func (b *batcher) sendContext(ctx context.Context, batch []batchEntry) error {
+	b.mu.Lock()
+	timeout := b.timeout
+	b.mu.Unlock()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	err := b.send(ctx, batch)
+	if cerr := ctx.Err(); err != nil && cerr != nil && !errors.Is(err, cerr) {
+		var pe *partialError
+		if errors.As(err, &pe) {
+			return &partialError{
+				rest: pe.rest,
+				err:  fmt.Errorf("error:\tsending %d entries is stopped: %w: %v", len(pe.rest), cerr, pe.err),
+			}
+		}
+		err = fmt.Errorf("error:\tsending %d entries is stopped: %w: %v", len(batch), cerr, err)
+	}
+	return err
+}
+
+// entriesSize returns the data size of the entries
+func entriesSize(entries []batchEntry) (n int) {
+	for _, e := range entries {
+		n += len(e.data)
+	}
+	return n
+}
@@ -0,0 +1,109 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBatcher_ErrorHandler(t *testing.T) {
+	var sent []string
+	b := newBatcher(func(_ context.Context, batch []batchEntry) error {
+		for _, e := range batch {
+			if strings.Contains(string(e.data), "unavailable") {
+				sent = append(sent, string(e.data))
+				return nil
+			}
+		}
+		return errors.New("unavailable")
+	}, 0, 0, 0)
+	b.interval, b.retries = 0, 0
+	g := New().SetMode(WRITER).SetWriter(b).DisableTimestamp().SetLineTraceMode(TraceLineNone)
+	// the handler logging to the failing writer itself must not deadlock on the batch being sent
+	b.onError = func(err error) {
+		g.Error(err)
+		b.Flush()
+	}
+	g.Info("hello")
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Flush()
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Flush() error = nil")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Flush() deadlocked in the error handler")
+	}
+	if len(sent) != 1 || sent[0] != "[ERR]:\tunavailable" {
+		t.Errorf("sent = %q, want the entry of the error handler", sent)
+	}
+}
+
+func TestSinkOptions(t *testing.T) {
+	var handled bool
+	w := NewRedisListWriter("localhost:0", "logs").
+		SetFlushInterval(0).
+		SetMaxRetries(1).
+		SetOverflowPolicy(OverflowDropNewest).
+		SetTimeout(time.Second).
+		SetErrorHandler(func(error) { handled = true }).
+		SetMaxLen(10)
+	if w.interval != 0 || w.retries != 1 || w.overflow != OverflowDropNewest || w.timeout != time.Second || w.maxLen != 10 {
+		t.Errorf("options = %v %v %v %v %v", w.interval, w.retries, w.overflow, w.timeout, w.maxLen)
+	}
+	w.reportError(errors.New("failed"))
+	if !handled {
+		t.Error("SetErrorHandler() handler was not called")
+	}
+	w.Close()
+}
+
+func TestSinkOptions_WhileWriting(t *testing.T) {
+	b := newBatcher(func(context.Context, []batchEntry) error {
+		return errors.New("unavailable")
+	}, 1, 0, 0)
+	b.interval, b.retries = time.Millisecond, 0
+	o := sinkOptions[*batcher]{b: b, self: b}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			b.Write([]byte("a"))
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		o.SetOverflowPolicy(OverflowPolicy(i % 3))
+		o.SetMetricsHook(func(string, LEVEL, int64) {})
+		o.SetTimeout(time.Duration(i) * time.Millisecond)
+		o.SetErrorHandler(func(error) {})
+	}
+	<-done
+	b.Close()
+}
@@ -0,0 +1,153 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// SpanKey is the field key of the span group logged by Begin and End, e.g. {"span":{"name":"sync-users","id":"..."}} in JSON
+	SpanKey = "span"
+	// SpanNameKey is the key of the span name in the span group
+	SpanNameKey = "name"
+	// SpanIDKey is the key of the span ID in the span group
+	SpanIDKey = "id"
+	// SpanParentKey is the key of the parent span ID in the span group
+	SpanParentKey = "parent"
+	// SpanPathKey is the key of the names of the enclosing spans joined by "/", e.g. "sync-users/fetch"
+	SpanPathKey = "path"
+)
+
+// SpanID identifies the span begun by Begin
+type SpanID string
+
+// span is the open span of the instance
+type span struct {
+	name   string
+	path   string
+	parent SpanID
+	start  time.Time
+	fields []Field
+}
+
+// spans are the open spans of the instance
+type spans struct {
+	mu   sync.Mutex
+	open map[SpanID]*span
+}
+
+// Begin logs the start of the span name at DEBG and returns its ID, End(id) logs the finish with the elapsed duration.
+// Both entries carry the span group with the shared ID, e.g. defer g.End(g.Begin("sync-users"))
+func (g *Glg) Begin(name string, fields ...Field) SpanID {
+	id, vals := g.begin("", name, fields)
+	g.out(DEBG, g.blankFormat(len(vals)), vals...)
+	return id
+}
+
+// BeginChild logs the start of the span name nested in the span parent, the entries carry the parent ID and the path of the names
+func (g *Glg) BeginChild(parent SpanID, name string, fields ...Field) SpanID {
+	id, vals := g.begin(parent, name, fields)
+	g.out(DEBG, g.blankFormat(len(vals)), vals...)
+	return id
+}
+
+// End logs the finish of the span id with the elapsed duration at INFO, or the level of SetTimerThreshold when the duration exceeds the threshold.
+// An error is returned when the span is not open
+func (g *Glg) End(id SpanID, fields ...Field) error {
+	lv, vals, err := g.end(id, fields)
+	if err != nil {
+		return err
+	}
+	return g.out(lv, g.blankFormat(len(vals)), vals...)
+}
+
+// Begin logs the start of the span name of the global instance
+func Begin(name string, fields ...Field) SpanID {
+	g := Get()
+	id, vals := g.begin("", name, fields)
+	g.out(DEBG, g.blankFormat(len(vals)), vals...)
+	return id
+}
+
+// BeginChild logs the start of the span name nested in the span parent of the global instance
+func BeginChild(parent SpanID, name string, fields ...Field) SpanID {
+	g := Get()
+	id, vals := g.begin(parent, name, fields)
+	g.out(DEBG, g.blankFormat(len(vals)), vals...)
+	return id
+}
+
+// End logs the finish of the span id of the global instance
+func End(id SpanID, fields ...Field) error {
+	g := Get()
+	lv, vals, err := g.end(id, fields)
+	if err != nil {
+		return err
+	}
+	return g.out(lv, g.blankFormat(len(vals)), vals...)
+}
+
+// begin opens the span and returns its ID and the values of the start entry
+func (g *Glg) begin(parent SpanID, name string, fields []Field) (SpanID, []interface{}) {
+	id := SpanID(NewID())
+	s := &span{name: name, path: name, parent: parent, start: time.Now(), fields: fields}
+	g.spans.mu.Lock()
+	if p, ok := g.spans.open[parent]; ok {
+		s.path = p.path + "/" + name
+	}
+	if g.spans.open == nil {
+		g.spans.open = make(map[SpanID]*span)
+	}
+	g.spans.open[id] = s
+	g.spans.mu.Unlock()
+	return id, timerVals(name+" started", append([]Field{s.field(id)}, fields...))
+}
+
+// end closes the span and returns the level and the values of the finish entry
+func (g *Glg) end(id SpanID, fields []Field) (LEVEL, []interface{}, error) {
+	g.spans.mu.Lock()
+	s, ok := g.spans.open[id]
+	delete(g.spans.open, id)
+	g.spans.mu.Unlock()
+	if !ok {
+		return 0, nil, fmt.Errorf("error:\tspan %q is not open", id)
+	}
+	lv, vals := g.timerFinish(s.start, s.name, append(append([]Field{s.field(id)}, s.fields...), fields...))
+	return lv, vals, nil
+}
+
+// field returns the span group of the span id
+func (s *span) field(id SpanID) Field {
+	fields := []Field{String(SpanNameKey, s.name), String(SpanIDKey, string(id))}
+	if s.parent != "" {
+		fields = append(fields, String(SpanParentKey, string(s.parent)))
+	}
+	if s.path != s.name {
+		fields = append(fields, String(SpanPathKey, s.path))
+	}
+	return Group(SpanKey, fields...)
+}
@@ -0,0 +1,104 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGlg_Begin(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineShort)
+	id := g.Begin("sync-users", String("db", "main"))
+	if err := g.End(id); err != nil {
+		t.Fatalf("End() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Begin() output = %q, want 2 lines", buf.String())
+	}
+	span := "span.id=" + string(id)
+	if !strings.Contains(lines[0], "[DEBG]") || !strings.Contains(lines[0], "sync-users started") ||
+		!strings.Contains(lines[0], span) || !strings.Contains(lines[0], "db=main") {
+		t.Errorf("Begin() start = %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "[INFO]") || !strings.Contains(lines[1], "sync-users finished") ||
+		!strings.Contains(lines[1], span) || !strings.Contains(lines[1], "db=main") ||
+		!strings.Contains(lines[1], TimerElapsedKey+"=") {
+		t.Errorf("End() finish = %q", lines[1])
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "span_test.go") {
+			t.Errorf("caller of %q is not the test", line)
+		}
+	}
+	if err := g.End(id); err == nil {
+		t.Error("End() of the ended span error = nil")
+	}
+}
+
+func TestGlg_BeginChild(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).EnableJSON()
+	parent := g.Begin("sync-users")
+	child := g.BeginChild(parent, "fetch")
+	g.End(child)
+	g.End(parent)
+
+	type spanGroup struct {
+		Fields struct {
+			Span struct {
+				Name   string `json:"name"`
+				ID     string `json:"id"`
+				Parent string `json:"parent"`
+				Path   string `json:"path"`
+			} `json:"span"`
+		} `json:"fields"`
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("output = %q, want 4 lines", buf.String())
+	}
+	var got []spanGroup
+	for _, line := range lines {
+		var s spanGroup
+		if err := json.Unmarshal([]byte(line), &s); err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		got = append(got, s)
+	}
+	for i, want := range []struct{ name, id, parent, path string }{
+		{"sync-users", string(parent), "", ""},
+		{"fetch", string(child), string(parent), "sync-users/fetch"},
+		{"fetch", string(child), string(parent), "sync-users/fetch"},
+		{"sync-users", string(parent), "", ""},
+	} {
+		s := got[i].Fields.Span
+		if s.Name != want.name || s.ID != want.id || s.Parent != want.parent || s.Path != want.path {
+			t.Errorf("line %d span = %+v, want %+v", i, s, want)
+		}
+	}
+}
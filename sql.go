@@ -0,0 +1,160 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"io"
+	"strings"
+	"time"
+
+	json "github.com/goccy/go-json"
+)
+
+// SQLDialect selects the placeholders and the column types of the SQL writer
+type SQLDialect uint8
+
+const (
+	// SQLite uses ? placeholders
+	SQLite SQLDialect = iota
+	// PostgreSQL uses $n placeholders and JSONB fields
+	PostgreSQL
+)
+
+const (
+	sqlMaxBatchEntries = 500
+	sqlMaxBatchBytes   = 4 << 20
+)
+
+// SQLWriter is io.Writer which inserts entries into the table in batched transactions.
+// The table (id, ts, level, message, fields) is created if not exists.
+// JSON entries are stored structured, detail as message and fields as JSON, text entries are stored as message.
+// glg does not import any database driver, db is opened by the application
+type SQLWriter struct {
+	*batcher
+	sinkOptions[*SQLWriter]
+	db         *sql.DB
+	dialect    SQLDialect
+	table      string
+	autoCreate bool
+	created    bool
+}
+
+// NewSQLWriter returns SQLWriter of the table, Close it to insert the buffered entries
+func NewSQLWriter(db *sql.DB, dialect SQLDialect, table string) *SQLWriter {
+	w := &SQLWriter{
+		db:         db,
+		dialect:    dialect,
+		table:      `"` + strings.ReplaceAll(table, `"`, `""`) + `"`,
+		autoCreate: true,
+	}
+	w.batcher = newBatcher(w.send, sqlMaxBatchEntries, sqlMaxBatchBytes, 0)
+	w.sinkOptions = sinkOptions[*SQLWriter]{b: w.batcher, self: w}
+	return w
+}
+
+// DisableAutoCreate disables creating the table on the first insert
+func (w *SQLWriter) DisableAutoCreate() *SQLWriter {
+	w.autoCreate = false
+	return w
+}
+
+// Level returns io.Writer which stores the entries with the level
+func (w *SQLWriter) Level(level LEVEL) io.Writer {
+	return levelWriter{
+		b:     w.batcher,
+		level: level,
+	}
+}
+
+func (w *SQLWriter) createTable() string {
+	if w.dialect == PostgreSQL {
+		return "CREATE TABLE IF NOT EXISTS " + w.table +
+			" (id BIGSERIAL PRIMARY KEY, ts TIMESTAMPTZ NOT NULL, level TEXT NOT NULL, message TEXT NOT NULL, fields JSONB)"
+	}
+	return "CREATE TABLE IF NOT EXISTS " + w.table +
+		" (id INTEGER PRIMARY KEY AUTOINCREMENT, ts TIMESTAMP NOT NULL, level TEXT NOT NULL, message TEXT NOT NULL, fields TEXT)"
+}
+
+func (w *SQLWriter) insert() string {
+	if w.dialect == PostgreSQL {
+		return "INSERT INTO " + w.table + " (ts, level, message, fields) VALUES ($1, $2, $3, $4)"
+	}
+	return "INSERT INTO " + w.table + " (ts, level, message, fields) VALUES (?, ?, ?, ?)"
+}
+
+func (w *SQLWriter) send(ctx context.Context, batch []batchEntry) error {
+	if w.autoCreate && !w.created {
+		if _, err := w.db.ExecContext(ctx, w.createTable()); err != nil {
+			return err
+		}
+		w.created = true
+	}
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.PrepareContext(ctx, w.insert())
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	for _, e := range batch {
+		level, msg, fields := sqlColumns(e)
+		if _, err = stmt.ExecContext(ctx, time.Unix(0, e.ts).UTC(), level, msg, fields); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// sqlColumns returns the level, the message and the fields (nil when no fields) of the entry
+func sqlColumns(e batchEntry) (level, msg string, fields interface{}) {
+	level = e.level.String()
+	if !bytes.HasPrefix(e.data, []byte("{")) {
+		return level, string(e.data), nil
+	}
+	var entry struct {
+		Level  string          `json:"level"`
+		Detail json.RawMessage `json:"detail"`
+		Fields json.RawMessage `json:"fields"`
+	}
+	if json.Unmarshal(e.data, &entry) != nil {
+		return level, string(e.data), nil
+	}
+	if level == "" {
+		level = entry.Level
+	}
+	if json.Unmarshal(entry.Detail, &msg) != nil {
+		msg = string(entry.Detail)
+	}
+	if len(entry.Fields) != 0 {
+		fields = string(entry.Fields)
+	}
+	return level, msg, fields
+}
@@ -0,0 +1,189 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSQL is database/sql driver recording the executed statements
+type fakeSQL struct {
+	mu    sync.Mutex
+	execs []string
+	args  [][]driver.Value
+	fail  bool
+}
+
+var fakeSQLs sync.Map
+
+func (d *fakeSQL) Open(name string) (driver.Conn, error) {
+	db, _ := fakeSQLs.Load(name)
+	return &fakeSQLConn{db: db.(*fakeSQL)}, nil
+}
+
+type fakeSQLConn struct {
+	db *fakeSQL
+}
+
+func (c *fakeSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeSQLStmt{db: c.db, query: query}, nil
+}
+
+func (c *fakeSQLConn) Close() error {
+	return nil
+}
+
+func (c *fakeSQLConn) Begin() (driver.Tx, error) {
+	c.db.record("BEGIN", nil)
+	return c, nil
+}
+
+func (c *fakeSQLConn) Commit() error {
+	c.db.record("COMMIT", nil)
+	return nil
+}
+
+func (c *fakeSQLConn) Rollback() error {
+	c.db.record("ROLLBACK", nil)
+	return nil
+}
+
+type fakeSQLStmt struct {
+	db    *fakeSQL
+	query string
+}
+
+func (s *fakeSQLStmt) Close() error {
+	return nil
+}
+
+func (s *fakeSQLStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mu.Lock()
+	fail := s.db.fail && strings.HasPrefix(s.query, "INSERT")
+	s.db.mu.Unlock()
+	if fail {
+		return nil, errors.New("disk full")
+	}
+	s.db.record(s.query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (d *fakeSQL) record(query string, args []driver.Value) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.execs = append(d.execs, query)
+	d.args = append(d.args, args)
+}
+
+func init() {
+	sql.Register("glgfake", new(fakeSQL))
+}
+
+func openFakeSQL(t *testing.T) (*sql.DB, *fakeSQL) {
+	rec := new(fakeSQL)
+	fakeSQLs.Store(t.Name(), rec)
+	db, err := sql.Open("glgfake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, rec
+}
+
+func TestSQLWriter(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect SQLDialect
+		create  string
+		insert  string
+	}{
+		{
+			name:    "sqlite",
+			dialect: SQLite,
+			create:  `CREATE TABLE IF NOT EXISTS "logs" (id INTEGER PRIMARY KEY AUTOINCREMENT`,
+			insert:  `INSERT INTO "logs" (ts, level, message, fields) VALUES (?, ?, ?, ?)`,
+		},
+		{
+			name:    "postgres",
+			dialect: PostgreSQL,
+			create:  `CREATE TABLE IF NOT EXISTS "logs" (id BIGSERIAL PRIMARY KEY`,
+			insert:  `INSERT INTO "logs" (ts, level, message, fields) VALUES ($1, $2, $3, $4)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, rec := openFakeSQL(t)
+			defer db.Close()
+			w := NewSQLWriter(db, tt.dialect, "logs").SetFlushInterval(0)
+			g := New().SetMode(WRITER).DisableTimestamp().SetLineTraceMode(TraceLineNone).
+				SetLevelWriter(INFO, w.Level(INFO)).
+				SetLevelWriter(WARN, w)
+			g.Info("hello")
+			g.EnableJSON().Warn("slow", F("ms", 120))
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if len(rec.execs) != 5 || !strings.HasPrefix(rec.execs[0], tt.create) ||
+				rec.execs[1] != "BEGIN" || rec.execs[2] != tt.insert || rec.execs[4] != "COMMIT" {
+				t.Fatalf("SQLWriter statements = %q", rec.execs)
+			}
+			if args := rec.args[2]; args[1] != "INFO" || args[2] != "[INFO]:\thello" || args[3] != nil {
+				t.Errorf("SQLWriter text args = %v", args)
+			}
+			if args := rec.args[3]; args[1] != "WARN" || args[2] != "slow" || args[3] != `{"ms":120}` {
+				t.Errorf("SQLWriter json args = %v", args)
+			}
+			if ts, ok := rec.args[2][0].(time.Time); !ok || ts.IsZero() {
+				t.Errorf("SQLWriter ts = %v", rec.args[2][0])
+			}
+		})
+	}
+}
+
+func TestSQLWriter_Rollback(t *testing.T) {
+	db, rec := openFakeSQL(t)
+	defer db.Close()
+	rec.fail = true
+	w := NewSQLWriter(db, SQLite, "logs").DisableAutoCreate().SetFlushInterval(0).SetMaxRetries(0)
+	w.Write([]byte("lost"))
+	if err := w.Flush(); err == nil {
+		t.Error("SQLWriter.Flush() error = nil")
+	}
+	if got := strings.Join(rec.execs, ","); got != "BEGIN,ROLLBACK" {
+		t.Errorf("SQLWriter statements = %s", got)
+	}
+	w.Close()
+}
@@ -0,0 +1,130 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SinkStatus is the health snapshot of the writer or the asynchronous queue
+type SinkStatus struct {
+	LastError    string    `json:"last_error,omitempty"`
+	LastErrorAt  time.Time `json:"last_error_at,omitempty"`
+	LastSuccess  time.Time `json:"last_success,omitempty"`
+	QueueDepth   int       `json:"queue_depth"`
+	BytesWritten uint64    `json:"bytes_written"`
+	Dropped      uint64    `json:"dropped"`
+}
+
+// Healthy reports the writer has not failed since its last success
+func (s SinkStatus) Healthy() bool {
+	return s.LastError == "" || s.LastSuccess.After(s.LastErrorAt)
+}
+
+// StatusReporter is implemented by the writers reporting their health to Status,
+// the batching writers, LogFile and MmapWriter implement it
+type StatusReporter interface {
+	Status() SinkStatus
+}
+
+// sinkStats records the results of the writes
+type sinkStats struct {
+	mu      sync.Mutex
+	err     string
+	errAt   time.Time
+	success time.Time
+	bytes   uint64
+}
+
+func (s *sinkStats) record(n int, err error) {
+	now := time.Now()
+	s.mu.Lock()
+	s.bytes += uint64(n)
+	if err != nil {
+		s.err, s.errAt = err.Error(), now
+	} else {
+		s.success = now
+	}
+	s.mu.Unlock()
+}
+
+func (s *sinkStats) status() SinkStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return SinkStatus{
+		LastError:    s.err,
+		LastErrorAt:  s.errAt,
+		LastSuccess:  s.success,
+		BytesWritten: s.bytes,
+	}
+}
+
+// Status returns the health of the writers set to the instance and the asynchronous queues.
+// Writers are keyed by their Name method, e.g. the path of *os.File and LogFile, or their type,
+// the asynchronous queues are keyed by "async" and "async:" + level tag.
+// Writers which do not implement StatusReporter report empty statuses
+func (g *Glg) Status() map[string]SinkStatus {
+	g.writersMu.Lock()
+	writers := make([]io.Writer, len(g.writers))
+	copy(writers, g.writers)
+	g.writersMu.Unlock()
+
+	st := make(map[string]SinkStatus, len(writers)+1)
+	for _, w := range writers {
+		name := writerName(w)
+		for i := 2; ; i++ {
+			if _, ok := st[name]; !ok {
+				break
+			}
+			name = writerName(w) + "#" + strconv.Itoa(i)
+		}
+		var s SinkStatus
+		if r, ok := w.(StatusReporter); ok {
+			s = r.Status()
+		}
+		st[name] = s
+	}
+	if a := g.asyncer(); a != nil {
+		for name, s := range a.status(g) {
+			st[name] = s
+		}
+	}
+	return st
+}
+
+// Status returns the health of the writers of the global instance
+func Status() map[string]SinkStatus {
+	return Get().Status()
+}
+
+func writerName(w io.Writer) string {
+	if n, ok := w.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+	return fmt.Sprintf("%T", w)
+}
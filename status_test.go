@@ -0,0 +1,89 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestGlg_Status(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := NewLogFile(path, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	fail := true
+	b := newBatcher(func(context.Context, []batchEntry) error {
+		if fail {
+			return errors.New("unavailable")
+		}
+		return nil
+	}, 0, 0, 0)
+	b.interval, b.retries = 0, 0
+
+	g := New().SetMode(WRITER).DisableTimestamp().SetLineTraceMode(TraceLineNone).
+		SetWriter(f).AddWriter(new(bytes.Buffer)).AddWriter(new(bytes.Buffer)).
+		AddLevelWriter(ERR, levelWriter{b: b, level: ERR}).SetAsyncQueue(ERR, 4, 1)
+	defer g.DisableAsync()
+	g.Info("hello")
+	g.Error("failed")
+	if err = g.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err = b.Flush(); err == nil {
+		t.Fatal("batcher.Flush() error = nil")
+	}
+
+	st := g.Status()
+	for _, name := range []string{path, "*bytes.Buffer", "*bytes.Buffer#2", "glg.levelWriter", "async", "async:ERR"} {
+		if _, ok := st[name]; !ok {
+			t.Errorf("Status() = %v, %s is missing", st, name)
+		}
+	}
+	if s := st[path]; !s.Healthy() || s.BytesWritten != uint64(len("[INFO]:\thello\n[ERR]:\tfailed\n")) {
+		t.Errorf("Status()[%s] = %+v", path, s)
+	}
+	if s := st["glg.levelWriter"]; s.Healthy() || s.LastError != "unavailable" || s.QueueDepth != 0 {
+		t.Errorf("Status()[levelWriter] = %+v", s)
+	}
+	if s := st["async"]; !s.Healthy() || s.BytesWritten == 0 {
+		t.Errorf("Status()[async] = %+v", s)
+	}
+
+	fail = false
+	b.write([]byte("retry"), ERR)
+	if s := g.Status()["glg.levelWriter"]; s.QueueDepth != 1 {
+		t.Errorf("Status()[levelWriter] = %+v, want queued entry", s)
+	}
+	if err = b.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if s := g.Status()["glg.levelWriter"]; !s.Healthy() || s.BytesWritten != uint64(len("retry")) {
+		t.Errorf("Status()[levelWriter] = %+v, want healthy", s)
+	}
+}
@@ -0,0 +1,180 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultStdBufferSize is the size of the std output buffered by SetStdFlushInterval
+const DefaultStdBufferSize = 64 << 10
+
+// stdFlusher buffers the std outputs redirected to the pipes or the files and flushes them periodically
+type stdFlusher struct {
+	mu   sync.Mutex
+	bufs map[*os.File]*bufio.Writer
+	tty  map[*os.File]bool
+	// closed makes the entries still holding the replaced flusher write through
+	closed bool
+	once   sync.Once
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// bufferedStd is the std output written through the buffer of the flusher,
+// the entries ranked ERR and above flush the buffer immediately
+type bufferedStd struct {
+	f      *stdFlusher
+	w      *bufio.Writer
+	file   *os.File
+	urgent bool
+}
+
+func (bs bufferedStd) Write(p []byte) (n int, err error) {
+	bs.f.mu.Lock()
+	defer bs.f.mu.Unlock()
+	if bs.f.closed {
+		if err = bs.w.Flush(); err != nil {
+			return 0, err
+		}
+		return bs.file.Write(p)
+	}
+	if n, err = bs.w.Write(p); err == nil && bs.urgent {
+		err = bs.w.Flush()
+	}
+	return n, err
+}
+
+// SetStdFlushInterval buffers the std output redirected to the pipe or the file, e.g. the stdout of the container,
+// and flushes it every d, when the buffer is full, on the entry ranked ERR and above, by Flush and by Shutdown.
+// By default each entry is written to std by one write call, so it reaches the reader of the pipe such as kubectl logs -f
+// immediately, the buffering trades the latency of d for fewer system calls under the heavy output.
+// The terminal is always written immediately. Zero or negative d flushes the buffers and restores the default.
+// The copies made by Clone share the buffers
+func (g *Glg) SetStdFlushInterval(d time.Duration) *Glg {
+	var f *stdFlusher
+	if d > 0 {
+		f = &stdFlusher{
+			bufs: make(map[*os.File]*bufio.Writer),
+			tty:  make(map[*os.File]bool),
+			stop: make(chan struct{}),
+			done: make(chan struct{}),
+		}
+		go f.run(d)
+	}
+	var old *stdFlusher
+	g.setSwitches(func(s *switches) {
+		old, s.stdFlush = s.stdFlush, f
+	})
+	if old != nil {
+		old.close()
+	}
+	return g
+}
+
+// SetStdFlushInterval sets the flush interval of the std output of the global instance
+func SetStdFlushInterval(d time.Duration) *Glg {
+	return Get().SetStdFlushInterval(d)
+}
+
+// writer returns the buffered std output of w ranked rank, w is returned when it is not the redirected file
+func (f *stdFlusher) writer(w io.Writer, rank LEVEL) io.Writer {
+	file, ok := w.(*os.File)
+	if !ok {
+		return w
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	bw, ok := f.bufs[file]
+	if !ok {
+		tty, checked := f.tty[file]
+		if !checked {
+			info, err := file.Stat()
+			tty = err != nil || info.Mode()&os.ModeCharDevice != 0
+			f.tty[file] = tty
+		}
+		if tty {
+			return w
+		}
+		bw = bufio.NewWriterSize(file, DefaultStdBufferSize)
+		f.bufs[file] = bw
+	}
+	return bufferedStd{f: f, w: bw, file: file, urgent: rank >= ERR}
+}
+
+func (f *stdFlusher) run(d time.Duration) {
+	defer close(f.done)
+	ticker := time.NewTicker(d)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+			f.flush()
+		}
+	}
+}
+
+// flush writes the buffered std outputs
+func (f *stdFlusher) flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.flushLocked()
+}
+
+func (f *stdFlusher) flushLocked() error {
+	var errs []error
+	for _, bw := range f.bufs {
+		if err := bw.Flush(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// close stops the periodic flush and writes the buffered std outputs
+func (f *stdFlusher) close() error {
+	f.once.Do(func() {
+		close(f.stop)
+	})
+	<-f.done
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return f.flushLocked()
+}
+
+// flushStd writes the std outputs buffered by SetStdFlushInterval
+func (g *Glg) flushStd() error {
+	if f := g.opts().stdFlush; f != nil {
+		return f.flush()
+	}
+	return nil
+}
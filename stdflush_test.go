@@ -0,0 +1,80 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGlg_SetStdFlushInterval(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Skip(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	g := New().SetMode(STD).DisableTimestamp().DisableColor().SetLineTraceMode(TraceLineNone)
+	g.updateLoggers(func(_ LEVEL, l *logger) {
+		l.std = w
+	})
+	read := func(timeout time.Duration) string {
+		r.SetReadDeadline(time.Now().Add(timeout))
+		buf := make([]byte, 1024)
+		n, _ := r.Read(buf)
+		return string(buf[:n])
+	}
+
+	g.SetStdFlushInterval(time.Hour)
+	defer g.SetStdFlushInterval(0)
+	g.Info("buffered")
+	if got := read(50 * time.Millisecond); got != "" {
+		t.Errorf("buffered entry is written before the flush: %q", got)
+	}
+	g.Error("urgent")
+	if got := read(time.Second); got != "[INFO]:\tbuffered\n[ERR]:\turgent\n" {
+		t.Errorf("ERR flushed %q", got)
+	}
+	g.Info("flushed")
+	if err = g.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(time.Second); got != "[INFO]:\tflushed\n" {
+		t.Errorf("Flush() wrote %q", got)
+	}
+
+	g.SetStdFlushInterval(10 * time.Millisecond)
+	g.Info("ticked")
+	if got := read(time.Second); got != "[INFO]:\tticked\n" {
+		t.Errorf("interval flush wrote %q", got)
+	}
+
+	g.Info("pending")
+	g.SetStdFlushInterval(0)
+	g.Info("direct")
+	if got := read(time.Second); !strings.HasPrefix("[INFO]:\tpending\n[INFO]:\tdirect\n", got) || got == "" {
+		t.Errorf("restoring the default wrote %q", got)
+	}
+}
@@ -0,0 +1,106 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"io"
+	"strconv"
+)
+
+// stdPriority prefixes each line of the std output with the syslog priority of the level
+type stdPriority struct {
+	w      io.Writer
+	prefix string
+}
+
+// Write writes p by one write call, the prefix is inserted at the start of each line
+func (sp stdPriority) Write(p []byte) (int, error) {
+	b := make([]byte, 0, len(p)+len(sp.prefix)*2)
+	start := true
+	for _, c := range p {
+		if start {
+			b = append(b, sp.prefix...)
+		}
+		b = append(b, c)
+		start = c == '\n'
+	}
+	if _, err := sp.w.Write(b); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// syslogPriority maps the rank of the level to the syslog priority
+func syslogPriority(rank LEVEL) int {
+	switch {
+	case rank <= TRACE:
+		return 7 // debug
+	case rank <= INFO:
+		return 6 // info
+	case rank == OK:
+		return 5 // notice
+	case rank == WARN:
+		return 4 // warning
+	case rank == ERR:
+		return 3 // err
+	}
+	return 2 // crit
+}
+
+// EnableStdPriority prefixes each line of the std output with the syslog priority of the level in the sd-daemon framing,
+// e.g. "<4>" for WARN, so systemd and journald assign the priorities to the output of the simple service.
+// DEBG and TRACE are debug, INFO and below are info, OK is notice, WARN is warning, ERR is err and FAIL and FATAL are crit,
+// the custom levels follow their ranks. The writers set to the levels are not prefixed
+func (g *Glg) EnableStdPriority() *Glg {
+	g.setSwitches(func(s *switches) {
+		s.enablePriority = true
+	})
+	return g
+}
+
+// DisableStdPriority stops prefixing the std output with the priority
+func (g *Glg) DisableStdPriority() *Glg {
+	g.setSwitches(func(s *switches) {
+		s.enablePriority = false
+	})
+	return g
+}
+
+// EnableStdPriority prefixes each line of the std output of the global instance with the syslog priority
+func EnableStdPriority() *Glg {
+	return Get().EnableStdPriority()
+}
+
+// DisableStdPriority stops prefixing the std output of the global instance with the priority
+func DisableStdPriority() *Glg {
+	return Get().DisableStdPriority()
+}
+
+// priorityStd returns std prefixed with the priority of rank
+func priorityStd(std io.Writer, rank LEVEL) io.Writer {
+	if std == nil {
+		return nil
+	}
+	return stdPriority{w: std, prefix: "<" + strconv.Itoa(syslogPriority(rank)) + ">"}
+}
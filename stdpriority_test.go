@@ -0,0 +1,54 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGlg_EnableStdPriority(t *testing.T) {
+	std, writer := new(bytes.Buffer), new(bytes.Buffer)
+	g := New().SetMode(BOTH).SetWriter(writer).DisableColor().SetLineTraceMode(TraceLineNone).DisableTimestamp().
+		AddStdLevel("AUDIT", STD, false, LevelOptions{Rank: WARN, DisableTimestamp: true}).
+		EnableStdPriority()
+	g.updateLoggers(func(_ LEVEL, l *logger) { l.std = std })
+
+	g.Debug("debug")
+	g.Info("info")
+	g.Success("ok")
+	g.Warn("multi\nline")
+	g.Error("error")
+	g.Fail("fail")
+	g.CustomLog("AUDIT", "audit")
+	g.DisableStdPriority().Info("plain")
+
+	want := "<7>[DEBG]:\tdebug\n<6>[INFO]:\tinfo\n<5>[OK]:\tok\n<4>[WARN]:\tmulti\n<4>line\n<3>[ERR]:\terror\n<2>[FAIL]:\tfail\n" +
+		"<4>[AUDIT]:\taudit\n[INFO]:\tplain\n"
+	if std.String() != want {
+		t.Errorf("std = %q, want %q", std.String(), want)
+	}
+	if bytes.Contains(writer.Bytes(), []byte("<")) {
+		t.Errorf("writer = %q, want no priorities", writer.String())
+	}
+}
@@ -0,0 +1,76 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import "os"
+
+// StdRouting selects os.Stdout or os.Stderr as the std output of each level by its rank, see SetLevelRank
+type StdRouting struct {
+	// Stderr is the lowest rank written to stderr, the levels ranked below it are written to stdout.
+	// Zero writes all the levels to stdout
+	Stderr LEVEL
+	// Stdout lists the levels written to stdout regardless of Stderr, e.g. PRINT as the data output of the command
+	Stdout []LEVEL
+}
+
+var (
+	// DefaultStdRouting writes ERR, FAIL and FATAL to stderr and the rest to stdout, as New does
+	DefaultStdRouting = StdRouting{Stderr: ERR}
+	// CLIStdRouting writes WARN and above to stderr and the rest to stdout, as NewCLI does
+	CLIStdRouting = StdRouting{Stderr: WARN}
+	// StderrStdRouting writes everything to stderr except PRINT, so the output of the command piped to stdout is kept clean
+	StderrStdRouting = StdRouting{Stderr: DEBG, Stdout: []LEVEL{PRINT}}
+)
+
+// stderr reports the level of rank is written to stderr
+func (r StdRouting) stderr(lv, rank LEVEL) bool {
+	for _, l := range r.Stdout {
+		if l == lv {
+			return false
+		}
+	}
+	return r.Stderr != 0 && rank >= r.Stderr
+}
+
+// SetStdRouting sets the std outputs of the levels by policy, e.g.
+//
+//	g.SetStdRouting(glg.StderrStdRouting)
+//
+// The levels added later keep the output of AddStdLevel or AddErrLevel
+func (g *Glg) SetStdRouting(policy StdRouting) *Glg {
+	g.updateLoggers(func(lv LEVEL, l *logger) {
+		if policy.stderr(lv, l.rankOf(lv)) {
+			l.std = os.Stderr
+		} else {
+			l.std = os.Stdout
+		}
+		l.updateMode()
+	})
+	return g
+}
+
+// SetStdRouting sets the std outputs of the levels of the global instance by policy
+func SetStdRouting(policy StdRouting) *Glg {
+	return Get().SetStdRouting(policy)
+}
@@ -0,0 +1,72 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"io"
+	"os"
+	"testing"
+)
+
+func TestGlg_SetStdRouting(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy StdRouting
+		stderr []LEVEL
+	}{
+		{name: "default", policy: DefaultStdRouting, stderr: []LEVEL{ERR, FAIL, FATAL}},
+		{name: "CLI", policy: CLIStdRouting, stderr: []LEVEL{WARN, ERR, FAIL, FATAL}},
+		{name: "stderr", policy: StderrStdRouting, stderr: []LEVEL{DEBG, TRACE, LOG, INFO, OK, WARN, ERR, FAIL, FATAL}},
+		{name: "stdout", policy: StdRouting{}},
+		{name: "stdout exceptions", policy: StdRouting{Stderr: INFO, Stdout: []LEVEL{OK, FATAL}}, stderr: []LEVEL{INFO, WARN, ERR, FAIL}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := New().SetStdRouting(tt.policy)
+			want := make(map[LEVEL]bool)
+			for _, lv := range tt.stderr {
+				want[lv] = true
+			}
+			for _, lv := range []LEVEL{DEBG, TRACE, PRINT, LOG, INFO, OK, WARN, ERR, FAIL, FATAL} {
+				l, _ := g.logger.Load(lv)
+				var w io.Writer = os.Stdout
+				if want[lv] {
+					w = os.Stderr
+				}
+				if l.std != w {
+					t.Errorf("%v std is stderr = %v, want %v", lv, l.std == os.Stderr, want[lv])
+				}
+			}
+		})
+	}
+}
+
+func TestGlg_SetStdRouting_CustomLevel(t *testing.T) {
+	g := New().AddStdLevel("NOTICE", STD, false, LevelOptions{Rank: WARN}).AddErrLevel("AUDIT", STD, false)
+	g.SetStdRouting(CLIStdRouting)
+	notice, _ := g.logger.Load(g.TagStringToLevel("NOTICE"))
+	audit, _ := g.logger.Load(g.TagStringToLevel("AUDIT"))
+	if notice.std != os.Stderr || audit.std != os.Stderr {
+		t.Errorf("custom levels are not routed by rank, NOTICE stderr = %v, AUDIT stderr = %v", notice.std == os.Stderr, audit.std == os.Stderr)
+	}
+}
@@ -0,0 +1,116 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MalformedFormatMessage is the message of the WARN entry reporting a malformed format in the strict format mode
+const MalformedFormatMessage = "malformed log format"
+
+// EnableStrictFormat validates the formatted messages, a WARN entry with the format and the formatted message
+// follows the entries having the %!verb(MISSING), %!(EXTRA ...) or other fmt error artifacts
+func (g *Glg) EnableStrictFormat() *Glg {
+	g.setSwitches(func(s *switches) {
+		s.strictFormat = true
+	})
+	return g
+}
+
+// DisableStrictFormat stops validating the formatted messages
+func (g *Glg) DisableStrictFormat() *Glg {
+	g.setSwitches(func(s *switches) {
+		s.strictFormat = false
+	})
+	return g
+}
+
+// EnableStrictFormat validates the formatted messages of the global instance
+func EnableStrictFormat() *Glg {
+	return Get().EnableStrictFormat()
+}
+
+// DisableStrictFormat stops validating the formatted messages of the global instance
+func DisableStrictFormat() *Glg {
+	return Get().DisableStrictFormat()
+}
+
+// checkFormat formats val by format, the returned function logs a WARN entry about the level tag when the result has fmt error artifacts.
+// It is called by output with the untouched arguments, so go vet checks the format strings of the formatting methods
+func (g *Glg) checkFormat(tag string, format string, val ...interface{}) func() {
+	if format == "" || format == g.blankFormat(len(val)) {
+		return nil
+	}
+	var msg string
+	if vals := withoutFields(val); len(vals) != len(val) {
+		msg = fmt.Sprintf(format, vals...)
+	} else {
+		msg = fmt.Sprintf(format, val...)
+	}
+	if strings.Count(msg, "%!") <= argArtifacts(val) {
+		return nil
+	}
+	re := &replayEntry{caller: shortCaller(g.opts().callerDepth + 2)}
+	return func() {
+		g.output(WARN, re, g.blankFormat(4), MalformedFormatMessage,
+			String("level", tag),
+			String("format", format),
+			String("message", msg))
+	}
+}
+
+// withoutFields returns val without the Field values
+func withoutFields(val []interface{}) []interface{} {
+	for i, v := range val {
+		if _, ok := v.(Field); ok {
+			vals := append(make([]interface{}, 0, len(val)-1), val[:i]...)
+			for _, v := range val[i+1:] {
+				if _, ok := v.(Field); !ok {
+					vals = append(vals, v)
+				}
+			}
+			return vals
+		}
+	}
+	return val
+}
+
+// argArtifacts counts the %! sequences in the string arguments, they are not the format errors
+func argArtifacts(val []interface{}) (n int) {
+	for _, v := range val {
+		switch s := v.(type) {
+		case string:
+			n += strings.Count(s, "%!")
+		case []byte:
+			n += strings.Count(string(s), "%!")
+		case error:
+			n += strings.Count(s.Error(), "%!")
+		case fmt.Stringer:
+			n += strings.Count(s.String(), "%!")
+		}
+	}
+	return n
+}
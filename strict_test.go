@@ -0,0 +1,113 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGlg_EnableStrictFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		args   []interface{}
+		warned bool
+	}{
+		{
+			name:   "missing argument",
+			format: "user %s id %d",
+			args:   []interface{}{"gopher"},
+			warned: true,
+		},
+		{
+			name:   "extra argument",
+			format: "user %s",
+			args:   []interface{}{"gopher", 1},
+			warned: true,
+		},
+		{
+			name:   "wrong verb",
+			format: "id %d",
+			args:   []interface{}{"x"},
+			warned: true,
+		},
+		{
+			name:   "valid format with fields",
+			format: "user %s",
+			args:   []interface{}{"gopher", Int("id", 1)},
+		},
+		{
+			name:   "artifact in the argument",
+			format: "value %s",
+			args:   []interface{}{"%!d(MISSING)"},
+		},
+		{
+			name:   "artifact in the error argument",
+			format: "failed: %v",
+			args:   []interface{}{errors.New("%!(EXTRA int=1)")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone).EnableStrictFormat()
+			if err := g.Infof(tt.format, tt.args...); err != nil {
+				t.Fatalf("Infof() error = %v", err)
+			}
+			lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+			if !tt.warned {
+				if len(lines) != 1 {
+					t.Errorf("output = %q, want no warning", buf.String())
+				}
+				return
+			}
+			if len(lines) != 2 {
+				t.Fatalf("output = %q, want the entry and the warning", buf.String())
+			}
+			if !strings.Contains(lines[1], "[WARN]") || !strings.Contains(lines[1], MalformedFormatMessage) ||
+				!strings.Contains(lines[1], "format=") || !strings.Contains(lines[1], "strict_test.go:") {
+				t.Errorf("warning = %q", lines[1])
+			}
+		})
+	}
+
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).EnableStrictFormat()
+	g.Info("100%!", 1)
+	if strings.Contains(buf.String(), MalformedFormatMessage) {
+		t.Errorf("Info() output = %q, want no warning", buf.String())
+	}
+}
+
+func TestGlg_DisableStrictFormat(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).EnableStrictFormat().DisableStrictFormat()
+	format := "id %d"
+	g.Infof(format, "x")
+	if strings.Contains(buf.String(), MalformedFormatMessage) {
+		t.Errorf("output = %q, want no warning", buf.String())
+	}
+}
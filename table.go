@@ -0,0 +1,129 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	json "github.com/goccy/go-json"
+)
+
+// tableRow is the row of Table encoded as JSON object keeping the column order
+type tableRow struct {
+	headers []string
+	cells   []string
+}
+
+func (r tableRow) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, h := range r.headers {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(h)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		var cell string
+		if i < len(r.cells) {
+			cell = r.cells[i]
+		}
+		v, err := json.Marshal(cell)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+func (g *Glg) table(headers []string, rows [][]string) (string, interface{}) {
+	if g.opts().enableJSON {
+		objs := make([]tableRow, 0, len(rows))
+		for _, row := range rows {
+			objs = append(objs, tableRow{headers: headers, cells: row})
+		}
+		return "", objs
+	}
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = utf8.RuneCountInString(h)
+	}
+	for _, row := range rows {
+		for i := 0; i < len(row) && i < len(widths); i++ {
+			if n := utf8.RuneCountInString(row[i]); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	var sb strings.Builder
+	line := func(cells []string) {
+		var l strings.Builder
+		for i, w := range widths {
+			var cell string
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			l.WriteString(cell)
+			l.WriteString(strings.Repeat(spw, w-utf8.RuneCountInString(cell)+2))
+		}
+		sb.WriteString(rc)
+		sb.WriteString(strings.TrimRight(l.String(), spw))
+	}
+	line(headers)
+	seps := make([]string, len(widths))
+	for i, w := range widths {
+		seps[i] = strings.Repeat("-", w)
+	}
+	line(seps)
+	for _, row := range rows {
+		line(row)
+	}
+	return "%s", sb.String()
+}
+
+// Table outputs Info level table of the rows aligned in columns, JSON mode outputs the array of the objects keyed by the headers.
+// The cells beyond the headers are ignored
+func (g *Glg) Table(headers []string, rows [][]string) error {
+	if g.isModeEnable(INFO) {
+		format, detail := g.table(headers, rows)
+		return g.out(INFO, format, detail)
+	}
+	return nil
+}
+
+// Table outputs Info level table of the rows aligned in columns, JSON mode outputs the array of the objects keyed by the headers
+func Table(headers []string, rows [][]string) error {
+	if isModeEnable(INFO) {
+		format, detail := Get().table(headers, rows)
+		return Get().out(INFO, format, detail)
+	}
+	return nil
+}
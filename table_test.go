@@ -0,0 +1,75 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGlg_Table(t *testing.T) {
+	headers := []string{"NAME", "STATUS", "AGE"}
+	rows := [][]string{
+		{"api", "running", "3d"},
+		{"wörker", "crash"},
+		{"db", "running", "12d", "ignored"},
+	}
+	tests := []struct {
+		name string
+		json bool
+		want string
+	}{
+		{
+			name: "text",
+			want: "\n" +
+				"NAME    STATUS   AGE\n" +
+				"------  -------  ---\n" +
+				"api     running  3d\n" +
+				"wörker  crash\n" +
+				"db      running  12d\n",
+		},
+		{
+			name: "json",
+			json: true,
+			want: `"detail":[{"NAME":"api","STATUS":"running","AGE":"3d"},` +
+				`{"NAME":"wörker","STATUS":"crash","AGE":""},` +
+				`{"NAME":"db","STATUS":"running","AGE":"12d"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone).DisableTimestamp()
+			if tt.json {
+				g.EnableJSON()
+			}
+			if err := g.Table(headers, rows); err != nil {
+				t.Fatalf("Table() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("Table() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
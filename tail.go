@@ -0,0 +1,213 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTailBuffer is the number of the entries queued for each tail client, the entries are dropped for the slow client beyond it
+	DefaultTailBuffer = 256
+	// DefaultTailHeartbeat is the interval of the comments keeping the idle tail connections open through the proxies
+	DefaultTailHeartbeat = 15 * time.Second
+)
+
+// tailHub fans out the entries to the tail clients without blocking the logging goroutines
+type tailHub struct {
+	mu      sync.Mutex
+	clients map[*tailClient]struct{}
+	closed  bool
+}
+
+// tailClient is the queue of the tail client, dropped counts the entries discarded since the last notice
+type tailClient struct {
+	ch      chan []byte
+	mu      sync.Mutex
+	dropped uint64
+}
+
+// Write sends the copy of the entry to every client, the entry is dropped for the clients whose queue is full
+func (h *tailHub) Write(p []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.clients) == 0 {
+		return len(p), nil
+	}
+	e := make([]byte, len(p))
+	copy(e, p)
+	for c := range h.clients {
+		select {
+		case c.ch <- e:
+		default:
+			c.mu.Lock()
+			c.dropped++
+			c.mu.Unlock()
+		}
+	}
+	return len(p), nil
+}
+
+// Close disconnects the clients, it is called by Shutdown
+func (h *tailHub) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients {
+		close(c.ch)
+		delete(h.clients, c)
+	}
+	h.closed = true
+	return nil
+}
+
+// Name returns the name of the hub in Status
+func (h *tailHub) Name() string {
+	return "tail"
+}
+
+func (h *tailHub) subscribe() *tailClient {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	c := &tailClient{ch: make(chan []byte, DefaultTailBuffer)}
+	if h.closed {
+		close(c.ch)
+		return c
+	}
+	if h.clients == nil {
+		h.clients = make(map[*tailClient]struct{})
+	}
+	h.clients[c] = struct{}{}
+	return c
+}
+
+func (h *tailHub) unsubscribe(c *tailClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[c]; ok {
+		delete(h.clients, c)
+		close(c.ch)
+	}
+}
+
+// takeDropped returns and resets the number of the entries dropped for the client
+func (c *tailClient) takeDropped() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := c.dropped
+	c.dropped = 0
+	return n
+}
+
+// TailHandler streams the new entries of every enabled level in JSON to the clients as Server-Sent Events, like tail -f.
+// Each entry is the "data" of the message, the entries dropped for the slow client are notified by the "dropped" event
+// with their number, so the logging goroutines are never blocked by the clients. The entries are filtered per client by:
+//
+//	level  the lowest level, e.g. WARN streams WARN and the higher ranked levels
+//	q      the case-insensitive substring of the entry
+//
+// The entries are routed to the handlers of the instance since the first call, ResetRoutes stops them.
+// The clients are disconnected by Shutdown. WebSocket is not supported, EventSource of the browsers and curl -N read the stream
+func (g *Glg) TailHandler() http.Handler {
+	g.configMu.Lock()
+	h, created := g.tail, g.tail == nil
+	if created {
+		h = new(tailHub)
+		g.tail = h
+	}
+	g.configMu.Unlock()
+	if created {
+		g.Route(Route{Writer: h, JSON: LevelJSONOn})
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g.serveTail(h, w, r)
+	})
+}
+
+// TailHandler streams the new entries of the global instance
+func TailHandler() http.Handler {
+	return Get().TailHandler()
+}
+
+func (g *Glg) serveTail(h *tailHub, w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	min := LEVEL(0)
+	if s := q.Get("level"); s != "" {
+		lv := g.Atol(s)
+		if lv == UNKNOWN {
+			http.Error(w, "unknown log level "+strconv.Quote(s), http.StatusBadRequest)
+			return
+		}
+		min = g.rank(lv)
+	}
+	sub := []byte(strings.ToLower(q.Get("q")))
+	ctl := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := ctl.Flush(); err != nil {
+		return
+	}
+
+	c := h.subscribe()
+	defer h.unsubscribe(c)
+	heartbeat := time.NewTicker(DefaultTailHeartbeat)
+	defer heartbeat.Stop()
+	b := new(bytes.Buffer)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			b.WriteString(":\n\n")
+		case e, ok := <-c.ch:
+			if !ok {
+				return
+			}
+			if n := c.takeDropped(); n != 0 {
+				b.WriteString("event: dropped\ndata: ")
+				b.WriteString(strconv.FormatUint(n, 10))
+				b.WriteString("\n\n")
+			}
+			if !g.entryMatch(e, min, sub) {
+				continue
+			}
+			b.WriteString("data: ")
+			b.Write(bytes.TrimRight(e, "\n"))
+			b.WriteString("\n\n")
+		}
+		if _, err := w.Write(b.Bytes()); err != nil {
+			return
+		}
+		b.Reset()
+		if err := ctl.Flush(); err != nil {
+			return
+		}
+	}
+}
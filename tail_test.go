@@ -0,0 +1,115 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGlg_TailHandler(t *testing.T) {
+	g := New().SetMode(WRITER).SetWriter(io.Discard)
+	srv := httptest.NewServer(g.TailHandler())
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL + "?level=warn&q=disk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %s", ct)
+	}
+	waitClients(t, g.tail, 1)
+
+	g.Warn("disk slow")
+	g.Info("disk ok")
+	g.Warn("cpu hot")
+	g.Error("disk failed")
+
+	sc := bufio.NewScanner(res.Body)
+	var got []string
+	for len(got) < 2 && sc.Scan() {
+		if line := sc.Text(); strings.HasPrefix(line, "data: ") {
+			got = append(got, line)
+		}
+	}
+	if len(got) != 2 || !strings.Contains(got[0], `"detail":"disk slow"`) || !strings.Contains(got[1], `"detail":"disk failed"`) {
+		t.Errorf("events = %q", got)
+	}
+
+	g.Shutdown(context.Background())
+	for sc.Scan() {
+	}
+	waitClients(t, g.tail, 0)
+}
+
+func TestGlg_TailHandler_BadLevel(t *testing.T) {
+	rr := httptest.NewRecorder()
+	New().TailHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/tail?level=loud", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", rr.Code)
+	}
+}
+
+func TestTailHub_Dropped(t *testing.T) {
+	h := new(tailHub)
+	c := h.subscribe()
+	for i := 0; i < DefaultTailBuffer+3; i++ {
+		h.Write([]byte("x"))
+	}
+	if len(c.ch) != DefaultTailBuffer {
+		t.Errorf("queued = %d, want %d", len(c.ch), DefaultTailBuffer)
+	}
+	if n := c.takeDropped(); n != 3 {
+		t.Errorf("dropped = %d, want 3", n)
+	}
+	if n := c.takeDropped(); n != 0 {
+		t.Errorf("dropped after take = %d, want 0", n)
+	}
+	h.unsubscribe(c)
+	h.unsubscribe(c)
+	if _, ok := <-c.ch; !ok {
+		t.Error("queued entries are lost by unsubscribe")
+	}
+}
+
+// waitClients waits until the hub has n clients
+func waitClients(t *testing.T, h *tailHub, n int) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		h.mu.Lock()
+		cnt := len(h.clients)
+		h.mu.Unlock()
+		if cnt == n {
+			return
+		}
+	}
+	t.Fatalf("tail clients did not reach %d", n)
+}
@@ -0,0 +1,155 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultSuspendLimit is the maximum number of the entries held by Suspend, the newer entries are dropped
+const DefaultSuspendLimit = 10000
+
+// terminal coordinates the std output with the interactive output of the terminal
+type terminal struct {
+	active    int32 // 1 while suspended or locked, checked without mu on every entry
+	mu        sync.Mutex
+	lock      sync.Locker
+	suspended bool
+	held      []heldLine
+}
+
+// heldLine is the std output held by Suspend
+type heldLine struct {
+	w    io.Writer
+	data []byte
+}
+
+// termWriter is the std writer coordinated with the terminal
+type termWriter struct {
+	g     *Glg
+	w     io.Writer
+	level LEVEL
+}
+
+// terminal returns std coordinated with Suspend and SetOutputLock, std itself when they are not used
+func (g *Glg) terminal(level LEVEL, std io.Writer) io.Writer {
+	if std == nil || atomic.LoadInt32(&g.term.active) == 0 {
+		return std
+	}
+	return termWriter{g: g, w: std, level: level}
+}
+
+// Write implements io.Writer, p is held while suspended and written holding the output lock otherwise
+func (tw termWriter) Write(p []byte) (int, error) {
+	t := &tw.g.term
+	t.mu.Lock()
+	if t.suspended {
+		if len(t.held) >= DefaultSuspendLimit {
+			t.mu.Unlock()
+			tw.g.drop(tw.level)
+			return len(p), nil
+		}
+		data := make([]byte, len(p))
+		copy(data, p)
+		t.held = append(t.held, heldLine{w: tw.w, data: data})
+		t.mu.Unlock()
+		return len(p), nil
+	}
+	lock := t.lock
+	t.mu.Unlock()
+	if lock != nil {
+		lock.Lock()
+		defer lock.Unlock()
+	}
+	return tw.w.Write(p)
+}
+
+// SetOutputLock makes the std output hold l, e.g. the mutex of the progress bar redrawing the terminal,
+// so that the log lines are not interleaved with it. The holder of l must not log, nil removes the lock
+func (g *Glg) SetOutputLock(l sync.Locker) *Glg {
+	g.term.mu.Lock()
+	defer g.term.mu.Unlock()
+	g.term.lock = l
+	g.term.updateActive()
+	return g
+}
+
+// Suspend holds the std output of the entries until Resume, e.g. while the prompt reads the input.
+// The writers other than std are not suspended, up to DefaultSuspendLimit entries are held and the rest is counted by Dropped
+func (g *Glg) Suspend() *Glg {
+	g.term.mu.Lock()
+	defer g.term.mu.Unlock()
+	g.term.suspended = true
+	g.term.updateActive()
+	return g
+}
+
+// Resume writes the entries held by Suspend in the logged order and resumes the std output,
+// it returns the first write error
+func (g *Glg) Resume() (err error) {
+	t := &g.term
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.suspended {
+		return nil
+	}
+	t.suspended = false
+	t.updateActive()
+	held := t.held
+	t.held = nil
+	if len(held) == 0 {
+		return nil
+	}
+	if t.lock != nil {
+		t.lock.Lock()
+		defer t.lock.Unlock()
+	}
+	for _, h := range held {
+		if _, werr := h.w.Write(h.data); err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+// Suspend holds the std output of the global instance until Resume
+func Suspend() *Glg {
+	return Get().Suspend()
+}
+
+// Resume writes the entries held by Suspend and resumes the std output of the global instance
+func Resume() error {
+	return Get().Resume()
+}
+
+// updateActive updates the flag of the terminal coordination, mu must be held
+func (t *terminal) updateActive() {
+	var active int32
+	if t.suspended || t.lock != nil {
+		active = 1
+	}
+	atomic.StoreInt32(&t.active, active)
+}
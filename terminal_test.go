@@ -0,0 +1,125 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newStdBuffer(g *Glg) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	g.logger.Range(func(lev LEVEL, l *logger) bool {
+		l.std = buf
+		g.logger.Store(lev, l)
+		return true
+	})
+	return buf
+}
+
+func TestGlg_Suspend(t *testing.T) {
+	g := New().SetMode(BOTH).DisableColor().SetLineTraceMode(TraceLineNone)
+	std := newStdBuffer(g)
+	w := new(bytes.Buffer)
+	g.SetWriter(w)
+
+	g.Info("before")
+	g.Suspend()
+	g.Info("held 1")
+	g.Warn("held 2")
+	if strings.Contains(std.String(), "held") {
+		t.Errorf("std output while suspended = %q", std.String())
+	}
+	if strings.Count(w.String(), "held") != 2 {
+		t.Errorf("writer output while suspended = %q, want not suspended", w.String())
+	}
+	if err := g.Resume(); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	g.Info("after")
+	lines := strings.Split(strings.TrimSpace(std.String()), "\n")
+	want := []string{"before", "held 1", "held 2", "after"}
+	if len(lines) != len(want) {
+		t.Fatalf("std output = %q, want %d lines", std.String(), len(want))
+	}
+	for i, s := range want {
+		if !strings.HasSuffix(lines[i], s) {
+			t.Errorf("line %d = %q, want %q", i, lines[i], s)
+		}
+	}
+	if err := g.Resume(); err != nil {
+		t.Errorf("Resume() of not suspended error = %v", err)
+	}
+}
+
+func TestGlg_Suspend_Limit(t *testing.T) {
+	g := New().SetMode(STD).SetLineTraceMode(TraceLineNone)
+	std := newStdBuffer(g)
+	g.Suspend()
+	for i := 0; i < DefaultSuspendLimit+3; i++ {
+		g.Info("x")
+	}
+	if got := g.Dropped(); got != 3 {
+		t.Errorf("Dropped() = %d, want 3", got)
+	}
+	g.Resume()
+	if got := strings.Count(std.String(), "\n"); got != DefaultSuspendLimit {
+		t.Errorf("resumed lines = %d, want %d", got, DefaultSuspendLimit)
+	}
+}
+
+type countLocker struct {
+	sync.Mutex
+	locks int
+}
+
+func (c *countLocker) Lock() {
+	c.Mutex.Lock()
+	c.locks++
+}
+
+func TestGlg_SetOutputLock(t *testing.T) {
+	g := New().SetMode(STD).SetLineTraceMode(TraceLineNone).EnableAsync(16)
+	std := newStdBuffer(g)
+	l := new(countLocker)
+	g.SetOutputLock(l)
+	g.Info("locked")
+	if err := g.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	l.Lock()
+	locks := l.locks
+	l.Unlock()
+	// the last lock is taken by the test itself
+	if locks != 2 || !strings.Contains(std.String(), "locked") {
+		t.Errorf("output lock is taken %d times, output = %q", locks-1, std.String())
+	}
+
+	g.SetOutputLock(nil)
+	if g.term.active != 0 {
+		t.Error("SetOutputLock(nil) kept the coordination active")
+	}
+	g.DisableAsync()
+}
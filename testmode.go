@@ -0,0 +1,115 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// TestingT is the part of testing.TB used by TestMode, *testing.T and *testing.B satisfy it.
+// The package does not import testing so the binaries using glg do not link it and its flags
+type TestingT interface {
+	Helper()
+	Log(args ...interface{})
+	Cleanup(func())
+}
+
+var (
+	// testInstances maps the goroutines of the tests calling TestMode to their instances
+	testInstances sync.Map // map[uint64]*Glg
+	// testModes is the number of the running tests in TestMode, Get looks up testInstances only while it is not zero
+	testModes int32
+)
+
+// TestMode makes Get and the package-level functions called on the goroutine of the test use a new instance
+// writing to t.Log for the duration of the test, and returns the instance.
+// The tests calling TestMode, including the parallel ones, do not share the configuration with each other
+// nor change the global instance. The goroutines started by the test or the code under test log to the global instance
+// unless they are given the returned instance, e.g. by NewContext, and the subtests call TestMode by themselves.
+// The instance is shut down by t.Cleanup, the entries written later are discarded
+func TestMode(t TestingT) *Glg {
+	t.Helper()
+	Get()
+	tw := &testWriter{t: t}
+	g := New().SetMode(WRITER).SetWriter(tw).DisableColor()
+	id := goid()
+	testInstances.Store(id, g)
+	atomic.AddInt32(&testModes, 1)
+	t.Cleanup(func() {
+		testInstances.Delete(id)
+		atomic.AddInt32(&testModes, -1)
+		g.Shutdown(context.Background())
+		tw.mu.Lock()
+		tw.done = true
+		tw.mu.Unlock()
+	})
+	return g
+}
+
+// testInstance returns the instance of the test running on the current goroutine
+func testInstance() (*Glg, bool) {
+	if atomic.LoadInt32(&testModes) == 0 {
+		return nil, false
+	}
+	g, ok := testInstances.Load(goid())
+	if !ok {
+		return nil, false
+	}
+	return g.(*Glg), true
+}
+
+// goid returns the ID of the current goroutine read from the header of its stack, e.g. "goroutine 18 [running]:"
+func goid() uint64 {
+	var buf [64]byte
+	b := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], []byte("goroutine "))
+	var id uint64
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			break
+		}
+		id = id*10 + uint64(c-'0')
+	}
+	return id
+}
+
+// testWriter writes the entries to the log of the test until the test finishes,
+// mu makes the cleanup wait for the write logging to the test
+type testWriter struct {
+	mu   sync.Mutex
+	t    TestingT
+	done bool
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.done {
+		w.t.Log(string(bytes.TrimSuffix(p, []byte(rc))))
+	}
+	return len(p), nil
+}
@@ -0,0 +1,131 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+var (
+	_ TestingT = (*testing.T)(nil)
+	_ TestingT = (*testing.B)(nil)
+)
+
+// logTB records the logs of the test
+type logTB struct {
+	testing.TB
+	logs []string
+}
+
+func (t *logTB) Log(args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprint(args...))
+}
+
+func TestTestMode(t *testing.T) {
+	orig := Get()
+	var g *Glg
+	t.Run("isolated", func(t *testing.T) {
+		tb := &logTB{TB: t}
+		g = TestMode(tb)
+		if Get() != g {
+			t.Fatal("Get() is not the test instance")
+		}
+		Get().DisableTimestamp()
+		Info("hello")
+		Warnf("%d", 1)
+		want := []string{"[INFO]:\thello", "[WARN]:\t1"}
+		if fmt.Sprint(tb.logs) != fmt.Sprint(want) {
+			t.Errorf("TestMode() logs = %q, want %q", tb.logs, want)
+		}
+	})
+	if Get() != orig {
+		t.Error("TestMode() did not restore the global instance")
+	}
+	if err := g.Info("after"); err != nil {
+		t.Errorf("Info() after the test error = %v", err)
+	}
+}
+
+func TestTestMode_Parallel(t *testing.T) {
+	orig := Get()
+	t.Run("group", func(t *testing.T) {
+		for i := 0; i < 4; i++ {
+			i := i
+			t.Run(strconv.Itoa(i), func(t *testing.T) {
+				t.Parallel()
+				tb := &logTB{TB: t}
+				g := TestMode(tb).DisableTimestamp()
+				for j := 0; j < 50; j++ {
+					if Get() != g {
+						t.Fatal("Get() is not the instance of the test")
+					}
+					Infof("%d", i)
+				}
+				want := "[INFO]:\t" + strconv.Itoa(i)
+				for _, l := range tb.logs {
+					if l != want {
+						t.Fatalf("TestMode() logged %q of the other test, want %q", l, want)
+					}
+				}
+				if len(tb.logs) != 50 {
+					t.Errorf("TestMode() logged %d entries, want 50", len(tb.logs))
+				}
+			})
+		}
+	})
+	if Get() != orig {
+		t.Error("TestMode() changed the global instance")
+	}
+}
+
+func TestTestMode_WriteAfterCleanup(t *testing.T) {
+	var (
+		g    *Glg
+		wg   sync.WaitGroup
+		stop = make(chan struct{})
+	)
+	t.Run("finished", func(t *testing.T) {
+		g = TestMode(t)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					g.Info("racing the cleanup")
+				}
+			}
+		}()
+	})
+	// the writes after the test finished are discarded instead of calling t.Log
+	for i := 0; i < 100; i++ {
+		g.Info("after")
+	}
+	close(stop)
+	wg.Wait()
+}
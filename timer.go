@@ -0,0 +1,98 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import "time"
+
+// TimerElapsedKey is the field key of the elapsed duration logged by Timer and TimeTrack
+const TimerElapsedKey = "elapsed"
+
+// SetTimerThreshold logs the finish of Timer and TimeTrack at lv when the elapsed duration exceeds threshold,
+// e.g. SetTimerThreshold(500*time.Millisecond, WARN). The finish is logged at INFO otherwise, threshold <= 0 disables the escalation
+func (g *Glg) SetTimerThreshold(threshold time.Duration, lv LEVEL) *Glg {
+	g.setSwitches(func(s *switches) {
+		s.timerThreshold = threshold
+		s.timerLevel = lv
+	})
+	return g
+}
+
+// Timer logs the start of name at DEBG and returns the function logging the finish with the elapsed duration,
+// e.g. defer g.Timer("load users")()
+func (g *Glg) Timer(name string, fields ...Field) func() error {
+	start := time.Now()
+	vals := timerVals(name+" started", fields)
+	g.out(DEBG, g.blankFormat(len(vals)), vals...)
+	return func() error {
+		lv, vals := g.timerFinish(start, name, fields)
+		return g.out(lv, g.blankFormat(len(vals)), vals...)
+	}
+}
+
+// TimeTrack logs the finish of name with the duration elapsed since start, e.g. defer g.TimeTrack(time.Now(), "load users")
+func (g *Glg) TimeTrack(start time.Time, name string, fields ...Field) error {
+	lv, vals := g.timerFinish(start, name, fields)
+	return g.out(lv, g.blankFormat(len(vals)), vals...)
+}
+
+// Timer logs the start of name at DEBG and returns the function logging the finish with the elapsed duration
+func Timer(name string, fields ...Field) func() error {
+	start := time.Now()
+	vals := timerVals(name+" started", fields)
+	g := Get()
+	g.out(DEBG, g.blankFormat(len(vals)), vals...)
+	return func() error {
+		lv, vals := g.timerFinish(start, name, fields)
+		return g.out(lv, g.blankFormat(len(vals)), vals...)
+	}
+}
+
+// TimeTrack logs the finish of name with the duration elapsed since start
+func TimeTrack(start time.Time, name string, fields ...Field) error {
+	g := Get()
+	lv, vals := g.timerFinish(start, name, fields)
+	return g.out(lv, g.blankFormat(len(vals)), vals...)
+}
+
+// timerFinish returns the level and the values of the finish entry
+func (g *Glg) timerFinish(start time.Time, name string, fields []Field) (LEVEL, []interface{}) {
+	elapsed := time.Since(start)
+	lv := INFO
+	if o := g.opts(); o.timerThreshold > 0 && elapsed > o.timerThreshold {
+		lv = o.timerLevel
+	}
+	return lv, timerVals(name+" finished", fields, Dur(TimerElapsedKey, elapsed))
+}
+
+func timerVals(msg string, fields []Field, extra ...Field) []interface{} {
+	vals := make([]interface{}, 0, 1+len(fields)+len(extra))
+	vals = append(vals, msg)
+	for _, f := range fields {
+		vals = append(vals, f)
+	}
+	for _, f := range extra {
+		vals = append(vals, f)
+	}
+	return vals
+}
@@ -0,0 +1,97 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGlg_Timer(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		sleep     time.Duration
+		wantTag   string
+	}{
+		{
+			name:    "no threshold",
+			wantTag: "[INFO]",
+		},
+		{
+			name:      "under threshold",
+			threshold: time.Hour,
+			wantTag:   "[INFO]",
+		},
+		{
+			name:      "over threshold",
+			threshold: time.Millisecond,
+			sleep:     5 * time.Millisecond,
+			wantTag:   "[WARN]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineShort).
+				SetTimerThreshold(tt.threshold, WARN)
+			done := g.Timer("load users", String("db", "main"))
+			time.Sleep(tt.sleep)
+			if err := done(); err != nil {
+				t.Fatalf("Timer() error = %v", err)
+			}
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("Timer() output = %q, want 2 lines", buf.String())
+			}
+			if !strings.Contains(lines[0], "[DEBG]") || !strings.Contains(lines[0], "load users started") ||
+				!strings.Contains(lines[0], "db=main") {
+				t.Errorf("Timer() start = %q", lines[0])
+			}
+			if !strings.Contains(lines[1], tt.wantTag) || !strings.Contains(lines[1], "load users finished") ||
+				!strings.Contains(lines[1], "db=main") || !strings.Contains(lines[1], TimerElapsedKey+"=") {
+				t.Errorf("Timer() finish = %q, want tag %s", lines[1], tt.wantTag)
+			}
+			for _, line := range lines {
+				if !strings.Contains(line, "timer_test.go") {
+					t.Errorf("Timer() caller of %q is not the test", line)
+				}
+			}
+		})
+	}
+}
+
+func TestGlg_TimeTrack(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone).
+		SetTimerThreshold(time.Second, ERR)
+	if err := g.TimeTrack(time.Now().Add(-2*time.Second), "sync"); err != nil {
+		t.Fatalf("TimeTrack() error = %v", err)
+	}
+	if got := buf.String(); !strings.Contains(got, "[ERR]") || !strings.Contains(got, "sync finished") ||
+		strings.Contains(got, "started") {
+		t.Errorf("TimeTrack() output = %q", got)
+	}
+}
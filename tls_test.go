@@ -0,0 +1,212 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testPKI is the CA, the server certificate of 127.0.0.1 and the client certificate written in PEM files
+type testPKI struct {
+	dir    string
+	pool   *x509.CertPool
+	server tls.Certificate
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	p := &testPKI{dir: t.TempDir(), pool: x509.NewCertPool()}
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "glg test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, ca, ca, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ca, err = x509.ParseCertificate(der); err != nil {
+		t.Fatal(err)
+	}
+	p.pool.AddCert(ca)
+	p.write(t, "ca.pem", "CERTIFICATE", der)
+
+	issue := func(serial int64, name string, usage x509.ExtKeyUsage) tls.Certificate {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		kder, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.write(t, name+".pem", "CERTIFICATE", der)
+		p.write(t, name+"-key.pem", "EC PRIVATE KEY", kder)
+		return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	}
+	p.server = issue(2, "server", x509.ExtKeyUsageServerAuth)
+	issue(3, "client", x509.ExtKeyUsageClientAuth)
+	return p
+}
+
+func (p *testPKI) write(t *testing.T, name, typ string, der []byte) {
+	if err := os.WriteFile(p.path(name), pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (p *testPKI) path(name string) string {
+	return filepath.Join(p.dir, name)
+}
+
+// serverConfig requires the client certificate issued by the CA
+func (p *testPKI) serverConfig() *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{p.server},
+		ClientCAs:    p.pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	p := newTestPKI(t)
+	tests := []struct {
+		name    string
+		ca      string
+		cert    string
+		key     string
+		wantErr bool
+	}{
+		{name: "system roots"},
+		{name: "CA", ca: p.path("ca.pem")},
+		{name: "mTLS", ca: p.path("ca.pem"), cert: p.path("client.pem"), key: p.path("client-key.pem")},
+		{name: "missing CA", ca: p.path("none.pem"), wantErr: true},
+		{name: "no CA certificate", ca: p.path("client-key.pem"), wantErr: true},
+		{name: "missing key", cert: p.path("client.pem"), key: p.path("none.pem"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := NewTLSConfig(tt.ca, tt.cert, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewTLSConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if cfg.MinVersion != tls.VersionTLS12 || (cfg.RootCAs != nil) != (tt.ca != "") || (len(cfg.Certificates) != 0) != (tt.cert != "") {
+				t.Errorf("NewTLSConfig() = %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestRedisWriter_TLS(t *testing.T) {
+	p := newTestPKI(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	r := serveFakeRedis(tls.NewListener(ln, p.serverConfig()))
+	defer r.ln.Close()
+
+	cfg, err := NewTLSConfig(p.path("ca.pem"), p.path("client.pem"), p.path("client-key.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewRedisListWriter(ln.Addr().String(), "logs").SetFlushInterval(0).SetTLSConfig(cfg)
+	w.Write([]byte("secure"))
+	if err = w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	r.mu.Lock()
+	if len(r.cmds) != 1 || r.cmds[0][2] != "secure" {
+		t.Errorf("RedisWriter commands = %q", r.cmds)
+	}
+	r.mu.Unlock()
+
+	cfg, _ = NewTLSConfig(p.path("ca.pem"), "", "")
+	w = NewRedisListWriter(ln.Addr().String(), "logs").SetFlushInterval(0).SetMaxRetries(0).SetTLSConfig(cfg)
+	w.Write([]byte("rejected"))
+	if err = w.Close(); err == nil {
+		t.Error("Close() without the client certificate error = nil")
+	}
+}
+
+func TestAppInsightsWriter_TLS(t *testing.T) {
+	p := newTestPKI(t)
+	posted := make(chan string, 1)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posted <- r.TLS.PeerCertificates[0].Subject.CommonName
+	}))
+	srv.TLS = p.serverConfig()
+	srv.StartTLS()
+	defer srv.Close()
+
+	cfg, err := NewTLSConfig(p.path("ca.pem"), p.path("client.pem"), p.path("client-key.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ai := NewAppInsightsWriter("InstrumentationKey=k;IngestionEndpoint=" + srv.URL).SetFlushInterval(0).SetTLSConfig(cfg)
+	ai.Write([]byte("secure"))
+	if err = ai.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if cn := <-posted; cn != "client" {
+		t.Errorf("client certificate = %s, want client", cn)
+	}
+}
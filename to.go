@@ -0,0 +1,52 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import "io"
+
+// To returns derived logger which writes its entries to w as well as to the destinations of the levels, e.g.
+//
+//	g.To(conn).Warn("the session expires in 5 minutes")
+//
+// echoes the entry to the client connection without reconfiguring the level writers.
+// w gets the entries of the enabled levels in the format of the level without colors, like the route writers,
+// and it is neither tracked nor closed by Shutdown. The loggers derived from it by With write to w as well
+func (g *Glg) To(w io.Writer) *Glg {
+	if w == nil {
+		return g
+	}
+	to := make([]io.Writer, 0, len(g.to)+1)
+	return &Glg{
+		core:   g.core,
+		fields: g.fields,
+		groups: g.groups,
+		batch:  g.batch,
+		to:     append(append(to, g.to...), w),
+	}
+}
+
+// To returns derived logger of the global instance which writes its entries to w as well
+func To(w io.Writer) *Glg {
+	return Get().To(w)
+}
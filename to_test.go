@@ -0,0 +1,55 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGlg_To(t *testing.T) {
+	std := new(bytes.Buffer)
+	conn := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(std).SetLineTraceMode(TraceLineNone).DisableTimestamp()
+
+	g.To(conn).With(String("k", "v")).Warn("echoed")
+	g.Info("normal")
+	g.To(nil).Info("nil writer")
+	g.SetLevelMode(ERR, NONE).To(conn).Error("disabled")
+
+	if got := std.String(); !strings.Contains(got, "[WARN]:\techoed\tk=v\n") || !strings.Contains(got, "[INFO]:\tnormal\n") ||
+		!strings.Contains(got, "nil writer") || strings.Contains(got, "disabled") {
+		t.Errorf("level writer = %q", got)
+	}
+	if got := conn.String(); got != "[WARN]:\techoed\tk=v\n" {
+		t.Errorf("To() writer = %q", got)
+	}
+
+	conn.Reset()
+	other := new(bytes.Buffer)
+	g.EnableJSON().To(conn).To(other).Info("json")
+	if got := conn.String(); !strings.Contains(got, `"detail":"json"`) || other.String() != got {
+		t.Errorf("To() writers = %q, %q", got, other.String())
+	}
+}
@@ -0,0 +1,107 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	// TraceparentHeader is the W3C trace context header
+	TraceparentHeader = "traceparent"
+	// B3Header is the single B3 propagation header
+	B3Header = "b3"
+	// B3TraceIDHeader is the trace ID header of the multiple B3 propagation headers
+	B3TraceIDHeader = "X-B3-TraceId"
+	// B3SpanIDHeader is the span ID header of the multiple B3 propagation headers
+	B3SpanIDHeader = "X-B3-SpanId"
+)
+
+// TraceFields returns the trace and span fields of the W3C traceparent or B3 headers,
+// the keys are CloudLoggingTraceKey and CloudLoggingSpanKey. Nil is returned when the headers are missing or malformed
+func TraceFields(h http.Header) []Field {
+	traceID, spanID, ok := parseTraceparent(h.Get(TraceparentHeader))
+	if !ok {
+		traceID, spanID, ok = parseB3(h)
+	}
+	if !ok {
+		return nil
+	}
+	return []Field{
+		String(CloudLoggingTraceKey, traceID),
+		String(CloudLoggingSpanKey, spanID),
+	}
+}
+
+// parseTraceparent parses "version-traceid-parentid-flags", the future versions may append the fields
+func parseTraceparent(v string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return "", "", false
+	}
+	if !isTraceHex(parts[0], 2) || !isTraceHex(parts[1], 32) || !isTraceHex(parts[2], 16) || !isTraceHex(parts[3], 2) {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// parseB3 parses the single "traceid-spanid-sampled-parentspanid" header or the multiple headers,
+// the 64 bit trace ID is left padded to 128 bit
+func parseB3(h http.Header) (traceID, spanID string, ok bool) {
+	if v := strings.TrimSpace(h.Get(B3Header)); v != "" {
+		parts := strings.Split(v, "-")
+		if len(parts) < 2 {
+			// "0", "1" and "d" carry only the sampling decision
+			return "", "", false
+		}
+		traceID, spanID = parts[0], parts[1]
+	} else {
+		traceID, spanID = strings.TrimSpace(h.Get(B3TraceIDHeader)), strings.TrimSpace(h.Get(B3SpanIDHeader))
+	}
+	traceID, spanID = strings.ToLower(traceID), strings.ToLower(spanID)
+	if len(traceID) == 16 {
+		traceID = "0000000000000000" + traceID
+	}
+	if !isTraceHex(traceID, 32) || !isTraceHex(spanID, 16) {
+		return "", "", false
+	}
+	return traceID, spanID, true
+}
+
+// isTraceHex reports s is n lower case hex digits and not all zero
+func isTraceHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	zero := true
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+		zero = zero && c == '0'
+	}
+	return !zero || n == 2
+}
@@ -0,0 +1,109 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTraceFields(t *testing.T) {
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	want := []Field{String(CloudLoggingTraceKey, traceID), String(CloudLoggingSpanKey, spanID)}
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   []Field
+	}{
+		{
+			name:   "traceparent",
+			header: map[string]string{TraceparentHeader: "00-" + traceID + "-" + spanID + "-01"},
+			want:   want,
+		},
+		{
+			name:   "traceparent of future version",
+			header: map[string]string{TraceparentHeader: "01-" + traceID + "-" + spanID + "-01-extra"},
+			want:   want,
+		},
+		{
+			name:   "traceparent of zero trace ID",
+			header: map[string]string{TraceparentHeader: "00-" + strings.Repeat("0", 32) + "-" + spanID + "-01"},
+		},
+		{
+			name:   "malformed traceparent falls back to B3",
+			header: map[string]string{TraceparentHeader: "00-xyz", B3Header: traceID + "-" + spanID + "-1"},
+			want:   want,
+		},
+		{
+			name:   "single B3 of 64 bit trace ID",
+			header: map[string]string{B3Header: traceID[16:] + "-" + spanID},
+			want:   []Field{String(CloudLoggingTraceKey, strings.Repeat("0", 16)+traceID[16:]), String(CloudLoggingSpanKey, spanID)},
+		},
+		{
+			name:   "single B3 of sampling decision only",
+			header: map[string]string{B3Header: "1"},
+		},
+		{
+			name:   "multiple B3",
+			header: map[string]string{B3TraceIDHeader: strings.ToUpper(traceID), B3SpanIDHeader: spanID},
+			want:   want,
+		},
+		{
+			name: "no headers",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := make(http.Header)
+			for k, v := range tt.header {
+				h.Set(k, v)
+			}
+			if got := TraceFields(h); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TraceFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_HTTPLogger_Trace(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).SetLineTraceMode(TraceLineNone)
+	h := g.HTTPLoggerFunc("trace", func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("handled")
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	for _, want := range []string{"trace=4bf92f3577b34da6a3ce929d0e0e4736", "span_id=00f067aa0ba902b7"} {
+		if n := strings.Count(buf.String(), want); n != 2 {
+			t.Errorf("%s is logged %d times, want 2: %q", want, n, buf.String())
+		}
+	}
+}
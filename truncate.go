@@ -0,0 +1,140 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+const (
+	truncatedPrefix = "...(truncated "
+	truncatedSuffix = " bytes)"
+)
+
+// truncated wraps the message argument and truncates its formatted value
+type truncated struct {
+	v   interface{}
+	max int
+}
+
+func (t truncated) Format(f fmt.State, verb rune) {
+	io.WriteString(f, truncate(fmt.Sprintf(directive(f, verb), t.v), t.max))
+}
+
+// truncate cuts str to max bytes and appends truncation marker
+func truncate(str string, max int) string {
+	if max <= 0 || len(str) <= max {
+		return str
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(str[cut]) {
+		cut--
+	}
+	return str[:cut] + truncatedPrefix + strconv.Itoa(len(str)-cut) + truncatedSuffix
+}
+
+// truncateBuffer truncates the bytes written after start to max bytes and appends truncation marker
+func truncateBuffer(b *bytes.Buffer, start, max int) {
+	buf := b.Bytes()[start:]
+	if max <= 0 || len(buf) <= max {
+		return
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(buf[cut]) {
+		cut--
+	}
+	n := len(buf) - cut
+	b.Truncate(start + cut)
+	b.WriteString(truncatedPrefix)
+	b.WriteString(strconv.Itoa(n))
+	b.WriteString(truncatedSuffix)
+}
+
+// truncateArgs wraps the arguments of format to be truncated to max,
+// the arguments of %T and %p and those consumed by the * width or precision are passed as is
+func truncateArgs(format string, val []interface{}, max int) []interface{} {
+	verbs := argVerbs(format, len(val))
+	vals := make([]interface{}, len(val))
+	for i, v := range val {
+		if typeVerb(verbs[i]) {
+			vals[i] = v
+			continue
+		}
+		vals[i] = truncated{v: v, max: max}
+	}
+	return vals
+}
+
+// truncateDetail truncates string like JSON detail values
+func truncateDetail(v interface{}, max int) interface{} {
+	switch t := v.(type) {
+	case string:
+		return truncate(t, max)
+	case []byte:
+		return truncate(string(t), max)
+	case error:
+		return truncate(t.Error(), max)
+	case fmt.Stringer:
+		return truncate(t.String(), max)
+	}
+	return v
+}
+
+// writeMessage writes formatted message to b applying the message size limit
+func (o *switches) writeMessage(b *bytes.Buffer, format string, val ...interface{}) {
+	start := b.Len()
+	if !writeRaw(b, format, val) {
+		fmt.Fprintf(b, format, val...)
+	}
+	if o.maxMessageSize > 0 {
+		truncateBuffer(b, start, o.maxMessageSize)
+	}
+	if o.multiLineMode != MultiLineRaw {
+		foldBuffer(b, start, o.multiLineMode, o.contMarker)
+	}
+}
+
+// SetMaxMessageSize sets the maximum byte size of the formatted message,
+// oversized message is truncated with "...(truncated N bytes)" marker.
+// Zero or negative size disables the limit
+func (g *Glg) SetMaxMessageSize(size int) *Glg {
+	g.setSwitches(func(s *switches) {
+		s.maxMessageSize = size
+	})
+	return g
+}
+
+// SetMaxFieldSize sets the maximum byte size of each message argument,
+// oversized argument is truncated with "...(truncated N bytes)" marker.
+// Zero or negative size disables the limit
+func (g *Glg) SetMaxFieldSize(size int) *Glg {
+	g.setSwitches(func(s *switches) {
+		s.maxFieldSize = size
+	})
+	return g
+}
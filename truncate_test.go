@@ -0,0 +1,125 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_truncate(t *testing.T) {
+	tests := []struct {
+		name string
+		str  string
+		max  int
+		want string
+	}{
+		{
+			name: "no limit",
+			str:  "hello",
+			max:  0,
+			want: "hello",
+		},
+		{
+			name: "short",
+			str:  "hello",
+			max:  5,
+			want: "hello",
+		},
+		{
+			name: "long",
+			str:  "hello glg",
+			max:  5,
+			want: "hello...(truncated 4 bytes)",
+		},
+		{
+			name: "multi byte",
+			str:  "ああ",
+			max:  4,
+			want: "あ...(truncated 3 bytes)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncate(tt.str, tt.max); got != tt.want {
+				t.Errorf("truncate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlg_SetMaxMessageSize(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetMaxMessageSize(8)
+	g.Info(strings.Repeat("a", 20))
+	want := "[INFO]:\taaaaaaaa...(truncated 12 bytes)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Glg.SetMaxMessageSize() = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	g.EnableJSON().Info(strings.Repeat("a", 20))
+	if !strings.Contains(buf.String(), `"detail":"aaaaaaaa...(truncated 12 bytes)"`) {
+		t.Errorf("Glg.SetMaxMessageSize() json = %s", buf.String())
+	}
+}
+
+func TestGlg_SetMaxFieldSize(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetMaxFieldSize(3)
+	g.Infof("user=%s id=%d", "abcdef", 12345)
+	want := "[INFO]:\tuser=abc...(truncated 3 bytes) id=123...(truncated 2 bytes)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Glg.SetMaxFieldSize() = %q, want %q", got, want)
+	}
+
+	buf.Reset()
+	g.EnableJSON().Info("abcdef", 1)
+	if !strings.Contains(buf.String(), `"detail":["abc...(truncated 3 bytes)",1]`) {
+		t.Errorf("Glg.SetMaxFieldSize() json = %s", buf.String())
+	}
+}
+
+func TestGlg_SetMaxFieldSize_TypeVerb(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetMaxFieldSize(3)
+	g.Infof("%T=%s", "abcdef", "abcdef")
+	if got, want := buf.String(), "[INFO]:\tstring=abc...(truncated 3 bytes)\n"; got != want {
+		t.Errorf("Glg.SetMaxFieldSize() %%T = %q, want %q", got, want)
+	}
+	buf.Reset()
+	g.EnableJSON().Infof("%T", 12345)
+	if !strings.Contains(buf.String(), `"detail":"int"`) {
+		t.Errorf("Glg.SetMaxFieldSize() json %%T = %s", buf.String())
+	}
+}
+
+func TestGlg_SetMaxFieldSize_StarWidth(t *testing.T) {
+	buf := new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp().SetMaxFieldSize(100)
+	g.Infof("[%*d]", 5, 3)
+	if got, want := buf.String(), "[INFO]:\t[    3]\n"; got != want {
+		t.Errorf("Glg.SetMaxFieldSize() %%* = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,100 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultInvalidUTF8 is the replacement of the invalid UTF-8 written by the JSON output by default
+const DefaultInvalidUTF8 = "\uFFFD"
+
+// SetInvalidUTF8 replaces each run of the invalid UTF-8 bytes of the format, the string arguments
+// and the string fields with replacement in both the text and JSON outputs, e.g. "?", or "" to drop them.
+// By default the text output keeps the bytes as they are and the JSON output writes DefaultInvalidUTF8 for each byte
+func (g *Glg) SetInvalidUTF8(replacement string) *Glg {
+	g.setSwitches(func(s *switches) {
+		s.invalidUTF8 = &replacement
+	})
+	return g
+}
+
+// SetInvalidUTF8 sets the replacement of the invalid UTF-8 of the global instance
+func SetInvalidUTF8(replacement string) *Glg {
+	return Get().SetInvalidUTF8(replacement)
+}
+
+// validUTF8 returns the format, arguments and fields whose invalid UTF-8 is replaced with repl,
+// the arguments and fields are copied only when they are replaced
+func validUTF8(repl, format string, val []interface{}, fields []Field) (string, []interface{}, []Field) {
+	if !utf8.ValidString(format) {
+		format = strings.ToValidUTF8(format, repl)
+	}
+	copied := false
+	for i, v := range val {
+		s, ok := v.(string)
+		if !ok || utf8.ValidString(s) {
+			continue
+		}
+		if !copied {
+			val = append([]interface{}(nil), val...)
+			copied = true
+		}
+		val[i] = strings.ToValidUTF8(s, repl)
+	}
+	return format, val, validFields(repl, fields)
+}
+
+// validFields returns the fields whose keys and string values are valid UTF-8, the groups are checked recursively
+func validFields(repl string, fields []Field) []Field {
+	copied := false
+	for i, f := range fields {
+		changed := false
+		if !utf8.ValidString(f.Key) {
+			f.Key, changed = strings.ToValidUTF8(f.Key, repl), true
+		}
+		switch f.kind {
+		case fieldString:
+			if !utf8.ValidString(f.str) {
+				f.str, changed = strings.ToValidUTF8(f.str, repl), true
+			}
+		case fieldGroup:
+			if fs := f.iface.([]Field); len(fs) != 0 {
+				if vf := validFields(repl, fs); &vf[0] != &fs[0] {
+					f.iface, changed = vf, true
+				}
+			}
+		}
+		if !changed {
+			continue
+		}
+		if !copied {
+			fields = append([]Field(nil), fields...)
+			copied = true
+		}
+		fields[i] = f
+	}
+	return fields
+}
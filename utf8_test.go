@@ -0,0 +1,58 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGlg_SetInvalidUTF8(t *testing.T) {
+	tests := []struct {
+		name string
+		repl *string
+		json bool
+		want string
+	}{
+		{name: "text default keeps the bytes", want: "[INFO]:\ta\xffb \xfe\tk=x\xff\n"},
+		{name: "text replaced", repl: new(string), want: "[INFO]:\tab \tk=x\n"},
+		{name: "JSON default", json: true, want: `{"level":"INFO","detail":["a\ufffdb","\ufffd"],"fields":{"k":"x\ufffd"}}` + "\n"},
+		{name: "JSON replaced", repl: new(string), json: true, want: `{"level":"INFO","detail":["ab",""],"fields":{"k":"x"}}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			g := New().SetMode(WRITER).SetWriter(buf).DisableTimestamp()
+			if tt.json {
+				g.EnableJSON()
+			}
+			if tt.repl != nil {
+				g.SetInvalidUTF8(*tt.repl)
+			}
+			g.Info("a\xffb", "\xfe", String("k", "x\xff"))
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package glg can quickly output that are colored and leveled logs with simple syntax
+package glg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// WriterGroup is the named set of writers receiving every entry written to it.
+// The members are swapped atomically by Swap, so the levels routed to the group move to the new sinks at once,
+// e.g. for the blue/green migration of the sink without reconfiguring the levels
+type WriterGroup struct {
+	name    string
+	mu      sync.Mutex
+	mws     []WriterMiddleware
+	members atomic.Pointer[[]io.Writer]
+}
+
+// Name returns the name of the group
+func (wg *WriterGroup) Name() string {
+	return "group:" + wg.name
+}
+
+// Use sets the middleware wrapping the members set by the following Swap, the current members are kept as they are
+// so that the stream being written is not mixed with the transformed one
+func (wg *WriterGroup) Use(mws ...WriterMiddleware) *WriterGroup {
+	wg.mu.Lock()
+	wg.mws = append([]WriterMiddleware(nil), mws...)
+	wg.mu.Unlock()
+	return wg
+}
+
+// Members returns the current members of the group, wrapped by the middleware of Use
+func (wg *WriterGroup) Members() []io.Writer {
+	if ws := wg.members.Load(); ws != nil {
+		return append([]io.Writer(nil), *ws...)
+	}
+	return nil
+}
+
+// Swap replaces the members of the group by ws and returns the former members,
+// which are no longer written to when Swap returns except for the entries being written and are not closed by the group.
+// The members are wrapped by the middleware of Use, so the former members are returned wrapped to be closed by the caller
+func (wg *WriterGroup) Swap(ws ...io.Writer) (old []io.Writer) {
+	wg.mu.Lock()
+	defer wg.mu.Unlock()
+	members := make([]io.Writer, 0, len(ws))
+	for _, w := range ws {
+		if w != nil {
+			members = append(members, WrapWriter(w, wg.mws...))
+		}
+	}
+	if prev := wg.members.Swap(&members); prev != nil {
+		return *prev
+	}
+	return nil
+}
+
+// Write implements io.Writer, the entry is written to all the members even when some of them fail
+func (wg *WriterGroup) Write(p []byte) (int, error) {
+	ws := wg.members.Load()
+	if ws == nil || len(*ws) == 0 {
+		return len(p), nil
+	}
+	if len(*ws) == 1 {
+		return (*ws)[0].Write(p)
+	}
+	return fanout(*ws).Write(p)
+}
+
+// CloseContext closes the current members of the group, it is called by Shutdown
+func (wg *WriterGroup) CloseContext(ctx context.Context) error {
+	var errs []error
+	for _, w := range wg.Members() {
+		if err := closeWriter(ctx, w); err != nil {
+			errs = append(errs, writeError(w, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writerGroup returns the group named name, the empty group is created when it does not exist
+func (g *Glg) writerGroup(name string) *WriterGroup {
+	wg, _ := g.writerGroups.LoadOrStore(name, &WriterGroup{name: name})
+	return wg.(*WriterGroup)
+}
+
+// WriterGroup returns the group named name, or nil when it is neither added by AddWriterGroup nor routed by RouteLevelToGroup
+func (g *Glg) WriterGroup(name string) *WriterGroup {
+	if wg, ok := g.writerGroups.Load(name); ok {
+		return wg.(*WriterGroup)
+	}
+	return nil
+}
+
+// AddWriterGroup sets ws to the members of the group named name, e.g. AddWriterGroup("audit", file, siem).
+// The members of the existing group are replaced like SwapWriterGroup
+func (g *Glg) AddWriterGroup(name string, ws ...io.Writer) *Glg {
+	g.writerGroup(name).Swap(ws...)
+	return g
+}
+
+// SwapWriterGroup replaces the members of the group named name by ws atomically and returns the former members,
+// so that they are flushed or closed by the caller after the levels of the group moved to ws
+func (g *Glg) SwapWriterGroup(name string, ws ...io.Writer) (old []io.Writer) {
+	return g.writerGroup(name).Swap(ws...)
+}
+
+// RouteLevelToGroup adds the group named name to the writers of the level like AddLevelWriter.
+// The group is created empty when it is not added yet, and the current members of the group are closed by Shutdown
+func (g *Glg) RouteLevelToGroup(lv LEVEL, name string) *Glg {
+	return g.AddLevelWriter(lv, g.writerGroup(name))
+}
+
+// AddWriterGroup sets ws to the members of the group named name of the global instance
+func AddWriterGroup(name string, ws ...io.Writer) *Glg {
+	return Get().AddWriterGroup(name, ws...)
+}
+
+// SwapWriterGroup replaces the members of the group named name of the global instance and returns the former members
+func SwapWriterGroup(name string, ws ...io.Writer) (old []io.Writer) {
+	return Get().SwapWriterGroup(name, ws...)
+}
+
+// RouteLevelToGroup adds the group named name to the writers of the level of the global instance
+func RouteLevelToGroup(lv LEVEL, name string) *Glg {
+	return Get().RouteLevelToGroup(lv, name)
+}
@@ -0,0 +1,117 @@
+// MIT License
+//
+// Copyright (c) 2019 kpango (Yusuke Kato)
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package glg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestGlg_WriterGroup(t *testing.T) {
+	blue, green, siem := new(closeBuffer), new(closeBuffer), new(bytes.Buffer)
+	g := New().SetMode(WRITER).SetLineTraceMode(TraceLineNone).DisableTimestamp().
+		RouteLevelToGroup(WARN, "audit").
+		RouteLevelToGroup(ERR, "audit")
+	if wg := g.WriterGroup("audit"); wg == nil || len(wg.Members()) != 0 {
+		t.Fatalf("WriterGroup() = %v, want the empty group", wg)
+	}
+	if g.WriterGroup("none") != nil {
+		t.Error("WriterGroup() of the unknown name is not nil")
+	}
+	g.Warn("dropped")
+
+	g.AddWriterGroup("audit", blue, siem, nil)
+	g.Warn("warn")
+	g.Info("info")
+	old := g.SwapWriterGroup("audit", green, siem)
+	if len(old) != 2 || old[0] != io.Writer(blue) {
+		t.Errorf("SwapWriterGroup() = %v", old)
+	}
+	g.Error("error")
+
+	if got := blue.String(); got != "[WARN]:\twarn\n" {
+		t.Errorf("blue = %q", got)
+	}
+	if got := green.String(); got != "[ERR]:\terror\n" {
+		t.Errorf("green = %q", got)
+	}
+	if got := siem.String(); got != "[WARN]:\twarn\n[ERR]:\terror\n" {
+		t.Errorf("siem = %q", got)
+	}
+	if err := g.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if blue.closed != 0 || green.closed != 1 {
+		t.Errorf("Shutdown() closed blue %d, green %d times", blue.closed, green.closed)
+	}
+}
+
+func TestWriterGroup_Write(t *testing.T) {
+	errFull := errors.New("disk full")
+	buf := new(bytes.Buffer)
+	wg := new(WriterGroup)
+	wg.Swap(namedErrWriter{name: "disk", err: errFull}, buf)
+	var we *WriteError
+	if n, err := wg.Write([]byte("a")); n != 1 || !errors.As(err, &we) || we.Writer != "disk" || buf.String() != "a" {
+		t.Errorf("Write() = %d, %v", n, err)
+	}
+	wg.Swap(namedErrWriter{name: "disk", err: errFull})
+	if _, err := wg.Write([]byte("a")); err != errFull {
+		t.Errorf("Write() of the single member = %v, want the error as is", err)
+	}
+	wg.Swap()
+	if n, err := wg.Write([]byte("a")); n != 1 || err != nil {
+		t.Errorf("Write() of no member = %d, %v", n, err)
+	}
+}
+
+func TestWriterGroup_SwapConcurrent(t *testing.T) {
+	a, b := new(lockedBuffer), new(lockedBuffer)
+	g := New().SetMode(WRITER).SetLineTraceMode(TraceLineNone).RouteLevelToGroup(INFO, "g").AddWriterGroup("g", a)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				g.Info("entry")
+			}
+		}()
+	}
+	for j := 0; j < 100; j++ {
+		if j%2 == 0 {
+			g.SwapWriterGroup("g", b)
+		} else {
+			g.SwapWriterGroup("g", a)
+		}
+	}
+	wg.Wait()
+	if n := strings.Count(a.String(), "entry") + strings.Count(b.String(), "entry"); n != 400 {
+		t.Errorf("entries = %d, want 400", n)
+	}
+}